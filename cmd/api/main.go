@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"g3-g65-bsp/config"
 	"g3-g65-bsp/delivery/controller"
 	"g3-g65-bsp/delivery/route"
@@ -13,6 +14,11 @@ import (
 	"g3-g65-bsp/infrastructure/image"
 	"g3-g65-bsp/repository"
 	"g3-g65-bsp/usecase"
+	"g3-g65-bsp/utils"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/didip/tollbooth/v7"
@@ -40,17 +46,46 @@ func main() {
 	passwordResetRepo := repository.NewPasswordReset(db)
 	emailService := email.NewEmailService()
 	jwt := auth.NewJWT(accessSecret, refreshSecret, accessExpiry, refreshExpiry)
-	authUsecase := usecase.NewAuthUsecase(authRepo, tokenRepo, jwt, unActiveUserRepo, emailService, passwordResetRepo)
+	auditRepo := repository.NewAuditRepository(db)
+	// Revoked access tokens and per-user watermarks, checked on every authenticated request
+	revocationRepo := repository.NewTokenRevocationRepository(db, cache.NewInMemoryCache(5*time.Minute, 10*time.Minute), accessExpiry)
+	authUsecase := usecase.NewAuthUsecase(authRepo, tokenRepo, jwt, unActiveUserRepo, emailService, passwordResetRepo, auditRepo, revocationRepo)
 	authController := controller.NewAuthController(authUsecase, jwt)
 
 	// Initialize repository, usecase, controller for blogs
 	repoCacheService := cache.NewInMemoryCache(5*time.Minute, 10*time.Minute)
 	blogRepo := repository.NewBlogRepository(blogCollection, repoCacheService)
-	blogUsecase := usecase.NewBlogUsecase(blogRepo, authRepo)
+	blogRevisionRepo := repository.NewBlogRevisionRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	reactionRepo := repository.NewReactionRepository(db)
+	statsRepo := repository.NewBlogStatsRepository(db)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	cursorCodec := utils.NewCursorCodec(config.AppConfig.CursorSecret)
+	viewCounter := usecase.NewViewCounter(blogRepo, statsRepo, config.AppConfig.ViewDedupWindow, config.AppConfig.ViewFlushInterval, nil)
+	blogUsecase := usecase.NewBlogUsecase(blogRepo, authRepo, blogRevisionRepo, commentRepo, reactionRepo, statsRepo, bookmarkRepo, cursorCodec, viewCounter, repoCacheService)
 	blogController := controller.NewBlogController(blogUsecase)
 
+	// Initialize Cache
+	cacheService := cache.NewInMemoryCache(5*time.Minute, 10*time.Minute)
+
+	// Publish scheduled drafts in the background
+	blogPublisher := usecase.NewBlogPublisher(blogRepo, cacheService, time.Minute, nil)
+	go blogPublisher.Start(context.Background())
+
+	// Rescore blogs for the trending sort in the background
+	trendingRanker := usecase.NewTrendingRanker(statsRepo, cacheService, config.AppConfig.TrendingHalfLife, 10*time.Minute, nil)
+	go trendingRanker.Start(context.Background())
+
+	// Write counted views in batches; the last batch is written once the server has stopped
+	viewCtx, stopViews := context.WithCancel(context.Background())
+	viewsDone := make(chan struct{})
+	go func() {
+		viewCounter.Start(viewCtx)
+		close(viewsDone)
+	}()
+
 	// Initialize interaction usecase and controller
-	interactionUsecase := usecase.NewInteractionUsecase(blogRepo, authRepo)
+	interactionUsecase := usecase.NewInteractionUsecase(blogRepo, authRepo, commentRepo, reactionRepo, statsRepo, config.AppConfig.ReactionTypes)
 	interactionController := controller.NewInteractionController(interactionUsecase)
 
 	// Initialize OAuth usecase and controller
@@ -60,12 +95,9 @@ func main() {
 	// Initialize repository, usecase, controller for user management
 	imageUpload := image.NewCloudinaryService()
 	userRepo := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUsecase(userRepo, imageUpload)
+	userUsecase := usecase.NewUserUsecase(userRepo, imageUpload, revocationRepo)
 	userController := controller.NewUserController(userUsecase)
 
-	// Initialize Cache
-	cacheService := cache.NewInMemoryCache(5*time.Minute, 10*time.Minute)
-
 	aiservice := ai.NewGeminiService()
 	aiusecase := usecase.NewAIUsecaseImpl(aiservice)
 	aicontroller := controller.NewAIcontroller(aiusecase)
@@ -74,25 +106,63 @@ func main() {
     r := route.NewRouter()
 	contentCreationLimiter := tollbooth.NewLimiter(0.5, &limiter.ExpirableOptions{DefaultExpirationTTL: time.Hour})
 	contentReadLimiter := tollbooth.NewLimiter(1, &limiter.ExpirableOptions{DefaultExpirationTTL: time.Second})
-	route.BlogRouter(r, blogController, jwt, &cacheService, contentCreationLimiter, contentReadLimiter)
-	route.InteractionRouter(r, interactionController, jwt, contentCreationLimiter)
+	route.BlogRouter(r, blogController, jwt, revocationRepo, &cacheService, contentCreationLimiter, contentReadLimiter)
+	route.InteractionRouter(r, interactionController, jwt, revocationRepo, contentCreationLimiter, contentReadLimiter)
+
+	// Following authors and tags, and the home feed
+	followUsecase := usecase.NewFollowUsecase(repository.NewFollowRepository(db), authRepo, blogUsecase)
+	route.FollowRouter(r, controller.NewFollowController(followUsecase), jwt, revocationRepo, contentCreationLimiter, contentReadLimiter)
+
+	// Bookmarks and reading lists
+	bookmarkUsecase := usecase.NewBookmarkUsecase(bookmarkRepo, blogRepo)
+	route.BookmarkRouter(r, controller.NewBookmarkController(bookmarkUsecase), jwt, revocationRepo, contentCreationLimiter, contentReadLimiter)
+
+	// Per-post analytics and the author dashboard
+	statsController := controller.NewStatsController(usecase.NewStatsUsecase(blogRepo, statsRepo))
+	route.StatsRouter(r, statsController, jwt, revocationRepo, contentReadLimiter)
+
+	// Public syndication feeds
+	feedController := controller.NewFeedController(blogUsecase, cacheService, config.AppConfig.SiteURL)
+	route.FeedRouter(r, feedController, contentReadLimiter)
+
+	// Public sitemap, streamed straight from the blogs collection
+	sitemapRepo := repository.NewSitemapRepository(blogCollection)
+	sitemapUsecase := usecase.NewSitemapUsecase(sitemapRepo)
+	sitemapController := controller.NewSitemapController(sitemapUsecase, config.AppConfig.SiteURL)
+	route.SitemapRouter(r, sitemapController, contentReadLimiter)
 
 	// Register authentication routes
 	authLimiter := tollbooth.NewLimiter(0.16, &limiter.ExpirableOptions{DefaultExpirationTTL: time.Minute})
-	route.AuthRouter(r, authController, jwt, authLimiter)
+	route.AuthRouter(r, authController, jwt, revocationRepo, authLimiter)
 
 	// Register OAuth routes
 	route.OAuthRouter(r, oauthController, authLimiter)
 
 	// user management routes
-	route.UserRouter(r, userController, jwt, contentCreationLimiter, contentReadLimiter)
+	route.UserRouter(r, userController, jwt, revocationRepo, contentCreationLimiter, contentReadLimiter)
 
 	//ai features routes
-	route.AIRouter(r, aicontroller, jwt, contentCreationLimiter)
+	route.AIRouter(r, aicontroller, jwt, revocationRepo, contentCreationLimiter)
 
 	// Start the server on port 8080
-	// if err := r.Run("localhost:8080"); err != nil {
-	if err := r.Run("0.0.0.0:8080"); err != nil {
-		panic("Failed to start server: " + err.Error())
+	srv := &http.Server{Addr: "0.0.0.0:8080", Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			panic("Failed to start server: " + err.Error())
+		}
+	}()
+
+	// Stop taking requests on SIGINT/SIGTERM, then let the view counter write what it still holds
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	infrastructure.Log.Println("Shutting down server...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		infrastructure.Log.Printf("Server shutdown failed: %v", err)
 	}
+	stopViews()
+	<-viewsDone
 }
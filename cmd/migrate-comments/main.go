@@ -0,0 +1,26 @@
+// Command migrate-comments moves comments embedded in blog documents into the comments collection.
+// It only needs to run once after upgrading, and is safe to run again if it was interrupted.
+package main
+
+import (
+	"context"
+	"g3-g65-bsp/config"
+	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/database"
+	"g3-g65-bsp/repository"
+)
+
+func main() {
+	infrastructure.InitLogger()
+	config.LoadConfig()
+	db := database.InitMongoDB().Database(config.AppConfig.DbName)
+
+	// creates the comments indexes before the collection fills up
+	repository.NewCommentRepository(db)
+
+	result, err := repository.MigrateEmbeddedComments(context.Background(), db)
+	if err != nil {
+		infrastructure.Log.Fatalf("Migrating comments failed after %d blogs: %v", result.Blogs, err)
+	}
+	infrastructure.Log.Printf("Moved %d comments out of %d blogs", result.Comments, result.Blogs)
+}
@@ -0,0 +1,26 @@
+// Command migrate-reactions moves the likes and dislikes embedded in blog documents into the reactions
+// collection. It only needs to run once after upgrading, and is safe to run again if it was interrupted.
+package main
+
+import (
+	"context"
+	"g3-g65-bsp/config"
+	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/database"
+	"g3-g65-bsp/repository"
+)
+
+func main() {
+	infrastructure.InitLogger()
+	config.LoadConfig()
+	db := database.InitMongoDB().Database(config.AppConfig.DbName)
+
+	// creates the reactions indexes, the unique one being what makes reruns safe
+	repository.NewReactionRepository(db)
+
+	result, err := repository.MigrateEmbeddedReactions(context.Background(), db)
+	if err != nil {
+		infrastructure.Log.Fatalf("Migrating reactions failed after %d blogs: %v", result.Blogs, err)
+	}
+	infrastructure.Log.Printf("Moved %d reactions out of %d blogs", result.Reactions, result.Blogs)
+}
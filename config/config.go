@@ -1,8 +1,12 @@
 package config
 
 import (
+	"g3-g65-bsp/domain"
 	"log"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -18,6 +22,12 @@ type Config struct {
 	GoogleClientID     string
 	GoogleClientSecret string
 	OauthStateString    string
+	CursorSecret       string
+	SiteURL            string
+	ReactionTypes      []string
+	ViewDedupWindow    time.Duration
+	ViewFlushInterval  time.Duration
+	TrendingHalfLife   time.Duration
 }
 
 // AppConfig is the global config instance
@@ -42,6 +52,26 @@ func LoadConfig() {
 	googleClientSecret := os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET")
 	oauthStateString := os.Getenv("OAUTH_STATE_STRING")
 
+	// Listing cursors only need to be tamper-proof, so they may share the access token secret
+	cursorSecret := os.Getenv("CURSOR_SECRET")
+	if cursorSecret == "" {
+		cursorSecret = accessSecret
+	}
+
+	// Public base URL used for absolute links in feeds and sitemaps. It is required rather than taken
+	// from the request, since the Host header is client-supplied and those responses are cached.
+	siteURL := parseSiteURL(os.Getenv("SITE_URL"))
+
+	// Comma-separated reactions users can leave, e.g. "👍,❤️,🎉,🤔"
+	reactionTypes := parseReactionTypes(os.Getenv("REACTION_TYPES"))
+
+	// A reader is counted once per blog within the window; counted views are written every flush interval
+	viewDedupWindow := parseDurationOr("VIEW_DEDUP_WINDOW", os.Getenv("VIEW_DEDUP_WINDOW"), 30*time.Minute)
+	viewFlushInterval := parseDurationOr("VIEW_FLUSH_INTERVAL", os.Getenv("VIEW_FLUSH_INTERVAL"), 10*time.Second)
+
+	// Activity counts half as much towards a blog's trending score for every half-life that has passed
+	trendingHalfLife := parseDurationOr("TRENDING_HALF_LIFE", os.Getenv("TRENDING_HALF_LIFE"), 24*time.Hour)
+
 	AppConfig = &Config{
 		DbName 			:   dbName,
 		MongoURI		:mongoURI,
@@ -52,6 +82,12 @@ func LoadConfig() {
 		GoogleClientID:     googleClientID,
 		GoogleClientSecret: googleClientSecret,
 		OauthStateString:    oauthStateString,
+		CursorSecret:       cursorSecret,
+		SiteURL:            siteURL,
+		ReactionTypes:      reactionTypes,
+		ViewDedupWindow:    viewDedupWindow,
+		ViewFlushInterval:  viewFlushInterval,
+		TrendingHalfLife:   trendingHalfLife,
 	}
 }
 
@@ -63,3 +99,44 @@ func parseDuration(value string) time.Duration {
 	}
 	return duration
 }
+
+// parseDurationOr parses the named optional duration, using fallback when it is unset
+func parseDurationOr(name, value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Fatalf("Invalid duration(%s) value: %s", name, value)
+	}
+	return duration
+}
+
+// parseSiteURL checks that the site URL is an absolute http(s) URL and drops its trailing slash
+func parseSiteURL(value string) string {
+	u, err := url.Parse(value)
+	if value == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		log.Fatalf("Invalid site URL(SITE_URL) value: %q, an absolute URL such as https://blog.example.com is required", value)
+	}
+	return strings.TrimSuffix(value, "/")
+}
+
+// parseReactionTypes splits a comma-separated list of reaction types, falling back to the defaults.
+// Types become field names in MongoDB, so they may not contain dots or start with $.
+func parseReactionTypes(value string) []string {
+	var types []string
+	for _, reactionType := range strings.Split(value, ",") {
+		reactionType = strings.TrimSpace(reactionType)
+		if reactionType == "" || slices.Contains(types, reactionType) {
+			continue
+		}
+		if strings.Contains(reactionType, ".") || strings.HasPrefix(reactionType, "$") {
+			log.Fatalf("Invalid reaction type(REACTION_TYPES) value: %s", reactionType)
+		}
+		types = append(types, reactionType)
+	}
+	if len(types) == 0 {
+		return domain.DefaultReactionTypes
+	}
+	return types
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"g3-g65-bsp/domain"
 	"os"
 	"testing"
 	"time"
@@ -19,6 +20,9 @@ func TestLoadConfig(t *testing.T) {
 	os.Setenv("GOOGLE_OAUTH_CLIENT_ID", "google_id")
 	os.Setenv("GOOGLE_OAUTH_CLIENT_SECRET", "google_secret")
 	os.Setenv("OAUTH_STATE_STRING", "random_string")
+	os.Setenv("SITE_URL", "https://blog.example.com")
+	os.Setenv("REACTION_TYPES", "👍, ❤️,,🎉,👍")
+	os.Setenv("VIEW_DEDUP_WINDOW", "1h")
 
 	// Clean up environment variables after the test
 	defer func() {
@@ -31,6 +35,9 @@ func TestLoadConfig(t *testing.T) {
 		os.Unsetenv("GOOGLE_OAUTH_CLIENT_ID")
 		os.Unsetenv("GOOGLE_OAUTH_CLIENT_SECRET")
 		os.Unsetenv("OAUTH_STATE_STRING")
+		os.Unsetenv("SITE_URL")
+		os.Unsetenv("REACTION_TYPES")
+		os.Unsetenv("VIEW_DEDUP_WINDOW")
 	}()
 
 	// Load the configuration
@@ -47,4 +54,21 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "google_id", AppConfig.GoogleClientID)
 	assert.Equal(t, "google_secret", AppConfig.GoogleClientSecret)
 	assert.Equal(t, "random_string", AppConfig.OauthStateString)
+	assert.Equal(t, "access_secret", AppConfig.CursorSecret)
+	assert.Equal(t, "https://blog.example.com", AppConfig.SiteURL)
+	assert.Equal(t, []string{"👍", "❤️", "🎉"}, AppConfig.ReactionTypes)
+	assert.Equal(t, time.Hour, AppConfig.ViewDedupWindow)
+	assert.Equal(t, 10*time.Second, AppConfig.ViewFlushInterval)
+	assert.Equal(t, 24*time.Hour, AppConfig.TrendingHalfLife)
+}
+
+func TestParseSiteURL(t *testing.T) {
+	assert.Equal(t, "https://blog.example.com", parseSiteURL("https://blog.example.com/"))
+	assert.Equal(t, "http://localhost:8080/blog", parseSiteURL("http://localhost:8080/blog"))
+}
+
+func TestParseReactionTypes(t *testing.T) {
+	assert.Equal(t, domain.DefaultReactionTypes, parseReactionTypes(""))
+	assert.Equal(t, domain.DefaultReactionTypes, parseReactionTypes(" , "))
+	assert.Equal(t, []string{"like", "love"}, parseReactionTypes("like,love"))
 }
@@ -1,6 +1,8 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"g3-g65-bsp/domain"
 	"g3-g65-bsp/infrastructure/auth"
 	"net/http"
@@ -27,6 +29,8 @@ type UserProfileDTO struct {
 	Bio               string `json:"bio" validate:"min=3,max=500"`
 	ProfilePictureURL string `json:"profile_picture_url"`
 	ContactInfo       string `json:"contact_information" validate:"max=100"`
+	FollowerCount     int    `json:"followers_count"`
+	FollowingCount    int    `json:"following_count"`
 }
 
 // UnactivatedUserDTO represents a user who has not yet activated their account
@@ -88,6 +92,8 @@ func ConvertToUserDTO(u *domain.User) *UserDTO {
 			Bio:               u.Profile.Bio,
 			ProfilePictureURL: u.Profile.ProfilePictureURL,
 			ContactInfo:       u.Profile.ContactInfo,
+			FollowerCount:     u.Profile.FollowerCount,
+			FollowingCount:    u.Profile.FollowingCount,
 		},
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
@@ -135,6 +141,17 @@ type PasswordResetRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
 
+// SessionDTO is a device the user is logged in on
+type SessionDTO struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
 type AuthController struct {
 	authUsecase domain.AuthUsecase
 	jwt         *auth.JWT
@@ -168,7 +185,7 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, expiresIn, user, err := c.authUsecase.Login(ctx, req.Email, req.Password)
+	accessToken, refreshToken, expiresIn, user, err := c.authUsecase.Login(deviceContext(ctx), req.Email, req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
@@ -269,7 +286,7 @@ func (c *AuthController) RefreshAccessToken(ctx *gin.Context) {
 		refreshToken = req.RefreshToken
 	}
 
-	accessToken, refreshTokenNew, expiresIn, err := c.authUsecase.RefreshTokens(ctx.Request.Context(), refreshToken)
+	accessToken, refreshTokenNew, expiresIn, err := c.authUsecase.RefreshTokens(deviceContext(ctx), refreshToken)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -295,7 +312,8 @@ func (c *AuthController) Logout(ctx *gin.Context) {
 		refreshToken = req.RefreshToken
 	}
 
-	if err := c.authUsecase.Logout(ctx.Request.Context(), refreshToken); err != nil {
+	err := c.authUsecase.Logout(ctx.Request.Context(), refreshToken, ctx.GetString("token_id"), ctx.GetTime("token_expires_at"))
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "logout failed"})
 		return
 	}
@@ -324,3 +342,44 @@ func (c *AuthController) LogoutAll(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "logged out from all devices"})
 }
+
+// ListSessions lists the devices the caller is logged in on, most recently used first
+func (c *AuthController) ListSessions(ctx *gin.Context) {
+	sessions, err := c.authUsecase.ListSessions(ctx.Request.Context(), ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	dtos := make([]*SessionDTO, len(sessions))
+	for i, session := range sessions {
+		dtos[i] = &SessionDTO{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": dtos})
+}
+
+// RevokeSession logs the caller out on one device, such as a lost laptop
+func (c *AuthController) RevokeSession(ctx *gin.Context) {
+	err := c.authUsecase.RevokeSession(ctx.Request.Context(), ctx.GetString("user_id"), ctx.Param("id"))
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "session revoked"})
+}
+
+// deviceContext records the device a request came from, for the session a login or refresh stores
+func deviceContext(ctx *gin.Context) context.Context {
+	return domain.WithUserAgent(domain.WithClientIP(ctx.Request.Context(), ctx.ClientIP()), ctx.Request.UserAgent())
+}
@@ -55,8 +55,8 @@ func (m *MockAuthUsecase) RefreshTokens(ctx context.Context, refreshToken string
 	return args.String(0), args.String(1), args.Int(2), args.Error(3)
 }
 
-func (m *MockAuthUsecase) Logout(ctx context.Context, refreshToken string) error {
-	args := m.Called(ctx, refreshToken)
+func (m *MockAuthUsecase) Logout(ctx context.Context, refreshToken, accessTokenID string, accessExpiresAt time.Time) error {
+	args := m.Called(ctx, refreshToken, accessTokenID, accessExpiresAt)
 	return args.Error(0)
 }
 
@@ -65,6 +65,19 @@ func (m *MockAuthUsecase) LogoutAll(ctx context.Context, userID string) error {
 	return args.Error(0)
 }
 
+func (m *MockAuthUsecase) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Session), args.Error(1)
+}
+
+func (m *MockAuthUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
 func TestAuthController_Register(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -138,9 +151,11 @@ func TestAuthController_Login(t *testing.T) {
 		jsonBody, _ := json.Marshal(reqBody)
 		c.Request, _ = http.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonBody))
 		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.Header.Set("User-Agent", "curl/8.6.0")
 
 		user := &domain.User{ID: "1", Email: "test@example.com", Username: "testuser", CreatedAt: time.Now(), UpdatedAt: time.Now()}
-		mockAuthUsecase.On("Login", mock.Anything, reqBody.Email, reqBody.Password).Return("access_token", "refresh_token", 3600, user, nil)
+		fromDevice := mock.MatchedBy(func(ctx context.Context) bool { return domain.UserAgent(ctx) == "curl/8.6.0" })
+		mockAuthUsecase.On("Login", fromDevice, reqBody.Email, reqBody.Password).Return("access_token", "refresh_token", 3600, user, nil)
 
 		authController.Login(c)
 
@@ -183,3 +198,80 @@ func TestAuthController_Login(t *testing.T) {
 		mockAuthUsecase.AssertExpectations(t)
 	})
 }
+
+func TestAuthController_ListSessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockAuthUsecase := new(MockAuthUsecase)
+		authController := NewAuthController(mockAuthUsecase, nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/auth/sessions", nil)
+		c.Set("user_id", "user-1")
+		sessions := []*domain.Session{{ID: "family", UserID: "user-1", DeviceName: "Chrome on macOS", IP: "203.0.113.7"}}
+		mockAuthUsecase.On("ListSessions", mock.Anything, "user-1").Return(sessions, nil)
+
+		authController.ListSessions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []SessionDTO `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "family", response.Data[0].ID)
+		assert.Equal(t, "Chrome on macOS", response.Data[0].DeviceName)
+		mockAuthUsecase.AssertExpectations(t)
+	})
+}
+
+func TestAuthController_RevokeSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, tc := range map[string]struct {
+		err  error
+		code int
+	}{
+		"success":   {nil, http.StatusOK},
+		"not found": {domain.ErrSessionNotFound, http.StatusNotFound},
+		"failure":   {errors.New("db down"), http.StatusInternalServerError},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockAuthUsecase := new(MockAuthUsecase)
+			authController := NewAuthController(mockAuthUsecase, nil)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodDelete, "/auth/sessions/family", nil)
+			c.Params = gin.Params{{Key: "id", Value: "family"}}
+			c.Set("user_id", "user-1")
+			mockAuthUsecase.On("RevokeSession", mock.Anything, "user-1", "family").Return(tc.err)
+
+			authController.RevokeSession(c)
+
+			assert.Equal(t, tc.code, w.Code)
+			mockAuthUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthController_Logout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("revokes the access token used", func(t *testing.T) {
+		mockAuthUsecase := new(MockAuthUsecase)
+		authController := NewAuthController(mockAuthUsecase, nil)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/auth/logout", nil)
+		c.Request.Header.Set("X-Refresh-Token", "refresh")
+		expiresAt := time.Now().Add(15 * time.Minute)
+		c.Set("token_id", "jti")
+		c.Set("token_expires_at", expiresAt)
+		mockAuthUsecase.On("Logout", mock.Anything, "refresh", "jti", expiresAt).Return(nil)
+
+		authController.Logout(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAuthUsecase.AssertExpectations(t)
+	})
+}
@@ -1,9 +1,12 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"g3-g65-bsp/domain"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -11,82 +14,178 @@ import (
 
 // BlogDTO is a data transfer object for Blog with JSON restrictions
 type BlogDTO struct {
-	ID             string       `json:"id,omitempty"`
-	AuthorID       string       `json:"author_id"`
-	AuthorUsername string       `json:"author_username"`
-	Title          string       `json:"title" binding:"required"`
-	Content        string       `json:"content" binding:"required"`
-	Tags           []string     `json:"tags"`
-	Metrics        *MetricsDTO  `json:"metrics"`
-	Comments       []CommentDTO `json:"comments"`
-	CreatedAt      *time.Time   `json:"created_at,omitempty"`
-	UpdatedAt      *time.Time   `json:"updated_at,omitempty"`
+	ID             string        `json:"id,omitempty"`
+	AuthorID       string        `json:"author_id"`
+	AuthorUsername string        `json:"author_username"`
+	Title          string        `json:"title" binding:"required"`
+	Slug           string        `json:"slug,omitempty"`
+	Content        string        `json:"content" binding:"required"`
+	ContentFormat  string        `json:"content_format,omitempty"`
+	ContentHTML    string        `json:"content_html"`
+	Tags           []string      `json:"tags"`
+	Metrics        *MetricsDTO   `json:"metrics"`
+	Status         string        `json:"status,omitempty"`
+	PublishAt      *time.Time    `json:"publish_at,omitempty"`
+	PublishedAt    *time.Time    `json:"published_at,omitempty"`
+	CreatedAt      *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt      *time.Time    `json:"updated_at,omitempty"`
+	Score          float64       `json:"score,omitempty"`
+	Highlight      *HighlightDTO `json:"highlight,omitempty"`
+	// ViewerReaction lists the reactions the authenticated reader left; listings are cached for
+	// everyone, so it is only filled in when a single blog is read
+	ViewerReaction []string `json:"viewer_reaction,omitempty"`
 }
 
-type MetricsDTO struct {
-	ViewCount int       `json:"view_count"`
-	Likes     *LikesDTO `json:"likes"`
-	Dislikes  *LikesDTO `json:"dislikes"`
+// HighlightDTO carries the HTML-escaped parts of a search result with the matches in <mark>
+type HighlightDTO struct {
+	Title   string   `json:"title,omitempty"`
+	Content []string `json:"content,omitempty"`
 }
 
-type LikesDTO struct {
-	Count int      `json:"count"`
-	Users []string `json:"users"`
+type MetricsDTO struct {
+	ViewCount    int `json:"view_count"`
+	CommentCount int `json:"comment_count"`
+	// Reactions counts the reactions on the blog by type
+	Reactions map[string]int `json:"reactions"`
 }
 
 type CommentDTO struct {
-	ID             string     `json:"id,omitempty"`
-	AuthorID       string     `json:"author_id"`
-	AuthorUsername string     `json:"author_username"`
-	Content        string     `json:"content"`
-	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	ID             string         `json:"id,omitempty"`
+	AuthorID       string         `json:"author_id"`
+	AuthorUsername string         `json:"author_username"`
+	Content        string         `json:"content"`
+	ContentHTML    string         `json:"content_html"`
+	CreatedAt      *time.Time     `json:"created_at,omitempty"`
+	ParentID       string         `json:"parent_id,omitempty"`
+	Depth          int            `json:"depth"`
+	Deleted        bool           `json:"deleted,omitempty"`
+	Reactions      map[string]int `json:"reactions,omitempty"`
+	Replies        []CommentDTO   `json:"replies,omitempty"`
+}
+
+type BlogRevisionDTO struct {
+	ID            string    `json:"id"`
+	BlogID        string    `json:"blog_id"`
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	ContentFormat string    `json:"content_format,omitempty"`
+	Tags          []string  `json:"tags"`
+	EditorID      string    `json:"editor_id"`
+	RestoredFrom  int       `json:"restored_from,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type DiffLineDTO struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type RevisionDiffDTO struct {
+	From    *BlogRevisionDTO `json:"from"`
+	To      *BlogRevisionDTO `json:"to"`
+	Title   []DiffLineDTO    `json:"title"`
+	Tags    []DiffLineDTO    `json:"tags"`
+	Content []DiffLineDTO    `json:"content"`
 }
 
 // ConvertToDomain converts BlogDTO to domain.Blog
 func (dto *BlogDTO) ConvertToDomain() *domain.Blog {
 	return &domain.Blog{
-		Title:   dto.Title,
-		Content: dto.Content,
-		Tags:    dto.Tags,
+		Title:         dto.Title,
+		Content:       dto.Content,
+		ContentFormat: domain.ContentFormat(dto.ContentFormat),
+		Tags:          dto.Tags,
+		Status:        domain.BlogStatus(dto.Status),
+		PublishAt:     dto.PublishAt,
 	}
 }
 
+// ConvertCommentFromDomain converts a domain.Comment, with its replies, to CommentDTO
+func ConvertCommentFromDomain(comment *domain.Comment) CommentDTO {
+	dto := CommentDTO{
+		ID:             comment.ID,
+		AuthorID:       comment.AuthorID,
+		AuthorUsername: comment.AuthorUsername,
+		Content:        comment.Content,
+		ContentHTML:    comment.ContentHTML,
+		CreatedAt:      comment.CreatedAt,
+		ParentID:       comment.ParentID,
+		Depth:          comment.Depth,
+		Deleted:        comment.Deleted,
+		Reactions:      comment.Reactions,
+	}
+	for _, reply := range comment.Replies {
+		dto.Replies = append(dto.Replies, ConvertCommentFromDomain(reply))
+	}
+	return dto
+}
+
 // ConvertFromDomain converts a domain.Blog to BlogDTO
 func ConvertFromDomain(blog *domain.Blog) *BlogDTO {
-	comments := make([]CommentDTO, len(blog.Comments))
-	for i, c := range blog.Comments {
-		createdAt := c.CreatedAt
-		comments[i] = CommentDTO{
-			ID:             c.ID,
-			AuthorID:       c.AuthorID,
-			AuthorUsername: c.AuthorUsername,
-			Content:        c.Content,
-			CreatedAt:      createdAt,
-		}
-	}
 	createdAt := blog.CreatedAt
 	updatedAt := blog.UpdatedAt
+	var highlight *HighlightDTO
+	if blog.Highlight != nil {
+		highlight = &HighlightDTO{Title: blog.Highlight.Title, Content: blog.Highlight.Content}
+	}
 	return &BlogDTO{
 		ID:             blog.ID,
 		AuthorID:       blog.AuthorID,
 		AuthorUsername: blog.AuthorUsername,
 		Title:          blog.Title,
+		Slug:           blog.Slug,
 		Content:        blog.Content,
+		ContentFormat:  string(blog.ContentFormat),
+		ContentHTML:    blog.ContentHTML,
 		Tags:           blog.Tags,
 		Metrics: &MetricsDTO{
-			ViewCount: blog.Metrics.ViewCount,
-			Likes: &LikesDTO{
-				Count: blog.Metrics.Likes.Count,
-				Users: blog.Metrics.Likes.Users,
-			},
-			Dislikes: &LikesDTO{
-				Count: blog.Metrics.Dislikes.Count,
-				Users: blog.Metrics.Dislikes.Users,
-			},
+			ViewCount:    blog.Metrics.ViewCount,
+			CommentCount: blog.Metrics.CommentCount,
+			Reactions:    reactionCounts(blog.Metrics.Reactions),
 		},
-		Comments:  comments,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		Status:         string(blog.Status),
+		PublishAt:      blog.PublishAt,
+		PublishedAt:    blog.PublishedAt,
+		CreatedAt:      createdAt,
+		UpdatedAt:      updatedAt,
+		Score:          blog.Score,
+		Highlight:      highlight,
+		ViewerReaction: blog.ViewerReactions,
+	}
+}
+
+// ConvertRevisionFromDomain converts a domain.BlogRevision to BlogRevisionDTO
+func ConvertRevisionFromDomain(revision *domain.BlogRevision) *BlogRevisionDTO {
+	return &BlogRevisionDTO{
+		ID:            revision.ID,
+		BlogID:        revision.BlogID,
+		Number:        revision.Number,
+		Title:         revision.Title,
+		Content:       revision.Content,
+		ContentFormat: string(revision.ContentFormat),
+		Tags:          revision.Tags,
+		EditorID:      revision.EditorID,
+		RestoredFrom:  revision.RestoredFrom,
+		CreatedAt:     revision.CreatedAt,
+	}
+}
+
+// ConvertDiffFromDomain converts a domain.RevisionDiff to RevisionDiffDTO
+func ConvertDiffFromDomain(diff *domain.RevisionDiff) *RevisionDiffDTO {
+	convertLines := func(lines []domain.DiffLine) []DiffLineDTO {
+		dtos := make([]DiffLineDTO, len(lines))
+		for i, l := range lines {
+			dtos[i] = DiffLineDTO{Op: string(l.Op), Text: l.Text}
+		}
+		return dtos
+	}
+	return &RevisionDiffDTO{
+		From:    ConvertRevisionFromDomain(diff.From),
+		To:      ConvertRevisionFromDomain(diff.To),
+		Title:   convertLines(diff.Title),
+		Tags:    convertLines(diff.Tags),
+		Content: convertLines(diff.Content),
 	}
 }
 
@@ -110,7 +209,7 @@ func (c *BlogController) CreateBlog(ctx *gin.Context) {
 	}
 	newBlog, err := c.blogUsecase.CreateBlog(ctx, blog.ConvertToDomain(), userid)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.JSON(blogErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 	ctx.JSON(http.StatusCreated, ConvertFromDomain(newBlog))
@@ -118,7 +217,7 @@ func (c *BlogController) CreateBlog(ctx *gin.Context) {
 
 func (c *BlogController) GetBlogByID(ctx *gin.Context) {
 	id := ctx.Param("id")
-	blog, err := c.blogUsecase.GetBlogByID(ctx, id)
+	blog, err := c.blogUsecase.GetBlogByID(domain.WithClientIP(ctx, ctx.ClientIP()), id, ctx.GetString("user_id"), ctx.GetString("role"))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "blog not found"})
 		return
@@ -126,6 +225,22 @@ func (c *BlogController) GetBlogByID(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, ConvertFromDomain(blog))
 }
 
+// GetBlogBySlug serves a blog by its permalink. Links using a slug the blog had
+// before its title changed are redirected permanently to the current one.
+func (c *BlogController) GetBlogBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+	blog, err := c.blogUsecase.GetBlogBySlug(domain.WithClientIP(ctx, ctx.ClientIP()), slug, ctx.GetString("user_id"), ctx.GetString("role"))
+	if err != nil {
+		ctx.JSON(blogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if blog.Slug != slug {
+		ctx.Redirect(http.StatusMovedPermanently, "/blogs/slug/"+url.PathEscape(blog.Slug))
+		return
+	}
+	ctx.JSON(http.StatusOK, ConvertFromDomain(blog))
+}
+
 func (c *BlogController) UpdateBlog(ctx *gin.Context) {
 	userid, ok := ctx.Get("user_id")
 	if !ok {
@@ -140,6 +255,10 @@ func (c *BlogController) UpdateBlog(ctx *gin.Context) {
 		return
 	}
 	updatedBlog, err := c.blogUsecase.UpdateBlog(ctx, blog.ConvertToDomain(), userid.(string), id)
+	if errors.Is(err, domain.ErrInvalidContentFormat) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "blog not found " + err.Error()})
 		return
@@ -227,7 +346,32 @@ func (c *BlogController) ListBlogs(ctx *gin.Context) {
 		filter["order"] = order
 	}
 
+	// A cursor from next_cursor/prev_cursor takes precedence over page
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		filter["cursor"] = cursor
+	}
+	if includeTotal := ctx.Query("include_total"); includeTotal != "" {
+		filter["include_total"] = includeTotal == "true" || includeTotal == "1"
+	}
+
+	// Parse status; anything other than published is scoped to the viewer
+	if status := ctx.Query("status"); status != "" {
+		filter["status"] = status
+		filter["viewer_id"] = ctx.GetString("user_id")
+		filter["viewer_role"] = ctx.GetString("role")
+		// Keep per-user listings out of the shared page cache
+		ctx.Header("Cache-Control", "private")
+	}
+
 	blogs, pagination, err := c.blogUsecase.ListBlogs(ctx, filter, page, limit)
+	if errors.Is(err, domain.ErrInvalidBlogStatus) || errors.Is(err, domain.ErrInvalidCursor) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, domain.ErrUnauthorized) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -237,11 +381,144 @@ func (c *BlogController) ListBlogs(ctx *gin.Context) {
 		dtos[i] = ConvertFromDomain(b)
 	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"data":       dtos,
-		"pagination": pagination,
+		"data": dtos,
+		// keys kept as they were before cursors; Total is null when it was not counted
+		"pagination": gin.H{
+			"Total": pagination.Total,
+			"Page":  pagination.Page,
+			"Limit": pagination.Limit,
+		},
+		"next_cursor": pagination.NextCursor,
+		"prev_cursor": pagination.PrevCursor,
+	})
+}
+
+func (c *BlogController) PublishBlog(ctx *gin.Context) {
+	c.actOnBlog(ctx, c.blogUsecase.PublishBlog)
+}
+
+func (c *BlogController) UnpublishBlog(ctx *gin.Context) {
+	c.actOnBlog(ctx, c.blogUsecase.UnpublishBlog)
+}
+
+func (c *BlogController) ArchiveBlog(ctx *gin.Context) {
+	c.actOnBlog(ctx, c.blogUsecase.ArchiveBlog)
+}
+
+type ScheduleRequest struct {
+	PublishAt *time.Time `json:"publish_at" binding:"required"`
+}
+
+func (c *BlogController) ScheduleBlog(ctx *gin.Context) {
+	var req ScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	c.actOnBlog(ctx, func(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+		return c.blogUsecase.ScheduleBlog(ctx, id, userid, role, req.PublishAt)
+	})
+}
+
+func (c *BlogController) CancelSchedule(ctx *gin.Context) {
+	c.actOnBlog(ctx, func(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+		return c.blogUsecase.ScheduleBlog(ctx, id, userid, role, nil)
+	})
+}
+
+func (c *BlogController) ListRevisions(ctx *gin.Context) {
+	revisions, err := c.blogUsecase.ListRevisions(ctx, ctx.Param("id"), ctx.GetString("user_id"), ctx.GetString("role"))
+	if err != nil {
+		ctx.JSON(blogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	dtos := make([]*BlogRevisionDTO, len(revisions))
+	for i, r := range revisions {
+		dtos[i] = ConvertRevisionFromDomain(r)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": dtos})
+}
+
+func (c *BlogController) DiffRevisions(ctx *gin.Context) {
+	from, errFrom := parseInt(ctx.Query("from"))
+	to, errTo := parseInt(ctx.Query("to"))
+	if errFrom != nil || errTo != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "from and to revision numbers are required"})
+		return
+	}
+	diff, err := c.blogUsecase.DiffRevisions(ctx, ctx.Param("id"), from, to, ctx.GetString("user_id"), ctx.GetString("role"))
+	if err != nil {
+		ctx.JSON(blogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, ConvertDiffFromDomain(diff))
+}
+
+func (c *BlogController) RestoreRevision(ctx *gin.Context) {
+	number, err := parseInt(ctx.Param("revision"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid revision number"})
+		return
+	}
+	c.actOnBlog(ctx, func(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+		return c.blogUsecase.RestoreRevision(ctx, id, number, userid, role)
 	})
 }
 
+// RelatedBlogs lists the published blogs most closely related to the one in the path, limit of them
+// (5 by default, at most domain.MaxRelatedBlogs). Each carries how closely it relates as its score.
+func (c *BlogController) RelatedBlogs(ctx *gin.Context) {
+	limit := 5
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := parseInt(l); err == nil && v > 0 {
+			limit = min(v, domain.MaxRelatedBlogs)
+		}
+	}
+	blogs, err := c.blogUsecase.RelatedBlogs(ctx, ctx.Param("id"), ctx.GetString("user_id"), ctx.GetString("role"), limit)
+	if err != nil {
+		ctx.JSON(blogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	dtos := make([]*BlogDTO, len(blogs))
+	for i, b := range blogs {
+		dtos[i] = ConvertFromDomain(b)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": dtos})
+}
+
+// actOnBlog runs an action on the blog in the path on behalf of the caller and returns the result
+func (c *BlogController) actOnBlog(ctx *gin.Context, action func(context.Context, string, string, string) (*domain.Blog, error)) {
+	userid := ctx.GetString("user_id")
+	if userid == "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	blog, err := action(ctx, ctx.Param("id"), userid, ctx.GetString("role"))
+	if err != nil {
+		ctx.JSON(blogErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, ConvertFromDomain(blog))
+}
+
+// blogErrorStatus maps usecase errors to HTTP status codes
+func blogErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrBlogNotFound), errors.Is(err, domain.ErrRevisionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidBlogStatus), errors.Is(err, domain.ErrInvalidPublishTime), errors.Is(err, domain.ErrInvalidContentFormat):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrInvalidStatusTransition), errors.Is(err, domain.ErrSlugTaken):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrDiffTooLarge):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // splitAndTrim splits a string by sep and trims spaces
 func splitAndTrim(s, sep string) []string {
 	arr := make([]string, 0)
@@ -287,3 +564,11 @@ func parseInt(s string) (int, error) {
 	_, err := fmt.Sscanf(s, "%d", &n)
 	return n, err
 }
+
+// reactionCounts keeps a blog nobody reacted to from rendering its reactions as null
+func reactionCounts(counts map[string]int) map[string]int {
+	if counts == nil {
+		return map[string]int{}
+	}
+	return counts
+}
@@ -28,8 +28,16 @@ func (m *MockBlogUsecase) CreateBlog(ctx context.Context, blog *domain.Blog, use
 	return args.Get(0).(*domain.Blog), args.Error(1)
 }
 
-func (m *MockBlogUsecase) GetBlogByID(ctx context.Context, id string) (*domain.Blog, error) {
-	args := m.Called(ctx, id)
+func (m *MockBlogUsecase) GetBlogByID(ctx context.Context, id, userID, role string) (*domain.Blog, error) {
+	args := m.Called(ctx, id, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogUsecase) GetBlogBySlug(ctx context.Context, slug, userID, role string) (*domain.Blog, error) {
+	args := m.Called(ctx, slug, userID, role)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -62,6 +70,78 @@ func (m *MockBlogUsecase) ListBlogs(ctx context.Context, filter map[string]any,
 	return blogs, pagination, args.Error(2)
 }
 
+func (m *MockBlogUsecase) PublishBlog(ctx context.Context, id, userID, role string) (*domain.Blog, error) {
+	args := m.Called(ctx, id, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogUsecase) UnpublishBlog(ctx context.Context, id, userID, role string) (*domain.Blog, error) {
+	args := m.Called(ctx, id, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogUsecase) ArchiveBlog(ctx context.Context, id, userID, role string) (*domain.Blog, error) {
+	args := m.Called(ctx, id, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogUsecase) ScheduleBlog(ctx context.Context, id, userID, role string, publishAt *time.Time) (*domain.Blog, error) {
+	args := m.Called(ctx, id, userID, role, publishAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogUsecase) ListRevisions(ctx context.Context, id, userID, role string) ([]*domain.BlogRevision, error) {
+	args := m.Called(ctx, id, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.BlogRevision), args.Error(1)
+}
+
+func (m *MockBlogUsecase) GetAuthor(ctx context.Context, authorID string) (*domain.User, error) {
+	args := m.Called(ctx, authorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockBlogUsecase) DiffRevisions(ctx context.Context, id string, from, to int, userID, role string) (*domain.RevisionDiff, error) {
+	args := m.Called(ctx, id, from, to, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RevisionDiff), args.Error(1)
+}
+
+func (m *MockBlogUsecase) RestoreRevision(ctx context.Context, id string, number int, userID, role string) (*domain.Blog, error) {
+	args := m.Called(ctx, id, number, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogUsecase) RelatedBlogs(ctx context.Context, id, userID, role string, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, id, userID, role, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
 func TestBlogController_CreateBlog(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -79,7 +159,7 @@ func TestBlogController_CreateBlog(t *testing.T) {
 		c.Request.Header.Set("Content-Type", "application/json")
 
 		now := time.Now()
-		blog := &domain.Blog{ID: "1", Title: "Test Title", Content: "Test Content", AuthorID: "user123", CreatedAt: &now, UpdatedAt: &now, Metrics: &domain.Metrics{Likes: &domain.Likes{}, Dislikes: &domain.Likes{}}}
+		blog := &domain.Blog{ID: "1", Title: "Test Title", Content: "Test Content", AuthorID: "user123", CreatedAt: &now, UpdatedAt: &now, Metrics: &domain.Metrics{}}
 		mockBlogUsecase.On("CreateBlog", mock.Anything, mock.AnythingOfType("*domain.Blog"), "user123").Return(blog, nil)
 
 		blogController.CreateBlog(c)
@@ -103,10 +183,13 @@ func TestBlogController_GetBlogByID(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1", nil)
+		c.Request.RemoteAddr = "203.0.113.7:4321"
 
 		now := time.Now()
-		blog := &domain.Blog{ID: "1", Title: "Test Title", Content: "Test Content", AuthorID: "user123", CreatedAt: &now, UpdatedAt: &now, Metrics: &domain.Metrics{Likes: &domain.Likes{}, Dislikes: &domain.Likes{}}}
-		mockBlogUsecase.On("GetBlogByID", mock.Anything, "1").Return(blog, nil)
+		blog := &domain.Blog{ID: "1", Title: "Test Title", Content: "Test Content", ContentFormat: domain.ContentFormatMarkdown, ContentHTML: "<p>Test Content</p>\n", AuthorID: "user123", CreatedAt: &now, UpdatedAt: &now, Metrics: &domain.Metrics{Reactions: map[string]int{"🎉": 2}}, ViewerReactions: []string{"🎉"}}
+		withClientIP := mock.MatchedBy(func(ctx context.Context) bool { return domain.ClientIP(ctx) == "203.0.113.7" })
+		mockBlogUsecase.On("GetBlogByID", withClientIP, "1", "", "").Return(blog, nil)
 
 		blogController.GetBlogByID(c)
 
@@ -115,6 +198,11 @@ func TestBlogController_GetBlogByID(t *testing.T) {
 		err := json.Unmarshal(w.Body.Bytes(), &response)
 		assert.NoError(t, err)
 		assert.Equal(t, "Test Title", response.Title)
+		assert.Equal(t, "Test Content", response.Content)
+		assert.Equal(t, "markdown", response.ContentFormat)
+		assert.Equal(t, "<p>Test Content</p>\n", response.ContentHTML)
+		assert.Equal(t, map[string]int{"🎉": 2}, response.Metrics.Reactions)
+		assert.Equal(t, []string{"🎉"}, response.ViewerReaction)
 		mockBlogUsecase.AssertExpectations(t)
 	})
 
@@ -127,7 +215,7 @@ func TestBlogController_GetBlogByID(t *testing.T) {
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
 		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1", nil)
 
-		mockBlogUsecase.On("GetBlogByID", mock.Anything, "1").Return(nil, errors.New("not found"))
+		mockBlogUsecase.On("GetBlogByID", mock.Anything, "1", "", "").Return(nil, errors.New("not found"))
 
 		blogController.GetBlogByID(c)
 
@@ -136,6 +224,60 @@ func TestBlogController_GetBlogByID(t *testing.T) {
 	})
 }
 
+func TestBlogController_GetBlogBySlug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	blog := &domain.Blog{ID: "1", Title: "New Title", Slug: "new-title", Metrics: &domain.Metrics{}}
+
+	t.Run("current slug", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "slug", Value: "new-title"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/slug/new-title", nil)
+		mockBlogUsecase.On("GetBlogBySlug", mock.Anything, "new-title", "", "").Return(blog, nil)
+
+		blogController.GetBlogBySlug(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response BlogDTO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "new-title", response.Slug)
+	})
+
+	t.Run("old slug redirects", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "slug", Value: "old-title"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/slug/old-title", nil)
+		mockBlogUsecase.On("GetBlogBySlug", mock.Anything, "old-title", "", "").Return(blog, nil)
+
+		blogController.GetBlogBySlug(c)
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "/blogs/slug/new-title", w.Header().Get("Location"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "slug", Value: "missing"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/slug/missing", nil)
+		mockBlogUsecase.On("GetBlogBySlug", mock.Anything, "missing", "", "").Return(nil, domain.ErrBlogNotFound)
+
+		blogController.GetBlogBySlug(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestBlogController_UpdateBlog(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -154,7 +296,7 @@ func TestBlogController_UpdateBlog(t *testing.T) {
 		c.Request.Header.Set("Content-Type", "application/json")
 
 		now := time.Now()
-		blog := &domain.Blog{ID: "1", Title: "Updated Title", Content: "Updated Content", AuthorID: "user123", CreatedAt: &now, UpdatedAt: &now, Metrics: &domain.Metrics{Likes: &domain.Likes{}, Dislikes: &domain.Likes{}}}
+		blog := &domain.Blog{ID: "1", Title: "Updated Title", Content: "Updated Content", AuthorID: "user123", CreatedAt: &now, UpdatedAt: &now, Metrics: &domain.Metrics{}}
 		mockBlogUsecase.On("UpdateBlog", mock.Anything, mock.AnythingOfType("*domain.Blog"), "user123", "1").Return(blog, nil)
 
 		blogController.UpdateBlog(c)
@@ -197,8 +339,9 @@ func TestBlogController_ListBlogs(t *testing.T) {
 	controller := NewBlogController(mockUsecase)
 
 	t.Run("success", func(t *testing.T) {
-		blogs := []*domain.Blog{{ID: "1", Title: "Test Blog", AuthorID: "user123", Content: "content", Tags: []string{"tag1"}, Metrics: &domain.Metrics{Likes: &domain.Likes{}, Dislikes: &domain.Likes{}}}}
-		pagination := &domain.Pagination{Total: 1, Page: 1, Limit: 10}
+		blogs := []*domain.Blog{{ID: "1", Title: "Test Blog", AuthorID: "user123", Content: "content", Tags: []string{"tag1"}, Metrics: &domain.Metrics{}}}
+		total := 1
+		pagination := &domain.Pagination{Total: &total, Page: 1, Limit: 10}
 		mockUsecase.On("ListBlogs", mock.Anything, mock.Anything, 1, 10).Return(blogs, pagination, nil).Once()
 
 		w := httptest.NewRecorder()
@@ -217,6 +360,300 @@ func TestBlogController_ListBlogs(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Len(t, responseBody.Data, 1)
 		assert.Equal(t, "Test Blog", responseBody.Data[0].Title)
-		assert.Equal(t, 1, responseBody.Pagination.Total)
+		if assert.NotNil(t, responseBody.Pagination.Total) {
+			assert.Equal(t, 1, *responseBody.Pagination.Total)
+		}
+	})
+
+	t.Run("cursor mode", func(t *testing.T) {
+		pagination := &domain.Pagination{Page: 1, Limit: 10, HasNext: true, NextCursor: "next-token"}
+		mockUsecase.On("ListBlogs", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+			return filter["cursor"] == "abc" && filter["include_total"] == false
+		}), 1, 10).Return([]*domain.Blog{}, pagination, nil).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs?cursor=abc&include_total=false", nil)
+
+		controller.ListBlogs(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var responseBody struct {
+			Pagination map[string]any `json:"pagination"`
+			NextCursor string         `json:"next_cursor"`
+			PrevCursor string         `json:"prev_cursor"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &responseBody))
+		assert.Equal(t, "next-token", responseBody.NextCursor)
+		assert.Empty(t, responseBody.PrevCursor)
+		assert.Nil(t, responseBody.Pagination["Total"])
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		mockUsecase.On("ListBlogs", mock.Anything, mock.Anything, 1, 10).Return(nil, nil, domain.ErrInvalidCursor).Once()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs?cursor=forged", nil)
+
+		controller.ListBlogs(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestBlogController_PublishBlog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/1/publish", nil)
+
+		blog := &domain.Blog{ID: "1", Title: "Test Title", AuthorID: "user123", Status: domain.BlogStatusPublished, Metrics: &domain.Metrics{}}
+		mockBlogUsecase.On("PublishBlog", mock.Anything, "1", "user123", "user").Return(blog, nil)
+
+		blogController.PublishBlog(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response BlogDTO
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "published", response.Status)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "other")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/1/publish", nil)
+
+		mockBlogUsecase.On("PublishBlog", mock.Anything, "1", "other", "user").Return(nil, domain.ErrUnauthorized)
+
+		blogController.PublishBlog(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+
+	t.Run("invalid transition", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/1/unpublish", nil)
+
+		mockBlogUsecase.On("UnpublishBlog", mock.Anything, "1", "user123", "user").Return(nil, domain.ErrInvalidStatusTransition)
+
+		blogController.UnpublishBlog(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+}
+
+func TestBlogController_ScheduleBlog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+		publishAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		jsonBody, _ := json.Marshal(ScheduleRequest{PublishAt: &publishAt})
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/1/schedule", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		blog := &domain.Blog{ID: "1", AuthorID: "user123", Status: domain.BlogStatusDraft, PublishAt: &publishAt, Metrics: &domain.Metrics{}}
+		mockBlogUsecase.On("ScheduleBlog", mock.Anything, "1", "user123", "user", mock.MatchedBy(func(t *time.Time) bool {
+			return t != nil && t.Equal(publishAt)
+		})).Return(blog, nil)
+
+		blogController.ScheduleBlog(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response BlogDTO
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.True(t, publishAt.Equal(*response.PublishAt))
+		mockBlogUsecase.AssertExpectations(t)
+	})
+
+	t.Run("publish time in the past", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+
+		publishAt := time.Now().Add(-time.Hour)
+		jsonBody, _ := json.Marshal(ScheduleRequest{PublishAt: &publishAt})
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/1/schedule", bytes.NewBuffer(jsonBody))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		mockBlogUsecase.On("ScheduleBlog", mock.Anything, "1", "user123", "user", mock.Anything).Return(nil, domain.ErrInvalidPublishTime)
+
+		blogController.ScheduleBlog(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+}
+
+func TestBlogController_DiffRevisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1/revisions/diff?from=1&to=2", nil)
+
+		diff := &domain.RevisionDiff{
+			From:    &domain.BlogRevision{Number: 1},
+			To:      &domain.BlogRevision{Number: 2},
+			Content: []domain.DiffLine{{Op: domain.DiffInsert, Text: "new line"}},
+		}
+		mockBlogUsecase.On("DiffRevisions", mock.Anything, "1", 1, 2, "user123", "user").Return(diff, nil)
+
+		blogController.DiffRevisions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response RevisionDiffDTO
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, []DiffLineDTO{{Op: "insert", Text: "new line"}}, response.Content)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+
+	t.Run("missing revision numbers", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1/revisions/diff?from=1", nil)
+
+		blogController.DiffRevisions(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("revisions too large", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1/revisions/diff?from=1&to=2", nil)
+		mockBlogUsecase.On("DiffRevisions", mock.Anything, "1", 1, 2, "user123", "user").Return(nil, domain.ErrDiffTooLarge)
+
+		blogController.DiffRevisions(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+}
+
+func TestBlogController_RestoreRevision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockBlogUsecase := new(MockBlogUsecase)
+	blogController := NewBlogController(mockBlogUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "user123")
+	c.Set("role", "user")
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}, gin.Param{Key: "revision", Value: "3"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/1/revisions/3/restore", nil)
+
+	mockBlogUsecase.On("RestoreRevision", mock.Anything, "1", 3, "user123", "user").Return(nil, domain.ErrRevisionNotFound)
+
+	blogController.RestoreRevision(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockBlogUsecase.AssertExpectations(t)
+}
+
+func TestBlogController_RelatedBlogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1/related?limit=100", nil)
+
+		related := []*domain.Blog{{ID: "2", Title: "Related", Metrics: &domain.Metrics{}, Score: 0.7}}
+		mockBlogUsecase.On("RelatedBlogs", mock.Anything, "1", "user123", "user", domain.MaxRelatedBlogs).Return(related, nil)
+
+		blogController.RelatedBlogs(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data []BlogDTO `json:"data"`
+		}
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Data, 1)
+		assert.Equal(t, "2", response.Data[0].ID)
+		assert.Equal(t, 0.7, response.Data[0].Score)
+		mockBlogUsecase.AssertExpectations(t)
+	})
+
+	t.Run("hidden blog", func(t *testing.T) {
+		mockBlogUsecase := new(MockBlogUsecase)
+		blogController := NewBlogController(mockBlogUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "1"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/1/related", nil)
+
+		mockBlogUsecase.On("RelatedBlogs", mock.Anything, "1", "", "", 5).Return(nil, domain.ErrBlogNotFound)
+
+		blogController.RelatedBlogs(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockBlogUsecase.AssertExpectations(t)
 	})
 }
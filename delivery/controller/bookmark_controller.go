@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"errors"
+	"g3-g65-bsp/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxBookmarkPageSize caps how many bookmarks one page holds
+const maxBookmarkPageSize = 100
+
+type BookmarkDTO struct {
+	BlogID      string    `json:"blog_id"`
+	Title       string    `json:"title"`
+	CreatedAt   time.Time `json:"created_at"`
+	Unavailable bool      `json:"unavailable"`
+	Blog        *BlogDTO  `json:"blog,omitempty"`
+}
+
+type ReadingListItemDTO struct {
+	BlogID      string    `json:"blog_id"`
+	Title       string    `json:"title"`
+	AddedAt     time.Time `json:"added_at"`
+	Unavailable bool      `json:"unavailable"`
+	Blog        *BlogDTO  `json:"blog,omitempty"`
+}
+
+type ReadingListDTO struct {
+	ID         string                `json:"id"`
+	OwnerID    string                `json:"owner_id"`
+	Name       string                `json:"name"`
+	Shared     bool                  `json:"shared"`
+	ShareToken string                `json:"share_token,omitempty"`
+	Items      []*ReadingListItemDTO `json:"items"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+type CreateReadingListRequest struct {
+	Name   string `json:"name" binding:"required"`
+	Shared bool   `json:"shared"`
+}
+
+// UpdateReadingListRequest leaves out what is not to change
+type UpdateReadingListRequest struct {
+	Name   *string `json:"name"`
+	Shared *bool   `json:"shared"`
+}
+
+type ReorderReadingListRequest struct {
+	BlogIDs []string `json:"blog_ids" binding:"required"`
+}
+
+type AddReadingListItemRequest struct {
+	BlogID string `json:"blog_id" binding:"required"`
+}
+
+type BookmarkController struct {
+	usecase domain.BookmarkUsecase
+}
+
+func NewBookmarkController(usecase domain.BookmarkUsecase) *BookmarkController {
+	return &BookmarkController{usecase: usecase}
+}
+
+func (c *BookmarkController) Bookmark(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if err := c.usecase.Bookmark(ctx, userID, ctx.Param("id"), ctx.GetString("role")); err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"blog_id": ctx.Param("id"), "bookmarked": true})
+}
+
+func (c *BookmarkController) RemoveBookmark(ctx *gin.Context) {
+	if err := c.usecase.RemoveBookmark(ctx, ctx.GetString("user_id"), ctx.Param("id")); err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"blog_id": ctx.Param("id"), "bookmarked": false})
+}
+
+// ListBookmarks lists the caller's bookmarks, newest first
+func (c *BookmarkController) ListBookmarks(ctx *gin.Context) {
+	page, limit := 1, 20
+	if p := ctx.Query("page"); p != "" {
+		if v, err := parseInt(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := parseInt(l); err == nil && v > 0 {
+			limit = min(v, maxBookmarkPageSize)
+		}
+	}
+	bookmarks, pagination, err := c.usecase.ListBookmarks(ctx, ctx.GetString("user_id"), ctx.GetString("role"), page, limit)
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	dtos := make([]*BookmarkDTO, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		dtos[i] = &BookmarkDTO{
+			BlogID:      bookmark.BlogID,
+			Title:       bookmark.Title,
+			CreatedAt:   bookmark.CreatedAt,
+			Unavailable: bookmark.Unavailable,
+		}
+		if bookmark.Blog != nil {
+			dtos[i].Blog = ConvertFromDomain(bookmark.Blog)
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": dtos,
+		"pagination": gin.H{
+			"total":    pagination.Total,
+			"page":     pagination.Page,
+			"limit":    pagination.Limit,
+			"has_next": pagination.HasNext,
+			"has_prev": pagination.HasPrev,
+		},
+	})
+}
+
+// BookmarkStates tells which of the comma-separated blogs in ?ids= the caller has bookmarked, so a page
+// of blogs can show its bookmark buttons with one request
+func (c *BookmarkController) BookmarkStates(ctx *gin.Context) {
+	ids := splitAndTrim(ctx.Query("ids"), ",")
+	states, err := c.usecase.BookmarkStates(ctx, ctx.GetString("user_id"), ids)
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": states})
+}
+
+func (c *BookmarkController) CreateReadingList(ctx *gin.Context) {
+	var req CreateReadingListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	list, err := c.usecase.CreateReadingList(ctx, ctx.GetString("user_id"), req.Name, req.Shared)
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convertReadingList(list))
+}
+
+// ListReadingLists lists the caller's reading lists, most recently changed first
+func (c *BookmarkController) ListReadingLists(ctx *gin.Context) {
+	lists, err := c.usecase.ListReadingLists(ctx, ctx.GetString("user_id"))
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	dtos := make([]*ReadingListDTO, len(lists))
+	for i, list := range lists {
+		dtos[i] = convertReadingList(list)
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": dtos})
+}
+
+func (c *BookmarkController) GetReadingList(ctx *gin.Context) {
+	list, err := c.usecase.GetReadingList(ctx, ctx.Param("id"), ctx.GetString("user_id"), ctx.GetString("role"))
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convertReadingList(list))
+}
+
+// GetSharedReadingList serves a shared list to anyone with its link, without authentication
+func (c *BookmarkController) GetSharedReadingList(ctx *gin.Context) {
+	list, err := c.usecase.GetSharedReadingList(ctx, ctx.Param("token"), ctx.GetString("user_id"), ctx.GetString("role"))
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convertReadingList(list))
+}
+
+func (c *BookmarkController) UpdateReadingList(ctx *gin.Context) {
+	var req UpdateReadingListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	list, err := c.usecase.UpdateReadingList(ctx, ctx.Param("id"), ctx.GetString("user_id"), req.Name, req.Shared)
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convertReadingList(list))
+}
+
+func (c *BookmarkController) ReorderReadingList(ctx *gin.Context) {
+	var req ReorderReadingListRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	list, err := c.usecase.ReorderReadingList(ctx, ctx.Param("id"), ctx.GetString("user_id"), req.BlogIDs)
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convertReadingList(list))
+}
+
+func (c *BookmarkController) DeleteReadingList(ctx *gin.Context) {
+	if err := c.usecase.DeleteReadingList(ctx, ctx.Param("id"), ctx.GetString("user_id")); err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "reading list deleted"})
+}
+
+func (c *BookmarkController) AddToReadingList(ctx *gin.Context) {
+	var req AddReadingListItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	err := c.usecase.AddToReadingList(ctx, ctx.Param("id"), ctx.GetString("user_id"), req.BlogID, ctx.GetString("role"))
+	if err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"blog_id": req.BlogID, "listed": true})
+}
+
+func (c *BookmarkController) RemoveFromReadingList(ctx *gin.Context) {
+	blogID := ctx.Param("blog_id")
+	if err := c.usecase.RemoveFromReadingList(ctx, ctx.Param("id"), ctx.GetString("user_id"), blogID); err != nil {
+		respondBookmarkError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"blog_id": blogID, "listed": false})
+}
+
+func convertReadingList(list *domain.ReadingList) *ReadingListDTO {
+	items := make([]*ReadingListItemDTO, len(list.Items))
+	for i, item := range list.Items {
+		items[i] = &ReadingListItemDTO{
+			BlogID:      item.BlogID,
+			Title:       item.Title,
+			AddedAt:     item.AddedAt,
+			Unavailable: item.Unavailable,
+		}
+		if item.Blog != nil {
+			items[i].Blog = ConvertFromDomain(item.Blog)
+		}
+	}
+	return &ReadingListDTO{
+		ID:         list.ID,
+		OwnerID:    list.OwnerID,
+		Name:       list.Name,
+		Shared:     list.Shared,
+		ShareToken: list.ShareToken,
+		Items:      items,
+		CreatedAt:  list.CreatedAt,
+		UpdatedAt:  list.UpdatedAt,
+	}
+}
+
+func respondBookmarkError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrBlogNotFound), errors.Is(err, domain.ErrReadingListNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrInvalidReadingListName), errors.Is(err, domain.ErrInvalidReadingListOrder), errors.Is(err, domain.ErrTooManyBlogIDs):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrReadingListFull), errors.Is(err, domain.ErrReadingListChanged):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,338 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"g3-g65-bsp/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBookmarkUsecase struct {
+	mock.Mock
+}
+
+func (m *MockBookmarkUsecase) Bookmark(ctx context.Context, userID, blogID, role string) error {
+	args := m.Called(ctx, userID, blogID, role)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkUsecase) RemoveBookmark(ctx context.Context, userID, blogID string) error {
+	args := m.Called(ctx, userID, blogID)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkUsecase) ListBookmarks(ctx context.Context, userID, role string, page, limit int) ([]*domain.Bookmark, *domain.Pagination, error) {
+	args := m.Called(ctx, userID, role, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Bookmark), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockBookmarkUsecase) BookmarkStates(ctx context.Context, userID string, blogIDs []string) (map[string]bool, error) {
+	args := m.Called(ctx, userID, blogIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) CreateReadingList(ctx context.Context, userID, name string, shared bool) (*domain.ReadingList, error) {
+	args := m.Called(ctx, userID, name, shared)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) ListReadingLists(ctx context.Context, userID string) ([]*domain.ReadingList, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) GetReadingList(ctx context.Context, id, userID, role string) (*domain.ReadingList, error) {
+	args := m.Called(ctx, id, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) GetSharedReadingList(ctx context.Context, token, viewerID, role string) (*domain.ReadingList, error) {
+	args := m.Called(ctx, token, viewerID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) UpdateReadingList(ctx context.Context, id, userID string, name *string, shared *bool) (*domain.ReadingList, error) {
+	args := m.Called(ctx, id, userID, name, shared)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) ReorderReadingList(ctx context.Context, id, userID string, blogIDs []string) (*domain.ReadingList, error) {
+	args := m.Called(ctx, id, userID, blogIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkUsecase) DeleteReadingList(ctx context.Context, id, userID string) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkUsecase) AddToReadingList(ctx context.Context, id, userID, blogID, role string) error {
+	args := m.Called(ctx, id, userID, blogID, role)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkUsecase) RemoveFromReadingList(ctx context.Context, id, userID, blogID string) error {
+	args := m.Called(ctx, id, userID, blogID)
+	return args.Error(0)
+}
+
+func TestBookmarkController_Bookmark(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(MockBookmarkUsecase)
+		bookmarkController := NewBookmarkController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog1"}}
+		mockUsecase.On("Bookmark", mock.Anything, "reader", "blog1", "user").Return(nil).Once()
+
+		bookmarkController.Bookmark(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"blog_id":"blog1","bookmarked":true}`, w.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("unknown blog", func(t *testing.T) {
+		mockUsecase := new(MockBookmarkUsecase)
+		bookmarkController := NewBookmarkController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "ghost"}}
+		mockUsecase.On("Bookmark", mock.Anything, "reader", "ghost", "").Return(domain.ErrBlogNotFound).Once()
+
+		bookmarkController.Bookmark(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestBookmarkController_ListBookmarks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockBookmarkUsecase)
+	bookmarkController := NewBookmarkController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "reader")
+	c.Request, _ = http.NewRequest(http.MethodGet, "/bookmarks?limit=500", nil)
+	total := 2
+	bookmarks := []*domain.Bookmark{
+		{BlogID: "1", Title: "Hello", Blog: &domain.Blog{ID: "1", Title: "Hello", Metrics: &domain.Metrics{}}},
+		{BlogID: "2", Title: "Gone", Unavailable: true},
+	}
+	mockUsecase.On("ListBookmarks", mock.Anything, "reader", "", 1, maxBookmarkPageSize).
+		Return(bookmarks, &domain.Pagination{Total: &total, Page: 1, Limit: maxBookmarkPageSize}, nil).Once()
+
+	bookmarkController.ListBookmarks(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []BookmarkDTO `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Data, 2) {
+		assert.NotNil(t, response.Data[0].Blog)
+		assert.True(t, response.Data[1].Unavailable)
+		assert.Nil(t, response.Data[1].Blog)
+	}
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookmarkController_BookmarkStates(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(MockBookmarkUsecase)
+		bookmarkController := NewBookmarkController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Request, _ = http.NewRequest(http.MethodGet, "/bookmarks/state?ids=a,%20b,", nil)
+		mockUsecase.On("BookmarkStates", mock.Anything, "reader", []string{"a", "b"}).Return(map[string]bool{"a": true, "b": false}, nil).Once()
+
+		bookmarkController.BookmarkStates(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{"a":true,"b":false}}`, w.Body.String())
+	})
+
+	t.Run("too many ids", func(t *testing.T) {
+		mockUsecase := new(MockBookmarkUsecase)
+		bookmarkController := NewBookmarkController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Request, _ = http.NewRequest(http.MethodGet, "/bookmarks/state?ids=a", nil)
+		mockUsecase.On("BookmarkStates", mock.Anything, "reader", []string{"a"}).Return(nil, domain.ErrTooManyBlogIDs).Once()
+
+		bookmarkController.BookmarkStates(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestBookmarkController_CreateReadingList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockBookmarkUsecase)
+	bookmarkController := NewBookmarkController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "reader")
+	c.Request, _ = http.NewRequest(http.MethodPost, "/reading-lists", bytes.NewBufferString(`{"name":"Weekend","shared":true}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	list := &domain.ReadingList{ID: "list1", OwnerID: "reader", Name: "Weekend", Shared: true, ShareToken: "token", Items: []*domain.ReadingListItem{}}
+	mockUsecase.On("CreateReadingList", mock.Anything, "reader", "Weekend", true).Return(list, nil).Once()
+
+	bookmarkController.CreateReadingList(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response ReadingListDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "token", response.ShareToken)
+	assert.Empty(t, response.Items)
+}
+
+func TestBookmarkController_UpdateReadingList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("only the given fields", func(t *testing.T) {
+		mockUsecase := new(MockBookmarkUsecase)
+		bookmarkController := NewBookmarkController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "list1"}}
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/reading-lists/list1", bytes.NewBufferString(`{"shared":false}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		mockUsecase.On("UpdateReadingList", mock.Anything, "list1", "reader", (*string)(nil), mock.MatchedBy(func(shared *bool) bool {
+			return shared != nil && !*shared
+		})).Return(&domain.ReadingList{ID: "list1"}, nil).Once()
+
+		bookmarkController.UpdateReadingList(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("changed meanwhile", func(t *testing.T) {
+		mockUsecase := new(MockBookmarkUsecase)
+		bookmarkController := NewBookmarkController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "list1"}}
+		c.Request, _ = http.NewRequest(http.MethodPatch, "/reading-lists/list1", bytes.NewBufferString(`{"name":"Soon"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		mockUsecase.On("UpdateReadingList", mock.Anything, "list1", "reader", mock.Anything, (*bool)(nil)).Return(nil, domain.ErrReadingListChanged).Once()
+
+		bookmarkController.UpdateReadingList(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestBookmarkController_ReorderReadingList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockBookmarkUsecase)
+	bookmarkController := NewBookmarkController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "reader")
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "list1"}}
+	c.Request, _ = http.NewRequest(http.MethodPut, "/reading-lists/list1/order", bytes.NewBufferString(`{"blog_ids":["b","a"]}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	mockUsecase.On("ReorderReadingList", mock.Anything, "list1", "reader", []string{"b", "a"}).Return(nil, domain.ErrInvalidReadingListOrder).Once()
+
+	bookmarkController.ReorderReadingList(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookmarkController_AddToReadingList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockBookmarkUsecase)
+	bookmarkController := NewBookmarkController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "reader")
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "list1"}}
+	c.Request, _ = http.NewRequest(http.MethodPost, "/reading-lists/list1/items", bytes.NewBufferString(`{"blog_id":"blog1"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	mockUsecase.On("AddToReadingList", mock.Anything, "list1", "reader", "blog1", "").Return(domain.ErrReadingListFull).Once()
+
+	bookmarkController.AddToReadingList(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestBookmarkController_GetSharedReadingList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockBookmarkUsecase)
+	bookmarkController := NewBookmarkController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "token", Value: "token"}}
+	list := &domain.ReadingList{ID: "list1", Name: "Weekend", Shared: true, Items: []*domain.ReadingListItem{
+		{BlogID: "1", Title: "Hello", Blog: &domain.Blog{ID: "1", Title: "Hello", Metrics: &domain.Metrics{}}},
+		{BlogID: "2", Title: "Gone", Unavailable: true},
+	}}
+	mockUsecase.On("GetSharedReadingList", mock.Anything, "token", "", "").Return(list, nil).Once()
+
+	bookmarkController.GetSharedReadingList(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response ReadingListDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Items, 2) {
+		assert.Equal(t, "1", response.Items[0].Blog.ID)
+		assert.True(t, response.Items[1].Unavailable)
+	}
+}
@@ -0,0 +1,422 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// feedSize is how many of the newest posts a feed carries
+	feedSize = 20
+	// feedTTL is how long a rendered feed is served from the cache
+	feedTTL = 5 * time.Minute
+)
+
+type feedFormat string
+
+const (
+	feedRSS  feedFormat = "rss"
+	feedAtom feedFormat = "atom"
+	feedJSON feedFormat = "json"
+)
+
+var feedContentTypes = map[feedFormat]string{
+	feedRSS:  "application/rss+xml; charset=utf-8",
+	feedAtom: "application/atom+xml; charset=utf-8",
+	feedJSON: "application/feed+json; charset=utf-8",
+}
+
+// renderedFeed is what gets cached: the encoded body and the validators sent with it
+type renderedFeed struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+}
+
+// feedInfo describes the feed as a whole
+type feedInfo struct {
+	Title   string
+	HomeURL string
+	SelfURL string
+	Updated time.Time
+}
+
+type FeedController struct {
+	blogUsecase domain.BlogUsecase
+	cache       cache.Service
+	siteURL     string
+}
+
+// NewFeedController serves syndication feeds. siteURL is the public base URL used for links.
+func NewFeedController(blogUsecase domain.BlogUsecase, cacheService cache.Service, siteURL string) *FeedController {
+	return &FeedController{
+		blogUsecase: blogUsecase,
+		cache:       cacheService,
+		siteURL:     strings.TrimSuffix(siteURL, "/"),
+	}
+}
+
+// RSS serves an RSS 2.0 feed of the newest published posts
+func (c *FeedController) RSS(ctx *gin.Context) {
+	c.serve(ctx, feedRSS)
+}
+
+// Atom serves an Atom 1.0 feed of the newest published posts
+func (c *FeedController) Atom(ctx *gin.Context) {
+	c.serve(ctx, feedAtom)
+}
+
+// JSON serves a JSON Feed 1.1 of the newest published posts
+func (c *FeedController) JSON(ctx *gin.Context) {
+	c.serve(ctx, feedJSON)
+}
+
+// serve renders the feed for the tag or author in the path (or the whole site),
+// answering conditional requests with 304 when the client's copy is still current.
+func (c *FeedController) serve(ctx *gin.Context, format feedFormat) {
+	// links come from the configured site URL, so a feed depends on the path alone and no header the
+	// client sends can end up in the cached copy everyone else is served
+	key := "feed:" + ctx.Request.URL.Path
+
+	var feed *renderedFeed
+	if cached, found := c.cache.Get(key); found {
+		feed, _ = cached.(*renderedFeed)
+	}
+	if feed == nil {
+		var err error
+		feed, err = c.render(ctx, format, c.siteURL)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+			return
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build feed"})
+			return
+		}
+		c.cache.Set(key, feed, feedTTL)
+	}
+
+	ctx.Header("ETag", feed.ETag)
+	if !feed.LastModified.IsZero() {
+		ctx.Header("Last-Modified", feed.LastModified.UTC().Format(http.TimeFormat))
+	}
+	ctx.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(feedTTL.Seconds())))
+	if notModified(ctx.Request, feed) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, feedContentTypes[format], feed.Body)
+}
+
+// notModified checks If-None-Match first and only falls back to If-Modified-Since without it, as RFC 9110 asks
+func notModified(req *http.Request, feed *renderedFeed) bool {
+	if match := req.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == feed.ETag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil || feed.LastModified.IsZero() {
+		return false
+	}
+	return !feed.LastModified.Truncate(time.Second).After(since)
+}
+
+func (c *FeedController) render(ctx *gin.Context, format feedFormat, base string) (*renderedFeed, error) {
+	// newest by when they went public, so a post drafted long before it was published still leads
+	filter := map[string]any{
+		"sortBy":        "published_at",
+		"order":         "desc",
+		"include_total": false,
+	}
+	title := "Latest posts"
+	if tag := ctx.Param("tag"); tag != "" {
+		filter["tags"] = []string{tag}
+		title = "Posts tagged " + tag
+	}
+	if authorID := ctx.Param("author_id"); authorID != "" {
+		author, err := c.blogUsecase.GetAuthor(ctx, authorID)
+		if err != nil {
+			return nil, err
+		}
+		filter["author_id"] = authorID
+		title = "Posts by " + author.Username
+	}
+
+	blogs, _, err := c.blogUsecase.ListBlogs(ctx, filter, 1, feedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	info := feedInfo{
+		Title:   title,
+		HomeURL: base + "/blogs",
+		SelfURL: base + ctx.Request.URL.Path,
+	}
+	for _, blog := range blogs {
+		if updated := blogUpdated(blog); updated.After(info.Updated) {
+			info.Updated = updated
+		}
+	}
+
+	var body []byte
+	switch format {
+	case feedRSS:
+		body, err = renderRSS(info, blogs, base)
+	case feedAtom:
+		body, err = renderAtom(info, blogs, base)
+	default:
+		body, err = renderJSONFeed(info, blogs, base)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	return &renderedFeed{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: info.Updated,
+	}, nil
+}
+
+// blogURL is the permalink of a post
+func blogURL(base string, blog *domain.Blog) string {
+	if blog.Slug != "" {
+		return base + "/blogs/slug/" + url.PathEscape(blog.Slug)
+	}
+	return base + "/blogs/" + blog.ID
+}
+
+func blogPublished(blog *domain.Blog) time.Time {
+	if blog.PublishedAt != nil {
+		return *blog.PublishedAt
+	}
+	if blog.CreatedAt != nil {
+		return *blog.CreatedAt
+	}
+	return time.Time{}
+}
+
+func blogUpdated(blog *domain.Blog) time.Time {
+	published := blogPublished(blog)
+	if blog.UpdatedAt != nil && blog.UpdatedAt.After(published) {
+		return *blog.UpdatedAt
+	}
+	return published
+}
+
+// RSS 2.0, https://www.rssboard.org/rss-specification
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func renderRSS(info feedInfo, blogs []*domain.Blog, base string) ([]byte, error) {
+	channel := rssChannel{
+		Title:       info.Title,
+		Link:        info.HomeURL,
+		Description: info.Title,
+		AtomLink:    atomLink{Href: info.SelfURL, Rel: "self", Type: "application/rss+xml"},
+		Items:       make([]rssItem, 0, len(blogs)),
+	}
+	if !info.Updated.IsZero() {
+		channel.LastBuildDate = info.Updated.UTC().Format(time.RFC1123Z)
+	}
+	for _, blog := range blogs {
+		link := blogURL(base, blog)
+		item := rssItem{
+			Title:       blog.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: false, Value: "blog:" + blog.ID},
+			Creator:     blog.AuthorUsername,
+			Categories:  blog.Tags,
+			Description: blog.ContentHTML,
+		}
+		if published := blogPublished(blog); !published.IsZero() {
+			item.PubDate = published.UTC().Format(time.RFC1123Z)
+		}
+		channel.Items = append(channel.Items, item)
+	}
+	return encodeXML(rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: channel,
+	})
+}
+
+// Atom 1.0, RFC 4287
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+func renderAtom(info feedInfo, blogs []*domain.Blog, base string) ([]byte, error) {
+	feed := atomFeed{
+		Title:   info.Title,
+		ID:      info.SelfURL,
+		Updated: info.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: info.SelfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: info.HomeURL, Rel: "alternate"},
+		},
+		Entries: make([]atomEntry, 0, len(blogs)),
+	}
+	for _, blog := range blogs {
+		entry := atomEntry{
+			Title:   blog.Title,
+			ID:      base + "/blogs/" + blog.ID,
+			Link:    atomLink{Href: blogURL(base, blog), Rel: "alternate"},
+			Updated: blogUpdated(blog).UTC().Format(time.RFC3339),
+			Author:  atomPerson{Name: blog.AuthorUsername},
+			Content: atomContent{Type: "html", Value: blog.ContentHTML},
+		}
+		if published := blogPublished(blog); !published.IsZero() {
+			entry.Published = published.UTC().Format(time.RFC3339)
+		}
+		for _, tag := range blog.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return encodeXML(feed)
+}
+
+func encodeXML(v any) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// JSON Feed 1.1, https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string           `json:"id"`
+	URL           string           `json:"url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	DatePublished string           `json:"date_published,omitempty"`
+	DateModified  string           `json:"date_modified,omitempty"`
+	Authors       []jsonFeedAuthor `json:"authors,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+func renderJSONFeed(info feedInfo, blogs []*domain.Blog, base string) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       info.Title,
+		HomePageURL: info.HomeURL,
+		FeedURL:     info.SelfURL,
+		Items:       make([]jsonFeedItem, 0, len(blogs)),
+	}
+	for _, blog := range blogs {
+		item := jsonFeedItem{
+			ID:          blog.ID,
+			URL:         blogURL(base, blog),
+			Title:       blog.Title,
+			ContentHTML: blog.ContentHTML,
+			Tags:        blog.Tags,
+		}
+		if published := blogPublished(blog); !published.IsZero() {
+			item.DatePublished = published.UTC().Format(time.RFC3339)
+		}
+		if updated := blogUpdated(blog); !updated.IsZero() {
+			item.DateModified = updated.UTC().Format(time.RFC3339)
+		}
+		if blog.AuthorUsername != "" {
+			item.Authors = []jsonFeedAuthor{{Name: blog.AuthorUsername}}
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return json.Marshal(feed)
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newFeedRouter(blogUsecase domain.BlogUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	feedController := NewFeedController(blogUsecase, cache.NewInMemoryCache(time.Minute, time.Minute), "https://blog.example.com/")
+	r := gin.New()
+	for _, prefix := range []string{"", "/tags/:tag", "/authors/:author_id"} {
+		r.GET("/feeds"+prefix+"/rss.xml", feedController.RSS)
+		r.GET("/feeds"+prefix+"/atom.xml", feedController.Atom)
+		r.GET("/feeds"+prefix+"/feed.json", feedController.JSON)
+	}
+	return r
+}
+
+func feedBlogs() []*domain.Blog {
+	published := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 5, 2, 12, 30, 0, 0, time.UTC)
+	return []*domain.Blog{
+		{ID: "2", Title: "Second & last", Slug: "second-last", ContentHTML: "<p>Hello <em>there</em></p>", AuthorUsername: "jane", Tags: []string{"go"}, PublishedAt: &published, UpdatedAt: &updated},
+		{ID: "1", Title: "First", ContentHTML: "<p>First post</p>", AuthorUsername: "john", CreatedAt: &published, UpdatedAt: &published},
+	}
+}
+
+func TestFeedController_RSS(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("ListBlogs", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+		return filter["sortBy"] == "published_at" && filter["order"] == "desc" && filter["tags"] == nil
+	}), 1, feedSize).Return(feedBlogs(), &domain.Pagination{}, nil).Once()
+	r := newFeedRouter(mockBlogUsecase)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds/rss.xml", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "Thu, 02 May 2024 12:30:00 GMT", w.Header().Get("Last-Modified"))
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	var feed rssFeed
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Equal(t, "2.0", feed.Version)
+	assert.Contains(t, w.Body.String(), `<atom:link href="https://blog.example.com/feeds/rss.xml" rel="self" type="application/rss+xml"></atom:link>`)
+	assert.Contains(t, w.Body.String(), `<dc:creator>jane</dc:creator>`)
+	if assert.Len(t, feed.Channel.Items, 2) {
+		item := feed.Channel.Items[0]
+		assert.Equal(t, "Second & last", item.Title)
+		assert.Equal(t, "https://blog.example.com/blogs/slug/second-last", item.Link)
+		assert.Equal(t, "Wed, 01 May 2024 10:00:00 +0000", item.PubDate)
+		assert.Equal(t, "<p>Hello <em>there</em></p>", item.Description)
+		assert.Equal(t, []string{"go"}, item.Categories)
+		assert.Equal(t, "https://blog.example.com/blogs/1", feed.Channel.Items[1].Link)
+	}
+
+	// the second request is served from the cache and revalidates against the ETag
+	req := httptest.NewRequest(http.MethodGet, "/feeds/rss.xml", nil)
+	req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w2 := httptest.NewRecorder()
+	r.ServeHTTP(w2, req)
+
+	assert.Equal(t, http.StatusNotModified, w2.Code)
+	assert.Empty(t, w2.Body.Bytes())
+	mockBlogUsecase.AssertExpectations(t)
+}
+
+func TestFeedController_IfModifiedSince(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("ListBlogs", mock.Anything, mock.Anything, 1, feedSize).Return(feedBlogs(), &domain.Pagination{}, nil).Once()
+	r := newFeedRouter(mockBlogUsecase)
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/atom.xml", nil)
+	req.Header.Set("If-Modified-Since", "Thu, 02 May 2024 12:30:00 GMT")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/atom.xml", nil)
+	req.Header.Set("If-Modified-Since", "Wed, 01 May 2024 12:30:00 GMT")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestFeedController_AtomForTag(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("ListBlogs", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+		tags, _ := filter["tags"].([]string)
+		return len(tags) == 1 && tags[0] == "go"
+	}), 1, feedSize).Return(feedBlogs()[:1], &domain.Pagination{}, nil)
+	r := newFeedRouter(mockBlogUsecase)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds/tags/go/atom.xml", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", w.Header().Get("Content-Type"))
+	var feed atomFeed
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Equal(t, "Posts tagged go", feed.Title)
+	assert.Equal(t, "2024-05-02T12:30:00Z", feed.Updated)
+	if assert.Len(t, feed.Entries, 1) {
+		entry := feed.Entries[0]
+		assert.Equal(t, "https://blog.example.com/blogs/2", entry.ID)
+		assert.Equal(t, "2024-05-01T10:00:00Z", entry.Published)
+		assert.Equal(t, "jane", entry.Author.Name)
+		assert.Equal(t, "html", entry.Content.Type)
+		assert.Equal(t, "<p>Hello <em>there</em></p>", entry.Content.Value)
+	}
+	mockBlogUsecase.AssertExpectations(t)
+}
+
+func TestFeedController_JSONForAuthor(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("GetAuthor", mock.Anything, "author1").Return(&domain.User{ID: "author1", Username: "jane"}, nil)
+	mockBlogUsecase.On("ListBlogs", mock.Anything, mock.MatchedBy(func(filter map[string]any) bool {
+		return filter["author_id"] == "author1"
+	}), 1, feedSize).Return(feedBlogs()[:1], &domain.Pagination{}, nil)
+	r := newFeedRouter(mockBlogUsecase)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds/authors/author1/feed.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/feed+json; charset=utf-8", w.Header().Get("Content-Type"))
+	var feed jsonFeed
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", feed.Version)
+	assert.Equal(t, "Posts by jane", feed.Title)
+	assert.Equal(t, "https://blog.example.com/feeds/authors/author1/feed.json", feed.FeedURL)
+	if assert.Len(t, feed.Items, 1) {
+		assert.Equal(t, "https://blog.example.com/blogs/slug/second-last", feed.Items[0].URL)
+		assert.Equal(t, "2024-05-02T12:30:00Z", feed.Items[0].DateModified)
+		assert.Equal(t, []jsonFeedAuthor{{Name: "jane"}}, feed.Items[0].Authors)
+	}
+	mockBlogUsecase.AssertExpectations(t)
+}
+
+func TestFeedController_AuthorWithoutPosts(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("GetAuthor", mock.Anything, "author1").Return(&domain.User{ID: "author1", Username: "jane"}, nil)
+	mockBlogUsecase.On("ListBlogs", mock.Anything, mock.Anything, 1, feedSize).Return([]*domain.Blog{}, &domain.Pagination{}, nil)
+	r := newFeedRouter(mockBlogUsecase)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds/authors/author1/feed.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var feed jsonFeed
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	assert.Equal(t, "Posts by jane", feed.Title)
+	assert.Empty(t, feed.Items)
+}
+
+func TestFeedController_UnknownAuthor(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("GetAuthor", mock.Anything, "nobody").Return(nil, domain.ErrUserNotFound)
+	r := newFeedRouter(mockBlogUsecase)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feeds/authors/nobody/rss.xml", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockBlogUsecase.AssertNotCalled(t, "ListBlogs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFeedController_IgnoresRequestHost(t *testing.T) {
+	mockBlogUsecase := new(MockBlogUsecase)
+	mockBlogUsecase.On("ListBlogs", mock.Anything, mock.Anything, 1, feedSize).Return(feedBlogs(), &domain.Pagination{}, nil).Once()
+	r := newFeedRouter(mockBlogUsecase)
+
+	for _, host := range []string{"evil.example.com", "other.example.com"} {
+		req := httptest.NewRequest(http.MethodGet, "/feeds/feed.json", nil)
+		req.Host = host
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), host)
+		assert.Contains(t, w.Body.String(), "https://blog.example.com/feeds/feed.json")
+	}
+	// both hosts were served the one cached feed
+	mockBlogUsecase.AssertExpectations(t)
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFollowPageSize caps how many follows one page of a followers or following list holds
+const maxFollowPageSize = 100
+
+// maxFeedPageSize caps how many posts one page of the home feed holds
+const maxFeedPageSize = 50
+
+type FollowDTO struct {
+	FollowerID string    `json:"follower_id"`
+	TargetType string    `json:"target_type"`
+	TargetID   string    `json:"target_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type FollowController struct {
+	usecase domain.FollowUsecase
+}
+
+func NewFollowController(usecase domain.FollowUsecase) *FollowController {
+	return &FollowController{usecase: usecase}
+}
+
+func (c *FollowController) FollowAuthor(ctx *gin.Context) {
+	c.changeFollow(ctx, c.usecase.Follow, domain.FollowTargetAuthor, ctx.Param("id"), true)
+}
+
+func (c *FollowController) UnfollowAuthor(ctx *gin.Context) {
+	c.changeFollow(ctx, c.usecase.Unfollow, domain.FollowTargetAuthor, ctx.Param("id"), false)
+}
+
+func (c *FollowController) FollowTag(ctx *gin.Context) {
+	c.changeFollow(ctx, c.usecase.Follow, domain.FollowTargetTag, ctx.Param("tag"), true)
+}
+
+func (c *FollowController) UnfollowTag(ctx *gin.Context) {
+	c.changeFollow(ctx, c.usecase.Unfollow, domain.FollowTargetTag, ctx.Param("tag"), false)
+}
+
+// ListFollowers lists the users following the author in the path, newest first
+func (c *FollowController) ListFollowers(ctx *gin.Context) {
+	page, limit := followPage(ctx)
+	follows, pagination, err := c.usecase.ListFollowers(ctx, domain.FollowTargetAuthor, ctx.Param("id"), page, limit)
+	if err != nil {
+		respondFollowError(ctx, err)
+		return
+	}
+	respondFollows(ctx, follows, pagination)
+}
+
+// ListFollowing lists the authors the user in the path follows, or their tags with ?type=tag
+func (c *FollowController) ListFollowing(ctx *gin.Context) {
+	targetType := domain.FollowTarget(ctx.DefaultQuery("type", string(domain.FollowTargetAuthor)))
+	page, limit := followPage(ctx)
+	follows, pagination, err := c.usecase.ListFollowing(ctx, ctx.Param("id"), targetType, page, limit)
+	if err != nil {
+		respondFollowError(ctx, err)
+		return
+	}
+	respondFollows(ctx, follows, pagination)
+}
+
+// Feed serves the caller's home feed. Further pages are read by passing next_cursor back as cursor.
+func (c *FollowController) Feed(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	limit := 20
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := parseInt(l); err == nil && v > 0 {
+			limit = min(v, maxFeedPageSize)
+		}
+	}
+	blogs, pagination, err := c.usecase.Feed(ctx, userID, ctx.Query("cursor"), limit)
+	if err != nil {
+		respondFollowError(ctx, err)
+		return
+	}
+	dtos := make([]*BlogDTO, len(blogs))
+	for i, b := range blogs {
+		dtos[i] = ConvertFromDomain(b)
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        dtos,
+		"next_cursor": pagination.NextCursor,
+	})
+}
+
+// changeFollow follows or unfollows a target on behalf of the caller
+func (c *FollowController) changeFollow(ctx *gin.Context, change func(context.Context, string, domain.FollowTarget, string) error, targetType domain.FollowTarget, targetID string, following bool) {
+	userID := ctx.GetString("user_id")
+	if userID == "" {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+		return
+	}
+	if err := change(ctx, userID, targetType, targetID); err != nil {
+		respondFollowError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"target_type": targetType, "target_id": targetID, "following": following})
+}
+
+func followPage(ctx *gin.Context) (int, int) {
+	page, limit := 1, 20
+	if p := ctx.Query("page"); p != "" {
+		if v, err := parseInt(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := parseInt(l); err == nil && v > 0 {
+			limit = min(v, maxFollowPageSize)
+		}
+	}
+	return page, limit
+}
+
+func respondFollows(ctx *gin.Context, follows []*domain.Follow, pagination *domain.Pagination) {
+	dtos := make([]FollowDTO, len(follows))
+	for i, follow := range follows {
+		dtos[i] = FollowDTO{
+			FollowerID: follow.FollowerID,
+			TargetType: string(follow.TargetType),
+			TargetID:   follow.TargetID,
+			CreatedAt:  follow.CreatedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": dtos,
+		"pagination": gin.H{
+			"total":    pagination.Total,
+			"page":     pagination.Page,
+			"limit":    pagination.Limit,
+			"has_next": pagination.HasNext,
+			"has_prev": pagination.HasPrev,
+		},
+	})
+}
+
+func respondFollowError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrCannotFollowSelf), errors.Is(err, domain.ErrInvalidFollowTarget), errors.Is(err, domain.ErrInvalidCursor):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
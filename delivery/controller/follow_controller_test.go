@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"g3-g65-bsp/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockFollowUsecase struct {
+	mock.Mock
+}
+
+func (m *MockFollowUsecase) Follow(ctx context.Context, userID string, targetType domain.FollowTarget, targetID string) error {
+	args := m.Called(ctx, userID, targetType, targetID)
+	return args.Error(0)
+}
+
+func (m *MockFollowUsecase) Unfollow(ctx context.Context, userID string, targetType domain.FollowTarget, targetID string) error {
+	args := m.Called(ctx, userID, targetType, targetID)
+	return args.Error(0)
+}
+
+func (m *MockFollowUsecase) ListFollowers(ctx context.Context, targetType domain.FollowTarget, targetID string, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	args := m.Called(ctx, targetType, targetID, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Follow), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockFollowUsecase) ListFollowing(ctx context.Context, userID string, targetType domain.FollowTarget, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	args := m.Called(ctx, userID, targetType, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Follow), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockFollowUsecase) Feed(ctx context.Context, userID, cursor string, limit int) ([]*domain.Blog, *domain.Pagination, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Blog), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func TestFollowController_FollowAuthor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(MockFollowUsecase)
+		followController := NewFollowController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "author"}}
+		mockUsecase.On("Follow", mock.Anything, "reader", domain.FollowTargetAuthor, "author").Return(nil).Once()
+
+		followController.FollowAuthor(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"target_type":"author","target_id":"author","following":true}`, w.Body.String())
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("following yourself", func(t *testing.T) {
+		mockUsecase := new(MockFollowUsecase)
+		followController := NewFollowController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "reader"}}
+		mockUsecase.On("Follow", mock.Anything, "reader", domain.FollowTargetAuthor, "reader").Return(domain.ErrCannotFollowSelf).Once()
+
+		followController.FollowAuthor(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown author", func(t *testing.T) {
+		mockUsecase := new(MockFollowUsecase)
+		followController := NewFollowController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "ghost"}}
+		mockUsecase.On("Follow", mock.Anything, "reader", domain.FollowTargetAuthor, "ghost").Return(domain.ErrUserNotFound).Once()
+
+		followController.FollowAuthor(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestFollowController_UnfollowTag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockFollowUsecase)
+	followController := NewFollowController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "reader")
+	c.Params = gin.Params{gin.Param{Key: "tag", Value: "go"}}
+	mockUsecase.On("Unfollow", mock.Anything, "reader", domain.FollowTargetTag, "go").Return(nil).Once()
+
+	followController.UnfollowTag(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"target_type":"tag","target_id":"go","following":false}`, w.Body.String())
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestFollowController_ListFollowing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockUsecase := new(MockFollowUsecase)
+	followController := NewFollowController(mockUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{gin.Param{Key: "id", Value: "reader"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/users/reader/following?type=tag&limit=500", nil)
+	total := 1
+	follows := []*domain.Follow{{FollowerID: "reader", TargetType: domain.FollowTargetTag, TargetID: "go"}}
+	mockUsecase.On("ListFollowing", mock.Anything, "reader", domain.FollowTargetTag, 1, maxFollowPageSize).
+		Return(follows, &domain.Pagination{Total: &total, Page: 1, Limit: maxFollowPageSize}, nil).Once()
+
+	followController.ListFollowing(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data []FollowDTO `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Data, 1) {
+		assert.Equal(t, "go", response.Data[0].TargetID)
+	}
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestFollowController_Feed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockUsecase := new(MockFollowUsecase)
+		followController := NewFollowController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Request, _ = http.NewRequest(http.MethodGet, "/feed?cursor=abc&limit=5", nil)
+		blogs := []*domain.Blog{{ID: "1", Title: "Hello", Metrics: &domain.Metrics{}}}
+		mockUsecase.On("Feed", mock.Anything, "reader", "abc", 5).Return(blogs, &domain.Pagination{NextCursor: "next"}, nil).Once()
+
+		followController.Feed(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data       []BlogDTO `json:"data"`
+			NextCursor string    `json:"next_cursor"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Data, 1)
+		assert.Equal(t, "next", response.NextCursor)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		mockUsecase := new(MockFollowUsecase)
+		followController := NewFollowController(mockUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "reader")
+		c.Request, _ = http.NewRequest(http.MethodGet, "/feed?cursor=bad", nil)
+		mockUsecase.On("Feed", mock.Anything, "reader", "bad", 20).Return(nil, nil, domain.ErrInvalidCursor).Once()
+
+		followController.Feed(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
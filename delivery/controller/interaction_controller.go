@@ -1,23 +1,40 @@
 package controller
 
 import (
+	"errors"
 	"g3-g65-bsp/domain"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// LikeRequest is the body of the original like endpoint; preftype may be any configured reaction type
 type LikeRequest struct {
-	Preftype string `json:"preftype" binding:"required,oneof=like dislike"`
+	Preftype string `json:"preftype" binding:"required"`
+}
+
+type ReactionRequest struct {
+	Type string `json:"type" binding:"required"`
+}
+
+// ReactionDTO is one entry in the list of who reacted to a blog
+type ReactionDTO struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username,omitempty"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type CommentRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content  string `json:"content" binding:"required"`
+	ParentID string `json:"parent_id"`
 }
 
 func (c *CommentRequest) ConvertToDomain() *domain.Comment {
 	return &domain.Comment{
 		Content:        c.Content,
+		ParentID:       c.ParentID,
 	}
 }
 
@@ -31,6 +48,7 @@ func NewInteractionController(usecase domain.InteractionUsecase) *InteractionCon
 	}	
 }
 
+// LikeBlog toggles a reaction on a blog through the original like endpoint
 func (c *InteractionController) LikeBlog(ctx *gin.Context) {
 	userID := ctx.GetString("user_id")
 	blogID := ctx.Param("id")
@@ -45,12 +63,122 @@ func (c *InteractionController) LikeBlog(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.usecase.LikeBlog(ctx, userID, blogID, req.Preftype); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to like blog"})
+	reacted, err := c.usecase.ReactToBlog(ctx, userID, blogID, ctx.GetString("role"), req.Preftype)
+	if err != nil {
+		respondReactionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "reaction updated successfully", "reacted": reacted})
+}
+
+// ReactToBlog toggles one of the user's reactions on a blog
+func (c *InteractionController) ReactToBlog(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	blogID := ctx.Param("id")
+	if blogID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "blog_id is required"})
+		return
+	}
+
+	var req ReactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	reacted, err := c.usecase.ReactToBlog(ctx, userID, blogID, ctx.GetString("role"), req.Type)
+	if err != nil {
+		respondReactionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "reaction updated successfully", "type": req.Type, "reacted": reacted})
+}
+
+// ReactToComment toggles one of the user's reactions on a comment
+func (c *InteractionController) ReactToComment(ctx *gin.Context) {
+	userID := ctx.GetString("user_id")
+	blogID := ctx.Param("id")
+	commentID := ctx.Param("comment_id")
+	if blogID == "" || commentID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "blog_id and comment_id are required"})
+		return
+	}
+
+	var req ReactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	reacted, err := c.usecase.ReactToComment(ctx, userID, blogID, commentID, ctx.GetString("role"), req.Type)
+	if err != nil {
+		respondReactionError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "blog liked successfully"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "reaction updated successfully", "type": req.Type, "reacted": reacted})
+}
+
+// maxReactionPageSize caps how many reactions one page of the reactors list holds
+const maxReactionPageSize = 100
+
+// ListReactions shows the blog's author or an admin who reacted to it, newest first, optionally filtered by type
+func (c *InteractionController) ListReactions(ctx *gin.Context) {
+	blogID := ctx.Param("id")
+	page, limit := 1, 20
+	if p := ctx.Query("page"); p != "" {
+		if v, err := parseInt(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := parseInt(l); err == nil && v > 0 {
+			limit = min(v, maxReactionPageSize)
+		}
+	}
+
+	reactions, pagination, err := c.usecase.ListReactions(ctx, blogID, ctx.GetString("user_id"), ctx.GetString("role"), ctx.Query("type"), page, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthorized) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		respondReactionError(ctx, err)
+		return
+	}
+
+	dtos := make([]ReactionDTO, len(reactions))
+	for i, reaction := range reactions {
+		dtos[i] = ReactionDTO{
+			UserID:    reaction.UserID,
+			Username:  reaction.Username,
+			Type:      reaction.Type,
+			CreatedAt: reaction.CreatedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": dtos,
+		"pagination": gin.H{
+			"total":    pagination.Total,
+			"page":     pagination.Page,
+			"limit":    pagination.Limit,
+			"has_next": pagination.HasNext,
+			"has_prev": pagination.HasPrev,
+		},
+	})
+}
+
+func respondReactionError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidpreftype):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrBlogNotFound), errors.Is(err, domain.ErrCommentNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update reaction"})
+	}
 }
 
 func (c *InteractionController) CommentOnBlog(ctx *gin.Context) {
@@ -68,12 +196,22 @@ func (c *InteractionController) CommentOnBlog(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.usecase.CommentOnBlog(ctx, userID, blogID, comment.ConvertToDomain()); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to comment on blog"})
+	newComment := comment.ConvertToDomain()
+	if err := c.usecase.CommentOnBlog(ctx, userID, blogID, ctx.GetString("role"), newComment); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBlogNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrCommentNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "parent comment not found"})
+		case errors.Is(err, domain.ErrCommentTooDeep):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to comment on blog"})
+		}
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "comment added successfully"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "comment added successfully", "comment_id": newComment.ID})
 }
 
 func (c *InteractionController) UpdateComment(ctx *gin.Context) {
@@ -92,7 +230,11 @@ func (c *InteractionController) UpdateComment(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.usecase.UpdateComment(ctx, userID, blogID, commentID, comment.Content); err != nil {
+	if err := c.usecase.UpdateComment(ctx, userID, blogID, commentID, ctx.GetString("role"), comment.Content); err != nil {
+		if errors.Is(err, domain.ErrBlogNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update comment"})
 		return
 	}
@@ -111,9 +253,66 @@ func (c *InteractionController) DeleteComment(ctx *gin.Context) {
 	}
 
 	if err := c.usecase.DeleteComment(ctx, userID, blogID, commentID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete comment"})
+		switch {
+		case errors.Is(err, domain.ErrCommentNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrUnauthorized):
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete comment"})
+		}
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "comment deleted successfully"})
+}
+
+// maxCommentPageSize caps how many threads one page of comments holds, since each brings all its replies
+const maxCommentPageSize = 50
+
+// ListComments returns a page of a blog's comment threads as reply trees, or as a flat list in thread
+// order with ?view=flat. page and limit count top-level comments.
+func (c *InteractionController) ListComments(ctx *gin.Context) {
+	blogID := ctx.Param("id")
+	view := ctx.DefaultQuery("view", "tree")
+	if view != "tree" && view != "flat" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "view must be tree or flat"})
+		return
+	}
+	page, limit := 1, 20
+	if p := ctx.Query("page"); p != "" {
+		if v, err := parseInt(p); err == nil && v > 0 {
+			page = v
+		}
+	}
+	if l := ctx.Query("limit"); l != "" {
+		if v, err := parseInt(l); err == nil && v > 0 {
+			limit = min(v, maxCommentPageSize)
+		}
+	}
+
+	comments, pagination, err := c.usecase.ListComments(ctx, blogID, ctx.GetString("user_id"), ctx.GetString("role"), view == "flat", page, limit)
+	if err != nil {
+		if errors.Is(err, domain.ErrBlogNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list comments"})
+		return
+	}
+
+	dtos := make([]CommentDTO, len(comments))
+	for i, comment := range comments {
+		dtos[i] = ConvertCommentFromDomain(comment)
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"data": dtos,
+		"pagination": gin.H{
+			"total":    pagination.Total,
+			"page":     pagination.Page,
+			"limit":    pagination.Limit,
+			"has_next": pagination.HasNext,
+			"has_prev": pagination.HasPrev,
+		},
+	})
 }
\ No newline at end of file
@@ -19,18 +19,31 @@ type MockInteractionUsecase struct {
 	mock.Mock
 }
 
-func (m *MockInteractionUsecase) LikeBlog(ctx context.Context, userID, blogID, prefType string) error {
-	args := m.Called(ctx, userID, blogID, prefType)
-	return args.Error(0)
+func (m *MockInteractionUsecase) ReactToBlog(ctx context.Context, userID, blogID, role, reactionType string) (bool, error) {
+	args := m.Called(ctx, userID, blogID, role, reactionType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInteractionUsecase) ReactToComment(ctx context.Context, userID, blogID, commentID, role, reactionType string) (bool, error) {
+	args := m.Called(ctx, userID, blogID, commentID, role, reactionType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockInteractionUsecase) ListReactions(ctx context.Context, blogID, userID, role, reactionType string, page, limit int) ([]*domain.Reaction, *domain.Pagination, error) {
+	args := m.Called(ctx, blogID, userID, role, reactionType, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Reaction), args.Get(1).(*domain.Pagination), args.Error(2)
 }
 
-func (m *MockInteractionUsecase) CommentOnBlog(ctx context.Context, userID, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, userID, blogID, comment)
+func (m *MockInteractionUsecase) CommentOnBlog(ctx context.Context, userID, blogID, role string, comment *domain.Comment) error {
+	args := m.Called(ctx, userID, blogID, role, comment)
 	return args.Error(0)
 }
 
-func (m *MockInteractionUsecase) UpdateComment(ctx context.Context, userID, blogID, commentID, content string) error {
-	args := m.Called(ctx, userID, blogID, commentID, content)
+func (m *MockInteractionUsecase) UpdateComment(ctx context.Context, userID, blogID, commentID, role, content string) error {
+	args := m.Called(ctx, userID, blogID, commentID, role, content)
 	return args.Error(0)
 }
 
@@ -39,6 +52,14 @@ func (m *MockInteractionUsecase) DeleteComment(ctx context.Context, userID, blog
 	return args.Error(0)
 }
 
+func (m *MockInteractionUsecase) ListComments(ctx context.Context, blogID, userID, role string, flat bool, page, limit int) ([]*domain.Comment, *domain.Pagination, error) {
+	args := m.Called(ctx, blogID, userID, role, flat, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Comment), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
 func TestInteractionController_LikeBlog(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -49,6 +70,7 @@ func TestInteractionController_LikeBlog(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Set("user_id", "user123")
+		c.Set("role", "user")
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
 
 		reqBody := LikeRequest{Preftype: "like"}
@@ -56,13 +78,166 @@ func TestInteractionController_LikeBlog(t *testing.T) {
 		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/blog123/like", bytes.NewBuffer(jsonBody))
 		c.Request.Header.Set("Content-Type", "application/json")
 
-		mockInteractionUsecase.On("LikeBlog", mock.Anything, "user123", "blog123", "like").Return(nil)
+		mockInteractionUsecase.On("ReactToBlog", mock.Anything, "user123", "blog123", "user", "like").Return(true, nil)
 
 		interactionController.LikeBlog(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockInteractionUsecase.AssertExpectations(t)
 	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := map[error]int{
+			domain.ErrInvalidpreftype: http.StatusBadRequest,
+			domain.ErrBlogNotFound:    http.StatusNotFound,
+		}
+		for usecaseErr, status := range cases {
+			mockInteractionUsecase := new(MockInteractionUsecase)
+			interactionController := NewInteractionController(mockInteractionUsecase)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", "user123")
+			c.Set("role", "user")
+			c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+			c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/blog123/like", bytes.NewBufferString(`{"preftype":"like"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			mockInteractionUsecase.On("ReactToBlog", mock.Anything, "user123", "blog123", "user", "like").Return(false, usecaseErr)
+
+			interactionController.LikeBlog(c)
+
+			assert.Equal(t, status, w.Code, usecaseErr.Error())
+		}
+	})
+}
+
+func TestInteractionController_ReactToBlog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/blog123/reactions", bytes.NewBufferString(`{"type":"🎉"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		mockInteractionUsecase.On("ReactToBlog", mock.Anything, "user123", "blog123", "user", "🎉").Return(true, nil)
+
+		interactionController.ReactToBlog(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]any
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, true, body["reacted"])
+		assert.Equal(t, "🎉", body["type"])
+	})
+
+	t.Run("missing type", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/blog123/reactions", bytes.NewBufferString(`{}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		interactionController.ReactToBlog(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockInteractionUsecase.AssertNotCalled(t, "ReactToBlog")
+	})
+}
+
+func TestInteractionController_ReactToComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := map[error]int{
+		nil:                       http.StatusOK,
+		domain.ErrInvalidpreftype: http.StatusBadRequest,
+		domain.ErrCommentNotFound: http.StatusNotFound,
+		domain.ErrBlogNotFound:    http.StatusNotFound,
+		errors.New("db down"):     http.StatusInternalServerError,
+	}
+	for usecaseErr, status := range cases {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}, gin.Param{Key: "comment_id", Value: "c1"}}
+		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/blog123/comments/c1/reactions", bytes.NewBufferString(`{"type":"👍"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		mockInteractionUsecase.On("ReactToComment", mock.Anything, "user123", "blog123", "c1", "user", "👍").Return(false, usecaseErr)
+
+		interactionController.ReactToComment(c)
+
+		assert.Equal(t, status, w.Code, "%v", usecaseErr)
+	}
+}
+
+func TestInteractionController_ListReactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("success", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "author1")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/reactions?type=🎉&page=2&limit=500", nil)
+		total := 21
+		reactions := []*domain.Reaction{{UserID: "user1", Username: "one", Type: "🎉"}}
+		pagination := &domain.Pagination{Total: &total, Page: 2, Limit: maxReactionPageSize, HasPrev: true}
+		mockInteractionUsecase.On("ListReactions", mock.Anything, "blog123", "author1", "user", "🎉", 2, maxReactionPageSize).Return(reactions, pagination, nil)
+
+		interactionController.ListReactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Data       []ReactionDTO  `json:"data"`
+			Pagination map[string]any `json:"pagination"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		if assert.Len(t, body.Data, 1) {
+			assert.Equal(t, "one", body.Data[0].Username)
+			assert.Equal(t, "🎉", body.Data[0].Type)
+		}
+		assert.Equal(t, float64(21), body.Pagination["total"])
+		mockInteractionUsecase.AssertExpectations(t)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := map[error]int{
+			domain.ErrUnauthorized:    http.StatusForbidden,
+			domain.ErrBlogNotFound:    http.StatusNotFound,
+			domain.ErrInvalidpreftype: http.StatusBadRequest,
+		}
+		for usecaseErr, status := range cases {
+			mockInteractionUsecase := new(MockInteractionUsecase)
+			interactionController := NewInteractionController(mockInteractionUsecase)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", "user123")
+			c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+			c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/reactions", nil)
+			mockInteractionUsecase.On("ListReactions", mock.Anything, "blog123", "user123", "", "", 1, 20).Return(nil, nil, usecaseErr)
+
+			interactionController.ListReactions(c)
+
+			assert.Equal(t, status, w.Code, usecaseErr.Error())
+		}
+	})
 }
 
 func TestInteractionController_CommentOnBlog(t *testing.T) {
@@ -75,6 +250,7 @@ func TestInteractionController_CommentOnBlog(t *testing.T) {
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Set("user_id", "user123")
+		c.Set("role", "user")
 		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
 
 		reqBody := CommentRequest{Content: "Test Comment"}
@@ -82,7 +258,7 @@ func TestInteractionController_CommentOnBlog(t *testing.T) {
 		c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/blog123/comments", bytes.NewBuffer(jsonBody))
 		c.Request.Header.Set("Content-Type", "application/json")
 
-		mockInteractionUsecase.On("CommentOnBlog", mock.Anything, "user123", "blog123", mock.AnythingOfType("*domain.Comment")).Return(nil)
+		mockInteractionUsecase.On("CommentOnBlog", mock.Anything, "user123", "blog123", "user", mock.AnythingOfType("*domain.Comment")).Return(nil)
 
 		interactionController.CommentOnBlog(c)
 
@@ -91,6 +267,135 @@ func TestInteractionController_CommentOnBlog(t *testing.T) {
 	})
 }
 
+func TestInteractionController_ReplyToComment(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"success", nil, http.StatusOK},
+		{"parent missing", domain.ErrCommentNotFound, http.StatusNotFound},
+		{"blog not visible", domain.ErrBlogNotFound, http.StatusNotFound},
+		{"too deep", domain.ErrCommentTooDeep, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockInteractionUsecase := new(MockInteractionUsecase)
+			interactionController := NewInteractionController(mockInteractionUsecase)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("user_id", "user123")
+			c.Set("role", "user")
+			c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+			jsonBody, _ := json.Marshal(CommentRequest{Content: "Reply", ParentID: "comment123"})
+			c.Request, _ = http.NewRequest(http.MethodPost, "/blogs/comment/blog123", bytes.NewBuffer(jsonBody))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			mockInteractionUsecase.On("CommentOnBlog", mock.Anything, "user123", "blog123", "user", mock.MatchedBy(func(comment *domain.Comment) bool {
+				return comment.ParentID == "comment123"
+			})).Return(tc.err)
+
+			interactionController.CommentOnBlog(c)
+
+			assert.Equal(t, tc.expected, w.Code)
+			mockInteractionUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestInteractionController_ListComments(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reply := &domain.Comment{ID: "c2", ParentID: "c1", Depth: 1, Content: "Reply"}
+	tombstone := &domain.Comment{ID: "c1", Content: domain.DeletedCommentContent, Deleted: true, Replies: []*domain.Comment{reply}}
+	total := 3
+
+	t.Run("tree", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/comments", nil)
+		pagination := &domain.Pagination{Total: &total, Page: 1, Limit: 20, HasNext: false}
+		mockInteractionUsecase.On("ListComments", mock.Anything, "blog123", "user123", "", false, 1, 20).Return([]*domain.Comment{tombstone}, pagination, nil)
+
+		interactionController.ListComments(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data       []CommentDTO `json:"data"`
+			Pagination struct {
+				Total   int  `json:"total"`
+				Page    int  `json:"page"`
+				HasNext bool `json:"has_next"`
+			} `json:"pagination"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Data, 1) {
+			assert.True(t, response.Data[0].Deleted)
+			assert.Equal(t, "[deleted]", response.Data[0].Content)
+			if assert.Len(t, response.Data[0].Replies, 1) {
+				assert.Equal(t, "c1", response.Data[0].Replies[0].ParentID)
+				assert.Equal(t, 1, response.Data[0].Replies[0].Depth)
+			}
+		}
+		assert.Equal(t, 3, response.Pagination.Total)
+		assert.Equal(t, 1, response.Pagination.Page)
+		assert.False(t, response.Pagination.HasNext)
+	})
+
+	t.Run("flat", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/comments?view=flat&page=2&limit=500", nil)
+		pagination := &domain.Pagination{Total: &total, Page: 2, Limit: maxCommentPageSize, HasPrev: true}
+		mockInteractionUsecase.On("ListComments", mock.Anything, "blog123", "", "", true, 2, maxCommentPageSize).Return([]*domain.Comment{reply}, pagination, nil)
+
+		interactionController.ListComments(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockInteractionUsecase.AssertExpectations(t)
+	})
+
+	t.Run("invalid view", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/comments?view=nested", nil)
+
+		interactionController.ListComments(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("blog not found", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/comments", nil)
+		mockInteractionUsecase.On("ListComments", mock.Anything, "blog123", "", "", false, 1, 20).Return(nil, nil, domain.ErrBlogNotFound)
+
+		interactionController.ListComments(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
 func TestInteractionController_UpdateComment(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -108,13 +413,31 @@ func TestInteractionController_UpdateComment(t *testing.T) {
 		c.Request, _ = http.NewRequest(http.MethodPut, "/blogs/blog123/comments/comment123", bytes.NewBuffer(jsonBody))
 		c.Request.Header.Set("Content-Type", "application/json")
 
-		mockInteractionUsecase.On("UpdateComment", mock.Anything, "user123", "blog123", "comment123", "Updated Comment").Return(nil)
+		mockInteractionUsecase.On("UpdateComment", mock.Anything, "user123", "blog123", "comment123", "", "Updated Comment").Return(nil)
 
 		interactionController.UpdateComment(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockInteractionUsecase.AssertExpectations(t)
 	})
+
+	t.Run("blog the user cannot see", func(t *testing.T) {
+		mockInteractionUsecase := new(MockInteractionUsecase)
+		interactionController := NewInteractionController(mockInteractionUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "user123")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}, gin.Param{Key: "comment_id", Value: "comment123"}}
+		c.Request, _ = http.NewRequest(http.MethodPut, "/blogs/blog123/comments/comment123", bytes.NewBufferString(`{"content":"Updated Comment"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		mockInteractionUsecase.On("UpdateComment", mock.Anything, "user123", "blog123", "comment123", "user", "Updated Comment").Return(domain.ErrBlogNotFound)
+
+		interactionController.UpdateComment(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
 
 func TestInteractionController_DeleteComment(t *testing.T) {
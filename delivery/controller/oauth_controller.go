@@ -60,7 +60,7 @@ func (oc *OAuthController) HandleGoogleCallback(c *gin.Context) {
 	}
 
 	// Call the OAuthLogin usecase to handle token exchange, user info retrieval,
-	accessToken, refreshToken, accessExpirySeconds, user, err := oc.usecase.OAuthLogin(c.Request.Context(), *googleOauthConfig, code)
+	accessToken, refreshToken, accessExpirySeconds, user, err := oc.usecase.OAuthLogin(deviceContext(c), *googleOauthConfig, code)
 	if err != nil {
 		// Log the error for debugging purposes (optional, but recommended)
 		fmt.Printf("Error during OAuthLogin: %v\n", err)
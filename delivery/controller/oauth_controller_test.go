@@ -44,6 +44,7 @@ func TestOAuthController_HandleGoogleLogin(t *testing.T) {
 	t.Setenv("GOOGLE_OAUTH_CLIENT_SECRET", "test-client-secret")
 	t.Setenv("ACCESS_TOKEN_EXPIRY", "1m")
 	t.Setenv("REFRESH_TOKEN_EXPIRY", "1m")
+	t.Setenv("SITE_URL", "https://blog.example.com")
 	config.LoadConfig()
 
 	oauthController.HandleGoogleLogin(c)
@@ -60,6 +61,7 @@ func TestOAuthController_HandleGoogleCallback(t *testing.T) {
 	t.Setenv("GOOGLE_OAUTH_CLIENT_SECRET", "test-client-secret")
 	t.Setenv("ACCESS_TOKEN_EXPIRY", "1m")
 	t.Setenv("REFRESH_TOKEN_EXPIRY", "1m")
+	t.Setenv("SITE_URL", "https://blog.example.com")
 	config.LoadConfig()
 
 	t.Run("success", func(t *testing.T) {
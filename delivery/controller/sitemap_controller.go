@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"encoding/xml"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sitemapNamespace   = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapContentType = "application/xml; charset=utf-8"
+	sitemapCacheHeader = "public, max-age=3600"
+)
+
+// sitemapRef is a <sitemap> element in a sitemap index
+type sitemapRef struct {
+	XMLName xml.Name `xml:"sitemap"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+// sitemapURL is a <url> element in a sitemap
+type sitemapURL struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+type SitemapController struct {
+	sitemapUsecase domain.SitemapUsecase
+	siteURL        string
+}
+
+// NewSitemapController serves the sitemap index and its files. siteURL is the public base URL
+// used for links.
+func NewSitemapController(sitemapUsecase domain.SitemapUsecase, siteURL string) *SitemapController {
+	return &SitemapController{
+		sitemapUsecase: sitemapUsecase,
+		siteURL:        strings.TrimSuffix(siteURL, "/"),
+	}
+}
+
+// Index serves /sitemap.xml, the sitemap index pointing at one file per 50k pages of each section
+func (c *SitemapController) Index(ctx *gin.Context) {
+	chunks, err := c.sitemapUsecase.Index(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sitemap"})
+		return
+	}
+
+	index := sitemapIndex{XMLNS: sitemapNamespace, Sitemaps: make([]sitemapRef, 0, len(chunks))}
+	for _, chunk := range chunks {
+		loc := c.siteURL + "/sitemaps/" + string(chunk.Section) + "-" + strconv.Itoa(chunk.Page) + ".xml"
+		if chunk.After != "" {
+			loc += "?after=" + url.QueryEscape(chunk.After)
+		}
+		index.Sitemaps = append(index.Sitemaps, sitemapRef{
+			Loc:     loc,
+			LastMod: sitemapLastMod(chunk.LastModified),
+		})
+	}
+	body, err := encodeXML(index)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sitemap"})
+		return
+	}
+	ctx.Header("Cache-Control", sitemapCacheHeader)
+	ctx.Data(http.StatusOK, sitemapContentType, body)
+}
+
+// Chunk serves one sitemap file such as /sitemaps/blogs-2.xml?after=<id>, as linked from the index,
+// writing URLs as they come off the cursor
+func (c *SitemapController) Chunk(ctx *gin.Context) {
+	section, page, ok := parseSitemapFile(ctx.Param("file"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": domain.ErrSitemapNotFound.Error()})
+		return
+	}
+
+	var enc *xml.Encoder
+	err := c.sitemapUsecase.StreamChunk(ctx, section, page, ctx.Query("after"), func(entry *domain.SitemapEntry) error {
+		if enc == nil {
+			// the response is only started once there is something to send, so errors before it can still be reported
+			ctx.Header("Cache-Control", sitemapCacheHeader)
+			ctx.Header("Content-Type", sitemapContentType)
+			ctx.Status(http.StatusOK)
+			if _, err := io.WriteString(ctx.Writer, xml.Header+`<urlset xmlns="`+sitemapNamespace+`">`+"\n"); err != nil {
+				return err
+			}
+			enc = xml.NewEncoder(ctx.Writer)
+		}
+		return enc.Encode(sitemapURL{
+			Loc:     sitemapLoc(c.siteURL, section, entry),
+			LastMod: sitemapLastMod(entry.LastModified),
+		})
+	})
+
+	if enc == nil {
+		if errors.Is(err, domain.ErrSitemapNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to build sitemap"})
+		}
+		return
+	}
+	if err != nil {
+		// the status line is already out; leaving the document unterminated keeps crawlers from trusting it
+		infrastructure.Log.Printf("Streaming sitemap %s-%d failed: %v", section, page, err)
+		return
+	}
+	io.WriteString(ctx.Writer, "\n</urlset>\n")
+}
+
+// parseSitemapFile splits a file name like "blogs-2.xml" into its section and page
+func parseSitemapFile(file string) (domain.SitemapSection, int, bool) {
+	name, ok := strings.CutSuffix(file, ".xml")
+	if !ok {
+		return "", 0, false
+	}
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return "", 0, false
+	}
+	page, err := strconv.Atoi(name[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return domain.SitemapSection(name[:i]), page, true
+}
+
+// sitemapLoc links tags and authors to their feeds, the public pages there are for them
+func sitemapLoc(base string, section domain.SitemapSection, entry *domain.SitemapEntry) string {
+	switch section {
+	case domain.SitemapSectionTags:
+		return base + "/feeds/tags/" + url.PathEscape(entry.ID) + "/rss.xml"
+	case domain.SitemapSectionAuthors:
+		return base + "/feeds/authors/" + entry.ID + "/rss.xml"
+	default:
+		return blogURL(base, &domain.Blog{ID: entry.ID, Slug: entry.Slug})
+	}
+}
+
+func sitemapLastMod(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSitemapUsecase struct {
+	mock.Mock
+}
+
+func (m *MockSitemapUsecase) Index(ctx context.Context) ([]*domain.SitemapChunk, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.SitemapChunk), args.Error(1)
+}
+
+func (m *MockSitemapUsecase) StreamChunk(ctx context.Context, section domain.SitemapSection, page int, after string, fn func(*domain.SitemapEntry) error) error {
+	args := m.Called(ctx, section, page, after, fn)
+	if entries, ok := args.Get(0).([]*domain.SitemapEntry); ok {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func newSitemapRouter(sitemapUsecase domain.SitemapUsecase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	sitemapController := NewSitemapController(sitemapUsecase, "https://blog.example.com")
+	r := gin.New()
+	r.GET("/sitemap.xml", sitemapController.Index)
+	r.GET("/sitemaps/:file", sitemapController.Chunk)
+	return r
+}
+
+// validateSitemap checks a document against the sitemaps.org schema with xmllint
+func validateSitemap(t *testing.T, schema string, body []byte) {
+	t.Helper()
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint is not installed, skipping schema validation")
+	}
+	cmd := exec.Command(xmllint, "--noout", "--schema", "testdata/"+schema, "-")
+	cmd.Stdin = bytes.NewReader(body)
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func TestSitemapController_Index(t *testing.T) {
+	mockSitemapUsecase := new(MockSitemapUsecase)
+	lastModified := time.Date(2024, 5, 2, 12, 30, 0, 0, time.FixedZone("EAT", 3*60*60))
+	mockSitemapUsecase.On("Index", mock.Anything).Return([]*domain.SitemapChunk{
+		{Section: domain.SitemapSectionBlogs, Page: 1, LastModified: &lastModified},
+		{Section: domain.SitemapSectionBlogs, Page: 2, After: "665f1c2e9b1e8a0001a1b2c3", LastModified: &lastModified},
+		{Section: domain.SitemapSectionTags, Page: 1},
+	}, nil)
+	r := newSitemapRouter(mockSitemapUsecase)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, sitemapContentType, w.Header().Get("Content-Type"))
+	var index sitemapIndex
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &index))
+	if assert.Len(t, index.Sitemaps, 3) {
+		assert.Equal(t, "https://blog.example.com/sitemaps/blogs-2.xml?after=665f1c2e9b1e8a0001a1b2c3", index.Sitemaps[1].Loc)
+		assert.Equal(t, "2024-05-02T09:30:00Z", index.Sitemaps[1].LastMod)
+		assert.Equal(t, "https://blog.example.com/sitemaps/tags-1.xml", index.Sitemaps[2].Loc)
+		assert.Empty(t, index.Sitemaps[2].LastMod)
+	}
+	validateSitemap(t, "siteindex.xsd", w.Body.Bytes())
+}
+
+func TestSitemapController_Chunk(t *testing.T) {
+	updated := time.Date(2024, 5, 2, 12, 30, 0, 0, time.UTC)
+	cases := []struct {
+		file     string
+		section  domain.SitemapSection
+		page     int
+		after    string
+		entries  []*domain.SitemapEntry
+		expected []string
+	}{
+		{
+			file:    "blogs-2.xml?after=665f1c2e9b1e8a0001a1b2c2",
+			section: domain.SitemapSectionBlogs,
+			page:    2,
+			after:   "665f1c2e9b1e8a0001a1b2c2",
+			entries: []*domain.SitemapEntry{
+				{ID: "665f1c2e9b1e8a0001a1b2c3", Slug: "hello-world", LastModified: &updated},
+				{ID: "665f1c2e9b1e8a0001a1b2c4"},
+			},
+			expected: []string{
+				"https://blog.example.com/blogs/slug/hello-world",
+				"https://blog.example.com/blogs/665f1c2e9b1e8a0001a1b2c4",
+			},
+		},
+		{
+			file:     "tags-1.xml",
+			section:  domain.SitemapSectionTags,
+			page:     1,
+			entries:  []*domain.SitemapEntry{{ID: "c++ & go", LastModified: &updated}},
+			expected: []string{"https://blog.example.com/feeds/tags/c++%20&%20go/rss.xml"},
+		},
+		{
+			file:     "authors-1.xml",
+			section:  domain.SitemapSectionAuthors,
+			page:     1,
+			entries:  []*domain.SitemapEntry{{ID: "665f1c2e9b1e8a0001a1b2c5", LastModified: &updated}},
+			expected: []string{"https://blog.example.com/feeds/authors/665f1c2e9b1e8a0001a1b2c5/rss.xml"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			mockSitemapUsecase := new(MockSitemapUsecase)
+			mockSitemapUsecase.On("StreamChunk", mock.Anything, tc.section, tc.page, tc.after, mock.Anything).Return(tc.entries, nil)
+			r := newSitemapRouter(mockSitemapUsecase)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemaps/"+tc.file, nil))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, sitemapContentType, w.Header().Get("Content-Type"))
+			var urlset struct {
+				XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+				URLs    []sitemapURL `xml:"url"`
+			}
+			assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &urlset))
+			var locs []string
+			for _, u := range urlset.URLs {
+				locs = append(locs, u.Loc)
+			}
+			assert.Equal(t, tc.expected, locs)
+			assert.Equal(t, "2024-05-02T12:30:00Z", urlset.URLs[0].LastMod)
+			validateSitemap(t, "sitemap.xsd", w.Body.Bytes())
+			mockSitemapUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSitemapController_ChunkErrors(t *testing.T) {
+	t.Run("malformed file name", func(t *testing.T) {
+		mockSitemapUsecase := new(MockSitemapUsecase)
+		r := newSitemapRouter(mockSitemapUsecase)
+
+		for _, file := range []string{"blogs.xml", "blogs-1", "blogs-x.xml"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemaps/"+file, nil))
+			assert.Equal(t, http.StatusNotFound, w.Code, file)
+		}
+		mockSitemapUsecase.AssertNotCalled(t, "StreamChunk")
+	})
+
+	t.Run("page past the end", func(t *testing.T) {
+		mockSitemapUsecase := new(MockSitemapUsecase)
+		mockSitemapUsecase.On("StreamChunk", mock.Anything, domain.SitemapSectionBlogs, 9, "", mock.Anything).Return(nil, domain.ErrSitemapNotFound)
+		r := newSitemapRouter(mockSitemapUsecase)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemaps/blogs-9.xml", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("failure before the first entry", func(t *testing.T) {
+		mockSitemapUsecase := new(MockSitemapUsecase)
+		mockSitemapUsecase.On("StreamChunk", mock.Anything, domain.SitemapSectionBlogs, 1, "", mock.Anything).Return(nil, errors.New("db down"))
+		r := newSitemapRouter(mockSitemapUsecase)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemaps/blogs-1.xml", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("failure mid-stream leaves the document unterminated", func(t *testing.T) {
+		infrastructure.Log = log.New(io.Discard, "TEST: ", log.LstdFlags)
+		mockSitemapUsecase := new(MockSitemapUsecase)
+		entries := []*domain.SitemapEntry{{ID: "665f1c2e9b1e8a0001a1b2c3", Slug: "hello-world"}}
+		mockSitemapUsecase.On("StreamChunk", mock.Anything, domain.SitemapSectionBlogs, 1, "", mock.Anything).Return(entries, errors.New("cursor died"))
+		r := newSitemapRouter(mockSitemapUsecase)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sitemaps/blogs-1.xml", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "</urlset>")
+		assert.Error(t, xml.Unmarshal(w.Body.Bytes(), new(struct{})))
+	})
+}
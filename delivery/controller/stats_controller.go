@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"errors"
+	"g3-g65-bsp/domain"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	statsDateLayout = "2006-01-02"
+	// defaultStatsDays is how far back stats go when the request does not say
+	defaultStatsDays = 30
+)
+
+// StatsDTO is what happened to a blog, or all of an author's blogs, over a period
+type StatsDTO struct {
+	Views         int `json:"views"`
+	UniqueViewers int `json:"unique_viewers"`
+	Reactions     int `json:"reactions"`
+	Comments      int `json:"comments"`
+}
+
+func statsDTO(stats domain.BlogStats) StatsDTO {
+	return StatsDTO{
+		Views:         stats.Views,
+		UniqueViewers: stats.UniqueViewers,
+		Reactions:     stats.Reactions,
+		Comments:      stats.Comments,
+	}
+}
+
+// StatsBucketDTO is one day or week of a blog's time series
+type StatsBucketDTO struct {
+	Start string `json:"start"`
+	StatsDTO
+}
+
+// PostStatsDTO is one post on the author dashboard
+type PostStatsDTO struct {
+	BlogID string `json:"blog_id"`
+	Title  string `json:"title"`
+	StatsDTO
+}
+
+type AuthorDashboardDTO struct {
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	Totals StatsDTO       `json:"totals"`
+	Posts  []PostStatsDTO `json:"posts"`
+}
+
+type StatsController struct {
+	usecase domain.StatsUsecase
+}
+
+func NewStatsController(usecase domain.StatsUsecase) *StatsController {
+	return &StatsController{usecase: usecase}
+}
+
+// BlogStats serves a blog's time series. from and to are YYYY-MM-DD days in UTC, both inclusive, and
+// default to the last 30 days; granularity is day (the default) or week.
+func (c *StatsController) BlogStats(ctx *gin.Context) {
+	from, to, ok := parseStatsRange(ctx)
+	if !ok {
+		return
+	}
+	granularity := domain.StatsGranularity(ctx.DefaultQuery("granularity", string(domain.StatsGranularityDay)))
+
+	buckets, err := c.usecase.BlogStats(ctx, ctx.Param("id"), ctx.GetString("user_id"), ctx.GetString("role"), from, to, granularity)
+	if err != nil {
+		respondStatsError(ctx, err)
+		return
+	}
+
+	dtos := make([]StatsBucketDTO, len(buckets))
+	for i, bucket := range buckets {
+		dtos[i] = StatsBucketDTO{Start: bucket.Start.Format(statsDateLayout), StatsDTO: statsDTO(bucket.BlogStats)}
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"granularity": granularity,
+		"from":        from.Format(statsDateLayout),
+		"to":          to.Format(statsDateLayout),
+		"data":        dtos,
+	})
+}
+
+// Dashboard serves the totals across all of the signed-in author's posts, with each post's share, for
+// the same from and to as BlogStats
+func (c *StatsController) Dashboard(ctx *gin.Context) {
+	from, to, ok := parseStatsRange(ctx)
+	if !ok {
+		return
+	}
+
+	dashboard, err := c.usecase.AuthorDashboard(ctx, ctx.GetString("user_id"), from, to)
+	if err != nil {
+		respondStatsError(ctx, err)
+		return
+	}
+
+	posts := make([]PostStatsDTO, len(dashboard.Posts))
+	for i, post := range dashboard.Posts {
+		posts[i] = PostStatsDTO{BlogID: post.BlogID, Title: post.Title, StatsDTO: statsDTO(post.BlogStats)}
+	}
+	ctx.JSON(http.StatusOK, AuthorDashboardDTO{
+		From:   dashboard.From.Format(statsDateLayout),
+		To:     dashboard.To.Format(statsDateLayout),
+		Totals: statsDTO(dashboard.Totals),
+		Posts:  posts,
+	})
+}
+
+// parseStatsRange reads the from and to days, answering 400 itself when either is malformed
+func parseStatsRange(ctx *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now().UTC()
+	if v := ctx.Query("to"); v != "" {
+		parsed, err := time.Parse(statsDateLayout, v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date like 2006-01-02"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, 1-defaultStatsDays)
+	if v := ctx.Query("from"); v != "" {
+		parsed, err := time.Parse(statsDateLayout, v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date like 2006-01-02"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+	return from, to, true
+}
+
+func respondStatsError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidStatsRange), errors.Is(err, domain.ErrInvalidGranularity):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrUnauthorized):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrBlogNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load stats"})
+	}
+}
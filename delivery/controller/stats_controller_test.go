@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"g3-g65-bsp/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockStatsUsecase struct {
+	mock.Mock
+}
+
+func (m *MockStatsUsecase) BlogStats(ctx context.Context, blogID, userID, role string, from, to time.Time, granularity domain.StatsGranularity) ([]*domain.StatsBucket, error) {
+	args := m.Called(ctx, blogID, userID, role, from, to, granularity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StatsBucket), args.Error(1)
+}
+
+func (m *MockStatsUsecase) AuthorDashboard(ctx context.Context, userID string, from, to time.Time) (*domain.AuthorDashboard, error) {
+	args := m.Called(ctx, userID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AuthorDashboard), args.Error(1)
+}
+
+func TestStatsController_BlogStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC)
+
+	t.Run("weekly series", func(t *testing.T) {
+		mockStatsUsecase := new(MockStatsUsecase)
+		statsController := NewStatsController(mockStatsUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("user_id", "author1")
+		c.Set("role", "user")
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/stats?from=2025-03-03&to=2025-03-16&granularity=week", nil)
+		buckets := []*domain.StatsBucket{
+			{Start: from, BlogStats: domain.BlogStats{Views: 12, UniqueViewers: 9, Reactions: 2, Comments: 1}},
+			{Start: from.AddDate(0, 0, 7)},
+		}
+		mockStatsUsecase.On("BlogStats", mock.Anything, "blog123", "author1", "user", from, to, domain.StatsGranularityWeek).Return(buckets, nil)
+
+		statsController.BlogStats(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body struct {
+			Granularity string           `json:"granularity"`
+			Data        []StatsBucketDTO `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "week", body.Granularity)
+		assert.Equal(t, []StatsBucketDTO{
+			{Start: "2025-03-03", StatsDTO: StatsDTO{Views: 12, UniqueViewers: 9, Reactions: 2, Comments: 1}},
+			{Start: "2025-03-10"},
+		}, body.Data)
+		mockStatsUsecase.AssertExpectations(t)
+	})
+
+	t.Run("malformed date", func(t *testing.T) {
+		mockStatsUsecase := new(MockStatsUsecase)
+		statsController := NewStatsController(mockStatsUsecase)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+		c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/stats?from=yesterday", nil)
+
+		statsController.BlogStats(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockStatsUsecase.AssertNotCalled(t, "BlogStats")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		cases := map[error]int{
+			domain.ErrUnauthorized:       http.StatusForbidden,
+			domain.ErrBlogNotFound:       http.StatusNotFound,
+			domain.ErrInvalidStatsRange:  http.StatusBadRequest,
+			domain.ErrInvalidGranularity: http.StatusBadRequest,
+		}
+		for usecaseErr, status := range cases {
+			mockStatsUsecase := new(MockStatsUsecase)
+			statsController := NewStatsController(mockStatsUsecase)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{gin.Param{Key: "id", Value: "blog123"}}
+			c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/blog123/stats", nil)
+			mockStatsUsecase.On("BlogStats", mock.Anything, "blog123", "", "", mock.Anything, mock.Anything, domain.StatsGranularityDay).Return(nil, usecaseErr)
+
+			statsController.BlogStats(c)
+
+			assert.Equal(t, status, w.Code, usecaseErr.Error())
+		}
+	})
+}
+
+func TestStatsController_Dashboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockStatsUsecase := new(MockStatsUsecase)
+	statsController := NewStatsController(mockStatsUsecase)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("user_id", "author1")
+	c.Request, _ = http.NewRequest(http.MethodGet, "/blogs/dashboard?to=2025-03-30", nil)
+
+	// without from, the last 30 days up to to
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC)
+	dashboard := &domain.AuthorDashboard{
+		From:   from,
+		To:     to,
+		Totals: domain.BlogStats{Views: 14, UniqueViewers: 12, Reactions: 2, Comments: 3},
+		Posts: []*domain.PostStats{
+			{BlogID: "blog1", Title: "First", BlogStats: domain.BlogStats{Views: 14, UniqueViewers: 12, Reactions: 2, Comments: 3}},
+		},
+	}
+	mockStatsUsecase.On("AuthorDashboard", mock.Anything, "author1", from, to).Return(dashboard, nil)
+
+	statsController.Dashboard(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var body AuthorDashboardDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "2025-03-01", body.From)
+	assert.Equal(t, StatsDTO{Views: 14, UniqueViewers: 12, Reactions: 2, Comments: 3}, body.Totals)
+	if assert.Len(t, body.Posts, 1) {
+		assert.Equal(t, "First", body.Posts[0].Title)
+		assert.Equal(t, 14, body.Posts[0].Views)
+	}
+	mockStatsUsecase.AssertExpectations(t)
+}
@@ -2,6 +2,7 @@ package route
 
 import (
 	"g3-g65-bsp/delivery/controller"
+	"g3-g65-bsp/domain"
 	"g3-g65-bsp/infrastructure/cache"
 	"g3-g65-bsp/infrastructure/auth"
 	"g3-g65-bsp/infrastructure/middleware"
@@ -13,33 +14,121 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func InteractionRouter (r *gin.Engine, interactionController *controller.InteractionController, jwt *auth.JWT, contentCreationLimiter *limiter.Limiter) {
+func InteractionRouter (r *gin.Engine, interactionController *controller.InteractionController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
     interactionGroup := r.Group("/blogs")
-    interactionGroup.Use(middleware.AuthMiddleware(jwt)) // Apply auth middleware
-    interactionGroup.Use(tollbooth_gin.LimitHandler(contentCreationLimiter)) // Apply rate limiting middleware
+    interactionGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
     {
-        interactionGroup.POST("/like/:id", interactionController.LikeBlog)
-        interactionGroup.POST("/comment/:id", interactionController.CommentOnBlog)
-        interactionGroup.PUT("/comment/:id/:comment_id", interactionController.UpdateComment)
-        interactionGroup.DELETE("/comment/:id/:comment_id", interactionController.DeleteComment)
+        interactionGroup.POST("/like/:id", tollbooth_gin.LimitHandler(contentCreationLimiter), interactionController.LikeBlog)
+        interactionGroup.POST("/:id/reactions", tollbooth_gin.LimitHandler(contentCreationLimiter), interactionController.ReactToBlog)
+        interactionGroup.GET("/:id/reactions", tollbooth_gin.LimitHandler(contentReadLimiter), interactionController.ListReactions)
+        interactionGroup.POST("/:id/comments/:comment_id/reactions", tollbooth_gin.LimitHandler(contentCreationLimiter), interactionController.ReactToComment)
+        interactionGroup.POST("/comment/:id", tollbooth_gin.LimitHandler(contentCreationLimiter), interactionController.CommentOnBlog)
+        interactionGroup.PUT("/comment/:id/:comment_id", tollbooth_gin.LimitHandler(contentCreationLimiter), interactionController.UpdateComment)
+        interactionGroup.DELETE("/comment/:id/:comment_id", tollbooth_gin.LimitHandler(contentCreationLimiter), interactionController.DeleteComment)
+        interactionGroup.GET("/:id/comments", tollbooth_gin.LimitHandler(contentReadLimiter), interactionController.ListComments)
     }
 }
 
-func BlogRouter(r *gin.Engine, blogController *controller.BlogController, jwt *auth.JWT, cacheService *cache.Service, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
-    cachingMiddleware := middleware.CachePage(*cacheService, 2*time.Minute)
+func BlogRouter(r *gin.Engine, blogController *controller.BlogController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, cacheService *cache.Service, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
+    cachingMiddleware := middleware.CacheGroupPage(*cacheService, domain.BlogListCacheGroup, 2*time.Minute)
     revalidateMiddleware := middleware.RevalidateCache(*cacheService)
+    // edits and status changes change what the lists show, so they drop every cached list page
+    revalidateListsMiddleware := middleware.RevalidateGroup(*cacheService, domain.BlogListCacheGroup)
+    // permalinks are public, so feed and sitemap readers can follow them; drafts stay visible to their
+    // signed-in authors only
+    r.GET("/blogs/slug/:slug", tollbooth_gin.LimitHandler(contentReadLimiter), middleware.OptionalAuthMiddleware(jwt, revocations), blogController.GetBlogBySlug)
+
     blogGroup := r.Group("/blogs")
-    blogGroup.Use(middleware.AuthMiddleware(jwt)) // Apply auth middleware
+    blogGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
     {
         blogGroup.POST("/", tollbooth_gin.LimitHandler(contentCreationLimiter), blogController.CreateBlog)
         blogGroup.GET("/", tollbooth_gin.LimitHandler(contentReadLimiter), cachingMiddleware, blogController.ListBlogs)
         blogGroup.GET(":id", tollbooth_gin.LimitHandler(contentReadLimiter), blogController.GetBlogByID)
-        blogGroup.PUT(":id", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, blogController.UpdateBlog)
-        blogGroup.DELETE(":id", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, blogController.DeleteBlog)
+        blogGroup.PUT(":id", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.UpdateBlog)
+        blogGroup.DELETE(":id", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.DeleteBlog)
+        blogGroup.POST(":id/publish", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.PublishBlog)
+        blogGroup.POST(":id/unpublish", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.UnpublishBlog)
+        blogGroup.POST(":id/archive", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.ArchiveBlog)
+        blogGroup.POST(":id/schedule", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.ScheduleBlog)
+        blogGroup.DELETE(":id/schedule", tollbooth_gin.LimitHandler(contentCreationLimiter), revalidateMiddleware, revalidateListsMiddleware, blogController.CancelSchedule)
+        blogGroup.GET(":id/related", tollbooth_gin.LimitHandler(contentReadLimiter), blogController.RelatedBlogs)
+        blogGroup.GET(":id/revisions", tollbooth_gin.LimitHandler(contentReadLimiter), blogController.ListRevisions)
+        blogGroup.GET(":id/revisions/diff", tollbooth_gin.LimitHandler(contentReadLimiter), blogController.DiffRevisions)
+        blogGroup.POST(":id/revisions/:revision/restore", tollbooth_gin.LimitHandler(contentCreationLimiter), blogController.RestoreRevision)
     }
 }
 
-func AuthRouter(r *gin.Engine, authController *controller.AuthController, jwt *auth.JWT, authLimiter *limiter.Limiter) {
+// StatsRouter serves blog analytics to their authors and admins
+func StatsRouter(r *gin.Engine, statsController *controller.StatsController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, contentReadLimiter *limiter.Limiter) {
+	statsGroup := r.Group("/blogs")
+	statsGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
+	{
+		statsGroup.GET("/dashboard", tollbooth_gin.LimitHandler(contentReadLimiter), statsController.Dashboard)
+		statsGroup.GET("/:id/stats", tollbooth_gin.LimitHandler(contentReadLimiter), statsController.BlogStats)
+	}
+}
+
+// FollowRouter serves following authors and tags, and the home feed built from them
+func FollowRouter(r *gin.Engine, followController *controller.FollowController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
+	followGroup := r.Group("/")
+	followGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
+	{
+		followGroup.POST("/users/:id/follow", tollbooth_gin.LimitHandler(contentCreationLimiter), followController.FollowAuthor)
+		followGroup.DELETE("/users/:id/follow", tollbooth_gin.LimitHandler(contentCreationLimiter), followController.UnfollowAuthor)
+		followGroup.GET("/users/:id/followers", tollbooth_gin.LimitHandler(contentReadLimiter), followController.ListFollowers)
+		followGroup.GET("/users/:id/following", tollbooth_gin.LimitHandler(contentReadLimiter), followController.ListFollowing)
+		followGroup.POST("/tags/:tag/follow", tollbooth_gin.LimitHandler(contentCreationLimiter), followController.FollowTag)
+		followGroup.DELETE("/tags/:tag/follow", tollbooth_gin.LimitHandler(contentCreationLimiter), followController.UnfollowTag)
+		followGroup.GET("/feed", tollbooth_gin.LimitHandler(contentReadLimiter), followController.Feed)
+	}
+}
+
+// BookmarkRouter serves the caller's bookmarks and reading lists. A shared list is read by its link
+// without signing in.
+func BookmarkRouter(r *gin.Engine, bookmarkController *controller.BookmarkController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
+	r.GET("/reading-lists/shared/:token", tollbooth_gin.LimitHandler(contentReadLimiter), bookmarkController.GetSharedReadingList)
+
+	bookmarkGroup := r.Group("/")
+	bookmarkGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
+	{
+		bookmarkGroup.POST("/blogs/:id/bookmark", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.Bookmark)
+		bookmarkGroup.DELETE("/blogs/:id/bookmark", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.RemoveBookmark)
+		bookmarkGroup.GET("/bookmarks", tollbooth_gin.LimitHandler(contentReadLimiter), bookmarkController.ListBookmarks)
+		bookmarkGroup.GET("/bookmarks/state", tollbooth_gin.LimitHandler(contentReadLimiter), bookmarkController.BookmarkStates)
+
+		bookmarkGroup.POST("/reading-lists", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.CreateReadingList)
+		bookmarkGroup.GET("/reading-lists", tollbooth_gin.LimitHandler(contentReadLimiter), bookmarkController.ListReadingLists)
+		bookmarkGroup.GET("/reading-lists/:id", tollbooth_gin.LimitHandler(contentReadLimiter), bookmarkController.GetReadingList)
+		bookmarkGroup.PATCH("/reading-lists/:id", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.UpdateReadingList)
+		bookmarkGroup.DELETE("/reading-lists/:id", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.DeleteReadingList)
+		bookmarkGroup.PUT("/reading-lists/:id/order", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.ReorderReadingList)
+		bookmarkGroup.POST("/reading-lists/:id/items", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.AddToReadingList)
+		bookmarkGroup.DELETE("/reading-lists/:id/items/:blog_id", tollbooth_gin.LimitHandler(contentCreationLimiter), bookmarkController.RemoveFromReadingList)
+	}
+}
+
+// FeedRouter serves the public syndication feeds, so unlike /blogs it does not require authentication
+func FeedRouter(r *gin.Engine, feedController *controller.FeedController, contentReadLimiter *limiter.Limiter) {
+	feedGroup := r.Group("/feeds")
+	feedGroup.Use(tollbooth_gin.LimitHandler(contentReadLimiter)) // Apply rate limiting middleware
+	for _, prefix := range []string{"", "/tags/:tag", "/authors/:author_id"} {
+		feedGroup.GET(prefix+"/rss.xml", feedController.RSS)
+		feedGroup.GET(prefix+"/atom.xml", feedController.Atom)
+		feedGroup.GET(prefix+"/feed.json", feedController.JSON)
+	}
+}
+
+// SitemapRouter serves the public sitemap index and the sitemap files it points at
+func SitemapRouter(r *gin.Engine, sitemapController *controller.SitemapController, contentReadLimiter *limiter.Limiter) {
+	sitemapGroup := r.Group("/")
+	sitemapGroup.Use(tollbooth_gin.LimitHandler(contentReadLimiter)) // Apply rate limiting middleware
+	{
+		sitemapGroup.GET("/sitemap.xml", sitemapController.Index)
+		sitemapGroup.GET("/sitemaps/:file", sitemapController.Chunk)
+	}
+}
+
+func AuthRouter(r *gin.Engine, authController *controller.AuthController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, authLimiter *limiter.Limiter) {
     authGroup := r.Group("/auth")
     authGroup.Use(tollbooth_gin.LimitHandler(authLimiter)) // Apply rate limiting middleware
     {
@@ -51,10 +140,12 @@ func AuthRouter(r *gin.Engine, authController *controller.AuthController, jwt *a
         authGroup.POST("/reset-password", authController.ResetPassword)
         authGroup.POST("/refresh_token", authController.RefreshAccessToken)
         
-        authGroup.Use(middleware.AuthMiddleware(jwt)) // Apply auth middleware
+        authGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
         {
             authGroup.POST("/logout", authController.Logout)      // Single device
             authGroup.POST("/logout-all", authController.LogoutAll) // All devices
+            authGroup.GET("/sessions", authController.ListSessions)
+            authGroup.DELETE("/sessions/:id", authController.RevokeSession) // One device
         }
     }
 }
@@ -68,10 +159,10 @@ func OAuthRouter(r *gin.Engine, oauthController *controller.OAuthController, aut
     }
 }
 
-func UserRouter(r *gin.Engine, userController *controller.UserController, jwt *auth.JWT, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
+func UserRouter(r *gin.Engine, userController *controller.UserController, jwt *auth.JWT, revocations domain.TokenRevocationRepository, contentCreationLimiter *limiter.Limiter, contentReadLimiter *limiter.Limiter) {
     userGroup := r.Group("/user")
     {
-        userGroup.Use(middleware.AuthMiddleware(jwt)) // Apply auth middleware
+        userGroup.Use(middleware.AuthMiddleware(jwt, revocations)) // Apply auth middleware
         {
             userGroup.POST("/update-profile", tollbooth_gin.LimitHandler(contentCreationLimiter), userController.HandleUpdateUser)
             userGroup.POST("/promote", tollbooth_gin.LimitHandler(contentCreationLimiter), middleware.RoleMiddleware(), userController.HandlePromote)
@@ -81,10 +172,10 @@ func UserRouter(r *gin.Engine, userController *controller.UserController, jwt *a
 	}
 }
 
-func AIRouter(r *gin.Engine, aicontroller *controller.AIcontroller, jwt *auth.JWT, contentCreationLimiter *limiter.Limiter) {
+func AIRouter(r *gin.Engine, aicontroller *controller.AIcontroller, jwt *auth.JWT, revocations domain.TokenRevocationRepository, contentCreationLimiter *limiter.Limiter) {
 	aigroup := r.Group("/ai")
 	{
-		aigroup.Use(middleware.AuthMiddleware(jwt))
+		aigroup.Use(middleware.AuthMiddleware(jwt, revocations))
 		{
 			aigroup.POST("/content", tollbooth_gin.LimitHandler(contentCreationLimiter), aicontroller.HandleAIContentrequest)
 			aigroup.POST("/enhance", tollbooth_gin.LimitHandler(contentCreationLimiter), aicontroller.HandleAIEnhancement)
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+type AuditEventType string
+
+const (
+	// AuditRefreshTokenReuse is recorded when a refresh token is presented after it was used, which means
+	// it was copied; its whole family is revoked
+	AuditRefreshTokenReuse AuditEventType = "refresh_token_reuse"
+)
+
+// AuditEvent is one recorded security event. Details holds whatever identifies what happened, such as
+// the token family or the client's address.
+type AuditEvent struct {
+	ID        string
+	Type      AuditEventType
+	UserID    string
+	Details   map[string]string
+	CreatedAt time.Time
+}
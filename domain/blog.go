@@ -9,29 +9,128 @@ type Blog struct {
     AuthorID  string
     AuthorUsername string   
     Title     string   
+    Slug      string
+    PreviousSlugs []string
     Content   string   
+    ContentFormat ContentFormat
+    ContentHTML   string
     Tags      []string 
     Metrics   *Metrics  
-    Comments  []Comment 
+    Status    BlogStatus
+    PublishAt *time.Time
+    PublishedAt *time.Time
     CreatedAt *time.Time            
     UpdatedAt *time.Time            
+    // TrendingScore ranks the blog by its recent, time-decayed activity; the trending job keeps it up to date
+    TrendingScore float64
+    // Score is set on the results of a text search, and on related blogs to say how closely they relate;
+    // Highlight is only set on search results
+    Score     float64
+    Highlight *SearchHighlight
+    // ViewerReactions are the reaction types the reading user has left; only set when a single blog is read
+    ViewerReactions []string
 }
 
+// MaxRelatedBlogs is the most related blogs listed for a blog
+const MaxRelatedBlogs = 20
+
+// BlogListCacheGroup is the cache group holding cached blog list pages. Anything that changes
+// which blogs are listed drops the group.
+const BlogListCacheGroup = "blogs:list"
+
+// SearchHighlight holds the parts of a blog that matched a search, HTML-escaped with the matches in <mark>
+type SearchHighlight struct {
+    Title   string
+    Content []string
+}
+
+// BlogStatus is the lifecycle state of a blog post
+type BlogStatus string
+
+const (
+    BlogStatusDraft     BlogStatus = "draft"
+    BlogStatusPublished BlogStatus = "published"
+    BlogStatusArchived  BlogStatus = "archived"
+)
+
+// ContentFormat is the markup a blog's content is written in
+type ContentFormat string
+
+const (
+    ContentFormatMarkdown ContentFormat = "markdown"
+    ContentFormatHTML     ContentFormat = "html"
+)
+
 type Metrics struct {
     ViewCount int    
-    Likes     *Likes
-    Dislikes  *Likes  
+    CommentCount int
+    // Reactions counts the reactions left on the blog by type
+    Reactions map[string]int
 }
 
-type Likes struct {
-    Count int                  
-    Users []string 
-}
+// MaxCommentDepth is how deeply replies may nest; top-level comments have depth 0
+const MaxCommentDepth = 5
+
+// DeletedCommentContent replaces the content of a deleted comment that still has replies
+const DeletedCommentContent = "[deleted]"
 
 type Comment struct {
     ID             string
+    BlogID         string
     AuthorID       string    
     AuthorUsername string    
     Content        string   
+    ContentHTML    string
     CreatedAt      *time.Time 
+    // ParentID is empty for top-level comments
+    ParentID       string
+    Depth          int
+    // Deleted marks a tombstone left in place of a deleted comment so its replies keep their parent
+    Deleted        bool
+    ReplyCount     int
+    // Reactions counts the reactions left on the comment by type
+    Reactions      map[string]int
+    // Replies is only filled in when comments are read as a tree
+    Replies        []*Comment
+}
+
+// BlogRevision is a snapshot of a blog's editable fields taken on every create, update or restore
+type BlogRevision struct {
+    ID           string
+    BlogID       string
+    Number       int
+    Title        string
+    Content      string
+    ContentFormat ContentFormat
+    Tags         []string
+    EditorID     string
+    RestoredFrom int
+    CreatedAt    time.Time
+}
+
+// DiffOp is the kind of change a DiffLine represents
+type DiffOp string
+
+const (
+    DiffEqual  DiffOp = "equal"
+    DiffInsert DiffOp = "insert"
+    DiffDelete DiffOp = "delete"
+)
+
+// MaxDiffLines is the most lines either side of a diff may have
+const MaxDiffLines = 5000
+
+// DiffLine is a single line of a line-based diff
+type DiffLine struct {
+    Op   DiffOp
+    Text string
+}
+
+// RevisionDiff holds the line-based differences between two revisions of a blog
+type RevisionDiff struct {
+    From    *BlogRevision
+    To      *BlogRevision
+    Title   []DiffLine
+    Tags    []DiffLine
+    Content []DiffLine
 }
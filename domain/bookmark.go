@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Bookmark is a blog a user saved. Title is the blog's title when it was saved, so a bookmark can still
+// be shown once the blog is gone.
+type Bookmark struct {
+	ID        string
+	UserID    string
+	BlogID    string
+	Title     string
+	CreatedAt time.Time
+	// Unavailable is set once the blog has been deleted, or when the reader may not see it
+	Unavailable bool
+	// Blog is only filled in when bookmarks are listed, and only for available blogs
+	Blog *Blog
+}
+
+// ReadingListItem is one blog in a reading list, kept like a bookmark
+type ReadingListItem struct {
+	BlogID      string
+	Title       string
+	AddedAt     time.Time
+	Unavailable bool
+	Blog        *Blog
+}
+
+// ReadingList is a named, ordered list of blogs. Only its owner sees it, unless it is shared, in which
+// case anyone with its ShareToken can read it.
+type ReadingList struct {
+	ID         string
+	OwnerID    string
+	Name       string
+	Shared     bool
+	ShareToken string
+	Items      []*ReadingListItem
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+const (
+	// MaxReadingListItems is how many blogs one reading list holds
+	MaxReadingListItems = 500
+	// MaxReadingListNameLength is the longest name a reading list can have, in characters
+	MaxReadingListNameLength = 100
+	// MaxBookmarkStateIDs is how many blogs the bookmarked state can be asked for at once
+	MaxBookmarkStateIDs = 100
+)
+
+var ErrReadingListNotFound = errors.New("reading list not found")
+var ErrInvalidReadingListName = errors.New("reading list name must be 1 to 100 characters")
+var ErrReadingListFull = errors.New("reading list is full")
+var ErrInvalidReadingListOrder = errors.New("order must list every blog in the reading list exactly once")
+var ErrReadingListChanged = errors.New("reading list was changed by another request")
+var ErrTooManyBlogIDs = errors.New("too many blog IDs")
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// FollowTarget is what a user follows: an author, or every post with a tag
+type FollowTarget string
+
+const (
+	FollowTargetAuthor FollowTarget = "author"
+	FollowTargetTag    FollowTarget = "tag"
+)
+
+// Follow is one user following one author or tag
+type Follow struct {
+	ID         string
+	FollowerID string
+	TargetType FollowTarget
+	// TargetID is the followed author's user ID, or the tag itself
+	TargetID  string
+	CreatedAt time.Time
+}
+
+// MaxFeedFollows is how many of a user's follows of each kind, newest first, make up their feed
+const MaxFeedFollows = 1000
+
+var ErrCannotFollowSelf = errors.New("users cannot follow themselves")
+var ErrInvalidFollowTarget = errors.New("follow target must be author or tag")
@@ -1,7 +1,27 @@
 package domain
 
+import "errors"
+
+// Pagination describes one page of a listing.
+// Total is nil when the caller did not ask for the (costly) count.
 type Pagination struct {
-	Total int
-	Page  int
-	Limit int
+	Total      *int
+	Page       int
+	Limit      int
+	HasNext    bool
+	HasPrev    bool
+	NextCursor string
+	PrevCursor string
+}
+
+// BlogCursor is a keyset position in a blog listing: the sort value and ID of the blog it points at.
+// Value is a time.Time, string or int depending on SortBy.
+type BlogCursor struct {
+	SortBy   string
+	Order    string
+	Value    any
+	ID       string
+	Backward bool // set on a prev cursor: list the blogs before the position instead of after it
 }
+
+var ErrInvalidCursor = errors.New("invalid cursor")
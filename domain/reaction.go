@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ReactionTarget is the kind of content a reaction is left on
+type ReactionTarget string
+
+const (
+	ReactionTargetBlog    ReactionTarget = "blog"
+	ReactionTargetComment ReactionTarget = "comment"
+)
+
+// DefaultReactionTypes are offered when no reaction types are configured
+var DefaultReactionTypes = []string{"like", "dislike", "love", "celebrate", "thinking"}
+
+// Reaction is one user's reaction of one type to a blog or a comment.
+// A user can leave several types on the same target, but each type only once.
+type Reaction struct {
+	ID         string
+	TargetType ReactionTarget
+	TargetID   string
+	// BlogID is the blog the target belongs to, so a blog's reactions can be removed with it
+	BlogID    string
+	UserID    string
+	Username  string
+	Type      string
+	CreatedAt time.Time
+}
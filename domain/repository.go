@@ -2,20 +2,124 @@ package domain
 
 import (
 	"context"
+	"fmt"
 	"time"
 )
 
 type BlogRepository interface {
 	CreateBlog(ctx context.Context, blog *Blog) (string, error)
 	GetBlogByID(ctx context.Context, id string) (*Blog, error)
+	GetBlogBySlug(ctx context.Context, slug string) (*Blog, error)
+	SlugExists(ctx context.Context, slug string, excludeID string) (bool, error)
 	UpdateBlog(ctx context.Context, blog *Blog) error
 	DeleteBlog(ctx context.Context, id string) error
 	ListBlogs(ctx context.Context, filter map[string]any, page, limit int) ([]*Blog, *Pagination, error)
-	IncrementBlogViewCount(ctx context.Context, id string, blog *Blog) error
-	AddComment(ctx context.Context, blogID string, comment *Comment) error
-	UpdateComment(ctx context.Context, blogID string, comment *Comment) error
+	// IncrementViewCounts adds a batch of views, keyed by blog ID. A batch that was only partly written
+	// returns a *PartialWriteError[string] holding the IDs of the blogs whose views were not added.
+	IncrementViewCounts(ctx context.Context, views map[string]int) error
+	IncrementCommentCount(ctx context.Context, id string, delta int) error
+	IncrementReactionCount(ctx context.Context, id string, reactionType string, delta int) error
+	PublishDueBlog(ctx context.Context, now time.Time) (*Blog, error)
+	// RelatedCandidates returns published blogs other than blog that share its words, tags or author
+	RelatedCandidates(ctx context.Context, blog *Blog, limit int) ([]*Blog, error)
+	// GetBlogsByIDs returns the blogs that exist among ids, in no particular order
+	GetBlogsByIDs(ctx context.Context, ids []string) ([]*Blog, error)
+}
+
+type BlogRevisionRepository interface {
+	CreateRevision(ctx context.Context, revision *BlogRevision) (*BlogRevision, error)
+	ListRevisions(ctx context.Context, blogID string) ([]*BlogRevision, error)
+	GetRevision(ctx context.Context, blogID string, number int) (*BlogRevision, error)
+	DeleteRevisions(ctx context.Context, blogID string) error
+}
+
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment *Comment) error
 	GetCommentByID(ctx context.Context, blogID string, commentID string) (*Comment, error)
+	UpdateComment(ctx context.Context, comment *Comment) error
 	DeleteComment(ctx context.Context, blogID string, commentID string) error
+	ListThreads(ctx context.Context, blogID string, page, limit int) ([]*Comment, *Pagination, error)
+	DeleteComments(ctx context.Context, blogID string) error
+	IncrementReactionCount(ctx context.Context, blogID string, commentID string, reactionType string, delta int) error
+}
+
+// ReactionRepository stores who reacted to what; the counts live on the blogs and comments themselves
+type ReactionRepository interface {
+	// ToggleReaction adds the reaction, or takes it back if the user already left it, and returns how the
+	// count of that reaction changed: 1, -1, or 0 when a concurrent toggle by the same user got there first
+	ToggleReaction(ctx context.Context, reaction *Reaction) (int, error)
+	// UserReactions returns the types of reaction the user has left on a target
+	UserReactions(ctx context.Context, targetType ReactionTarget, targetID string, userID string) ([]string, error)
+	// ListReactions pages through a target's reactions, newest first, optionally of one type only
+	ListReactions(ctx context.Context, targetType ReactionTarget, targetID string, reactionType string, page, limit int) ([]*Reaction, *Pagination, error)
+	DeleteReactions(ctx context.Context, targetType ReactionTarget, targetID string) error
+	DeleteBlogReactions(ctx context.Context, blogID string) error
+}
+
+// BlogStatsRepository keeps daily stats per blog and sums them over a period
+type BlogStatsRepository interface {
+	// AddDailyStats adds a batch of stats. A batch that was only partly written returns a
+	// *PartialWriteError[int] holding the indexes in stats of the entries that were not added.
+	AddDailyStats(ctx context.Context, stats []*DailyBlogStats) error
+	BlogStats(ctx context.Context, blogID string, from, to time.Time, granularity StatsGranularity) ([]*StatsBucket, error)
+	AuthorStats(ctx context.Context, authorID string, from, to time.Time) ([]*PostStats, error)
+	DeleteBlogStats(ctx context.Context, blogID string) error
+	// UpdateTrendingScores rescores every blog from its recent daily stats, each day's activity weighing
+	// half as much for every halfLife that has passed since
+	UpdateTrendingScores(ctx context.Context, now time.Time, halfLife time.Duration) error
+}
+
+// PartialWriteError is returned by a batch write of which only some entries were written: the ones in
+// Failed were not, and every other entry was, so only the failed ones may be retried
+type PartialWriteError[K comparable] struct {
+	Failed []K
+	Err    error
+}
+
+func (e *PartialWriteError[K]) Error() string {
+	return fmt.Sprintf("%d writes of the batch failed: %v", len(e.Failed), e.Err)
+}
+
+func (e *PartialWriteError[K]) Unwrap() error {
+	return e.Err
+}
+
+// BookmarkRepository stores users' bookmarks and reading lists
+type BookmarkRepository interface {
+	// AddBookmark saves the bookmark and reports whether it is new
+	AddBookmark(ctx context.Context, bookmark *Bookmark) (bool, error)
+	RemoveBookmark(ctx context.Context, userID, blogID string) error
+	// ListBookmarks pages through a user's bookmarks, newest first
+	ListBookmarks(ctx context.Context, userID string, page, limit int) ([]*Bookmark, *Pagination, error)
+	// BookmarkedBlogIDs returns which of blogIDs the user has bookmarked
+	BookmarkedBlogIDs(ctx context.Context, userID string, blogIDs []string) ([]string, error)
+
+	CreateReadingList(ctx context.Context, list *ReadingList) error
+	GetReadingList(ctx context.Context, id string) (*ReadingList, error)
+	GetReadingListByShareToken(ctx context.Context, token string) (*ReadingList, error)
+	// ListReadingLists returns a user's reading lists, most recently changed first
+	ListReadingLists(ctx context.Context, ownerID string) ([]*ReadingList, error)
+	// UpdateReadingList saves the list's name, sharing and item order, provided nobody changed it since
+	// it was read (going by UpdatedAt); otherwise it returns ErrReadingListChanged
+	UpdateReadingList(ctx context.Context, list *ReadingList, readAt time.Time) error
+	DeleteReadingList(ctx context.Context, id string) error
+	// AddReadingListItem appends the blog unless it is already in the list; it returns ErrReadingListFull
+	// when the list holds MaxReadingListItems blogs
+	AddReadingListItem(ctx context.Context, listID string, item *ReadingListItem) error
+	RemoveReadingListItem(ctx context.Context, listID, blogID string) error
+
+	// MarkBlogUnavailable flags every bookmark and reading list item of a deleted blog
+	MarkBlogUnavailable(ctx context.Context, blogID string) error
+}
+
+// SitemapRepository streams the public pages listed in the sitemap. A section is ordered by its entries'
+// IDs and paged by ID range: after is the ID of the last entry already listed, empty to start at the
+// beginning. An after that is not a valid ID of the section gives ErrSitemapNotFound.
+type SitemapRepository interface {
+	Summarize(ctx context.Context, section SitemapSection) (*SitemapSummary, error)
+	// LastID returns the ID of the limit-th entry after after, or the last one if there are fewer
+	LastID(ctx context.Context, section SitemapSection, after string, limit int) (string, error)
+	Stream(ctx context.Context, section SitemapSection, after string, limit int, fn func(*SitemapEntry) error) error
 }
 
 type UserRepository interface {
@@ -27,6 +131,19 @@ type UserRepository interface {
 	UpdateActiveStatus(ctx context.Context, email string) error
 	UpdateUserPassword(ctx context.Context, email string, newPasswordHash string) error
 	GetAllUsers(ctx context.Context, page int, limit int) ([]User, int64, error)
+	IncrementFollowCounts(ctx context.Context, userID string, followers, following int) error
+}
+
+// FollowRepository stores who follows which authors and tags; the follow counts live on the users
+type FollowRepository interface {
+	// Follow records the follow and reports whether it is new; following twice is not an error
+	Follow(ctx context.Context, follow *Follow) (bool, error)
+	// Unfollow removes the follow and reports whether there was one
+	Unfollow(ctx context.Context, followerID string, targetType FollowTarget, targetID string) (bool, error)
+	// ListFollowers pages through the users following a target, newest first
+	ListFollowers(ctx context.Context, targetType FollowTarget, targetID string, page, limit int) ([]*Follow, *Pagination, error)
+	// ListFollowing pages through the targets of one kind a user follows, newest first
+	ListFollowing(ctx context.Context, followerID string, targetType FollowTarget, page, limit int) ([]*Follow, *Pagination, error)
 }
 
 type UnactiveUserRepo interface {
@@ -43,13 +160,40 @@ type PasswordResetRepository interface {
 }
 
 type TokenRepository interface {
+	// StoreRefreshToken starts a new family for a token that has none
 	StoreRefreshToken(ctx context.Context, accessToken *RefreshToken) error
 	FindRefreshToken(ctx context.Context, token string) (*RefreshToken, error)
+	// ConsumeRefreshToken marks the token used and returns it. A token that was already used is returned
+	// with ErrRefreshTokenReused; an unknown one gives ErrInvalidRefreshToken.
+	ConsumeRefreshToken(ctx context.Context, token string) (*RefreshToken, error)
+	// RevokeFamily deletes every token of the family, used or not, and returns those whose access token
+	// has not expired yet
+	RevokeFamily(ctx context.Context, familyID string) ([]*RefreshToken, error)
 	DeleteRefreshToken(ctx context.Context, token string) (error)
 	DeleteAllForUser(ctx context.Context, userID string) error
+	// ListSessions lists the user's families that still hold an unused, unexpired token, most recently
+	// used first
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+	// RevokeSession deletes one of the user's families like RevokeFamily; another user's family gives
+	// ErrSessionNotFound
+	RevokeSession(ctx context.Context, userID, sessionID string) ([]*RefreshToken, error)
 }
 
-type InteractionRepository interface {
-	LikeBlog(ctx context.Context, userID string, blogID string, preftype string) error
-	CommentOnBlog(ctx context.Context, userID string, blogID string, comment *Comment) error
+// TokenRevocationRepository keeps what makes an otherwise valid access token unusable: its ID on the
+// denylist, or an issue time before its user's watermark
+type TokenRevocationRepository interface {
+	// RevokeAccessToken denies the token with the ID until expiresAt, when it stops working anyway
+	RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	// RevokeTokensIssuedBefore invalidates every access token issued to the user before t, or in the
+	// same second as t. The watermark only moves forward.
+	RevokeTokensIssuedBefore(ctx context.Context, userID string, t time.Time) error
+	// TokensRevokedBefore returns the user's watermark, or the zero time when there is none
+	TokensRevokedBefore(ctx context.Context, userID string) (time.Time, error)
 }
+
+// AuditRepository records security-relevant events for later review
+type AuditRepository interface {
+	Record(ctx context.Context, event *AuditEvent) error
+}
+
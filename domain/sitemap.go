@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// SitemapMaxURLs is the most URLs the sitemap protocol allows in a single sitemap file
+const SitemapMaxURLs = 50000
+
+// SitemapSection is one kind of page listed in the sitemap
+type SitemapSection string
+
+const (
+	SitemapSectionBlogs   SitemapSection = "blogs"
+	SitemapSectionTags    SitemapSection = "tags"
+	SitemapSectionAuthors SitemapSection = "authors"
+)
+
+// SitemapSections lists the sections in the order they appear in the sitemap index
+var SitemapSections = []SitemapSection{SitemapSectionBlogs, SitemapSectionTags, SitemapSectionAuthors}
+
+// SitemapEntry is one page in a sitemap section.
+// ID is the blog ID, tag or author ID; Slug is only set for blogs.
+type SitemapEntry struct {
+	ID           string
+	Slug         string
+	LastModified *time.Time
+}
+
+// SitemapSummary is the size of a section and the newest change in it
+type SitemapSummary struct {
+	Count        int
+	LastModified *time.Time
+}
+
+// SitemapChunk is one sitemap file listed in the sitemap index. Page starts at 1. After is the ID of
+// the last entry of the previous file, where this one starts; it is empty for the first file.
+type SitemapChunk struct {
+	Section      SitemapSection
+	Page         int
+	After        string
+	LastModified *time.Time
+}
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// MaxStatsRange is the longest period stats can be asked for at once
+const MaxStatsRange = 366 * 24 * time.Hour
+
+// StatsGranularity is the size of the buckets a stats time series is grouped into
+type StatsGranularity string
+
+const (
+	StatsGranularityDay  StatsGranularity = "day"
+	StatsGranularityWeek StatsGranularity = "week"
+)
+
+// BlogStats is what happened to a blog over some period. UniqueViewers counts each reader once per day,
+// so summed over several days it is viewer-days rather than distinct people. Reactions is the net
+// number added, so it can be negative on a day more were taken back than left.
+type BlogStats struct {
+	Views         int
+	UniqueViewers int
+	Reactions     int
+	Comments      int
+}
+
+// Add adds other's counts to s
+func (s *BlogStats) Add(other BlogStats) {
+	s.Views += other.Views
+	s.UniqueViewers += other.UniqueViewers
+	s.Reactions += other.Reactions
+	s.Comments += other.Comments
+}
+
+// DailyBlogStats is one blog's stats for one UTC day
+type DailyBlogStats struct {
+	BlogID   string
+	AuthorID string
+	Day      time.Time
+	BlogStats
+}
+
+// StatsBucket is one point of a stats time series, starting at Start: a day, or a week from Monday
+type StatsBucket struct {
+	Start time.Time
+	BlogStats
+}
+
+// PostStats is one blog's stats summed over the period of an author dashboard
+type PostStats struct {
+	BlogID string
+	Title  string
+	BlogStats
+}
+
+// AuthorDashboard sums the stats of all of an author's posts between From and To, both inclusive days
+type AuthorDashboard struct {
+	From   time.Time
+	To     time.Time
+	Totals BlogStats
+	Posts  []*PostStats
+}
+
+// Weights of each kind of activity in a blog's trending score
+const (
+	TrendingViewWeight     = 1
+	TrendingReactionWeight = 3
+	TrendingCommentWeight  = 5
+)
+
+// TrendingHalfLives is how many half-lives of activity the trending score looks back over; anything
+// older would weigh in at under 1%
+const TrendingHalfLives = 7
+
+// StatsDay returns the start of the UTC day t falls on, the key stats are recorded under
+func StatsDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// StatsWeek returns the start of the UTC week (from Monday) t falls on
+func StatsWeek(t time.Time) time.Time {
+	day := StatsDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+var ErrInvalidStatsRange = errors.New("invalid stats range")
+var ErrInvalidGranularity = errors.New("granularity must be day or week")
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type PasswordResetToken struct {
 	Email     string
@@ -9,8 +12,42 @@ type PasswordResetToken struct {
 }
 
 
+// RefreshToken is used once: refreshing consumes it and issues its successor in the same family. The
+// family is every token descended from one login, so a leaked token can be cut off with all its successors.
 type RefreshToken struct {
 	UserID    string
 	Token     string
+	FamilyID  string
 	ExpiresAt time.Time
-}
\ No newline at end of file
+	// ConsumedAt is set once the token has been exchanged for a new one
+	ConsumedAt *time.Time
+	// the device the family's login came from; UserAgent and IP are those of the latest refresh
+	DeviceName string
+	UserAgent  string
+	IP         string
+	// CreatedAt is when the family's login happened and LastUsedAt when this token was issued
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	// AccessTokenID and AccessExpiresAt identify the access token issued along with this token, so
+	// revoking the session can revoke that access token as well
+	AccessTokenID   string
+	AccessExpiresAt time.Time
+}
+
+// Session is one login on one device, as the user sees it. Its ID is the refresh token family, so
+// revoking it cuts off that device without logging the user out elsewhere.
+type Session struct {
+	ID         string
+	UserID     string
+	DeviceName string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastUsedAt time.Time
+	ExpiresAt  time.Time
+}
+
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+var ErrRefreshTokenExpired = errors.New("refresh token has expired")
+var ErrSessionNotFound = errors.New("session not found")
+var ErrRefreshTokenReused = errors.New("refresh token was already used; all sessions from that login have been revoked")
\ No newline at end of file
@@ -4,16 +4,73 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 
 	"golang.org/x/oauth2"
 )
 
 type BlogUsecase interface {
 	CreateBlog(ctx context.Context, blog *Blog, userid string) (*Blog, error)
-	GetBlogByID(ctx context.Context, id string) (*Blog, error)
+	GetBlogByID(ctx context.Context, id, userid, role string) (*Blog, error)
+	GetBlogBySlug(ctx context.Context, slug, userid, role string) (*Blog, error)
 	UpdateBlog(ctx context.Context, blog *Blog, userid, id string) (*Blog, error)
 	DeleteBlog(ctx context.Context, id, userid, role string) error
 	ListBlogs(ctx context.Context, filter map[string]any, page, limit int) ([]*Blog, *Pagination, error)
+	PublishBlog(ctx context.Context, id, userid, role string) (*Blog, error)
+	UnpublishBlog(ctx context.Context, id, userid, role string) (*Blog, error)
+	ArchiveBlog(ctx context.Context, id, userid, role string) (*Blog, error)
+	ScheduleBlog(ctx context.Context, id, userid, role string, publishAt *time.Time) (*Blog, error)
+	ListRevisions(ctx context.Context, id, userid, role string) ([]*BlogRevision, error)
+	DiffRevisions(ctx context.Context, id string, from, to int, userid, role string) (*RevisionDiff, error)
+	RestoreRevision(ctx context.Context, id string, number int, userid, role string) (*Blog, error)
+	// RelatedBlogs lists up to limit published blogs related to the blog, most closely related first
+	RelatedBlogs(ctx context.Context, id, userid, role string, limit int) ([]*Blog, error)
+	// GetAuthor returns the user whose posts an author listing shows, or ErrUserNotFound
+	GetAuthor(ctx context.Context, authorID string) (*User, error)
+}
+
+type FollowUsecase interface {
+	// Follow and Unfollow can be repeated safely; only an actual change moves the follow counts
+	Follow(ctx context.Context, userID string, targetType FollowTarget, targetID string) error
+	Unfollow(ctx context.Context, userID string, targetType FollowTarget, targetID string) error
+	ListFollowers(ctx context.Context, targetType FollowTarget, targetID string, page, limit int) ([]*Follow, *Pagination, error)
+	ListFollowing(ctx context.Context, userID string, targetType FollowTarget, page, limit int) ([]*Follow, *Pagination, error)
+	// Feed pages through the published posts of the followed authors and tags, newest first, by cursor
+	Feed(ctx context.Context, userID, cursor string, limit int) ([]*Blog, *Pagination, error)
+}
+
+type BookmarkUsecase interface {
+	// Bookmark and RemoveBookmark can be repeated safely
+	Bookmark(ctx context.Context, userID, blogID, role string) error
+	RemoveBookmark(ctx context.Context, userID, blogID string) error
+	ListBookmarks(ctx context.Context, userID, role string, page, limit int) ([]*Bookmark, *Pagination, error)
+	// BookmarkStates tells for each of blogIDs whether the user has bookmarked it
+	BookmarkStates(ctx context.Context, userID string, blogIDs []string) (map[string]bool, error)
+
+	CreateReadingList(ctx context.Context, userID, name string, shared bool) (*ReadingList, error)
+	ListReadingLists(ctx context.Context, userID string) ([]*ReadingList, error)
+	// GetReadingList returns one of the user's own lists with its blogs resolved
+	GetReadingList(ctx context.Context, id, userID, role string) (*ReadingList, error)
+	// GetSharedReadingList returns a shared list by its share token to any viewer, who may be anonymous
+	GetSharedReadingList(ctx context.Context, token, viewerID, role string) (*ReadingList, error)
+	// UpdateReadingList renames the list and turns sharing on or off; nil leaves a field as it is
+	UpdateReadingList(ctx context.Context, id, userID string, name *string, shared *bool) (*ReadingList, error)
+	// ReorderReadingList puts the list's blogs in the given order, which must name each of them once
+	ReorderReadingList(ctx context.Context, id, userID string, blogIDs []string) (*ReadingList, error)
+	DeleteReadingList(ctx context.Context, id, userID string) error
+	AddToReadingList(ctx context.Context, id, userID, blogID, role string) error
+	RemoveFromReadingList(ctx context.Context, id, userID, blogID string) error
+}
+
+type SitemapUsecase interface {
+	Index(ctx context.Context) ([]*SitemapChunk, error)
+	StreamChunk(ctx context.Context, section SitemapSection, page int, after string, fn func(*SitemapEntry) error) error
+}
+
+type StatsUsecase interface {
+	// BlogStats and AuthorDashboard take from and to as inclusive days; only a blog's author and admins see its stats
+	BlogStats(ctx context.Context, blogID, userID, role string, from, to time.Time, granularity StatsGranularity) ([]*StatsBucket, error)
+	AuthorDashboard(ctx context.Context, userID string, from, to time.Time) (*AuthorDashboard, error)
 }
 
 type UserUsecase interface {
@@ -26,9 +83,16 @@ type UserUsecase interface {
 type AuthUsecase interface {
 	Register(ctx context.Context, email, username, password string) error
 	Login(ctx context.Context, email, password string) (string, string, int, *User, error)
+	// RefreshTokens returns a new access token and the refresh token replacing the one given, which is
+	// used up; reusing it revokes its family and returns ErrRefreshTokenReused
 	RefreshTokens(ctx context.Context, refreshToken string) (string, string, int, error)
-	Logout(ctx context.Context, refreshToken string) error
+	// Logout ends the session of the refresh token and revokes the access token used to log out
+	Logout(ctx context.Context, refreshToken, accessTokenID string, accessExpiresAt time.Time) error
+	// LogoutAll ends every session and invalidates every access token issued to the user so far
 	LogoutAll(ctx context.Context, userID string) error
+	// ListSessions lists the devices the user is logged in on; RevokeSession logs one of them out
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
 	ActivateUser(ctx context.Context, token, email string) error
 	ResendActivationEmail(ctx context.Context, email string) error
 	ForgotPassword(ctx context.Context, email string) error
@@ -40,14 +104,30 @@ type OAuthUsecase interface {
 }
 
 type InteractionUsecase interface {
-	LikeBlog(ctx context.Context, userID string, blogID string, preftype string) error
-	CommentOnBlog(ctx context.Context, userID string, blogID string, comment *Comment) error
-	UpdateComment(ctx context.Context, userID string, blogID string, commentID string, content string) error
+	// ReactToBlog and ReactToComment toggle a reaction and report whether the user now has it
+	ReactToBlog(ctx context.Context, userID string, blogID string, role string, reactionType string) (bool, error)
+	ReactToComment(ctx context.Context, userID string, blogID string, commentID string, role string, reactionType string) (bool, error)
+	// ListReactions shows who reacted to a blog; only its author and admins may see it
+	ListReactions(ctx context.Context, blogID, userID, role, reactionType string, page, limit int) ([]*Reaction, *Pagination, error)
+	CommentOnBlog(ctx context.Context, userID string, blogID string, role string, comment *Comment) error
+	UpdateComment(ctx context.Context, userID string, blogID string, commentID string, role string, content string) error
 	DeleteComment(ctx context.Context, userID string, blogID string, commentID string) error
+	ListComments(ctx context.Context, blogID, userID, role string, flat bool, page, limit int) ([]*Comment, *Pagination, error)
 }
 
 var ErrUnauthorized = errors.New("unauthorized action")
-var ErrInvalidpreftype = errors.New("invalid preference type")
+var ErrInvalidpreftype = errors.New("invalid reaction type")
+var ErrBlogNotFound = errors.New("blog not found")
+var ErrInvalidBlogStatus = errors.New("invalid blog status")
+var ErrInvalidStatusTransition = errors.New("invalid blog status transition")
+var ErrInvalidPublishTime = errors.New("publish time must be in the future")
+var ErrRevisionNotFound = errors.New("revision not found")
+var ErrDiffTooLarge = errors.New("revisions are too large to diff")
+var ErrSlugTaken = errors.New("slug is already taken")
+var ErrInvalidContentFormat = errors.New("content format must be markdown or html")
+var ErrCommentNotFound = errors.New("comment not found")
+var ErrUserNotFound = errors.New("user not found")
+var ErrCommentTooDeep = errors.New("replies cannot be nested any deeper")
 
 type AIUseCase interface {
 	GenerateIntialSuggestion(ctx context.Context, title string) (string, error)
@@ -29,6 +29,9 @@ type UserProfile struct {
 	Bio               string
 	ProfilePictureURL string 
 	ContactInfo       string 
+	// FollowerCount is how many users follow this user, FollowingCount how many authors they follow
+	FollowerCount     int
+	FollowingCount    int
 }
 
 // UnactivatedUser represents a user who has not yet activated their account
@@ -38,7 +41,9 @@ type UnactivatedUser struct {
 	Email                 string
 	Password              string 
 	Activated			  bool 
+	// ActivationToken is only known when the token is issued; stores keep ActivationTokenHash instead
 	ActivationToken       string  
+	ActivationTokenHash   string
 	ActivationTokenExpiry *time.Time  
 	CreatedAt             time.Time 
 	UpdatedAt             time.Time 
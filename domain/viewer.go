@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+type clientIPKey struct{}
+
+type userAgentKey struct{}
+
+// WithClientIP records the address a request came from, for telling anonymous readers apart
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIP returns the address recorded by WithClientIP, or "" when there is none
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// WithUserAgent records the User-Agent a request came with, for describing the device behind a login
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentKey{}, userAgent)
+}
+
+// UserAgent returns the User-Agent recorded by WithUserAgent, or "" when there is none
+func UserAgent(ctx context.Context) string {
+	userAgent, _ := ctx.Value(userAgentKey{}).(string)
+	return userAgent
+}
@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"strconv"
+	"time"
+)
+
+// generationKey holds the current generation of a group of cached keys.
+func generationKey(group string) string {
+	return "generation:" + group
+}
+
+// GroupKey returns key as stored in group. It carries the group's current generation,
+// so InvalidateGroup can drop every key in the group without knowing what they are.
+func GroupKey(service Service, group, key string) string {
+	generation, found := service.Get(generationKey(group))
+	if !found {
+		generation = "0"
+	}
+	return group + ":" + generation.(string) + ":" + key
+}
+
+// InvalidateGroup moves group to a new generation. Keys from the old one are never read again
+// and expire on their own.
+func InvalidateGroup(service Service, group string) {
+	// a negative duration keeps the generation until the next invalidation
+	service.Set(generationKey(group), strconv.FormatInt(time.Now().UnixNano(), 10), -1)
+}
@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidateGroup(t *testing.T) {
+	cache := NewInMemoryCache(5*time.Minute, 10*time.Minute)
+
+	key := GroupKey(cache, "lists", "/blogs/?page=1")
+	cache.Set(key, "page one", time.Minute)
+	assert.Equal(t, key, GroupKey(cache, "lists", "/blogs/?page=1"))
+	assert.NotEqual(t, key, GroupKey(cache, "other", "/blogs/?page=1"))
+
+	InvalidateGroup(cache, "lists")
+	_, found := cache.Get(GroupKey(cache, "lists", "/blogs/?page=1"))
+	assert.False(t, found)
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 	"github.com/golang-jwt/jwt/v5"
@@ -29,25 +31,47 @@ func NewJWT(accessSecret, refreshSecret string, accessExpiry, refreshExpiry time
 }
 
 func (j *JWT) GenerateAccessToken(userID, role string) (string, error) {
+	token, _, err := j.IssueAccessToken(userID, role)
+	return token, err
+}
+
+// IssueAccessToken is GenerateAccessToken returning the token's claims as well, for the caller to keep
+// its ID and expiry
+func (j *JWT) IssueAccessToken(userID, role string) (string, *Claims, error) {
 	if userID == "" || role == "" {
-		return "", errors.New("userID and role cannot be empty")
+		return "", nil, errors.New("userID and role cannot be empty")
 	}
 
+	id, err := newTokenID()
+	if err != nil {
+		return "", nil, err
+	}
 	claims := &Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.AccessExpiry)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.AccessSecret))
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(j.AccessSecret))
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
 }
 
+// GenerateRefreshToken issues a refresh token with a random ID, so tokens issued within the same second
+// still differ; each one is stored and used once
 func (j *JWT) GenerateRefreshToken() (string, error) {
+	id, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
 	claims := &jwt.RegisteredClaims{
+		ID:        id,
 		ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.RefreshExpiry)),
 		IssuedAt:  jwt.NewNumericDate(time.Now()),
 	}
@@ -56,6 +80,15 @@ func (j *JWT) GenerateRefreshToken() (string, error) {
 	return token.SignedString([]byte(j.RefreshSecret))
 }
 
+// newTokenID returns a random token ID for the jti claim, by which a single token can be revoked
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
 func (j *JWT) ValidateAccessToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {		
 		return []byte(j.AccessSecret), nil
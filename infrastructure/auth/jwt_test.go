@@ -23,8 +23,21 @@ func TestJWT(t *testing.T) {
 	assert.Equal(t, userID, claims.UserID)
 	assert.Equal(t, role, claims.Role)
 
+	// Each access token has its own ID to be revoked by
+	assert.Len(t, claims.ID, 32)
+	another, err := jwt.GenerateAccessToken(userID, role)
+	assert.NoError(t, err)
+	anotherClaims, _ := jwt.ValidateAccessToken(another)
+	assert.NotEqual(t, claims.ID, anotherClaims.ID)
+	assert.WithinDuration(t, time.Now(), claims.IssuedAt.Time, time.Second)
+
 	// Test refresh token generation
 	refreshToken, err := jwt.GenerateRefreshToken()
 	assert.NoError(t, err)
 	assert.NotEmpty(t, refreshToken)
+
+	// Refresh tokens issued together must still differ
+	anotherRefresh, err := jwt.GenerateRefreshToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, refreshToken, anotherRefresh)
 }
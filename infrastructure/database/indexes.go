@@ -0,0 +1,255 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IndexSpec declares one index a collection must have. An index without a name gets MongoDB's own
+// default, such as "user_id_1", so indexes created before they were declared are recognised.
+type IndexSpec struct {
+	Name   string
+	Keys   bson.D
+	Unique bool
+	Sparse bool
+	// ExpireAfter makes a TTL index: MongoDB removes a document once the date in the index's only
+	// field is this far in the past. Zero expires documents at that date.
+	ExpireAfter   *time.Duration
+	PartialFilter bson.M
+}
+
+// IndexName is the name the index is created under
+func (s IndexSpec) IndexName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	parts := make([]string, 0, 2*len(s.Keys))
+	for _, key := range s.Keys {
+		parts = append(parts, key.Key, fmt.Sprint(key.Value))
+	}
+	return strings.Join(parts, "_")
+}
+
+// ExpireAt declares a TTL index removing documents once the date in field has passed
+func ExpireAt(field string) IndexSpec {
+	var expireAfter time.Duration
+	return IndexSpec{Keys: bson.D{{Key: field, Value: 1}}, ExpireAfter: &expireAfter}
+}
+
+// CollectionIndexes declares all indexes of one collection
+type CollectionIndexes struct {
+	Collection string
+	Indexes    []IndexSpec
+	// Retired names indexes that earlier versions created and that are now wrong; they are dropped
+	Retired []string
+}
+
+// ExistingIndex is an index as the database reports it
+type ExistingIndex struct {
+	Name               string `bson:"name"`
+	Keys               bson.D `bson:"key"`
+	Unique             bool   `bson:"unique"`
+	Sparse             bool   `bson:"sparse"`
+	ExpireAfterSeconds *int32 `bson:"expireAfterSeconds"`
+	PartialFilter      bson.M `bson:"partialFilterExpression"`
+}
+
+// IndexView is the part of a collection's index management EnsureIndexes needs
+type IndexView interface {
+	List(ctx context.Context) ([]ExistingIndex, error)
+	Create(ctx context.Context, spec IndexSpec) error
+	Drop(ctx context.Context, name string) error
+}
+
+// IndexDrift is a difference between a collection's indexes and their declaration that EnsureIndexes
+// leaves alone, because fixing it means rebuilding or dropping an index someone may rely on
+type IndexDrift struct {
+	Index   string
+	Problem string
+}
+
+func (d IndexDrift) String() string {
+	return d.Index + ": " + d.Problem
+}
+
+// IndexReport tells what EnsureIndexes changed and what it found but left for an operator
+type IndexReport struct {
+	Collection string
+	Created    []string
+	Dropped    []string
+	Drift      []IndexDrift
+}
+
+// EnsureIndexes brings a collection's indexes in line with their declaration: missing indexes are
+// created and retired ones dropped. Indexes that exist with other keys or options, and indexes that
+// are not declared at all, are reported as drift and left as they are.
+func EnsureIndexes(ctx context.Context, view IndexView, declared CollectionIndexes) (*IndexReport, error) {
+	report := &IndexReport{Collection: declared.Collection}
+	existing, err := view.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]ExistingIndex, len(existing))
+	for _, index := range existing {
+		byName[index.Name] = index
+	}
+
+	for _, name := range declared.Retired {
+		if _, ok := byName[name]; !ok {
+			continue
+		}
+		if err := view.Drop(ctx, name); err != nil {
+			return nil, fmt.Errorf("dropping index %s: %w", name, err)
+		}
+		delete(byName, name)
+		report.Dropped = append(report.Dropped, name)
+	}
+
+	declaredNames := map[string]bool{"_id_": true}
+	for _, spec := range declared.Indexes {
+		name := spec.IndexName()
+		declaredNames[name] = true
+		index, ok := byName[name]
+		if !ok {
+			if other, found := findByKeys(byName, spec.Keys); found {
+				// creating it would fail, as MongoDB allows one index per key pattern and options
+				declaredNames[other.Name] = true
+				report.Drift = append(report.Drift, IndexDrift{Index: name, Problem: "exists as " + other.Name})
+				index = other
+			} else {
+				if err := view.Create(ctx, spec); err != nil {
+					return nil, fmt.Errorf("creating index %s: %w", name, err)
+				}
+				report.Created = append(report.Created, name)
+				continue
+			}
+		}
+		for _, problem := range compareIndex(spec, index) {
+			report.Drift = append(report.Drift, IndexDrift{Index: name, Problem: problem})
+		}
+	}
+
+	for _, index := range existing {
+		if _, ok := byName[index.Name]; ok && !declaredNames[index.Name] {
+			report.Drift = append(report.Drift, IndexDrift{Index: index.Name, Problem: "not declared"})
+		}
+	}
+	return report, nil
+}
+
+func findByKeys(indexes map[string]ExistingIndex, keys bson.D) (ExistingIndex, bool) {
+	for _, index := range indexes {
+		if sameKeys(index.Keys, keys) {
+			return index, true
+		}
+	}
+	return ExistingIndex{}, false
+}
+
+// compareIndex lists how an existing index differs from its declaration
+func compareIndex(spec IndexSpec, index ExistingIndex) []string {
+	var problems []string
+	if !sameKeys(index.Keys, spec.Keys) {
+		problems = append(problems, fmt.Sprintf("keys are %v, declared %v", index.Keys, spec.Keys))
+	}
+	if index.Unique != spec.Unique {
+		problems = append(problems, fmt.Sprintf("unique is %t, declared %t", index.Unique, spec.Unique))
+	}
+	if index.Sparse != spec.Sparse {
+		problems = append(problems, fmt.Sprintf("sparse is %t, declared %t", index.Sparse, spec.Sparse))
+	}
+	switch {
+	case spec.ExpireAfter == nil && index.ExpireAfterSeconds != nil:
+		problems = append(problems, fmt.Sprintf("expires after %ds, declared without expiry", *index.ExpireAfterSeconds))
+	case spec.ExpireAfter != nil && index.ExpireAfterSeconds == nil:
+		problems = append(problems, fmt.Sprintf("has no expiry, declared to expire after %s", *spec.ExpireAfter))
+	case spec.ExpireAfter != nil && int64(*index.ExpireAfterSeconds) != int64(spec.ExpireAfter.Seconds()):
+		problems = append(problems, fmt.Sprintf("expires after %ds, declared %s", *index.ExpireAfterSeconds, *spec.ExpireAfter))
+	}
+	if !samePartialFilter(index.PartialFilter, spec.PartialFilter) {
+		problems = append(problems, fmt.Sprintf("partial filter is %v, declared %v", index.PartialFilter, spec.PartialFilter))
+	}
+	return problems
+}
+
+// sameKeys compares key patterns field by field in order; the database reports 1 as an int32 or a
+// double where a declaration has an int
+func sameKeys(a, b bson.D) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Key != b[i].Key || fmt.Sprint(a[i].Value) != fmt.Sprint(b[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// samePartialFilter compares filters after a round trip through BSON, so both hold the types the
+// database would report
+func samePartialFilter(existing, declared bson.M) bool {
+	if len(existing) == 0 || len(declared) == 0 {
+		return len(existing) == len(declared)
+	}
+	raw, err := bson.Marshal(declared)
+	if err != nil {
+		return false
+	}
+	var normalized bson.M
+	if err := bson.Unmarshal(raw, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(existing, normalized)
+}
+
+type mongoIndexView struct {
+	indexes mongo.IndexView
+}
+
+// NewIndexView manages the indexes of a MongoDB collection
+func NewIndexView(coll *mongo.Collection) IndexView {
+	return &mongoIndexView{indexes: coll.Indexes()}
+}
+
+func (v *mongoIndexView) List(ctx context.Context) ([]ExistingIndex, error) {
+	cursor, err := v.indexes.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []ExistingIndex
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+func (v *mongoIndexView) Create(ctx context.Context, spec IndexSpec) error {
+	opts := options.Index().SetName(spec.IndexName())
+	if spec.Unique {
+		opts.SetUnique(true)
+	}
+	if spec.Sparse {
+		opts.SetSparse(true)
+	}
+	if spec.ExpireAfter != nil {
+		opts.SetExpireAfterSeconds(int32(spec.ExpireAfter.Seconds()))
+	}
+	if spec.PartialFilter != nil {
+		opts.SetPartialFilterExpression(spec.PartialFilter)
+	}
+	_, err := v.indexes.CreateOne(ctx, mongo.IndexModel{Keys: spec.Keys, Options: opts})
+	return err
+}
+
+func (v *mongoIndexView) Drop(ctx context.Context, name string) error {
+	_, err := v.indexes.DropOne(ctx, name)
+	return err
+}
@@ -0,0 +1,230 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// fakeIndexView keeps a collection's indexes in memory the way MongoDB reports them
+type fakeIndexView struct {
+	indexes   []ExistingIndex
+	createErr error
+}
+
+func (f *fakeIndexView) List(ctx context.Context) ([]ExistingIndex, error) {
+	return append([]ExistingIndex(nil), f.indexes...), nil
+}
+
+func (f *fakeIndexView) Create(ctx context.Context, spec IndexSpec) error {
+	if f.createErr != nil {
+		return f.createErr
+	}
+	index := ExistingIndex{Name: spec.IndexName(), Unique: spec.Unique, Sparse: spec.Sparse}
+	for _, key := range spec.Keys {
+		// the server reports key directions as int32
+		index.Keys = append(index.Keys, bson.E{Key: key.Key, Value: int32(key.Value.(int))})
+	}
+	if spec.ExpireAfter != nil {
+		seconds := int32(spec.ExpireAfter.Seconds())
+		index.ExpireAfterSeconds = &seconds
+	}
+	if spec.PartialFilter != nil {
+		raw, _ := bson.Marshal(spec.PartialFilter)
+		_ = bson.Unmarshal(raw, &index.PartialFilter)
+	}
+	f.indexes = append(f.indexes, index)
+	return nil
+}
+
+func (f *fakeIndexView) Drop(ctx context.Context, name string) error {
+	for i, index := range f.indexes {
+		if index.Name == name {
+			f.indexes = append(f.indexes[:i], f.indexes[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("index not found")
+}
+
+func (f *fakeIndexView) names() []string {
+	names := make([]string, len(f.indexes))
+	for i, index := range f.indexes {
+		names[i] = index.Name
+	}
+	return names
+}
+
+func idIndex() ExistingIndex {
+	return ExistingIndex{Name: "_id_", Keys: bson.D{{Key: "_id", Value: int32(1)}}}
+}
+
+func tokenIndexes() CollectionIndexes {
+	return CollectionIndexes{
+		Collection: "refresh_tokens",
+		Indexes: []IndexSpec{
+			ExpireAt("expires_at"),
+			{
+				Keys:          bson.D{{Key: "token_hash", Value: 1}},
+				Unique:        true,
+				PartialFilter: bson.M{"token_hash": bson.M{"$exists": true}},
+			},
+			{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		},
+		Retired: []string{"activation_token_expiry_1"},
+	}
+}
+
+func TestIndexSpec_IndexName(t *testing.T) {
+	assert.Equal(t, "user_id_1_created_at_-1", IndexSpec{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}}.IndexName())
+	assert.Equal(t, "named", IndexSpec{Name: "named", Keys: bson.D{{Key: "user_id", Value: 1}}}.IndexName())
+}
+
+func TestEnsureIndexes(t *testing.T) {
+	t.Run("creates missing indexes", func(t *testing.T) {
+		view := &fakeIndexView{indexes: []ExistingIndex{idIndex()}}
+
+		report, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"expires_at_1", "token_hash_1", "user_id_1"}, report.Created)
+		assert.Empty(t, report.Drift)
+		assert.Equal(t, []string{"_id_", "expires_at_1", "token_hash_1", "user_id_1"}, view.names())
+		assert.Equal(t, int32(0), *view.indexes[1].ExpireAfterSeconds)
+	})
+
+	t.Run("is idempotent", func(t *testing.T) {
+		view := &fakeIndexView{indexes: []ExistingIndex{idIndex()}}
+		_, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+
+		report, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+		assert.Empty(t, report.Created)
+		assert.Empty(t, report.Dropped)
+		assert.Empty(t, report.Drift)
+	})
+
+	t.Run("drops retired indexes", func(t *testing.T) {
+		expiry := int32(0)
+		view := &fakeIndexView{indexes: []ExistingIndex{
+			idIndex(),
+			{Name: "activation_token_expiry_1", Keys: bson.D{{Key: "activation_token_expiry", Value: int32(1)}}, ExpireAfterSeconds: &expiry},
+		}}
+
+		report, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"activation_token_expiry_1"}, report.Dropped)
+		assert.NotContains(t, view.names(), "activation_token_expiry_1")
+		assert.Empty(t, report.Drift)
+	})
+
+	t.Run("reports indexes whose options drifted", func(t *testing.T) {
+		hour := int32(3600)
+		view := &fakeIndexView{indexes: []ExistingIndex{
+			idIndex(),
+			{Name: "expires_at_1", Keys: bson.D{{Key: "expires_at", Value: int32(1)}}, ExpireAfterSeconds: &hour},
+			{Name: "token_hash_1", Keys: bson.D{{Key: "token_hash", Value: int32(1)}}},
+		}}
+
+		report, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"user_id_1"}, report.Created)
+		assert.Equal(t, []IndexDrift{
+			{Index: "expires_at_1", Problem: "expires after 3600s, declared 0s"},
+			{Index: "token_hash_1", Problem: "unique is false, declared true"},
+			{Index: "token_hash_1", Problem: "partial filter is map[], declared map[token_hash:map[$exists:true]]"},
+		}, report.Drift)
+		// drifted indexes are left for an operator to rebuild
+		assert.Nil(t, view.indexes[2].PartialFilter)
+	})
+
+	t.Run("reports a declared index existing under another name", func(t *testing.T) {
+		view := &fakeIndexView{indexes: []ExistingIndex{
+			idIndex(),
+			{Name: "by_user", Keys: bson.D{{Key: "user_id", Value: int32(1)}}},
+		}}
+
+		report, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"expires_at_1", "token_hash_1"}, report.Created)
+		assert.Equal(t, []IndexDrift{{Index: "user_id_1", Problem: "exists as by_user"}}, report.Drift)
+	})
+
+	t.Run("reports undeclared indexes", func(t *testing.T) {
+		view := &fakeIndexView{indexes: []ExistingIndex{
+			idIndex(),
+			{Name: "family_id_1", Keys: bson.D{{Key: "family_id", Value: int32(1)}}},
+		}}
+
+		report, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.NoError(t, err)
+		assert.Equal(t, []IndexDrift{{Index: "family_id_1", Problem: "not declared"}}, report.Drift)
+		assert.Contains(t, view.names(), "family_id_1")
+	})
+
+	t.Run("fails when an index cannot be created", func(t *testing.T) {
+		view := &fakeIndexView{createErr: errors.New("duplicate key")}
+
+		_, err := EnsureIndexes(context.Background(), view, tokenIndexes())
+		assert.ErrorContains(t, err, "creating index expires_at_1")
+	})
+}
+
+func TestMongoIndexView_Create(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("sends the declared options", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		expireAfter := 24 * time.Hour
+
+		err := NewIndexView(mt.Coll).Create(context.Background(), IndexSpec{
+			Keys:          bson.D{{Key: "expires_at", Value: 1}},
+			Unique:        true,
+			ExpireAfter:   &expireAfter,
+			PartialFilter: bson.M{"expires_at": bson.M{"$exists": true}},
+		})
+		assert.NoError(t, err)
+		index := mt.GetStartedEvent().Command.Lookup("indexes", "0").Document()
+		assert.Equal(t, "expires_at_1", index.Lookup("name").StringValue())
+		assert.True(t, index.Lookup("unique").Boolean())
+		assert.Equal(t, int32(86400), index.Lookup("expireAfterSeconds").Int32())
+		assert.True(t, index.Lookup("partialFilterExpression", "expires_at", "$exists").Boolean())
+	})
+}
+
+func TestMongoIndexView_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("decodes the index options", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}},
+			bson.D{
+				{Key: "v", Value: 2},
+				{Key: "key", Value: bson.D{{Key: "expires_at", Value: 1}}},
+				{Key: "name", Value: "expires_at_1"},
+				{Key: "expireAfterSeconds", Value: int32(0)},
+			},
+		))
+
+		indexes, err := NewIndexView(mt.Coll).List(context.Background())
+		assert.NoError(t, err)
+		if !assert.Len(t, indexes, 2) {
+			return
+		}
+		assert.Nil(t, indexes[0].ExpireAfterSeconds)
+		assert.Equal(t, "expires_at_1", indexes[1].Name)
+		assert.Equal(t, int32(0), *indexes[1].ExpireAfterSeconds)
+
+		report, err := EnsureIndexes(context.Background(), &fakeIndexView{indexes: indexes}, CollectionIndexes{
+			Collection: "refresh_tokens",
+			Indexes:    []IndexSpec{ExpireAt("expires_at")},
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, report.Drift)
+	})
+}
@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"context"
+	"g3-g65-bsp/domain"
 	"g3-g65-bsp/infrastructure/auth"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
-func AuthMiddleware(jwtHandler *auth.JWT) gin.HandlerFunc {
+
+// AuthMiddleware admits requests with a valid access token that has not been revoked, neither on its
+// own nor by its user's watermark. Without revocations only the token itself is checked.
+func AuthMiddleware(jwtHandler *auth.JWT, revocations domain.TokenRevocationRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -22,11 +27,55 @@ func AuthMiddleware(jwtHandler *auth.JWT) gin.HandlerFunc {
 			return
 		}
 
+		if revocations != nil {
+			revoked, err := isRevoked(c.Request.Context(), revocations, claims)
+			if err != nil {
+				c.AbortWithStatusJSON(503, gin.H{"error": "could not verify token"})
+				return
+			}
+			if revoked {
+				c.AbortWithStatusJSON(401, gin.H{"error": "token revoked"})
+				return
+			}
+		}
+
 		// Set claims in context
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("token_id", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		c.Next()
 	}
 }
 
+// OptionalAuthMiddleware lets requests without an Authorization header through anonymously, for routes
+// public readers share with signed-in users. A token that is sent must be valid, as with AuthMiddleware.
+func OptionalAuthMiddleware(jwtHandler *auth.JWT, revocations domain.TokenRevocationRepository) gin.HandlerFunc {
+	authenticate := AuthMiddleware(jwtHandler, revocations)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
+// isRevoked checks the token's ID against the denylist and its issue time against its user's
+// watermark. Tokens issued before access tokens had IDs can only be revoked by the watermark.
+// Issue times are whole seconds, so a token issued in the watermark's own second is revoked too.
+func isRevoked(ctx context.Context, revocations domain.TokenRevocationRepository, claims *auth.Claims) (bool, error) {
+	if claims.ID != "" {
+		if revoked, err := revocations.IsAccessTokenRevoked(ctx, claims.ID); err != nil || revoked {
+			return revoked, err
+		}
+	}
+	revokedBefore, err := revocations.TokensRevokedBefore(ctx, claims.UserID)
+	if err != nil || revokedBefore.IsZero() {
+		return false, err
+	}
+	return claims.IssuedAt == nil || claims.IssuedAt.Time.Unix() <= revokedBefore.Unix(), nil
+}
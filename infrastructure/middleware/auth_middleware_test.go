@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"g3-g65-bsp/infrastructure/auth"
 	"net/http"
 	"net/http/httptest"
@@ -22,7 +24,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 	// Setup router with middleware
 	router := gin.New()
-	router.Use(AuthMiddleware(jwt))
+	router.Use(AuthMiddleware(jwt, nil))
 	router.GET("/test", func(c *gin.Context) {
 		c.Status(http.StatusOK)
 	})
@@ -53,3 +55,134 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 	})
 }
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwt := auth.NewJWT("access-secret", "refresh-secret", 15*time.Minute, 24*time.Hour)
+	token, _ := jwt.GenerateAccessToken("test-user", "user")
+
+	router := gin.New()
+	router.Use(OptionalAuthMiddleware(jwt, nil))
+	router.GET("/test", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id"))
+	})
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("anonymous", func(t *testing.T) {
+		w := serve("")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+
+	t.Run("signed in", func(t *testing.T) {
+		w := serve("Bearer " + token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "test-user", w.Body.String())
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("Bearer invalid-token").Code)
+	})
+}
+
+// fakeRevocations keeps revocations in memory
+type fakeRevocations struct {
+	revoked    map[string]bool
+	watermarks map[string]time.Time
+	err        error
+}
+
+func (f *fakeRevocations) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	f.revoked[tokenID] = true
+	return nil
+}
+
+func (f *fakeRevocations) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	return f.revoked[tokenID], f.err
+}
+
+func (f *fakeRevocations) RevokeTokensIssuedBefore(ctx context.Context, userID string, t time.Time) error {
+	f.watermarks[userID] = t
+	return nil
+}
+
+func (f *fakeRevocations) TokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	return f.watermarks[userID], f.err
+}
+
+func TestAuthMiddleware_Revocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwt := auth.NewJWT("access-secret", "refresh-secret", 15*time.Minute, 24*time.Hour)
+
+	serve := func(revocations *fakeRevocations, token string) int {
+		router := gin.New()
+		router.Use(AuthMiddleware(jwt, revocations))
+		router.GET("/test", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	newRevocations := func() *fakeRevocations {
+		return &fakeRevocations{revoked: map[string]bool{}, watermarks: map[string]time.Time{}}
+	}
+
+	t.Run("denied token", func(t *testing.T) {
+		revocations := newRevocations()
+		token, _ := jwt.GenerateAccessToken("user-1", "admin")
+		other, _ := jwt.GenerateAccessToken("user-1", "admin")
+		claims, _ := jwt.ValidateAccessToken(token)
+		_ = revocations.RevokeAccessToken(context.Background(), claims.ID, claims.ExpiresAt.Time)
+
+		assert.Equal(t, http.StatusUnauthorized, serve(revocations, token))
+		assert.Equal(t, http.StatusOK, serve(revocations, other))
+	})
+
+	t.Run("tokens issued before the watermark", func(t *testing.T) {
+		revocations := newRevocations()
+		token, _ := jwt.GenerateAccessToken("user-1", "admin")
+		otherUser, _ := jwt.GenerateAccessToken("user-2", "admin")
+		claims, _ := jwt.ValidateAccessToken(token)
+		_ = revocations.RevokeTokensIssuedBefore(context.Background(), "user-1", claims.IssuedAt.Add(time.Second))
+
+		assert.Equal(t, http.StatusUnauthorized, serve(revocations, token))
+		assert.Equal(t, http.StatusOK, serve(revocations, otherUser))
+	})
+
+	t.Run("tokens issued in the watermark's second", func(t *testing.T) {
+		revocations := newRevocations()
+		token, _ := jwt.GenerateAccessToken("user-1", "admin")
+		claims, _ := jwt.ValidateAccessToken(token)
+		_ = revocations.RevokeTokensIssuedBefore(context.Background(), "user-1", claims.IssuedAt.Add(999*time.Millisecond))
+
+		assert.Equal(t, http.StatusUnauthorized, serve(revocations, token))
+	})
+
+	t.Run("tokens issued after the watermark's second", func(t *testing.T) {
+		revocations := newRevocations()
+		token, _ := jwt.GenerateAccessToken("user-1", "admin")
+		claims, _ := jwt.ValidateAccessToken(token)
+		_ = revocations.RevokeTokensIssuedBefore(context.Background(), "user-1", claims.IssuedAt.Add(-time.Millisecond))
+
+		assert.Equal(t, http.StatusOK, serve(revocations, token))
+	})
+
+	t.Run("revocations cannot be checked", func(t *testing.T) {
+		revocations := newRevocations()
+		revocations.err = errors.New("database down")
+		token, _ := jwt.GenerateAccessToken("user-1", "user")
+
+		assert.Equal(t, http.StatusServiceUnavailable, serve(revocations, token))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"g3-g65-bsp/infrastructure/cache"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -42,6 +43,12 @@ func (w *cachedWriter) WriteHeader(statusCode int) {
 // service: The cache service that fulfills the cache.Service interface.
 // ttl: The time-to-live for a cache entry.
 func CachePage(service cache.Service, ttl time.Duration) gin.HandlerFunc {
+	return CacheGroupPage(service, "", ttl)
+}
+
+// CacheGroupPage is CachePage for pages that belong to a cache group, so RevalidateGroup can drop
+// them all at once. An empty group caches the page under its URL, like CachePage.
+func CacheGroupPage(service cache.Service, group string, ttl time.Duration) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// We only cache GET requests.
 		if c.Request.Method != http.MethodGet {
@@ -51,6 +58,9 @@ func CachePage(service cache.Service, ttl time.Duration) gin.HandlerFunc {
 
 		// Use the request URL as the cache key.
 		key := c.Request.URL.String()
+		if group != "" {
+			key = cache.GroupKey(service, group, key)
+		}
 
 		// --- 1. CHECK CACHE (Cache Hit) ---
 		// Attempt to retrieve the response from the cache.
@@ -82,8 +92,9 @@ func CachePage(service cache.Service, ttl time.Duration) gin.HandlerFunc {
 		c.Next() // Process the request by calling the actual handler.
 
 		// --- 3. CACHE RESPONSE ---
-		// After the handler has run, we cache the response if it was successful (200 OK).
-		if writer.status == http.StatusOK {
+		// After the handler has run, we cache the response if it was successful (200 OK)
+		// and the handler did not mark it as specific to the requesting user.
+		if writer.status == http.StatusOK && !isPrivate(writer.Header()) {
 			responseToCache := responseCache{
 				Status: writer.status,
 				Header: writer.Header(),
@@ -94,6 +105,12 @@ func CachePage(service cache.Service, ttl time.Duration) gin.HandlerFunc {
 	}
 }
 
+// isPrivate reports whether the response must not be stored in a shared cache.
+func isPrivate(header http.Header) bool {
+	cacheControl := header.Get("Cache-Control")
+	return strings.Contains(cacheControl, "private") || strings.Contains(cacheControl, "no-store")
+}
+
 // RevalidateCache returns a Gin middleware that deletes the cached value for the current request URL.
 // Use this on endpoints that modify data and need to invalidate the cache for the affected resource.
 // service: The cache service that fulfills the cache.Service interface.
@@ -104,3 +121,14 @@ func RevalidateCache(service cache.Service) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RevalidateGroup returns a Gin middleware that drops every page in a cache group once the request
+// has succeeded. Use this on endpoints that change what the group's pages list.
+func RevalidateGroup(service cache.Service, group string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Writer.Status() < http.StatusBadRequest {
+			cache.InvalidateGroup(service, group)
+		}
+	}
+}
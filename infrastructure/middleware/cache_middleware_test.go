@@ -39,6 +39,26 @@ func TestCachePage(t *testing.T) {
 	assert.Equal(t, "this is a test", w2.Body.String())
 }
 
+func TestCachePage_SkipsPrivateResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cacheService := cache.NewInMemoryCache(5*time.Minute, 10*time.Minute)
+
+	router := gin.New()
+	router.Use(CachePage(cacheService, 1*time.Minute))
+	router.GET("/private", func(c *gin.Context) {
+		c.Header("Cache-Control", "private")
+		c.String(http.StatusOK, "only for you")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/private", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	_, found := cacheService.Get("/private")
+	assert.False(t, found)
+}
+
 func TestRevalidateCache(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	cacheService := cache.NewInMemoryCache(5*time.Minute, 10*time.Minute)
@@ -60,3 +80,39 @@ func TestRevalidateCache(t *testing.T) {
 	_, found := cacheService.Get(key)
 	assert.False(t, found)
 }
+
+func TestRevalidateGroup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cacheService := cache.NewInMemoryCache(5*time.Minute, 10*time.Minute)
+	calls := 0
+
+	router := gin.New()
+	router.GET("/list", CacheGroupPage(cacheService, "lists", time.Minute), func(c *gin.Context) {
+		calls++
+		c.String(http.StatusOK, "list")
+	})
+	router.POST("/ok", RevalidateGroup(cacheService, "lists"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.POST("/fail", RevalidateGroup(cacheService, "lists"), func(c *gin.Context) {
+		c.Status(http.StatusBadRequest)
+	})
+
+	for _, step := range []struct {
+		method string
+		path   string
+		calls  int
+	}{
+		{http.MethodGet, "/list", 1},
+		{http.MethodGet, "/list", 1},
+		{http.MethodPost, "/fail", 1},
+		{http.MethodGet, "/list", 1},
+		{http.MethodPost, "/ok", 1},
+		{http.MethodGet, "/list", 2},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(step.method, step.path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, step.calls, calls, step.path)
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditEventModel is the MongoDB representation of an audit event
+type AuditEventModel struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Type      string             `bson:"type"`
+	UserID    string             `bson:"user_id,omitempty"`
+	Details   map[string]string  `bson:"details,omitempty"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository appends audit events to the "audit_events" collection, which is never updated
+func NewAuditRepository(db *mongo.Database) domain.AuditRepository {
+	coll := db.Collection("audit_events")
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create audit event indexes: %v", err)
+	}
+	return &AuditRepository{collection: coll}
+}
+
+func (r *AuditRepository) Record(ctx context.Context, event *domain.AuditEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	result, err := r.collection.InsertOne(ctx, AuditEventModel{
+		Type:      string(event.Type),
+		UserID:    event.UserID,
+		Details:   event.Details,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		event.ID = oid.Hex()
+	}
+	return nil
+}
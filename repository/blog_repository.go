@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"g3-g65-bsp/domain"
+	"slices"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -19,96 +21,69 @@ type BlogModel struct {
 	AuthorID       primitive.ObjectID `bson:"author_id"`
 	AuthorUsername string             `bson:"author_username"`
 	Title          string             `bson:"title"`
+	Slug           string             `bson:"slug,omitempty"`
+	PreviousSlugs  []string           `bson:"previous_slugs,omitempty"`
 	Content        string             `bson:"content"`
+	ContentFormat  string             `bson:"content_format,omitempty"`
+	ContentHTML    string             `bson:"content_html,omitempty"`
 	Tags           []string           `bson:"tags"`
-	Metrics        *Metrics           `bson:"metrics"`
-	Comments       []Comment          `bson:"comments"`
+	Metrics        *Metrics           `bson:"metrics,omitempty"`
+	// CommentCount is only ever changed with $inc, so it is left out of the $set in UpdateBlog
+	CommentCount   int                `bson:"comment_count,omitempty"`
+	// ReactionCounts holds one count per reaction type and, like CommentCount, is only changed with $inc
+	ReactionCounts map[string]int     `bson:"reaction_counts,omitempty"`
+	// TrendingScore is written by the trending job. Only CreateBlog sets it, to 0, so that new blogs can
+	// be paged through by it before the job first gets to them.
+	TrendingScore  *float64           `bson:"trending_score,omitempty"`
+	Status         string             `bson:"status"`
+	PublishAt      *time.Time         `bson:"publish_at,omitempty"`
+	PublishedAt    *time.Time         `bson:"published_at,omitempty"`
 	CreatedAt      *time.Time         `bson:"created_at"`
 	UpdatedAt      *time.Time         `bson:"updated_at"`
 }
 
 type Metrics struct {
-	ViewCount int    `bson:"view_count"`
-	Likes     *Likes `bson:"likes"`
-	Dislikes  *Likes `bson:"dislikes"`
+	ViewCount int `bson:"view_count"`
 }
 
-type Likes struct {
-	Count int      `bson:"count"`
-	Users []string `bson:"users"`
-}
-
-type Comment struct {
-	ID             primitive.ObjectID `bson:"id"`
-	AuthorID       primitive.ObjectID `bson:"author_id"`
-	AuthorUsername string             `bson:"author_username"`
-	Content        string             `bson:"content"`
-	CreatedAt      *time.Time         `bson:"created_at"`
-}
-
-func FromDomain(comment *domain.Comment) *Comment {
-	var authorID primitive.ObjectID
-	var err error
-	authorID, err = primitive.ObjectIDFromHex(comment.AuthorID)
-	if err != nil {
-		authorID = primitive.NilObjectID
-	}
-	var id primitive.ObjectID
-	id, err = primitive.ObjectIDFromHex(comment.ID)
-	if err != nil {
-		id = primitive.NilObjectID
-	}
-	return &Comment{
-		ID:             id,
-		AuthorID:       authorID,
-		AuthorUsername: comment.AuthorUsername,
-		Content:        comment.Content,
-		CreatedAt:      comment.CreatedAt,
-	}
-}
-
-func (c *Comment) ToDomain() *domain.Comment {
-	return &domain.Comment{
-		ID:             c.ID.Hex(),
-		AuthorID:       c.AuthorID.Hex(),
-		AuthorUsername: c.AuthorUsername,
-		Content:        c.Content,
-		CreatedAt:      c.CreatedAt,
-	}
+// searchResultModel is a blog decoded together with its text search score
+type searchResultModel struct {
+	BlogModel `bson:",inline"`
+	Score     float64 `bson:"score"`
 }
 
 func (m *BlogModel) ToDomain() *domain.Blog {
-	comments := make([]domain.Comment, len(m.Comments))
-	for i, c := range m.Comments {
-		comments[i] = domain.Comment{
-			ID:             c.ID.Hex(),
-			AuthorID:       c.AuthorID.Hex(),
-			AuthorUsername: c.AuthorUsername,
-			Content:        c.Content,
-			CreatedAt:      c.CreatedAt,
-		}
+	// Blogs written before the status field existed were always public
+	status := domain.BlogStatus(m.Status)
+	if status == "" {
+		status = domain.BlogStatusPublished
+	}
+	var trendingScore float64
+	if m.TrendingScore != nil {
+		trendingScore = *m.TrendingScore
 	}
 	return &domain.Blog{
 		ID:             m.ID.Hex(),
 		AuthorID:       m.AuthorID.Hex(),
 		AuthorUsername: m.AuthorUsername,
 		Title:          m.Title,
+		Slug:           m.Slug,
+		PreviousSlugs:  m.PreviousSlugs,
 		Content:        m.Content,
+		ContentFormat:  domain.ContentFormat(m.ContentFormat),
+		ContentHTML:    m.ContentHTML,
 		Tags:           m.Tags,
 		Metrics: &domain.Metrics{
-			ViewCount: m.Metrics.ViewCount,
-			Likes: &domain.Likes{
-				Count: m.Metrics.Likes.Count,
-				Users: m.Metrics.Likes.Users,
-			},
-			Dislikes: &domain.Likes{
-				Count: m.Metrics.Dislikes.Count,
-				Users: m.Metrics.Dislikes.Users,
-			},
+			ViewCount:    m.Metrics.ViewCount,
+			CommentCount: m.CommentCount,
+			Reactions:    m.ReactionCounts,
 		},
-		Comments:  comments,
-		CreatedAt: m.CreatedAt,
-		UpdatedAt: m.UpdatedAt,
+		Status:        status,
+		TrendingScore: trendingScore,
+		PublishAt:     m.PublishAt,
+		PublishedAt:   m.PublishedAt,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
 	}
 }
 
@@ -124,41 +99,18 @@ func (m *BlogModel) FromDomain(blog *domain.Blog) {
 	}
 	m.AuthorUsername = blog.AuthorUsername
 	m.Title = blog.Title
+	m.Slug = blog.Slug
+	m.PreviousSlugs = blog.PreviousSlugs
 	m.Content = blog.Content
+	m.ContentFormat = string(blog.ContentFormat)
+	m.ContentHTML = blog.ContentHTML
 	m.Tags = blog.Tags
 	m.Metrics = &Metrics{
 		ViewCount: blog.Metrics.ViewCount,
-		Likes: &Likes{
-			Count: blog.Metrics.Likes.Count,
-			Users: blog.Metrics.Likes.Users,
-		},
-		Dislikes: &Likes{
-			Count: blog.Metrics.Dislikes.Count,
-			Users: blog.Metrics.Dislikes.Users,
-		},
 	}
-	comments := make([]Comment, len(blog.Comments))
-	for i, c := range blog.Comments {
-		var authorID primitive.ObjectID
-		var err error
-		authorID, err = primitive.ObjectIDFromHex(c.AuthorID)
-		if err != nil {
-			authorID = primitive.NilObjectID
-		}
-		var id primitive.ObjectID
-		id, err = primitive.ObjectIDFromHex(c.ID)
-		if err != nil {
-			id = primitive.NilObjectID
-		}
-		comments[i] = Comment{
-			ID:             id,
-			AuthorID:       authorID,
-			AuthorUsername: c.AuthorUsername,
-			Content:        c.Content,
-			CreatedAt:      c.CreatedAt,
-		}
-	}
-	m.Comments = comments
+	m.Status = string(blog.Status)
+	m.PublishAt = blog.PublishAt
+	m.PublishedAt = blog.PublishedAt
 	m.CreatedAt = blog.CreatedAt
 	m.UpdatedAt = blog.UpdatedAt
 }
@@ -171,8 +123,12 @@ func (r *mongoBlogRepository) CreateBlog(ctx context.Context, blog *domain.Blog)
 	var model BlogModel
 	model.FromDomain(blog)
 	model.ID = primitive.NewObjectID()
+	model.TrendingScore = new(float64)
 
 	res, err := r.collection.InsertOne(ctx, model)
+	if mongo.IsDuplicateKeyError(err) {
+		return "", domain.ErrSlugTaken
+	}
 	if err != nil {
 		return "", err
 	}
@@ -201,16 +157,58 @@ func (r *mongoBlogRepository) GetBlogByID(ctx context.Context, id string) (*doma
 	return model.ToDomain(), nil
 }
 
+// GetBlogBySlug finds a blog by its current slug or by one it had before a title change
+func (r *mongoBlogRepository) GetBlogBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"previous_slugs": slug},
+	}}
+	var model BlogModel
+	err := r.collection.FindOne(ctx, filter).Decode(&model)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrBlogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+// SlugExists reports whether any blog other than excludeID uses the slug, now or as a redirect
+func (r *mongoBlogRepository) SlugExists(ctx context.Context, slug string, excludeID string) (bool, error) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"slug": slug},
+		bson.M{"previous_slugs": slug},
+	}}
+	if oid, err := primitive.ObjectIDFromHex(excludeID); err == nil {
+		filter["_id"] = bson.M{"$ne": oid}
+	}
+	count, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
 func (r *mongoBlogRepository) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
 	var model BlogModel
 	model.FromDomain(blog)
 	now := time.Now()
 	model.UpdatedAt = &now
+	// writing back the metrics that were read would undo views and reactions that landed in between
+	model.Metrics = nil
 
 	filter := bson.M{"_id": model.ID}
 	update := bson.M{"$set": model}
+	if model.PublishAt == nil {
+		// publish_at is omitted from $set when empty, so clear a cancelled schedule explicitly
+		update["$unset"] = bson.M{"publish_at": ""}
+	}
 
 	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrSlugTaken
+	}
 	if err != nil {
 		return err
 	}
@@ -239,18 +237,45 @@ func (r *mongoBlogRepository) DeleteBlog(ctx context.Context, id string) error {
 func (r *mongoBlogRepository) ListBlogs(ctx context.Context, filter map[string]any, page, limit int) ([]*domain.Blog, *domain.Pagination, error) {
 	var andFilters []bson.M
 
-    if search, ok := filter["search"].(string); ok && search != "" {
-        orFilters := []bson.M{
-            {"title": bson.M{"$regex": search, "$options": "i"}},
-            {"author_username": bson.M{"$regex": search, "$options": "i"}},
-        }
-        andFilters = append(andFilters, bson.M{"$or": orFilters})
-    }
+	// $text goes through the text index and treats the query as words, never as a pattern
+	search, _ := filter["search"].(string)
+	if search != "" {
+		andFilters = append(andFilters, bson.M{"$text": bson.M{"$search": search}})
+	}
+
+	andFilters = append(andFilters, statusFilter(filter))
+
+	if authorID, ok := filter["author_id"].(string); ok && authorID != "" {
+		if oid, err := primitive.ObjectIDFromHex(authorID); err == nil {
+			andFilters = append(andFilters, bson.M{"author_id": oid})
+		} else {
+			// author IDs are stored as ObjectIDs, so the string matches nothing instead of every author
+			andFilters = append(andFilters, bson.M{"author_id": authorID})
+		}
+	}
 
 	if tags, ok := filter["tags"].([]string); ok && len(tags) > 0 {
 		andFilters = append(andFilters, bson.M{"tags": bson.M{"$all": tags}})
 	}
 
+	// a feed takes the posts of any followed author along with those carrying any followed tag
+	followed := bson.A{}
+	if authorIDs, ok := filter["followed_authors"].([]string); ok && len(authorIDs) > 0 {
+		oids := make([]primitive.ObjectID, 0, len(authorIDs))
+		for _, id := range authorIDs {
+			if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+				oids = append(oids, oid)
+			}
+		}
+		followed = append(followed, bson.M{"author_id": bson.M{"$in": oids}})
+	}
+	if tags, ok := filter["followed_tags"].([]string); ok && len(tags) > 0 {
+		followed = append(followed, bson.M{"tags": bson.M{"$in": tags}})
+	}
+	if len(followed) > 0 {
+		andFilters = append(andFilters, bson.M{"$or": followed})
+	}
+
 	if from, ok := filter["created_at_from"].(string); ok && from != "" {
 		if fromTime, err := time.Parse(time.RFC3339, from); err == nil {
 			andFilters = append(andFilters, bson.M{"created_at": bson.M{"$gte": fromTime}})
@@ -271,29 +296,63 @@ func (r *mongoBlogRepository) ListBlogs(ctx context.Context, filter map[string]a
 		bsonFilter["$and"] = andFilters
 	}
 
-	// Get total count for pagination
-	total, err := r.collection.CountDocuments(ctx, bsonFilter)
-	if err != nil {
-		return nil, nil, err
+	pagination := &domain.Pagination{
+		Page:  page,
+		Limit: limit,
+	}
+
+	// Counting scans every match, so it is only done on request
+	if includeTotal, _ := filter["include_total"].(bool); includeTotal {
+		total, err := r.collection.CountDocuments(ctx, bsonFilter)
+		if err != nil {
+			return nil, nil, err
+		}
+		count := int(total)
+		pagination.Total = &count
+	}
+
+	// A cursor seeks straight to its position through the sort index instead of skipping pages
+	cursor, _ := filter["cursor"].(*domain.BlogCursor)
+	if cursor != nil {
+		keyset, err := keysetFilter(cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		bsonFilter["$and"] = append(andFilters, keyset)
 	}
 
     opts := options.Find()
     if limit > 0 {
-        opts.SetLimit(int64(limit))
+        // one extra document tells whether there is another page
+        opts.SetLimit(int64(limit + 1))
     }
-    if page > 1 && limit > 0 {
+    if cursor == nil && page > 1 && limit > 0 {
         opts.SetSkip(int64((page - 1) * limit))
     }
 
+    textScore := bson.M{"$meta": "textScore"}
+    if search != "" {
+        opts.SetProjection(bson.M{"score": textScore})
+    }
+
     // Sorting logic
-    if sortBy, ok := filter["sortby"].(string); ok && sortBy != "" {
+    if sortBy, ok := filter["sortBy"].(string); ok && sortBy == "relevance" {
+        if search != "" {
+            opts.SetSort(bson.D{{Key: "score", Value: textScore}, {Key: "_id", Value: 1}})
+        }
+    } else if ok && sortBy != "" {
         sortOrder := 1 // ascending by default
         if order, ok := filter["order"].(string); ok {
             if order == "desc" {
                 sortOrder = -1
             }
         }
-        opts.SetSort(bson.D{{Key: sortBy, Value: sortOrder}})
+        // a prev cursor walks backwards from its position; the page is flipped back below
+        if cursor != nil && cursor.Backward {
+            sortOrder = -sortOrder
+        }
+        // _id breaks ties so that every blog has a unique position for cursors
+        opts.SetSort(bson.D{{Key: sortBy, Value: sortOrder}, {Key: "_id", Value: sortOrder}})
     }
 
 	cur, err := r.collection.Find(ctx, bsonFilter, opts)
@@ -303,100 +362,225 @@ func (r *mongoBlogRepository) ListBlogs(ctx context.Context, filter map[string]a
 	defer cur.Close(ctx)
 	var blogs []*domain.Blog
 	for cur.Next(ctx) {
-		var model BlogModel
+		var model searchResultModel
 		if err := cur.Decode(&model); err != nil {
 			return nil, nil, err
 		}
-		blogs = append(blogs, model.ToDomain())
+		blog := model.ToDomain()
+		blog.Score = model.Score
+		blogs = append(blogs, blog)
 	}
 	if err := cur.Err(); err != nil {
 		return nil, nil, err
 	}
 
-	pagination := &domain.Pagination{
-		Total: int(total),
-		Page:  page,
-		Limit: limit,
+	more := limit > 0 && len(blogs) > limit
+	if more {
+		blogs = blogs[:limit]
+	}
+	switch {
+	case cursor != nil && cursor.Backward:
+		slices.Reverse(blogs)
+		pagination.HasPrev = more
+		pagination.HasNext = true
+	case cursor != nil:
+		pagination.HasNext = more
+		pagination.HasPrev = true
+	default:
+		pagination.HasNext = more
+		pagination.HasPrev = page > 1
 	}
 
 	return blogs, pagination, nil
 }
 
-// IncrementBlogViewCount atomically increments the view count of a blog post
-func (r *mongoBlogRepository) IncrementBlogViewCount(ctx context.Context, id string, blog *domain.Blog) error {
-	oid, err := primitive.ObjectIDFromHex(id)
+// RelatedCandidates gathers blogs that may be related to blog: up to limit of the best text matches for
+// its title and tags, and up to limit of the newest blogs sharing a tag or the author. Only published
+// blogs other than blog itself are returned; ranking them is left to the caller.
+func (r *mongoBlogRepository) RelatedCandidates(ctx context.Context, blog *domain.Blog, limit int) ([]*domain.Blog, error) {
+	oid, err := primitive.ObjectIDFromHex(blog.ID)
 	if err != nil {
-		return ErrBlogNotFound
+		return nil, ErrBlogNotFound
 	}
-	filter := bson.M{"_id": oid}
-	update := bson.M{"$inc": bson.M{"metrics.view_count": 1}}
-	result, err := r.collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		return err
+	others := func(filter bson.M) bson.M {
+		return bson.M{"$and": bson.A{bson.M{"_id": bson.M{"$ne": oid}}, statusFilter(map[string]any{}), filter}}
 	}
-	if result.MatchedCount == 0 {
-		return ErrBlogNotFound
+	var blogs []*domain.Blog
+
+	if terms := strings.TrimSpace(blog.Title + " " + strings.Join(blog.Tags, " ")); terms != "" {
+		textScore := bson.M{"$meta": "textScore"}
+		opts := options.Find().
+			SetProjection(bson.M{"score": textScore}).
+			SetSort(bson.D{{Key: "score", Value: textScore}}).
+			SetLimit(int64(limit))
+		matches, err := r.findBlogs(ctx, others(bson.M{"$text": bson.M{"$search": terms}}), opts)
+		if err != nil {
+			return nil, err
+		}
+		blogs = append(blogs, matches...)
 	}
-	return nil
+
+	shared := bson.A{}
+	if len(blog.Tags) > 0 {
+		shared = append(shared, bson.M{"tags": bson.M{"$in": blog.Tags}})
+	}
+	if authorID, err := primitive.ObjectIDFromHex(blog.AuthorID); err == nil {
+		shared = append(shared, bson.M{"author_id": authorID})
+	}
+	if len(shared) > 0 {
+		opts := options.Find().
+			SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+			SetLimit(int64(limit))
+		matches, err := r.findBlogs(ctx, others(bson.M{"$or": shared}), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if !slices.ContainsFunc(blogs, func(b *domain.Blog) bool { return b.ID == match.ID }) {
+				blogs = append(blogs, match)
+			}
+		}
+	}
+	return blogs, nil
 }
 
-func (r *mongoBlogRepository) AddComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	oid, err := primitive.ObjectIDFromHex(blogID)
-	if err != nil {
-		return ErrBlogNotFound
+// GetBlogsByIDs looks up many blogs at once; ids that are malformed or match no blog are skipped
+func (r *mongoBlogRepository) GetBlogsByIDs(ctx context.Context, ids []string) ([]*domain.Blog, error) {
+	oids := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if oid, err := primitive.ObjectIDFromHex(id); err == nil {
+			oids = append(oids, oid)
+		}
 	}
-	newComment := FromDomain(comment)
-	newComment.ID = primitive.NewObjectID()
-	filter := bson.M{"_id": oid}
-	update := bson.M{"$push": bson.M{"comments": newComment}}
-	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if len(oids) == 0 {
+		return []*domain.Blog{}, nil
+	}
+	return r.findBlogs(ctx, bson.M{"_id": bson.M{"$in": oids}}, options.Find())
+}
+
+func (r *mongoBlogRepository) findBlogs(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.Blog, error) {
+	cur, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if result.MatchedCount == 0 {
-		return ErrBlogNotFound
+	defer cur.Close(ctx)
+	var models []BlogModel
+	if err := cur.All(ctx, &models); err != nil {
+		return nil, err
 	}
-	return nil
+	blogs := make([]*domain.Blog, len(models))
+	for i := range models {
+		blogs[i] = models[i].ToDomain()
+	}
+	return blogs, nil
 }
-func (r *mongoBlogRepository) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
-	blogOid, err := primitive.ObjectIDFromHex(blogID)
+
+// keysetFilter matches the blogs after the cursor's position in its sort order, or before it for a prev cursor.
+// Blogs with the same sort value are ordered by _id.
+func keysetFilter(cursor *domain.BlogCursor) (bson.M, error) {
+	oid, err := primitive.ObjectIDFromHex(cursor.ID)
 	if err != nil {
-		return nil, ErrBlogNotFound
+		return nil, domain.ErrInvalidCursor
 	}
-	commentOid, err := primitive.ObjectIDFromHex(commentID)
-	if err != nil {
-		return nil, errors.New("invalid comment ID")
+	op := "$gt"
+	if (cursor.Order == "desc") != cursor.Backward {
+		op = "$lt"
 	}
-	filter := bson.M{"_id": blogOid, "comments.id": commentOid}
+	return bson.M{"$or": bson.A{
+		bson.M{cursor.SortBy: bson.M{op: cursor.Value}},
+		bson.M{cursor.SortBy: cursor.Value, "_id": bson.M{op: oid}},
+	}}, nil
+}
+
+// PublishDueBlog atomically claims one draft whose publish_at has passed and publishes it.
+// Because the claim is a single findAndModify, concurrent publishers never publish the same blog twice.
+// It returns ErrBlogNotFound when nothing is due.
+func (r *mongoBlogRepository) PublishDueBlog(ctx context.Context, now time.Time) (*domain.Blog, error) {
+	filter := bson.M{
+		"status":     string(domain.BlogStatusDraft),
+		"publish_at": bson.M{"$lte": now},
+	}
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"status":       string(domain.BlogStatusPublished),
+			"published_at": "$publish_at",
+			"updated_at":   now,
+		}},
+		bson.M{"$unset": "publish_at"},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "publish_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
 	var model BlogModel
-	err = r.collection.FindOne(ctx, filter).Decode(&model)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&model)
 	if err == mongo.ErrNoDocuments {
 		return nil, ErrBlogNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-	for _, c := range model.Comments {
-		if c.ID == commentOid {
-			return c.ToDomain(), nil
+	return model.ToDomain(), nil
+}
+
+// statusFilter restricts a listing to the requested status, defaulting to published.
+// Documents without a status predate the lifecycle and are treated as published.
+func statusFilter(filter map[string]any) bson.M {
+	status, _ := filter["status"].(string)
+	if status == "" || status == string(domain.BlogStatusPublished) {
+		return bson.M{"status": bson.M{"$in": bson.A{string(domain.BlogStatusPublished), nil}}}
+	}
+	return bson.M{"status": status}
+}
+
+// IncrementViewCounts adds a batch of views in one round trip. Blogs deleted since they were viewed
+// are skipped.
+func (r *mongoBlogRepository) IncrementViewCounts(ctx context.Context, views map[string]int) error {
+	models := make([]mongo.WriteModel, 0, len(views))
+	ids := make([]string, 0, len(views))
+	for id, count := range views {
+		oid, err := primitive.ObjectIDFromHex(id)
+		if err != nil || count == 0 {
+			continue
 		}
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": oid}).
+			SetUpdate(bson.M{"$inc": bson.M{"metrics.view_count": count}}))
+		ids = append(ids, id)
+	}
+	if len(models) == 0 {
+		return nil
 	}
-	return nil, errors.New("comment not found")
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return partialWriteError(err, ids)
 }
 
-func (r *mongoBlogRepository) UpdateComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	blogOid, err := primitive.ObjectIDFromHex(blogID)
-	if err != nil {
-		return ErrBlogNotFound
+// partialWriteError turns the failure of an unordered bulk write in which only some writes failed into a
+// *domain.PartialWriteError naming the keys of those writes; keys[i] belongs to the i-th model.
+// Any other error is returned as it is, as there is no telling which writes were applied.
+func partialWriteError[K comparable](err error, keys []K) error {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return err
+	}
+	failed := make([]K, 0, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Index < 0 || writeErr.Index >= len(keys) {
+			return err
+		}
+		failed = append(failed, keys[writeErr.Index])
 	}
-	commentOid, err := primitive.ObjectIDFromHex(comment.ID)
+	return &domain.PartialWriteError[K]{Failed: failed, Err: err}
+}
+
+// IncrementCommentCount adjusts the blog's comment count by delta
+func (r *mongoBlogRepository) IncrementCommentCount(ctx context.Context, id string, delta int) error {
+	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return errors.New("invalid comment ID")
+		return ErrBlogNotFound
 	}
-	filter := bson.M{"_id": blogOid, "comments.id": commentOid}
-	update := bson.M{"$set": bson.M{
-		"comments.$.content": comment.Content,
-	}}
+	filter := bson.M{"_id": oid}
+	update := bson.M{"$inc": bson.M{"comment_count": delta}}
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
@@ -407,17 +591,14 @@ func (r *mongoBlogRepository) UpdateComment(ctx context.Context, blogID string,
 	return nil
 }
 
-func (r *mongoBlogRepository) DeleteComment(ctx context.Context, blogID string, commentID string) error {
-	blogOid, err := primitive.ObjectIDFromHex(blogID)
+// IncrementReactionCount adjusts the blog's count for one reaction type by delta
+func (r *mongoBlogRepository) IncrementReactionCount(ctx context.Context, id string, reactionType string, delta int) error {
+	oid, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return ErrBlogNotFound
 	}
-	commentOid, err := primitive.ObjectIDFromHex(commentID)
-	if err != nil {
-		return errors.New("invalid comment ID")
-	}
-	filter := bson.M{"_id": blogOid}
-	update := bson.M{"$pull": bson.M{"comments": bson.M{"id": commentOid}}}
+	filter := bson.M{"_id": oid}
+	update := bson.M{"$inc": bson.M{"reaction_counts." + reactionType: delta}}
 	result, err := r.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"g3-g65-bsp/domain"
 	"g3-g65-bsp/infrastructure"
@@ -10,6 +9,7 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // CacheService defines the interface for a cache.
@@ -28,7 +28,7 @@ type cachedBlogRepository struct {
 }
 
 // ErrBlogNotFound is returned when a blog is not found in the repository
-var ErrBlogNotFound = errors.New("blog not found")
+var ErrBlogNotFound = domain.ErrBlogNotFound
 
 // NewBlogRepository returns a MongoDB implementation of BlogRepository
 func NewBlogRepository(collection *mongo.Collection, cache CacheService) domain.BlogRepository {
@@ -40,6 +40,52 @@ func NewBlogRepository(collection *mongo.Collection, cache CacheService) domain.
 				{Key: "metrics.view_count", Value: -1},
 			},
 		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "author_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "publish_at", Value: 1},
+			},
+		},
+		{
+			// sortBy=trending pages through published blogs by score, _id breaking ties
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "trending_score", Value: -1},
+				{Key: "_id", Value: -1},
+			},
+		},
+		{
+			// Blogs created before slugs existed have none, so only index real slugs
+			Keys: bson.D{{Key: "slug", Value: 1}},
+			Options: options.Index().
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"slug": bson.M{"$type": "string"}}),
+		},
+		{
+			Keys: bson.D{{Key: "previous_slugs", Value: 1}},
+		},
+		{
+			// A collection can only have one text index, so it covers every searchable field
+			Keys: bson.D{
+				{Key: "title", Value: "text"},
+				{Key: "content", Value: "text"},
+				{Key: "tags", Value: "text"},
+			},
+			Options: options.Index().
+				SetName("blog_text_search").
+				SetWeights(bson.D{
+					{Key: "title", Value: 10},
+					{Key: "tags", Value: 5},
+					{Key: "content", Value: 1},
+				}),
+		},
 	}
 
 	if _, err := collection.Indexes().CreateMany(context.Background(), indexes); err != nil {
@@ -58,6 +104,36 @@ func blogCacheKey(id string) string {
 	return fmt.Sprintf("blog:%s", id)
 }
 
+// blogSlugCacheKey generates the cache key that maps a slug to its blog ID.
+// The blog itself stays cached under its ID, so invalidating by ID covers slug lookups too.
+func blogSlugCacheKey(slug string) string {
+	return fmt.Sprintf("blog:slug:%s", slug)
+}
+
+// GetBlogBySlug resolves the slug to an ID through the cache, then reads the blog like GetBlogByID.
+func (r *cachedBlogRepository) GetBlogBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	key := blogSlugCacheKey(slug)
+	if cached, found := r.cache.Get(key); found {
+		if id, ok := cached.(string); ok {
+			blog, err := r.GetBlogByID(ctx, id)
+			if err != ErrBlogNotFound {
+				return blog, err
+			}
+			// the blog was deleted and its slug may since belong to another one
+			r.cache.Delete(key)
+		}
+	}
+
+	blog, err := r.repo.GetBlogBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache.Set(key, blog.ID, r.defaultTTL)
+	r.cache.Set(blogCacheKey(blog.ID), blog, r.defaultTTL)
+	return blog, nil
+}
+
 // GetBlogByID checks the cache first before hitting the database.
 func (r *cachedBlogRepository) GetBlogByID(ctx context.Context, id string) (*domain.Blog, error) {
 	key := blogCacheKey(id)
@@ -88,6 +164,16 @@ func (r *cachedBlogRepository) ListBlogs(ctx context.Context, filter map[string]
 	return r.repo.ListBlogs(ctx, filter, page, limit)
 }
 
+// RelatedCandidates is passed through; the related lists built from it are cached by the usecase.
+func (r *cachedBlogRepository) RelatedCandidates(ctx context.Context, blog *domain.Blog, limit int) ([]*domain.Blog, error) {
+	return r.repo.RelatedCandidates(ctx, blog, limit)
+}
+
+// GetBlogsByIDs is passed through; its callers resolve many blogs at once and only read them.
+func (r *cachedBlogRepository) GetBlogsByIDs(ctx context.Context, ids []string) ([]*domain.Blog, error) {
+	return r.repo.GetBlogsByIDs(ctx, ids)
+}
+
 // UpdateBlog updates the blog in the DB and invalidates the cache.
 func (r *cachedBlogRepository) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
 	// First, execute the primary operation
@@ -109,37 +195,43 @@ func (r *cachedBlogRepository) DeleteBlog(ctx context.Context, id string) error
 	return nil
 }
 
-// AddComment invalidates the parent blog's cache.
-func (r *cachedBlogRepository) AddComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	if err := r.repo.AddComment(ctx, blogID, comment); err != nil {
+// IncrementCommentCount invalidates the blog's cache.
+func (r *cachedBlogRepository) IncrementCommentCount(ctx context.Context, id string, delta int) error {
+	if err := r.repo.IncrementCommentCount(ctx, id, delta); err != nil {
 		return err
 	}
-	r.cache.Delete(blogCacheKey(blogID))
+	r.cache.Delete(blogCacheKey(id))
 	return nil
 }
 
-// UpdateComment invalidates the parent blog's cache.
-func (r *cachedBlogRepository) UpdateComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	if err := r.repo.UpdateComment(ctx, blogID, comment); err != nil {
+// IncrementReactionCount invalidates the blog's cache.
+func (r *cachedBlogRepository) IncrementReactionCount(ctx context.Context, id string, reactionType string, delta int) error {
+	if err := r.repo.IncrementReactionCount(ctx, id, reactionType, delta); err != nil {
 		return err
 	}
-	r.cache.Delete(blogCacheKey(blogID))
+	r.cache.Delete(blogCacheKey(id))
 	return nil
 }
 
-// DeleteComment invalidates the parent blog's cache.
-func (r *cachedBlogRepository) DeleteComment(ctx context.Context, blogID string, commentID string) error {
-	if err := r.repo.DeleteComment(ctx, blogID, commentID); err != nil {
+// IncrementViewCounts invalidates the cache of every blog in the batch.
+func (r *cachedBlogRepository) IncrementViewCounts(ctx context.Context, views map[string]int) error {
+	if err := r.repo.IncrementViewCounts(ctx, views); err != nil {
 		return err
 	}
-	r.cache.Delete(blogCacheKey(blogID))
+	for id := range views {
+		r.cache.Delete(blogCacheKey(id))
+	}
 	return nil
 }
 
-// IncrementBlogViewCount invalidates the blog's cache.
-func (r *cachedBlogRepository) IncrementBlogViewCount(ctx context.Context, id string, blog *domain.Blog) error {
-
-	return r.repo.IncrementBlogViewCount(ctx, id, blog)
+// PublishDueBlog invalidates the cache entry of the blog it publishes.
+func (r *cachedBlogRepository) PublishDueBlog(ctx context.Context, now time.Time) (*domain.Blog, error) {
+	blog, err := r.repo.PublishDueBlog(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Delete(blogCacheKey(blog.ID))
+	return blog, nil
 }
 
 // Pass-through methods that don't affect single-blog caching
@@ -148,7 +240,6 @@ func (r *cachedBlogRepository) CreateBlog(ctx context.Context, blog *domain.Blog
 	return r.repo.CreateBlog(ctx, blog)
 }
 
-func (r *cachedBlogRepository) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
-	// Caching individual comments could be done, but for now we pass through
-	return r.repo.GetCommentByID(ctx, blogID, commentID)
+func (r *cachedBlogRepository) SlugExists(ctx context.Context, slug string, excludeID string) (bool, error) {
+	return r.repo.SlugExists(ctx, slug, excludeID)
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -29,6 +30,19 @@ func (m *MockBlogRepository) GetBlogByID(ctx context.Context, id string) (*domai
 	return args.Get(0).(*domain.Blog), args.Error(1)
 }
 
+func (m *MockBlogRepository) GetBlogBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) SlugExists(ctx context.Context, slug string, excludeID string) (bool, error) {
+	args := m.Called(ctx, slug, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockBlogRepository) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
 	args := m.Called(ctx, blog)
 	return args.Error(0)
@@ -47,32 +61,43 @@ func (m *MockBlogRepository) ListBlogs(ctx context.Context, filter map[string]an
 	return args.Get(0).([]*domain.Blog), args.Get(1).(*domain.Pagination), args.Error(2)
 }
 
-func (m *MockBlogRepository) IncrementBlogViewCount(ctx context.Context, id string, blog *domain.Blog) error {
-	args := m.Called(ctx, id, blog)
+func (m *MockBlogRepository) IncrementViewCounts(ctx context.Context, views map[string]int) error {
+	args := m.Called(ctx, views)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) AddComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, blogID, comment)
+func (m *MockBlogRepository) IncrementCommentCount(ctx context.Context, id string, delta int) error {
+	args := m.Called(ctx, id, delta)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) UpdateComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, blogID, comment)
+func (m *MockBlogRepository) IncrementReactionCount(ctx context.Context, id string, reactionType string, delta int) error {
+	args := m.Called(ctx, id, reactionType, delta)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
-	args := m.Called(ctx, blogID, commentID)
+func (m *MockBlogRepository) PublishDueBlog(ctx context.Context, now time.Time) (*domain.Blog, error) {
+	args := m.Called(ctx, now)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.Comment), args.Error(1)
+	return args.Get(0).(*domain.Blog), args.Error(1)
 }
 
-func (m *MockBlogRepository) DeleteComment(ctx context.Context, blogID string, commentID string) error {
-	args := m.Called(ctx, blogID, commentID)
-	return args.Error(0)
+func (m *MockBlogRepository) RelatedCandidates(ctx context.Context, blog *domain.Blog, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, blog, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) GetBlogsByIDs(ctx context.Context, ids []string) ([]*domain.Blog, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Blog), args.Error(1)
 }
 
 // MockCacheService is a mock implementation of the CacheService interface.
@@ -125,4 +150,110 @@ func TestCachedBlogRepository_GetBlogByID(t *testing.T) {
 	assert.Equal(t, blog, result)
 	mockRepo.AssertExpectations(t)
 	mockCache.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestCachedBlogRepository_GetBlogBySlug(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+	blog := &domain.Blog{ID: blogID, Slug: "test-blog", Title: "Test Blog"}
+
+	t.Run("cache miss stores the slug and the blog", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockCache := new(MockCacheService)
+		cachedRepo := &cachedBlogRepository{repo: mockRepo, cache: mockCache, defaultTTL: 10 * time.Minute}
+
+		mockCache.On("Get", blogSlugCacheKey("test-blog")).Return(nil, false).Once()
+		mockRepo.On("GetBlogBySlug", ctx, "test-blog").Return(blog, nil).Once()
+		mockCache.On("Set", blogSlugCacheKey("test-blog"), blogID, 10*time.Minute).Return().Once()
+		mockCache.On("Set", blogCacheKey(blogID), blog, 10*time.Minute).Return().Once()
+
+		result, err := cachedRepo.GetBlogBySlug(ctx, "test-blog")
+		assert.NoError(t, err)
+		assert.Equal(t, blog, result)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("cache hit resolves through the blog id", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockCache := new(MockCacheService)
+		cachedRepo := &cachedBlogRepository{repo: mockRepo, cache: mockCache, defaultTTL: 10 * time.Minute}
+
+		mockCache.On("Get", blogSlugCacheKey("test-blog")).Return(blogID, true).Once()
+		mockCache.On("Get", blogCacheKey(blogID)).Return(blog, true).Once()
+
+		result, err := cachedRepo.GetBlogBySlug(ctx, "test-blog")
+		assert.NoError(t, err)
+		assert.Equal(t, blog, result)
+		mockRepo.AssertNotCalled(t, "GetBlogBySlug", mock.Anything, mock.Anything)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("stale slug of a deleted blog", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockCache := new(MockCacheService)
+		cachedRepo := &cachedBlogRepository{repo: mockRepo, cache: mockCache, defaultTTL: 10 * time.Minute}
+
+		mockCache.On("Get", blogSlugCacheKey("test-blog")).Return("deleted", true).Once()
+		mockCache.On("Get", blogCacheKey("deleted")).Return(nil, false).Once()
+		mockRepo.On("GetBlogByID", ctx, "deleted").Return(nil, ErrBlogNotFound).Once()
+		mockCache.On("Delete", blogSlugCacheKey("test-blog")).Return().Once()
+		mockRepo.On("GetBlogBySlug", ctx, "test-blog").Return(blog, nil).Once()
+		mockCache.On("Set", blogSlugCacheKey("test-blog"), blogID, 10*time.Minute).Return().Once()
+		mockCache.On("Set", blogCacheKey(blogID), blog, 10*time.Minute).Return().Once()
+
+		result, err := cachedRepo.GetBlogBySlug(ctx, "test-blog")
+		assert.NoError(t, err)
+		assert.Equal(t, blog, result)
+		mockRepo.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+}
+
+func TestCachedBlogRepository_PublishDueBlog(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	mockCache := new(MockCacheService)
+	cachedRepo := &cachedBlogRepository{
+		repo:       mockRepo,
+		cache:      mockCache,
+		defaultTTL: 10 * time.Minute,
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	blog := &domain.Blog{ID: "blog123", Status: domain.BlogStatusPublished}
+
+	mockRepo.On("PublishDueBlog", ctx, now).Return(blog, nil).Once()
+	mockCache.On("Delete", blogCacheKey("blog123")).Return().Once()
+
+	result, err := cachedRepo.PublishDueBlog(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, blog, result)
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
+
+func TestCachedBlogRepository_IncrementViewCounts(t *testing.T) {
+	mockRepo := new(MockBlogRepository)
+	mockCache := new(MockCacheService)
+	cachedRepo := &cachedBlogRepository{
+		repo:       mockRepo,
+		cache:      mockCache,
+		defaultTTL: 10 * time.Minute,
+	}
+
+	ctx := context.Background()
+	views := map[string]int{"blog1": 2, "blog2": 1}
+
+	mockRepo.On("IncrementViewCounts", ctx, views).Return(nil).Once()
+	mockCache.On("Delete", blogCacheKey("blog1")).Return().Once()
+	mockCache.On("Delete", blogCacheKey("blog2")).Return().Once()
+	assert.NoError(t, cachedRepo.IncrementViewCounts(ctx, views))
+
+	// a failed write leaves the cache alone
+	mockRepo.On("IncrementViewCounts", ctx, views).Return(errors.New("db down")).Once()
+	assert.Error(t, cachedRepo.IncrementViewCounts(ctx, views))
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -25,10 +27,7 @@ func TestMongoBlogRepository_CreateBlog(t *testing.T) {
 			Tags:           []string{"go", "test"},
 			Metrics: &domain.Metrics{
 				ViewCount: 0,
-				Likes:     &domain.Likes{Count: 0, Users: []string{}},
-				Dislikes:  &domain.Likes{Count: 0, Users: []string{}},
 			},
-			Comments: []domain.Comment{},
 		}
 
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
@@ -36,6 +35,59 @@ func TestMongoBlogRepository_CreateBlog(t *testing.T) {
 		id, err := repo.CreateBlog(context.Background(), blog)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, id)
+
+		// new blogs start with a trending score so that paging by it reaches them
+		inserted := mt.GetStartedEvent().Command.Lookup("documents", "0").Document()
+		assert.Equal(t, 0.0, inserted.Lookup("trending_score").Double())
+	})
+
+	mt.Run("duplicate slug", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		blog := &domain.Blog{
+			Title:   "Test Title",
+			Slug:    "test-title",
+			Metrics: &domain.Metrics{},
+		}
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+
+		_, err := repo.CreateBlog(context.Background(), blog)
+		assert.ErrorIs(t, err, domain.ErrSlugTaken)
+	})
+}
+
+func TestMongoBlogRepository_GetBlogBySlug(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("found by previous slug", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		model := &BlogModel{
+			ID:            primitive.NewObjectID(),
+			Title:         "New Title",
+			Slug:          "new-title",
+			PreviousSlugs: []string{"old-title"},
+			Metrics:       &Metrics{},
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(model)))
+
+		blog, err := repo.GetBlogBySlug(context.Background(), "old-title")
+		assert.NoError(t, err)
+		assert.Equal(t, "new-title", blog.Slug)
+		assert.Equal(t, []string{"old-title"}, blog.PreviousSlugs)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := repo.GetBlogBySlug(context.Background(), "missing")
+		assert.Equal(t, ErrBlogNotFound, err)
 	})
 }
 
@@ -53,10 +105,7 @@ func TestMongoBlogRepository_GetBlogByID(t *testing.T) {
 			Content:        "Test Content",
 			Metrics: &Metrics{
 				ViewCount: 0,
-				Likes:     &Likes{Count: 0, Users: []string{}},
-				Dislikes:  &Likes{Count: 0, Users: []string{}},
 			},
-			Comments: []Comment{},
 		}
 
 		mt.AddMockResponses(mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, toBSOND(expectedBlog)))
@@ -66,6 +115,22 @@ func TestMongoBlogRepository_GetBlogByID(t *testing.T) {
 		assert.Equal(t, expectedBlog.Title, blog.Title)
 	})
 
+	mt.Run("legacy blog without status is published", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		id := primitive.NewObjectID()
+		legacyBlog := &BlogModel{
+			ID:      id,
+			Title:   "Old Title",
+			Metrics: &Metrics{},
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, toBSOND(legacyBlog)))
+
+		blog, err := repo.GetBlogByID(context.Background(), id.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BlogStatusPublished, blog.Status)
+	})
+
 	mt.Run("not found", func(mt *mtest.T) {
 		repo := &mongoBlogRepository{collection: mt.Coll}
 		id := primitive.NewObjectID()
@@ -103,83 +168,239 @@ func TestMongoBlogRepository_DeleteBlog(t *testing.T) {
 	})
 }
 
-func TestMongoBlogRepository_AddComment(t *testing.T) {
+func TestMongoBlogRepository_IncrementCommentCount(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	mt.Run("success", func(mt *mtest.T) {
 		repo := &mongoBlogRepository{collection: mt.Coll}
 		blogID := primitive.NewObjectID()
-		comment := &domain.Comment{
-			AuthorID: primitive.NewObjectID().Hex(),
-			Content:  "New Comment",
-		}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.D{{Key: "n", Value: 1}, {Key: "nModified", Value: 1}}...))
-
-		err := repo.AddComment(context.Background(), blogID.Hex(), comment)
+		err := repo.IncrementCommentCount(context.Background(), blogID.Hex(), -1)
 		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, int64(-1), update.Lookup("u", "$inc", "comment_count").AsInt64())
+	})
+
+	mt.Run("blog not found", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.IncrementCommentCount(context.Background(), primitive.NewObjectID().Hex(), 1)
+		assert.ErrorIs(t, err, ErrBlogNotFound)
 	})
 }
 
-func TestMongoBlogRepository_GetCommentByID(t *testing.T) {
+func TestMongoBlogRepository_IncrementReactionCount(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	mt.Run("success", func(mt *mtest.T) {
 		repo := &mongoBlogRepository{collection: mt.Coll}
-		blogID := primitive.NewObjectID()
-		commentID := primitive.NewObjectID()
-		expectedComment := &Comment{
-			ID:       commentID,
-			AuthorID: primitive.NewObjectID(),
-			Content:  "Test Comment",
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.IncrementReactionCount(context.Background(), primitive.NewObjectID().Hex(), "🎉", 1)
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, int64(1), update.Lookup("u", "$inc", "reaction_counts.🎉").AsInt64())
+	})
+
+	mt.Run("blog not found", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.IncrementReactionCount(context.Background(), primitive.NewObjectID().Hex(), "🎉", -1)
+		assert.ErrorIs(t, err, ErrBlogNotFound)
+	})
+}
+
+func TestMongoBlogRepository_IncrementViewCounts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("one bulk write for the batch", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+
+		err := repo.IncrementViewCounts(context.Background(), map[string]int{first.Hex(): 3, second.Hex(): 1, "not-an-id": 4})
+		assert.NoError(t, err)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 1) {
+			updates, _ := events[0].Command.Lookup("updates").Array().Values()
+			incs := map[primitive.ObjectID]int64{}
+			for _, update := range updates {
+				doc := update.Document()
+				incs[doc.Lookup("q", "_id").ObjectID()] = doc.Lookup("u", "$inc", "metrics.view_count").AsInt64()
+			}
+			assert.Equal(t, map[primitive.ObjectID]int64{first: 3, second: 1}, incs)
 		}
-		expectedBlog := &BlogModel{
-			ID:       blogID,
-			Comments: []Comment{*expectedComment},
+	})
+
+	mt.Run("partly failed batch names the blogs not written", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "write failed"}))
+
+		err := repo.IncrementViewCounts(context.Background(), map[string]int{first.Hex(): 3, second.Hex(): 1})
+		var partial *domain.PartialWriteError[string]
+		if assert.ErrorAs(t, err, &partial) {
+			failed := mt.GetStartedEvent().Command.Lookup("updates", "1", "q", "_id").ObjectID()
+			assert.Equal(t, []string{failed.Hex()}, partial.Failed)
 		}
+	})
 
-		mt.AddMockResponses(mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, toBSOND(expectedBlog)))
+	mt.Run("failed batch", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "db down"}))
 
-		comment, err := repo.GetCommentByID(context.Background(), blogID.Hex(), commentID.Hex())
-		assert.NoError(t, err)
-		assert.Equal(t, expectedComment.Content, comment.Content)
+		err := repo.IncrementViewCounts(context.Background(), map[string]int{primitive.NewObjectID().Hex(): 1})
+		var partial *domain.PartialWriteError[string]
+		assert.Error(t, err)
+		assert.False(t, errors.As(err, &partial))
+	})
+
+	mt.Run("nothing to write", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+
+		assert.NoError(t, repo.IncrementViewCounts(context.Background(), map[string]int{}))
+		assert.Empty(t, mt.GetAllStartedEvents())
 	})
 }
 
-func TestMongoBlogRepository_UpdateComment(t *testing.T) {
+func TestMongoBlogRepository_PublishDueBlog(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
-	mt.Run("success", func(mt *mtest.T) {
+	mt.Run("publishes a due draft", func(mt *mtest.T) {
 		repo := &mongoBlogRepository{collection: mt.Coll}
-		blogID := primitive.NewObjectID()
-		comment := &domain.Comment{
-			ID:      primitive.NewObjectID().Hex(),
-			Content: "Updated Comment",
+		id := primitive.NewObjectID()
+		published := &BlogModel{
+			ID:      id,
+			Title:   "Scheduled",
+			Status:  string(domain.BlogStatusPublished),
+			Metrics: &Metrics{},
 		}
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.D{{Key: "n", Value: 1}, {Key: "nModified", Value: 1}}...))
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: toBSOND(published)}))
 
-		err := repo.UpdateComment(context.Background(), blogID.Hex(), comment)
+		blog, err := repo.PublishDueBlog(context.Background(), time.Now())
 		assert.NoError(t, err)
+		assert.Equal(t, id.Hex(), blog.ID)
+		assert.Equal(t, domain.BlogStatusPublished, blog.Status)
+	})
+
+	mt.Run("nothing due", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+
+		_, err := repo.PublishDueBlog(context.Background(), time.Now())
+		assert.Equal(t, ErrBlogNotFound, err)
 	})
 }
 
-func TestMongoBlogRepository_DeleteComment(t *testing.T) {
+// toBSOND is a helper function to convert a struct to a BSON document for mock responses.
+func TestMongoBlogRepository_ListBlogs_Search(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
-	mt.Run("success", func(mt *mtest.T) {
+	mt.Run("text search ranked by relevance", func(mt *mtest.T) {
 		repo := &mongoBlogRepository{collection: mt.Coll}
-		blogID := primitive.NewObjectID()
-		commentID := primitive.NewObjectID()
+		model := toBSOND(&BlogModel{
+			ID:      primitive.NewObjectID(),
+			Title:   "Go generics",
+			Metrics: &Metrics{},
+		})
+		model = append(model, bson.E{Key: "score", Value: 2.5})
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "n", Value: 1}}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, model),
+		)
+
+		filter := map[string]any{"search": "generics.*(", "sortBy": "relevance", "include_total": true}
+		blogs, pagination, err := repo.ListBlogs(context.Background(), filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, *pagination.Total)
+		assert.Len(t, blogs, 1)
+		assert.Equal(t, 2.5, blogs[0].Score)
+
+		find := mt.GetStartedEvent()
+		for find != nil && find.CommandName != "find" {
+			find = mt.GetStartedEvent()
+		}
+		if assert.NotNil(t, find) {
+			// the query reaches the text index verbatim instead of being compiled as a regex
+			text := find.Command.Lookup("filter", "$and", "0", "$text", "$search")
+			assert.Equal(t, "generics.*(", text.StringValue())
+			assert.Equal(t, "textScore", find.Command.Lookup("sort", "score", "$meta").StringValue())
+			assert.Equal(t, "textScore", find.Command.Lookup("projection", "score", "$meta").StringValue())
+		}
+	})
+}
+
+func TestMongoBlogRepository_ListBlogs_Cursor(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	blogDoc := func(id primitive.ObjectID, views int) bson.D {
+		return toBSOND(&BlogModel{
+			ID: id,
+			Metrics: &Metrics{
+				ViewCount: views,
+			},
+		})
+	}
+
+	mt.Run("prev cursor walks backwards without counting", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		first, second, third := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+		cursor := &domain.BlogCursor{SortBy: "metrics.view_count", Order: "desc", Value: 5, ID: primitive.NewObjectID().Hex(), Backward: true}
+
+		// walking backwards, the nearest blogs come first and one extra shows there is more
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			blogDoc(third, 6), blogDoc(second, 7), blogDoc(first, 8)))
+
+		filter := map[string]any{"sortBy": "metrics.view_count", "order": "desc", "cursor": cursor}
+		blogs, pagination, err := repo.ListBlogs(context.Background(), filter, 1, 2)
+		assert.NoError(t, err)
+		assert.Nil(t, pagination.Total)
+		assert.True(t, pagination.HasPrev)
+		assert.True(t, pagination.HasNext)
+		if assert.Len(t, blogs, 2) {
+			assert.Equal(t, second.Hex(), blogs[0].ID)
+			assert.Equal(t, third.Hex(), blogs[1].ID)
+		}
 
-		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.D{{Key: "n", Value: 1}, {Key: "nModified", Value: 1}}...))
+		find := mt.GetStartedEvent()
+		assert.Equal(t, "find", find.CommandName)
+		sort := find.Command.Lookup("sort").Document()
+		assert.Equal(t, int32(1), sort.Lookup("metrics.view_count").Int32())
+		assert.Equal(t, int32(1), sort.Lookup("_id").Int32())
+		assert.Equal(t, int64(3), find.Command.Lookup("limit").Int64())
+		keyset := find.Command.Lookup("filter", "$and", "1", "$or", "0", "metrics.view_count", "$gt")
+		assert.Equal(t, int32(5), keyset.Int32())
+	})
+
+	mt.Run("last page in page mode", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, blogDoc(primitive.NewObjectID(), 1)))
 
-		err := repo.DeleteComment(context.Background(), blogID.Hex(), commentID.Hex())
+		blogs, pagination, err := repo.ListBlogs(context.Background(), map[string]any{"sortBy": "created_at", "order": "desc"}, 3, 2)
 		assert.NoError(t, err)
+		assert.Len(t, blogs, 1)
+		assert.False(t, pagination.HasNext)
+		assert.True(t, pagination.HasPrev)
+	})
+
+	mt.Run("malformed author id matches no author", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, _, err := repo.ListBlogs(context.Background(), map[string]any{"author_id": "not-an-id"}, 1, 10)
+		assert.NoError(t, err)
+		author := mt.GetStartedEvent().Command.Lookup("filter", "$and", "1", "author_id")
+		assert.Equal(t, "not-an-id", author.StringValue())
 	})
 }
 
-// toBSOND is a helper function to convert a struct to a BSON document for mock responses.
 func toBSOND(v any) primitive.D {
 	data, err := bson.Marshal(v)
 	if err != nil {
@@ -191,4 +412,97 @@ func toBSOND(v any) primitive.D {
 		panic(err)
 	}
 	return doc
-}
\ No newline at end of file
+}
+
+func TestMongoBlogRepository_ListBlogs_Followed(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("followed authors or tags", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		author := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		filter := map[string]any{
+			"followed_authors": []string{author.Hex(), "not-an-id"},
+			"followed_tags":    []string{"go"},
+			"sortBy":           "created_at",
+			"order":            "desc",
+		}
+		_, _, err := repo.ListBlogs(context.Background(), filter, 1, 10)
+		assert.NoError(t, err)
+
+		find := mt.GetStartedEvent().Command
+		followed := find.Lookup("filter", "$and", "1", "$or").Array()
+		authors := followed.Index(0).Value().Document().Lookup("author_id", "$in").Array()
+		if values, _ := authors.Values(); assert.Len(t, values, 1) {
+			assert.Equal(t, author, values[0].ObjectID())
+		}
+		assert.Equal(t, "go", followed.Index(1).Value().Document().Lookup("tags", "$in", "0").StringValue())
+	})
+}
+
+func TestMongoBlogRepository_RelatedCandidates(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("text matches then shared tags or author, without duplicates", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		source := &domain.Blog{ID: primitive.NewObjectID().Hex(), AuthorID: primitive.NewObjectID().Hex(), Title: "Go generics", Tags: []string{"go"}}
+		both := toBSOND(&BlogModel{ID: primitive.NewObjectID(), Title: "Generics in practice", Metrics: &Metrics{}})
+		shared := toBSOND(&BlogModel{ID: primitive.NewObjectID(), Title: "Go modules", Metrics: &Metrics{}})
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, both),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, shared, both),
+		)
+
+		blogs, err := repo.RelatedCandidates(context.Background(), source, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, blogs, 2) {
+			assert.Equal(t, "Generics in practice", blogs[0].Title)
+			assert.Equal(t, "Go modules", blogs[1].Title)
+		}
+
+		events := mt.GetAllStartedEvents()
+		var finds []bson.Raw
+		for _, event := range events {
+			if event.CommandName == "find" {
+				finds = append(finds, event.Command)
+			}
+		}
+		if assert.Len(t, finds, 2) {
+			sourceID, _ := primitive.ObjectIDFromHex(source.ID)
+			assert.Equal(t, sourceID, finds[0].Lookup("filter", "$and", "0", "_id", "$ne").ObjectID())
+			assert.Equal(t, "Go generics go", finds[0].Lookup("filter", "$and", "2", "$text", "$search").StringValue())
+			assert.Equal(t, "go", finds[1].Lookup("filter", "$and", "2", "$or", "0", "tags", "$in", "0").StringValue())
+			assert.Equal(t, source.AuthorID, finds[1].Lookup("filter", "$and", "2", "$or", "1", "author_id").ObjectID().Hex())
+		}
+	})
+}
+
+func TestMongoBlogRepository_GetBlogsByIDs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("looks up the well-formed ids", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&BlogModel{ID: id, Title: "Hello", Metrics: &Metrics{}})))
+
+		blogs, err := repo.GetBlogsByIDs(context.Background(), []string{id.Hex(), "not-an-id"})
+		assert.NoError(t, err)
+		if assert.Len(t, blogs, 1) {
+			assert.Equal(t, id.Hex(), blogs[0].ID)
+		}
+		ids, _ := mt.GetStartedEvent().Command.Lookup("filter", "_id", "$in").Array().Values()
+		if assert.Len(t, ids, 1) {
+			assert.Equal(t, id, ids[0].ObjectID())
+		}
+	})
+
+	mt.Run("no valid ids skips the query", func(mt *mtest.T) {
+		repo := &mongoBlogRepository{collection: mt.Coll}
+		blogs, err := repo.GetBlogsByIDs(context.Background(), []string{"nope"})
+		assert.NoError(t, err)
+		assert.Empty(t, blogs)
+		assert.Nil(t, mt.GetStartedEvent())
+	})
+}
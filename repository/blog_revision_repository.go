@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxRevisionInsertAttempts bounds the retries when two writers race for the same revision number
+const maxRevisionInsertAttempts = 5
+
+// BlogRevisionModel is the MongoDB representation of a blog revision
+type BlogRevisionModel struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	BlogID        primitive.ObjectID `bson:"blog_id"`
+	Number        int                `bson:"number"`
+	Title         string             `bson:"title"`
+	Content       string             `bson:"content"`
+	ContentFormat string             `bson:"content_format,omitempty"`
+	Tags          []string           `bson:"tags"`
+	EditorID      primitive.ObjectID `bson:"editor_id"`
+	RestoredFrom  int                `bson:"restored_from,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
+
+func (m *BlogRevisionModel) ToDomain() *domain.BlogRevision {
+	return &domain.BlogRevision{
+		ID:            m.ID.Hex(),
+		BlogID:        m.BlogID.Hex(),
+		Number:        m.Number,
+		Title:         m.Title,
+		Content:       m.Content,
+		ContentFormat: domain.ContentFormat(m.ContentFormat),
+		Tags:          m.Tags,
+		EditorID:      m.EditorID.Hex(),
+		RestoredFrom:  m.RestoredFrom,
+		CreatedAt:     m.CreatedAt,
+	}
+}
+
+func (m *BlogRevisionModel) FromDomain(revision *domain.BlogRevision) {
+	var err error
+	m.ID, err = primitive.ObjectIDFromHex(revision.ID)
+	if err != nil {
+		m.ID = primitive.NilObjectID
+	}
+	m.BlogID, err = primitive.ObjectIDFromHex(revision.BlogID)
+	if err != nil {
+		m.BlogID = primitive.NilObjectID
+	}
+	m.EditorID, err = primitive.ObjectIDFromHex(revision.EditorID)
+	if err != nil {
+		m.EditorID = primitive.NilObjectID
+	}
+	m.Number = revision.Number
+	m.Title = revision.Title
+	m.Content = revision.Content
+	m.ContentFormat = string(revision.ContentFormat)
+	m.Tags = revision.Tags
+	m.RestoredFrom = revision.RestoredFrom
+	m.CreatedAt = revision.CreatedAt
+}
+
+type BlogRevisionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBlogRevisionRepository returns a MongoDB implementation of BlogRevisionRepository
+func NewBlogRevisionRepository(db *mongo.Database) domain.BlogRevisionRepository {
+	coll := db.Collection("blog_revisions")
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "number", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	if _, err := coll.Indexes().CreateOne(context.Background(), index); err != nil {
+		infrastructure.Log.Fatalf("Failed to create index: %v", err)
+	}
+
+	return &BlogRevisionRepository{
+		collection: coll,
+	}
+}
+
+// CreateRevision stores the revision under the next free number for its blog.
+// The unique (blog_id, number) index turns a concurrent writer's number into a duplicate key, which is retried.
+func (r *BlogRevisionRepository) CreateRevision(ctx context.Context, revision *domain.BlogRevision) (*domain.BlogRevision, error) {
+	var model BlogRevisionModel
+	model.FromDomain(revision)
+	if model.BlogID.IsZero() {
+		return nil, ErrBlogNotFound
+	}
+
+	for attempt := 0; attempt < maxRevisionInsertAttempts; attempt++ {
+		latest, err := r.latestNumber(ctx, model.BlogID)
+		if err != nil {
+			return nil, err
+		}
+		model.ID = primitive.NewObjectID()
+		model.Number = latest + 1
+
+		_, err = r.collection.InsertOne(ctx, model)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return model.ToDomain(), nil
+	}
+	return nil, errors.New("failed to allocate revision number")
+}
+
+func (r *BlogRevisionRepository) latestNumber(ctx context.Context, blogID primitive.ObjectID) (int, error) {
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "number", Value: -1}}).
+		SetProjection(bson.M{"number": 1})
+
+	var latest BlogRevisionModel
+	err := r.collection.FindOne(ctx, bson.M{"blog_id": blogID}, opts).Decode(&latest)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return latest.Number, nil
+}
+
+// ListRevisions returns every revision of a blog, newest first
+func (r *BlogRevisionRepository) ListRevisions(ctx context.Context, blogID string) ([]*domain.BlogRevision, error) {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "number", Value: -1}})
+	cur, err := r.collection.Find(ctx, bson.M{"blog_id": oid}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	revisions := []*domain.BlogRevision{}
+	for cur.Next(ctx) {
+		var model BlogRevisionModel
+		if err := cur.Decode(&model); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, model.ToDomain())
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *BlogRevisionRepository) GetRevision(ctx context.Context, blogID string, number int) (*domain.BlogRevision, error) {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	var model BlogRevisionModel
+	err = r.collection.FindOne(ctx, bson.M{"blog_id": oid, "number": number}).Decode(&model)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+// DeleteRevisions removes the history of a deleted blog
+func (r *BlogRevisionRepository) DeleteRevisions(ctx context.Context, blogID string) error {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"blog_id": oid})
+	return err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestBlogRevisionRepository_CreateRevision(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("first revision", func(mt *mtest.T) {
+		repo := &BlogRevisionRepository{collection: mt.Coll}
+		revision := &domain.BlogRevision{
+			BlogID:    primitive.NewObjectID().Hex(),
+			Title:     "Title",
+			Content:   "Content",
+			EditorID:  primitive.NewObjectID().Hex(),
+			CreatedAt: time.Now(),
+		}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(),
+		)
+
+		created, err := repo.CreateRevision(context.Background(), revision)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, created.Number)
+		assert.NotEmpty(t, created.ID)
+	})
+
+	mt.Run("retries after losing a race for the number", func(mt *mtest.T) {
+		repo := &BlogRevisionRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		revision := &domain.BlogRevision{BlogID: blogID.Hex(), Title: "Title"}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "number", Value: 2}}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "number", Value: 3}}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		created, err := repo.CreateRevision(context.Background(), revision)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, created.Number)
+	})
+}
+
+func TestBlogRevisionRepository_GetRevision(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &BlogRevisionRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		expected := &BlogRevisionModel{ID: primitive.NewObjectID(), BlogID: blogID, Number: 2, Title: "Title"}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, toBSOND(expected)))
+
+		revision, err := repo.GetRevision(context.Background(), blogID.Hex(), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, revision.Number)
+		assert.Equal(t, "Title", revision.Title)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := &BlogRevisionRepository{collection: mt.Coll}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := repo.GetRevision(context.Background(), primitive.NewObjectID().Hex(), 5)
+		assert.Equal(t, domain.ErrRevisionNotFound, err)
+	})
+}
+
+func TestBlogRevisionRepository_ListRevisions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &BlogRevisionRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		rev2 := &BlogRevisionModel{ID: primitive.NewObjectID(), BlogID: blogID, Number: 2}
+		rev1 := &BlogRevisionModel{ID: primitive.NewObjectID(), BlogID: blogID, Number: 1}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, toBSOND(rev2), toBSOND(rev1)),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch),
+		)
+
+		revisions, err := repo.ListRevisions(context.Background(), blogID.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, 2, revisions[0].Number)
+	})
+}
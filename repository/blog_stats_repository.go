@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const millisecondsPerDay = 24 * 60 * 60 * 1000
+
+// blogStatsModel is a document produced by the stats pipelines; _id is the bucket start or the blog ID
+type blogStatsModel struct {
+	ID            bson.RawValue `bson:"_id"`
+	Title         string        `bson:"title,omitempty"`
+	Views         int           `bson:"views"`
+	UniqueViewers int           `bson:"unique_viewers"`
+	Reactions     int           `bson:"reactions"`
+	Comments      int           `bson:"comments"`
+}
+
+func (m *blogStatsModel) stats() domain.BlogStats {
+	return domain.BlogStats{
+		Views:         m.Views,
+		UniqueViewers: m.UniqueViewers,
+		Reactions:     m.Reactions,
+		Comments:      m.Comments,
+	}
+}
+
+type BlogStatsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBlogStatsRepository keeps one document per blog per UTC day in the "blog_stats_daily" collection
+func NewBlogStatsRepository(db *mongo.Database) domain.BlogStatsRepository {
+	coll := db.Collection("blog_stats_daily")
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "blog_id", Value: 1}, {Key: "day", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// an author's dashboard reads every post's days in the period
+		{Keys: bson.D{{Key: "author_id", Value: 1}, {Key: "day", Value: 1}}},
+	}
+
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create blog stats indexes: %v", err)
+	}
+
+	return &BlogStatsRepository{collection: coll}
+}
+
+// AddDailyStats adds each entry's counts to its blog's day, creating the day on first use
+func (r *BlogStatsRepository) AddDailyStats(ctx context.Context, stats []*domain.DailyBlogStats) error {
+	models := make([]mongo.WriteModel, 0, len(stats))
+	indexes := make([]int, 0, len(stats))
+	for i, entry := range stats {
+		oid, err := primitive.ObjectIDFromHex(entry.BlogID)
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, i)
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"blog_id": oid, "day": domain.StatsDay(entry.Day)}).
+			SetUpdate(bson.M{
+				"$inc": bson.M{
+					"views":          entry.Views,
+					"unique_viewers": entry.UniqueViewers,
+					"reactions":      entry.Reactions,
+					"comments":       entry.Comments,
+				},
+				"$setOnInsert": bson.M{"author_id": entry.AuthorID},
+			}).
+			SetUpsert(true))
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return partialWriteError(err, indexes)
+}
+
+// BlogStats sums a blog's days from from to to, both inclusive, into day or week buckets. Only buckets
+// with recorded activity are returned, oldest first.
+func (r *BlogStatsRepository) BlogStats(ctx context.Context, blogID string, from, to time.Time, granularity domain.StatsGranularity) ([]*domain.StatsBucket, error) {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	var bucket any = "$day"
+	if granularity == domain.StatsGranularityWeek {
+		// back to Monday: $isoDayOfWeek is 1 for Monday through 7 for Sunday
+		bucket = bson.M{"$subtract": bson.A{
+			"$day",
+			bson.M{"$multiply": bson.A{bson.M{"$subtract": bson.A{bson.M{"$isoDayOfWeek": "$day"}, 1}}, millisecondsPerDay}},
+		}}
+	}
+	pipeline := []bson.M{
+		{"$match": bson.M{"blog_id": oid, "day": dayRange(from, to)}},
+		statsGroup(bucket),
+		{"$sort": bson.M{"_id": 1}},
+	}
+	models, err := r.aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	buckets := make([]*domain.StatsBucket, len(models))
+	for i := range models {
+		buckets[i] = &domain.StatsBucket{Start: models[i].ID.Time().UTC(), BlogStats: models[i].stats()}
+	}
+	return buckets, nil
+}
+
+// AuthorStats sums each of the author's blogs over the days from from to to, both inclusive, busiest
+// first. Blogs that have since been deleted are left out.
+func (r *BlogStatsRepository) AuthorStats(ctx context.Context, authorID string, from, to time.Time) ([]*domain.PostStats, error) {
+	pipeline := []bson.M{
+		{"$match": bson.M{"author_id": authorID, "day": dayRange(from, to)}},
+		statsGroup("$blog_id"),
+		{"$lookup": bson.M{"from": "blogs", "localField": "_id", "foreignField": "_id", "as": "blog"}},
+		{"$match": bson.M{"blog": bson.M{"$ne": bson.A{}}}},
+		{"$set": bson.M{"title": bson.M{"$arrayElemAt": bson.A{"$blog.title", 0}}}},
+		{"$project": bson.M{"blog": 0}},
+		{"$sort": bson.D{{Key: "views", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	models, err := r.aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]*domain.PostStats, len(models))
+	for i := range models {
+		posts[i] = &domain.PostStats{BlogID: models[i].ID.ObjectID().Hex(), Title: models[i].Title, BlogStats: models[i].stats()}
+	}
+	return posts, nil
+}
+
+// DeleteBlogStats removes every day recorded for a blog
+func (r *BlogStatsRepository) DeleteBlogStats(ctx context.Context, blogID string) error {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"blog_id": oid})
+	return err
+}
+
+func (r *BlogStatsRepository) aggregate(ctx context.Context, pipeline []bson.M) ([]blogStatsModel, error) {
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var models []blogStatsModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+func dayRange(from, to time.Time) bson.M {
+	return bson.M{"$gte": domain.StatsDay(from), "$lte": domain.StatsDay(to)}
+}
+
+func statsGroup(id any) bson.M {
+	return bson.M{"$group": bson.M{
+		"_id":            id,
+		"views":          bson.M{"$sum": "$views"},
+		"unique_viewers": bson.M{"$sum": "$unique_viewers"},
+		"reactions":      bson.M{"$sum": "$reactions"},
+		"comments":       bson.M{"$sum": "$comments"},
+	}}
+}
+
+// UpdateTrendingScores scores every blog with activity in the last TrendingHalfLives half-lives and
+// writes the scores onto the blogs, then zeroes the blogs that had no recent activity. A day's activity
+// is taken to have happened at midday. Replicas may run it side by side: a run only zeroes blogs scored
+// before its own now, and whatever overlapping runs leave behind is set right by the next one.
+// The scores are written past any cache, so callers drop cached lists themselves.
+func (r *BlogStatsRepository) UpdateTrendingScores(ctx context.Context, now time.Time, halfLife time.Duration) error {
+	now = now.UTC()
+	since := domain.StatsDay(now.Add(-domain.TrendingHalfLives * halfLife))
+	midday := bson.M{"$add": bson.A{"$day", millisecondsPerDay / 2}}
+	age := bson.M{"$max": bson.A{0, bson.M{"$subtract": bson.A{now, midday}}}}
+	decay := bson.M{"$pow": bson.A{0.5, bson.M{"$divide": bson.A{age, halfLife.Milliseconds()}}}}
+	activity := bson.M{"$add": bson.A{
+		bson.M{"$multiply": bson.A{"$views", domain.TrendingViewWeight}},
+		bson.M{"$multiply": bson.A{"$reactions", domain.TrendingReactionWeight}},
+		bson.M{"$multiply": bson.A{"$comments", domain.TrendingCommentWeight}},
+	}}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"day": bson.M{"$gte": since}}},
+		{"$group": bson.M{"_id": "$blog_id", "trending_score": bson.M{"$sum": bson.M{"$multiply": bson.A{activity, decay}}}}},
+		// taken-back reactions can outweigh the rest
+		{"$set": bson.M{"trending_score": bson.M{"$max": bson.A{0, "$trending_score"}}, "trending_at": now}},
+		{"$merge": bson.M{"into": "blogs", "on": "_id", "whenMatched": "merge", "whenNotMatched": "discard"}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	cursor.Close(ctx)
+
+	// blogs this run did not score have gone quiet, or were never scored at all
+	blogs := r.collection.Database().Collection("blogs")
+	stale := bson.M{
+		"$or":            bson.A{bson.M{"trending_at": bson.M{"$lt": now}}, bson.M{"trending_at": bson.M{"$exists": false}}},
+		"trending_score": bson.M{"$ne": 0},
+	}
+	_, err = blogs.UpdateMany(ctx, stale, bson.M{"$set": bson.M{"trending_score": 0}})
+	return err
+}
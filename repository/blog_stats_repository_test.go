@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestBlogStatsRepository_AddDailyStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("upserts each day", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}})
+
+		err := repo.AddDailyStats(context.Background(), []*domain.DailyBlogStats{
+			{BlogID: blogID.Hex(), AuthorID: "author1", Day: time.Date(2025, 3, 2, 17, 45, 0, 0, time.UTC), BlogStats: domain.BlogStats{Views: 3, UniqueViewers: 2}},
+			{BlogID: "not-an-id", BlogStats: domain.BlogStats{Views: 1}},
+		})
+		assert.NoError(t, err)
+
+		updates, _ := mt.GetStartedEvent().Command.Lookup("updates").Array().Values()
+		if assert.Len(t, updates, 1) {
+			update := updates[0].Document()
+			assert.True(t, update.Lookup("upsert").Boolean())
+			assert.Equal(t, blogID, update.Lookup("q", "blog_id").ObjectID())
+			assert.Equal(t, time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), update.Lookup("q", "day").Time().UTC())
+			assert.EqualValues(t, 3, update.Lookup("u", "$inc", "views").AsInt64())
+			assert.EqualValues(t, 2, update.Lookup("u", "$inc", "unique_viewers").AsInt64())
+			assert.Equal(t, "author1", update.Lookup("u", "$setOnInsert", "author_id").StringValue())
+		}
+	})
+
+	mt.Run("partly failed batch names the entries not written", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 11000, Message: "write failed"}))
+
+		err := repo.AddDailyStats(context.Background(), []*domain.DailyBlogStats{
+			{BlogID: primitive.NewObjectID().Hex(), BlogStats: domain.BlogStats{Views: 1}},
+			{BlogID: "not-an-id", BlogStats: domain.BlogStats{Views: 1}},
+			{BlogID: primitive.NewObjectID().Hex(), BlogStats: domain.BlogStats{Views: 1}},
+		})
+		var partial *domain.PartialWriteError[int]
+		if assert.ErrorAs(t, err, &partial) {
+			// the second write is for the third entry, as the malformed one is skipped
+			assert.Equal(t, []int{2}, partial.Failed)
+		}
+	})
+
+	mt.Run("nothing to write", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+
+		assert.NoError(t, repo.AddDailyStats(context.Background(), nil))
+		assert.Empty(t, mt.GetAllStartedEvents())
+	})
+}
+
+func TestBlogStatsRepository_BlogStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	mt.Run("daily buckets", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: from}, {Key: "views", Value: 4}, {Key: "unique_viewers", Value: 3}, {Key: "reactions", Value: 1}, {Key: "comments", Value: 2}}))
+
+		buckets, err := repo.BlogStats(context.Background(), blogID.Hex(), from, to, domain.StatsGranularityDay)
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.StatsBucket{
+			{Start: from, BlogStats: domain.BlogStats{Views: 4, UniqueViewers: 3, Reactions: 1, Comments: 2}},
+		}, buckets)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		if assert.Len(t, stages, 3) {
+			match := stages[0].Document().Lookup("$match").Document()
+			assert.Equal(t, blogID, match.Lookup("blog_id").ObjectID())
+			assert.Equal(t, to, match.Lookup("day", "$lte").Time().UTC())
+			assert.Equal(t, "$day", stages[1].Document().Lookup("$group", "_id").StringValue())
+		}
+	})
+
+	mt.Run("weekly buckets group by Monday", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := repo.BlogStats(context.Background(), primitive.NewObjectID().Hex(), from, to, domain.StatsGranularityWeek)
+		assert.NoError(t, err)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		_, err = stages[1].Document().Lookup("$group", "_id", "$subtract").Array().Values()
+		assert.NoError(t, err)
+	})
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+
+		_, err := repo.BlogStats(context.Background(), "not-an-id", from, to, domain.StatsGranularityDay)
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+	})
+}
+
+func TestBlogStatsRepository_AuthorStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("sums per post with titles", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: blogID}, {Key: "title", Value: "Hello"}, {Key: "views", Value: 9}, {Key: "unique_viewers", Value: 7}, {Key: "reactions", Value: 0}, {Key: "comments", Value: 1}}))
+
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		posts, err := repo.AuthorStats(context.Background(), "author1", from, from.AddDate(0, 0, 29))
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.PostStats{
+			{BlogID: blogID.Hex(), Title: "Hello", BlogStats: domain.BlogStats{Views: 9, UniqueViewers: 7, Comments: 1}},
+		}, posts)
+
+		stages, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		assert.Equal(t, "author1", stages[0].Document().Lookup("$match", "author_id").StringValue())
+		assert.Equal(t, "blogs", stages[2].Document().Lookup("$lookup", "from").StringValue())
+	})
+}
+
+func TestBlogStatsRepository_DeleteBlogStats(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("removes the blog's days", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 12}))
+
+		assert.NoError(t, repo.DeleteBlogStats(context.Background(), blogID.Hex()))
+		deletes, _ := mt.GetStartedEvent().Command.Lookup("deletes").Array().Values()
+		assert.Equal(t, blogID, deletes[0].Document().Lookup("q", "blog_id").ObjectID())
+	})
+}
+
+func TestBlogStatsRepository_UpdateTrendingScores(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("merges the scores and zeroes quiet blogs", func(mt *mtest.T) {
+		repo := &BlogStatsRepository{collection: mt.Coll}
+		now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}},
+		)
+
+		assert.NoError(t, repo.UpdateTrendingScores(context.Background(), now, 24*time.Hour))
+
+		events := mt.GetAllStartedEvents()
+		if !assert.Len(t, events, 2) {
+			return
+		}
+		stages, _ := events[0].Command.Lookup("pipeline").Array().Values()
+		if assert.Len(t, stages, 4) {
+			// seven half-lives of a day back, from the start of that day
+			since := stages[0].Document().Lookup("$match", "day", "$gte").Time().UTC()
+			assert.Equal(t, time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), since)
+			assert.Equal(t, "blogs", stages[3].Document().Lookup("$merge", "into").StringValue())
+			assert.Equal(t, "discard", stages[3].Document().Lookup("$merge", "whenNotMatched").StringValue())
+		}
+
+		assert.Equal(t, "update", events[1].CommandName)
+		update := events[1].Command.Lookup("updates", "0").Document()
+		stale, _ := update.Lookup("q", "$or").Array().Values()
+		assert.Equal(t, now, stale[0].Document().Lookup("trending_at", "$lt").Time().UTC())
+		assert.True(t, update.Lookup("multi").Boolean())
+	})
+}
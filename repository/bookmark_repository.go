@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BookmarkModel is the MongoDB representation of a bookmark
+type BookmarkModel struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	UserID      string             `bson:"user_id"`
+	BlogID      string             `bson:"blog_id"`
+	Title       string             `bson:"title"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	Unavailable bool               `bson:"unavailable,omitempty"`
+}
+
+func (m *BookmarkModel) ToDomain() *domain.Bookmark {
+	return &domain.Bookmark{
+		ID:          m.ID.Hex(),
+		UserID:      m.UserID,
+		BlogID:      m.BlogID,
+		Title:       m.Title,
+		CreatedAt:   m.CreatedAt,
+		Unavailable: m.Unavailable,
+	}
+}
+
+// ReadingListItemModel is a blog embedded in a reading list; the array order is the list's order
+type ReadingListItemModel struct {
+	BlogID      string    `bson:"blog_id"`
+	Title       string    `bson:"title"`
+	AddedAt     time.Time `bson:"added_at"`
+	Unavailable bool      `bson:"unavailable,omitempty"`
+}
+
+// ReadingListModel is the MongoDB representation of a reading list
+type ReadingListModel struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerID string             `bson:"owner_id"`
+	Name    string             `bson:"name"`
+	Shared  bool               `bson:"shared"`
+	// ShareToken is only set while the list is shared
+	ShareToken string                 `bson:"share_token,omitempty"`
+	Items      []ReadingListItemModel `bson:"items"`
+	CreatedAt  time.Time              `bson:"created_at"`
+	// UpdatedAt changes with every write, which lets updates check nobody else wrote in between
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+func (m *ReadingListModel) ToDomain() *domain.ReadingList {
+	items := make([]*domain.ReadingListItem, len(m.Items))
+	for i, item := range m.Items {
+		items[i] = &domain.ReadingListItem{
+			BlogID:      item.BlogID,
+			Title:       item.Title,
+			AddedAt:     item.AddedAt,
+			Unavailable: item.Unavailable,
+		}
+	}
+	return &domain.ReadingList{
+		ID:         m.ID.Hex(),
+		OwnerID:    m.OwnerID,
+		Name:       m.Name,
+		Shared:     m.Shared,
+		ShareToken: m.ShareToken,
+		Items:      items,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+func readingListItemModels(items []*domain.ReadingListItem) []ReadingListItemModel {
+	models := make([]ReadingListItemModel, len(items))
+	for i, item := range items {
+		models[i] = ReadingListItemModel{
+			BlogID:      item.BlogID,
+			Title:       item.Title,
+			AddedAt:     item.AddedAt,
+			Unavailable: item.Unavailable,
+		}
+	}
+	return models
+}
+
+type BookmarkRepository struct {
+	bookmarks *mongo.Collection
+	lists     *mongo.Collection
+}
+
+// NewBookmarkRepository keeps bookmarks in the "bookmarks" collection and reading lists, with their
+// blogs embedded in order, in "reading_lists"
+func NewBookmarkRepository(db *mongo.Database) domain.BookmarkRepository {
+	bookmarks := db.Collection("bookmarks")
+	bookmarkIndexes := []mongo.IndexModel{
+		// a user bookmarks each blog at most once; also answers the bookmarked state of a page of blogs
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "blog_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		// finds the bookmarks of a deleted blog
+		{Keys: bson.D{{Key: "blog_id", Value: 1}}},
+	}
+	if _, err := bookmarks.Indexes().CreateMany(context.Background(), bookmarkIndexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create bookmark indexes: %v", err)
+	}
+
+	lists := db.Collection("reading_lists")
+	listIndexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner_id", Value: 1}, {Key: "updated_at", Value: -1}}},
+		{
+			Keys:    bson.D{{Key: "share_token", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		// finds the lists holding a deleted blog
+		{Keys: bson.D{{Key: "items.blog_id", Value: 1}}},
+	}
+	if _, err := lists.Indexes().CreateMany(context.Background(), listIndexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create reading list indexes: %v", err)
+	}
+
+	return &BookmarkRepository{bookmarks: bookmarks, lists: lists}
+}
+
+// AddBookmark upserts the bookmark, so bookmarking a blog twice keeps the first bookmark
+func (r *BookmarkRepository) AddBookmark(ctx context.Context, bookmark *domain.Bookmark) (bool, error) {
+	createdAt := bookmark.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	filter := bson.M{"user_id": bookmark.UserID, "blog_id": bookmark.BlogID}
+	update := bson.M{"$setOnInsert": bson.M{"title": bookmark.Title, "created_at": createdAt}}
+	result, err := r.bookmarks.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if result.UpsertedID == nil {
+		return false, nil
+	}
+	if oid, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		bookmark.ID = oid.Hex()
+	}
+	bookmark.CreatedAt = createdAt
+	return true, nil
+}
+
+func (r *BookmarkRepository) RemoveBookmark(ctx context.Context, userID, blogID string) error {
+	_, err := r.bookmarks.DeleteOne(ctx, bson.M{"user_id": userID, "blog_id": blogID})
+	return err
+}
+
+func (r *BookmarkRepository) ListBookmarks(ctx context.Context, userID string, page, limit int) ([]*domain.Bookmark, *domain.Pagination, error) {
+	filter := bson.M{"user_id": userID}
+	total64, err := r.bookmarks.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	total := int(total64)
+	pagination := &domain.Pagination{
+		Total:   &total,
+		Page:    page,
+		Limit:   limit,
+		HasNext: page*limit < total,
+		HasPrev: page > 1,
+	}
+
+	newestFirst := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	opts := options.Find().SetSort(newestFirst).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	cursor, err := r.bookmarks.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+	var models []BookmarkModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, nil, err
+	}
+	bookmarks := make([]*domain.Bookmark, len(models))
+	for i := range models {
+		bookmarks[i] = models[i].ToDomain()
+	}
+	return bookmarks, pagination, nil
+}
+
+func (r *BookmarkRepository) BookmarkedBlogIDs(ctx context.Context, userID string, blogIDs []string) ([]string, error) {
+	if len(blogIDs) == 0 {
+		return []string{}, nil
+	}
+	filter := bson.M{"user_id": userID, "blog_id": bson.M{"$in": blogIDs}}
+	opts := options.Find().SetProjection(bson.M{"blog_id": 1})
+	cursor, err := r.bookmarks.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var models []BookmarkModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(models))
+	for i, model := range models {
+		ids[i] = model.BlogID
+	}
+	return ids, nil
+}
+
+func (r *BookmarkRepository) CreateReadingList(ctx context.Context, list *domain.ReadingList) error {
+	// stored times only keep milliseconds; the list must carry what later updates will compare against
+	now := time.Now().Truncate(time.Millisecond)
+	model := ReadingListModel{
+		OwnerID:    list.OwnerID,
+		Name:       list.Name,
+		Shared:     list.Shared,
+		ShareToken: list.ShareToken,
+		Items:      readingListItemModels(list.Items),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	result, err := r.lists.InsertOne(ctx, model)
+	if err != nil {
+		return err
+	}
+	if oid, ok := result.InsertedID.(primitive.ObjectID); ok {
+		list.ID = oid.Hex()
+	}
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	return nil
+}
+
+func (r *BookmarkRepository) GetReadingList(ctx context.Context, id string) (*domain.ReadingList, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrReadingListNotFound
+	}
+	return r.findReadingList(ctx, bson.M{"_id": oid})
+}
+
+// GetReadingListByShareToken only finds lists that are still shared
+func (r *BookmarkRepository) GetReadingListByShareToken(ctx context.Context, token string) (*domain.ReadingList, error) {
+	if token == "" {
+		return nil, domain.ErrReadingListNotFound
+	}
+	return r.findReadingList(ctx, bson.M{"share_token": token, "shared": true})
+}
+
+func (r *BookmarkRepository) findReadingList(ctx context.Context, filter bson.M) (*domain.ReadingList, error) {
+	var model ReadingListModel
+	err := r.lists.FindOne(ctx, filter).Decode(&model)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrReadingListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *BookmarkRepository) ListReadingLists(ctx context.Context, ownerID string) ([]*domain.ReadingList, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.lists.Find(ctx, bson.M{"owner_id": ownerID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var models []ReadingListModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	lists := make([]*domain.ReadingList, len(models))
+	for i := range models {
+		lists[i] = models[i].ToDomain()
+	}
+	return lists, nil
+}
+
+func (r *BookmarkRepository) UpdateReadingList(ctx context.Context, list *domain.ReadingList, readAt time.Time) error {
+	oid, err := primitive.ObjectIDFromHex(list.ID)
+	if err != nil {
+		return domain.ErrReadingListNotFound
+	}
+	now := time.Now().Truncate(time.Millisecond)
+	set := bson.M{
+		"name":       list.Name,
+		"shared":     list.Shared,
+		"items":      readingListItemModels(list.Items),
+		"updated_at": now,
+	}
+	update := bson.M{"$set": set}
+	if list.ShareToken != "" {
+		set["share_token"] = list.ShareToken
+	} else {
+		// the sparse unique index only tolerates many unshared lists while the field is absent
+		update["$unset"] = bson.M{"share_token": ""}
+	}
+	result, err := r.lists.UpdateOne(ctx, bson.M{"_id": oid, "updated_at": readAt}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		if _, err := r.GetReadingList(ctx, list.ID); err != nil {
+			return err
+		}
+		return domain.ErrReadingListChanged
+	}
+	list.UpdatedAt = now
+	return nil
+}
+
+func (r *BookmarkRepository) DeleteReadingList(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return domain.ErrReadingListNotFound
+	}
+	result, err := r.lists.DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return domain.ErrReadingListNotFound
+	}
+	return nil
+}
+
+// AddReadingListItem pushes the item in one conditional update, so concurrent additions can neither
+// duplicate a blog nor grow the list past its limit
+func (r *BookmarkRepository) AddReadingListItem(ctx context.Context, listID string, item *domain.ReadingListItem) error {
+	oid, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return domain.ErrReadingListNotFound
+	}
+	if item.AddedAt.IsZero() {
+		item.AddedAt = time.Now()
+	}
+	filter := bson.M{
+		"_id":           oid,
+		"items.blog_id": bson.M{"$ne": item.BlogID},
+		// the list still has room when it has no item at the last allowed position
+		fmt.Sprintf("items.%d", domain.MaxReadingListItems-1): bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$push": bson.M{"items": readingListItemModels([]*domain.ReadingListItem{item})[0]},
+		"$set":  bson.M{"updated_at": time.Now().Truncate(time.Millisecond)},
+	}
+	result, err := r.lists.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	list, err := r.GetReadingList(ctx, listID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range list.Items {
+		if existing.BlogID == item.BlogID {
+			return nil
+		}
+	}
+	return domain.ErrReadingListFull
+}
+
+func (r *BookmarkRepository) RemoveReadingListItem(ctx context.Context, listID, blogID string) error {
+	oid, err := primitive.ObjectIDFromHex(listID)
+	if err != nil {
+		return domain.ErrReadingListNotFound
+	}
+	update := bson.M{
+		"$pull": bson.M{"items": bson.M{"blog_id": blogID}},
+		"$set":  bson.M{"updated_at": time.Now().Truncate(time.Millisecond)},
+	}
+	result, err := r.lists.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrReadingListNotFound
+	}
+	return nil
+}
+
+// MarkBlogUnavailable keeps bookmarks and list items of a deleted blog in place, only flagging them, so
+// lists keep their order and readers can see what went away
+func (r *BookmarkRepository) MarkBlogUnavailable(ctx context.Context, blogID string) error {
+	_, err := r.bookmarks.UpdateMany(ctx, bson.M{"blog_id": blogID}, bson.M{"$set": bson.M{"unavailable": true}})
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{
+		"items.$[item].unavailable": true,
+		"updated_at":                time.Now().Truncate(time.Millisecond),
+	}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"item.blog_id": blogID}},
+	})
+	_, err = r.lists.UpdateMany(ctx, bson.M{"items.blog_id": blogID}, update, opts)
+	return err
+}
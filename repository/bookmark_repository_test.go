@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestBookmarkRepository_AddBookmark(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("new bookmark", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "n", Value: 1},
+			{Key: "nModified", Value: 0},
+			{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: id}}}},
+		})
+		bookmark := &domain.Bookmark{UserID: "user1", BlogID: "blog1", Title: "Hello"}
+
+		created, err := repo.AddBookmark(context.Background(), bookmark)
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, id.Hex(), bookmark.ID)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "user1", update.Lookup("q", "user_id").StringValue())
+		assert.Equal(t, "blog1", update.Lookup("q", "blog_id").StringValue())
+		assert.Equal(t, "Hello", update.Lookup("u", "$setOnInsert", "title").StringValue())
+	})
+
+	mt.Run("already bookmarked", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key error"}))
+
+		created, err := repo.AddBookmark(context.Background(), &domain.Bookmark{UserID: "user1", BlogID: "blog1"})
+		assert.NoError(t, err)
+		assert.False(t, created)
+	})
+}
+
+func TestBookmarkRepository_BookmarkedBlogIDs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("only the bookmarked ones", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bookmarks", mtest.FirstBatch, bson.D{{Key: "blog_id", Value: "b"}}))
+
+		ids, err := repo.BookmarkedBlogIDs(context.Background(), "user1", []string{"a", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, ids)
+		filter := mt.GetStartedEvent().Command.Lookup("filter")
+		assert.Equal(t, "user1", filter.Document().Lookup("user_id").StringValue())
+		values, _ := filter.Document().Lookup("blog_id", "$in").Array().Values()
+		assert.Len(t, values, 2)
+	})
+}
+
+func TestBookmarkRepository_UpdateReadingList(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	readAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	mt.Run("unsharing drops the token", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		list := &domain.ReadingList{ID: id.Hex(), Name: "Later", Items: []*domain.ReadingListItem{{BlogID: "b"}, {BlogID: "a"}}}
+
+		err := repo.UpdateReadingList(context.Background(), list, readAt)
+		assert.NoError(t, err)
+		assert.True(t, list.UpdatedAt.After(readAt))
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, readAt, update.Lookup("q", "updated_at").Time().UTC())
+		assert.Equal(t, "b", update.Lookup("u", "$set", "items", "0", "blog_id").StringValue())
+		_, err = update.LookupErr("u", "$unset", "share_token")
+		assert.NoError(t, err)
+	})
+
+	mt.Run("changed since it was read", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		stored := toBSOND(&ReadingListModel{ID: id, Name: "Later", UpdatedAt: readAt.Add(time.Second)})
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+			mtest.CreateCursorResponse(0, "foo.reading_lists", mtest.FirstBatch, stored),
+		)
+
+		err := repo.UpdateReadingList(context.Background(), &domain.ReadingList{ID: id.Hex(), Name: "Soon"}, readAt)
+		assert.Equal(t, domain.ErrReadingListChanged, err)
+	})
+
+	mt.Run("deleted meanwhile", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+			mtest.CreateCursorResponse(0, "foo.reading_lists", mtest.FirstBatch),
+		)
+
+		err := repo.UpdateReadingList(context.Background(), &domain.ReadingList{ID: id.Hex(), Name: "Soon"}, readAt)
+		assert.Equal(t, domain.ErrReadingListNotFound, err)
+	})
+}
+
+func TestBookmarkRepository_AddReadingListItem(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	id := primitive.NewObjectID()
+	notMatched := bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}}
+
+	mt.Run("appended", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.AddReadingListItem(context.Background(), id.Hex(), &domain.ReadingListItem{BlogID: "b", Title: "Hello"})
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "b", update.Lookup("q", "items.blog_id", "$ne").StringValue())
+		assert.False(t, update.Lookup("q", "items.499", "$exists").Boolean())
+		assert.Equal(t, "Hello", update.Lookup("u", "$push", "items", "title").StringValue())
+	})
+
+	mt.Run("already in the list", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		stored := toBSOND(&ReadingListModel{ID: id, Items: []ReadingListItemModel{{BlogID: "b"}}})
+		mt.AddMockResponses(notMatched, mtest.CreateCursorResponse(0, "foo.reading_lists", mtest.FirstBatch, stored))
+
+		err := repo.AddReadingListItem(context.Background(), id.Hex(), &domain.ReadingListItem{BlogID: "b"})
+		assert.NoError(t, err)
+	})
+
+	mt.Run("full", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		stored := toBSOND(&ReadingListModel{ID: id, Items: []ReadingListItemModel{{BlogID: "a"}}})
+		mt.AddMockResponses(notMatched, mtest.CreateCursorResponse(0, "foo.reading_lists", mtest.FirstBatch, stored))
+
+		err := repo.AddReadingListItem(context.Background(), id.Hex(), &domain.ReadingListItem{BlogID: "b"})
+		assert.Equal(t, domain.ErrReadingListFull, err)
+	})
+
+	mt.Run("no such list", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(notMatched, mtest.CreateCursorResponse(0, "foo.reading_lists", mtest.FirstBatch))
+
+		err := repo.AddReadingListItem(context.Background(), id.Hex(), &domain.ReadingListItem{BlogID: "b"})
+		assert.Equal(t, domain.ErrReadingListNotFound, err)
+	})
+}
+
+func TestBookmarkRepository_MarkBlogUnavailable(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("flags bookmarks and list items", func(mt *mtest.T) {
+		repo := &BookmarkRepository{bookmarks: mt.Coll, lists: mt.Coll}
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+
+		assert.NoError(t, repo.MarkBlogUnavailable(context.Background(), "blog1"))
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 2) {
+			bookmarks := events[0].Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, "blog1", bookmarks.Lookup("q", "blog_id").StringValue())
+			assert.True(t, bookmarks.Lookup("u", "$set", "unavailable").Boolean())
+
+			lists := events[1].Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, "blog1", lists.Lookup("q", "items.blog_id").StringValue())
+			assert.True(t, lists.Lookup("u", "$set", "items.$[item].unavailable").Boolean())
+			assert.Equal(t, "blog1", lists.Lookup("arrayFilters", "0", "item.blog_id").StringValue())
+		}
+	})
+}
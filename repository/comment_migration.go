@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// embeddedComment is a comment as it was stored inside the blog document before comments had a collection
+type embeddedComment struct {
+	ID             primitive.ObjectID `bson:"id"`
+	AuthorID       primitive.ObjectID `bson:"author_id"`
+	AuthorUsername string             `bson:"author_username"`
+	Content        string             `bson:"content"`
+	ContentHTML    string             `bson:"content_html,omitempty"`
+	CreatedAt      *time.Time         `bson:"created_at"`
+	ParentID       primitive.ObjectID `bson:"parent_id,omitempty"`
+	Deleted        bool               `bson:"deleted,omitempty"`
+}
+
+type blogWithComments struct {
+	ID       primitive.ObjectID `bson:"_id"`
+	Comments []embeddedComment  `bson:"comments"`
+}
+
+// CommentMigrationResult reports how many blogs and comments MigrateEmbeddedComments moved
+type CommentMigrationResult struct {
+	Blogs    int
+	Comments int
+}
+
+// MigrateEmbeddedComments moves the comments embedded in blog documents into the comments collection,
+// then removes them from the blog and adds the live ones to its comment_count.
+// Comments keep their IDs, so a run interrupted between the two steps can be repeated safely.
+func MigrateEmbeddedComments(ctx context.Context, db *mongo.Database) (*CommentMigrationResult, error) {
+	blogs := db.Collection("blogs")
+	comments := db.Collection("comments")
+
+	result := &CommentMigrationResult{}
+	filter := bson.M{"comments.0": bson.M{"$exists": true}}
+	opts := options.Find().SetProjection(bson.M{"comments": 1}).SetBatchSize(100)
+	cursor, err := blogs.Find(ctx, filter, opts)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var blog blogWithComments
+		if err := cursor.Decode(&blog); err != nil {
+			return result, err
+		}
+		models := threadEmbeddedComments(blog.ID, blog.Comments)
+		docs := make([]interface{}, len(models))
+		live := 0
+		for i, model := range models {
+			docs[i] = model
+			if !model.Deleted {
+				live++
+			}
+		}
+
+		_, err := comments.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+		if err != nil && !onlyDuplicateKeys(err) {
+			return result, err
+		}
+		update := bson.M{
+			"$unset": bson.M{"comments": ""},
+			"$inc":   bson.M{"comment_count": live},
+		}
+		if _, err := blogs.UpdateOne(ctx, bson.M{"_id": blog.ID}, update); err != nil {
+			return result, err
+		}
+		result.Blogs++
+		result.Comments += len(models)
+	}
+	return result, cursor.Err()
+}
+
+// threadEmbeddedComments turns a blog's embedded comments into comment documents, working out each one's
+// root, depth and reply count. Replies whose parent is gone become top-level comments.
+func threadEmbeddedComments(blogID primitive.ObjectID, comments []embeddedComment) []*CommentModel {
+	models := make([]*CommentModel, len(comments))
+	byID := make(map[primitive.ObjectID]*CommentModel, len(comments))
+	for i, comment := range comments {
+		id := comment.ID
+		if id.IsZero() {
+			id = primitive.NewObjectID()
+		}
+		model := &CommentModel{
+			ID:             id,
+			BlogID:         blogID,
+			ParentID:       comment.ParentID,
+			AuthorID:       comment.AuthorID,
+			AuthorUsername: comment.AuthorUsername,
+			Content:        comment.Content,
+			ContentHTML:    comment.ContentHTML,
+			Deleted:        comment.Deleted,
+			CreatedAt:      comment.CreatedAt,
+		}
+		models[i] = model
+		byID[id] = model
+	}
+
+	for _, model := range models {
+		if byID[model.ParentID] == nil {
+			model.ParentID = primitive.NilObjectID
+		}
+	}
+	// a comment that is its own ancestor would never be listed, so it starts a thread instead
+	for _, model := range models {
+		seen := map[primitive.ObjectID]bool{model.ID: true}
+		for parentID := model.ParentID; !parentID.IsZero(); parentID = byID[parentID].ParentID {
+			if seen[parentID] {
+				model.ParentID = primitive.NilObjectID
+				break
+			}
+			seen[parentID] = true
+		}
+	}
+	for _, model := range models {
+		model.RootID, model.Depth = model.ID, 0
+		for parentID := model.ParentID; !parentID.IsZero(); parentID = byID[parentID].ParentID {
+			model.RootID = parentID
+			model.Depth++
+		}
+		if !model.ParentID.IsZero() {
+			byID[model.ParentID].ReplyCount++
+		}
+	}
+	return models
+}
+
+// onlyDuplicateKeys reports whether every failed write was a document that had already been moved
+func onlyDuplicateKeys(err error) bool {
+	bulkErr, ok := err.(mongo.BulkWriteException)
+	if !ok || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if writeErr.Code != 11000 {
+			return false
+		}
+	}
+	return true
+}
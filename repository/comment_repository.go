@@ -0,0 +1,355 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommentModel is the MongoDB representation of a comment
+type CommentModel struct {
+	ID       primitive.ObjectID `bson:"_id,omitempty"`
+	BlogID   primitive.ObjectID `bson:"blog_id"`
+	ParentID primitive.ObjectID `bson:"parent_id,omitempty"`
+	// RootID is the top-level comment of the thread; a top-level comment is its own root
+	RootID         primitive.ObjectID `bson:"root_id"`
+	Depth          int                `bson:"depth"`
+	AuthorID       primitive.ObjectID `bson:"author_id"`
+	AuthorUsername string             `bson:"author_username"`
+	Content        string             `bson:"content"`
+	ContentHTML    string             `bson:"content_html,omitempty"`
+	Deleted        bool               `bson:"deleted,omitempty"`
+	// ReplyCount guards deletion: a comment is only removed outright while nothing replies to it
+	ReplyCount int        `bson:"reply_count"`
+	CreatedAt  *time.Time `bson:"created_at"`
+	// ReactionCounts is only changed with $inc, one count per reaction type
+	ReactionCounts map[string]int `bson:"reaction_counts,omitempty"`
+}
+
+func (m *CommentModel) ToDomain() *domain.Comment {
+	comment := &domain.Comment{
+		ID:             m.ID.Hex(),
+		BlogID:         m.BlogID.Hex(),
+		AuthorID:       m.AuthorID.Hex(),
+		AuthorUsername: m.AuthorUsername,
+		Content:        m.Content,
+		ContentHTML:    m.ContentHTML,
+		CreatedAt:      m.CreatedAt,
+		Depth:          m.Depth,
+		Deleted:        m.Deleted,
+		ReplyCount:     m.ReplyCount,
+		Reactions:      m.ReactionCounts,
+	}
+	if !m.ParentID.IsZero() {
+		comment.ParentID = m.ParentID.Hex()
+	}
+	if m.Deleted {
+		comment.AuthorID = ""
+		comment.Reactions = nil
+	}
+	return comment
+}
+
+func (m *CommentModel) FromDomain(comment *domain.Comment) {
+	var err error
+	m.ID, err = primitive.ObjectIDFromHex(comment.ID)
+	if err != nil {
+		m.ID = primitive.NilObjectID
+	}
+	m.BlogID, err = primitive.ObjectIDFromHex(comment.BlogID)
+	if err != nil {
+		m.BlogID = primitive.NilObjectID
+	}
+	m.ParentID, err = primitive.ObjectIDFromHex(comment.ParentID)
+	if err != nil {
+		m.ParentID = primitive.NilObjectID
+	}
+	m.AuthorID, err = primitive.ObjectIDFromHex(comment.AuthorID)
+	if err != nil {
+		m.AuthorID = primitive.NilObjectID
+	}
+	m.AuthorUsername = comment.AuthorUsername
+	m.Content = comment.Content
+	m.ContentHTML = comment.ContentHTML
+	m.Depth = comment.Depth
+	m.Deleted = comment.Deleted
+	m.ReplyCount = comment.ReplyCount
+	m.CreatedAt = comment.CreatedAt
+}
+
+type CommentRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCommentRepository returns a MongoDB implementation of CommentRepository
+func NewCommentRepository(db *mongo.Database) domain.CommentRepository {
+	coll := db.Collection("comments")
+	indexes := []mongo.IndexModel{
+		// top-level comments of a blog, oldest first
+		{Keys: bson.D{{Key: "blog_id", Value: 1}, {Key: "parent_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		// every reply in a page of threads
+		{Keys: bson.D{{Key: "root_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	}
+
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create comment indexes: %v", err)
+	}
+
+	return &CommentRepository{
+		collection: coll,
+	}
+}
+
+// CreateComment stores a comment and sets its ID. A reply is only stored while its parent exists and is not
+// deleted, otherwise domain.ErrCommentNotFound is returned; its root and depth are taken from the parent.
+func (r *CommentRepository) CreateComment(ctx context.Context, comment *domain.Comment) error {
+	var model CommentModel
+	model.FromDomain(comment)
+	if model.BlogID.IsZero() {
+		return ErrBlogNotFound
+	}
+	model.ID = primitive.NewObjectID()
+	model.Deleted = false
+	model.ReplyCount = 0
+
+	if comment.ParentID == "" {
+		model.RootID = model.ID
+		model.Depth = 0
+	} else {
+		// counting the reply on the parent first means a concurrent delete sees it and leaves a tombstone
+		parent, err := r.adjustReplyCount(ctx, model.BlogID, model.ParentID, 1)
+		if err != nil {
+			return err
+		}
+		model.RootID = parent.RootID
+		model.Depth = parent.Depth + 1
+	}
+
+	if _, err := r.collection.InsertOne(ctx, model); err != nil {
+		if !model.ParentID.IsZero() {
+			_, _ = r.adjustReplyCount(context.Background(), model.BlogID, model.ParentID, -1)
+		}
+		return err
+	}
+	comment.ID = model.ID.Hex()
+	comment.Depth = model.Depth
+	return nil
+}
+
+// adjustReplyCount changes a live comment's reply count and returns the comment as it is afterwards
+func (r *CommentRepository) adjustReplyCount(ctx context.Context, blogID, commentID primitive.ObjectID, delta int) (*CommentModel, error) {
+	filter := bson.M{"_id": commentID, "blog_id": blogID}
+	if delta > 0 {
+		filter["deleted"] = bson.M{"$ne": true}
+	}
+	update := bson.M{"$inc": bson.M{"reply_count": delta}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var model CommentModel
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&model)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model, nil
+}
+
+func (r *CommentRepository) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
+	filter, err := commentFilter(blogID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	var model CommentModel
+	err = r.collection.FindOne(ctx, filter).Decode(&model)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.ToDomain(), nil
+}
+
+func (r *CommentRepository) UpdateComment(ctx context.Context, comment *domain.Comment) error {
+	filter, err := commentFilter(comment.BlogID, comment.ID)
+	if err != nil {
+		return err
+	}
+	filter["deleted"] = bson.M{"$ne": true}
+	update := bson.M{"$set": bson.M{
+		"content":      comment.Content,
+		"content_html": comment.ContentHTML,
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+// DeleteComment removes a comment. A comment with replies is turned into a tombstone instead so the
+// replies keep their place in the thread, and tombstones are removed once their last reply is gone.
+func (r *CommentRepository) DeleteComment(ctx context.Context, blogID string, commentID string) error {
+	filter, err := commentFilter(blogID, commentID)
+	if err != nil {
+		return err
+	}
+	filter["deleted"] = bson.M{"$ne": true}
+	var comment CommentModel
+	err = r.collection.FindOne(ctx, filter).Decode(&comment)
+	if err == mongo.ErrNoDocuments {
+		return domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	filter["reply_count"] = bson.M{"$lte": 0}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return r.tombstoneComment(ctx, comment.BlogID, comment.ID)
+	}
+
+	// Tidying up tombstones is best effort: the deletion itself has already succeeded
+	for parentID := comment.ParentID; !parentID.IsZero(); {
+		parent, err := r.adjustReplyCount(ctx, comment.BlogID, parentID, -1)
+		if err != nil || !parent.Deleted || parent.ReplyCount > 0 {
+			return nil
+		}
+		result, err := r.collection.DeleteOne(ctx, bson.M{"_id": parentID, "deleted": true, "reply_count": bson.M{"$lte": 0}})
+		if err != nil || result.DeletedCount == 0 {
+			return nil
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+// tombstoneComment blanks a comment's content and author but keeps it in the thread
+func (r *CommentRepository) tombstoneComment(ctx context.Context, blogID, commentID primitive.ObjectID) error {
+	filter := bson.M{"_id": commentID, "blog_id": blogID, "deleted": bson.M{"$ne": true}}
+	update := bson.M{"$set": bson.M{
+		"deleted":         true,
+		"content":         domain.DeletedCommentContent,
+		"content_html":    "",
+		"author_id":       primitive.NilObjectID,
+		"author_username": "",
+	}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+// ListThreads pages through a blog's top-level comments, oldest first, and returns them followed by
+// every reply in their threads. Total counts top-level comments only.
+func (r *CommentRepository) ListThreads(ctx context.Context, blogID string, page, limit int) ([]*domain.Comment, *domain.Pagination, error) {
+	blogOid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, nil, ErrBlogNotFound
+	}
+	rootFilter := bson.M{"blog_id": blogOid, "parent_id": nil}
+	total64, err := r.collection.CountDocuments(ctx, rootFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	total := int(total64)
+	pagination := &domain.Pagination{
+		Total:   &total,
+		Page:    page,
+		Limit:   limit,
+		HasNext: page*limit < total,
+		HasPrev: page > 1,
+	}
+
+	oldestFirst := bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+	opts := options.Find().SetSort(oldestFirst).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	var roots []CommentModel
+	if err := r.findAll(ctx, rootFilter, opts, &roots); err != nil {
+		return nil, nil, err
+	}
+	if len(roots) == 0 {
+		return []*domain.Comment{}, pagination, nil
+	}
+
+	rootIDs := make([]primitive.ObjectID, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = root.ID
+	}
+	var replies []CommentModel
+	replyFilter := bson.M{"root_id": bson.M{"$in": rootIDs}, "parent_id": bson.M{"$ne": nil}}
+	if err := r.findAll(ctx, replyFilter, options.Find().SetSort(oldestFirst), &replies); err != nil {
+		return nil, nil, err
+	}
+
+	comments := make([]*domain.Comment, 0, len(roots)+len(replies))
+	for _, model := range append(roots, replies...) {
+		comments = append(comments, model.ToDomain())
+	}
+	return comments, pagination, nil
+}
+
+func (r *CommentRepository) findAll(ctx context.Context, filter bson.M, opts *options.FindOptions, into *[]CommentModel) error {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, into)
+}
+
+// IncrementReactionCount adjusts a live comment's count for one reaction type by delta
+func (r *CommentRepository) IncrementReactionCount(ctx context.Context, blogID string, commentID string, reactionType string, delta int) error {
+	filter, err := commentFilter(blogID, commentID)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$inc": bson.M{"reaction_counts." + reactionType: delta}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	return nil
+}
+
+// DeleteComments removes every comment on a blog
+func (r *CommentRepository) DeleteComments(ctx context.Context, blogID string) error {
+	blogOid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"blog_id": blogOid})
+	return err
+}
+
+func commentFilter(blogID, commentID string) (bson.M, error) {
+	blogOid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	commentOid, err := primitive.ObjectIDFromHex(commentID)
+	if err != nil {
+		return nil, domain.ErrCommentNotFound
+	}
+	return bson.M{"_id": commentOid, "blog_id": blogOid}, nil
+}
@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestCommentRepository_CreateComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("top-level comment is its own root", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		comment := &domain.Comment{
+			BlogID:   primitive.NewObjectID().Hex(),
+			AuthorID: primitive.NewObjectID().Hex(),
+			Content:  "New Comment",
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		err := repo.CreateComment(context.Background(), comment)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, comment.ID)
+		doc := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, doc.Lookup("_id").ObjectID(), doc.Lookup("root_id").ObjectID())
+		assert.Equal(t, comment.ID, doc.Lookup("_id").ObjectID().Hex())
+	})
+
+	mt.Run("reply joins its parent's thread", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		rootID := primitive.NewObjectID()
+		parentID := primitive.NewObjectID()
+		parent := toBSOND(&CommentModel{ID: parentID, RootID: rootID, ParentID: rootID, Depth: 1, ReplyCount: 1})
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: parent}},
+			mtest.CreateSuccessResponse(),
+		)
+
+		reply := &domain.Comment{BlogID: primitive.NewObjectID().Hex(), ParentID: parentID.Hex(), Content: "Reply"}
+		err := repo.CreateComment(context.Background(), reply)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, reply.Depth)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 2) {
+			assert.Equal(t, parentID, events[0].Command.Lookup("query", "_id").ObjectID())
+			doc := events[1].Command.Lookup("documents").Array().Index(0).Value().Document()
+			assert.Equal(t, rootID, doc.Lookup("root_id").ObjectID())
+			assert.Equal(t, parentID, doc.Lookup("parent_id").ObjectID())
+		}
+	})
+
+	mt.Run("reply requires a live parent", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}})
+
+		reply := &domain.Comment{BlogID: primitive.NewObjectID().Hex(), ParentID: primitive.NewObjectID().Hex(), Content: "Reply"}
+		err := repo.CreateComment(context.Background(), reply)
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+		assert.Len(t, mt.GetAllStartedEvents(), 1)
+	})
+}
+
+func TestCommentRepository_GetCommentByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		commentID := primitive.NewObjectID()
+		stored := &CommentModel{ID: commentID, BlogID: blogID, RootID: commentID, AuthorID: primitive.NewObjectID(), Content: "Test Comment"}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(stored)))
+
+		comment, err := repo.GetCommentByID(context.Background(), blogID.Hex(), commentID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, "Test Comment", comment.Content)
+		assert.Equal(t, blogID.Hex(), comment.BlogID)
+		assert.Empty(t, comment.ParentID)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		_, err := repo.GetCommentByID(context.Background(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+	})
+}
+
+func TestCommentRepository_UpdateComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		comment := &domain.Comment{
+			ID:      primitive.NewObjectID().Hex(),
+			BlogID:  primitive.NewObjectID().Hex(),
+			Content: "Updated Comment",
+		}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.UpdateComment(context.Background(), comment)
+		assert.NoError(t, err)
+	})
+
+	mt.Run("deleted comments cannot be edited", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		comment := &domain.Comment{ID: primitive.NewObjectID().Hex(), BlogID: primitive.NewObjectID().Hex()}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.UpdateComment(context.Background(), comment)
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+	})
+}
+
+func TestCommentRepository_IncrementReactionCount(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID, commentID := primitive.NewObjectID(), primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.IncrementReactionCount(context.Background(), blogID.Hex(), commentID.Hex(), "👍", -1)
+		assert.NoError(t, err)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, commentID, update.Lookup("q", "_id").ObjectID())
+		assert.Equal(t, blogID, update.Lookup("q", "blog_id").ObjectID())
+		assert.Equal(t, int64(-1), update.Lookup("u", "$inc", "reaction_counts.👍").AsInt64())
+	})
+
+	mt.Run("comment not found", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.IncrementReactionCount(context.Background(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex(), "👍", 1)
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+	})
+}
+
+func TestCommentRepository_DeleteComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	deleted := func(n int) bson.D {
+		return bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: n}}
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		commentID := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&CommentModel{ID: commentID, BlogID: blogID, RootID: commentID})),
+			deleted(1),
+		)
+
+		err := repo.DeleteComment(context.Background(), blogID.Hex(), commentID.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, mt.GetAllStartedEvents(), 2)
+	})
+
+	mt.Run("comment with replies becomes a tombstone", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		commentID := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&CommentModel{ID: commentID, BlogID: blogID, RootID: commentID, ReplyCount: 2})),
+			deleted(0),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+
+		err := repo.DeleteComment(context.Background(), blogID.Hex(), commentID.Hex())
+		assert.NoError(t, err)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 3) {
+			del := events[1].Command.Lookup("deletes").Array().Index(0).Value().Document()
+			assert.NotNil(t, del.Lookup("q", "reply_count", "$lte").Value)
+			set := events[2].Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set").Document()
+			assert.True(t, set.Lookup("deleted").Boolean())
+			assert.Equal(t, domain.DeletedCommentContent, set.Lookup("content").StringValue())
+			assert.Equal(t, primitive.NilObjectID, set.Lookup("author_id").ObjectID())
+		}
+	})
+
+	mt.Run("last reply takes its tombstoned parent with it", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		parentID := primitive.NewObjectID()
+		replyID := primitive.NewObjectID()
+		parent := toBSOND(&CommentModel{ID: parentID, BlogID: blogID, RootID: parentID, Deleted: true})
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&CommentModel{ID: replyID, BlogID: blogID, ParentID: parentID, RootID: parentID, Depth: 1})),
+			deleted(1),
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: parent}},
+			deleted(1),
+		)
+
+		err := repo.DeleteComment(context.Background(), blogID.Hex(), replyID.Hex())
+		assert.NoError(t, err)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 4) {
+			assert.Equal(t, int64(-1), events[2].Command.Lookup("update", "$inc", "reply_count").AsInt64())
+			del := events[3].Command.Lookup("deletes").Array().Index(0).Value().Document()
+			assert.Equal(t, parentID, del.Lookup("q", "_id").ObjectID())
+		}
+	})
+
+	mt.Run("live parent is kept", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		parentID := primitive.NewObjectID()
+		replyID := primitive.NewObjectID()
+		parent := toBSOND(&CommentModel{ID: parentID, BlogID: blogID, RootID: parentID})
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&CommentModel{ID: replyID, BlogID: blogID, ParentID: parentID, RootID: parentID, Depth: 1})),
+			deleted(1),
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: parent}},
+		)
+
+		err := repo.DeleteComment(context.Background(), blogID.Hex(), replyID.Hex())
+		assert.NoError(t, err)
+		assert.Len(t, mt.GetAllStartedEvents(), 3)
+	})
+
+	mt.Run("comment not found", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		err := repo.DeleteComment(context.Background(), primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex())
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+	})
+}
+
+func TestCommentRepository_ListThreads(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("a page of roots with their replies", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		rootID := primitive.NewObjectID()
+		replyID := primitive.NewObjectID()
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		root := toBSOND(&CommentModel{ID: rootID, BlogID: blogID, RootID: rootID, Content: "Root", ReplyCount: 1, CreatedAt: &now})
+		reply := toBSOND(&CommentModel{ID: replyID, BlogID: blogID, RootID: rootID, ParentID: rootID, Depth: 1, Content: "Reply", CreatedAt: &now})
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "n", Value: 3}}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, root),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, reply),
+		)
+
+		comments, pagination, err := repo.ListThreads(context.Background(), blogID.Hex(), 2, 1)
+		assert.NoError(t, err)
+		if assert.Len(t, comments, 2) {
+			assert.Equal(t, rootID.Hex(), comments[0].ID)
+			assert.Equal(t, rootID.Hex(), comments[1].ParentID)
+		}
+		assert.Equal(t, 3, *pagination.Total)
+		assert.True(t, pagination.HasNext)
+		assert.True(t, pagination.HasPrev)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 3) {
+			assert.Equal(t, int64(1), events[1].Command.Lookup("skip").AsInt64())
+			assert.Equal(t, rootID, events[2].Command.Lookup("filter", "root_id", "$in").Array().Index(0).Value().ObjectID())
+		}
+	})
+
+	mt.Run("page past the end skips the replies", func(mt *mtest.T) {
+		repo := &CommentRepository{collection: mt.Coll}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "n", Value: 1}}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+		)
+
+		comments, pagination, err := repo.ListThreads(context.Background(), primitive.NewObjectID().Hex(), 5, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, comments)
+		assert.False(t, pagination.HasNext)
+		assert.Len(t, mt.GetAllStartedEvents(), 2)
+	})
+}
+
+func TestThreadEmbeddedComments(t *testing.T) {
+	blogID := primitive.NewObjectID()
+	root, reply, nested, orphan := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	loopA, loopB := primitive.NewObjectID(), primitive.NewObjectID()
+
+	models := threadEmbeddedComments(blogID, []embeddedComment{
+		{ID: root, Content: "Root", Deleted: true},
+		{ID: reply, ParentID: root, Content: "Reply"},
+		{ID: nested, ParentID: reply, Content: "Nested"},
+		{ID: orphan, ParentID: primitive.NewObjectID(), Content: "Orphan"},
+		{Content: "Written before comments had IDs"},
+		{ID: loopA, ParentID: loopB},
+		{ID: loopB, ParentID: loopA},
+	})
+
+	if assert.Len(t, models, 7) {
+		assert.Equal(t, root, models[0].RootID)
+		assert.Equal(t, 1, models[0].ReplyCount)
+		assert.Equal(t, root, models[2].RootID)
+		assert.Equal(t, 2, models[2].Depth)
+		assert.Equal(t, 1, models[1].ReplyCount)
+		assert.True(t, models[3].ParentID.IsZero())
+		assert.Equal(t, orphan, models[3].RootID)
+		assert.False(t, models[4].ID.IsZero())
+		assert.Equal(t, models[4].ID, models[4].RootID)
+		assert.True(t, models[5].ParentID.IsZero())
+		assert.Equal(t, loopA, models[6].RootID)
+		assert.Equal(t, 1, models[6].Depth)
+		for _, model := range models {
+			assert.Equal(t, blogID, model.BlogID)
+		}
+	}
+}
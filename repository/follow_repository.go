@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FollowModel is the MongoDB representation of a follow
+type FollowModel struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	FollowerID string             `bson:"follower_id"`
+	TargetType string             `bson:"target_type"`
+	TargetID   string             `bson:"target_id"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+func (m *FollowModel) ToDomain() *domain.Follow {
+	return &domain.Follow{
+		ID:         m.ID.Hex(),
+		FollowerID: m.FollowerID,
+		TargetType: domain.FollowTarget(m.TargetType),
+		TargetID:   m.TargetID,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+type FollowRepository struct {
+	collection *mongo.Collection
+}
+
+// NewFollowRepository keeps follows in the "follows" collection, indexed both ways: what a user follows
+// and who follows a target
+func NewFollowRepository(db *mongo.Database) domain.FollowRepository {
+	coll := db.Collection("follows")
+	indexes := []mongo.IndexModel{
+		// a user follows each target at most once; also lists what they follow, newest first
+		{
+			Keys:    bson.D{{Key: "follower_id", Value: 1}, {Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "target_type", Value: 1}, {Key: "created_at", Value: -1}}},
+		// who follows a target, newest first
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	}
+
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create follow indexes: %v", err)
+	}
+
+	return &FollowRepository{collection: coll}
+}
+
+// Follow upserts the follow, so only the request that actually creates it reports it as new
+func (r *FollowRepository) Follow(ctx context.Context, follow *domain.Follow) (bool, error) {
+	createdAt := follow.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	filter := bson.M{
+		"follower_id": follow.FollowerID,
+		"target_type": string(follow.TargetType),
+		"target_id":   follow.TargetID,
+	}
+	update := bson.M{"$setOnInsert": bson.M{"created_at": createdAt}}
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// a concurrent follow by the same user inserted it first
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if result.UpsertedID == nil {
+		return false, nil
+	}
+	if oid, ok := result.UpsertedID.(primitive.ObjectID); ok {
+		follow.ID = oid.Hex()
+	}
+	follow.CreatedAt = createdAt
+	return true, nil
+}
+
+func (r *FollowRepository) Unfollow(ctx context.Context, followerID string, targetType domain.FollowTarget, targetID string) (bool, error) {
+	filter := bson.M{"follower_id": followerID, "target_type": string(targetType), "target_id": targetID}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (r *FollowRepository) ListFollowers(ctx context.Context, targetType domain.FollowTarget, targetID string, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	return r.list(ctx, bson.M{"target_type": string(targetType), "target_id": targetID}, page, limit)
+}
+
+func (r *FollowRepository) ListFollowing(ctx context.Context, followerID string, targetType domain.FollowTarget, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	return r.list(ctx, bson.M{"follower_id": followerID, "target_type": string(targetType)}, page, limit)
+}
+
+func (r *FollowRepository) list(ctx context.Context, filter bson.M, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	total64, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	total := int(total64)
+	pagination := &domain.Pagination{
+		Total:   &total,
+		Page:    page,
+		Limit:   limit,
+		HasNext: page*limit < total,
+		HasPrev: page > 1,
+	}
+
+	newestFirst := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	opts := options.Find().SetSort(newestFirst).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+	var models []FollowModel
+	if err := cursor.All(ctx, &models); err != nil {
+		return nil, nil, err
+	}
+	follows := make([]*domain.Follow, len(models))
+	for i := range models {
+		follows[i] = models[i].ToDomain()
+	}
+	return follows, pagination, nil
+}
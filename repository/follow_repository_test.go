@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestFollowRepository_Follow(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("new follow", func(mt *mtest.T) {
+		repo := &FollowRepository{collection: mt.Coll}
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(bson.D{
+			{Key: "ok", Value: 1},
+			{Key: "n", Value: 1},
+			{Key: "nModified", Value: 0},
+			{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: id}}}},
+		})
+		follow := &domain.Follow{FollowerID: "user1", TargetType: domain.FollowTargetTag, TargetID: "go"}
+
+		created, err := repo.Follow(context.Background(), follow)
+		assert.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, id.Hex(), follow.ID)
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "user1", update.Lookup("q", "follower_id").StringValue())
+		assert.Equal(t, "tag", update.Lookup("q", "target_type").StringValue())
+		assert.Equal(t, "go", update.Lookup("q", "target_id").StringValue())
+		assert.True(t, update.Lookup("upsert").Boolean())
+	})
+
+	mt.Run("already following", func(mt *mtest.T) {
+		repo := &FollowRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}})
+
+		created, err := repo.Follow(context.Background(), &domain.Follow{FollowerID: "user1", TargetType: domain.FollowTargetAuthor, TargetID: "author1"})
+		assert.NoError(t, err)
+		assert.False(t, created)
+	})
+
+	mt.Run("concurrent follow inserted it first", func(mt *mtest.T) {
+		repo := &FollowRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key error"}))
+
+		created, err := repo.Follow(context.Background(), &domain.Follow{FollowerID: "user1", TargetType: domain.FollowTargetAuthor, TargetID: "author1"})
+		assert.NoError(t, err)
+		assert.False(t, created)
+	})
+}
+
+func TestFollowRepository_Unfollow(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("removed", func(mt *mtest.T) {
+		repo := &FollowRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		removed, err := repo.Unfollow(context.Background(), "user1", domain.FollowTargetAuthor, "author1")
+		assert.NoError(t, err)
+		assert.True(t, removed)
+	})
+
+	mt.Run("was not following", func(mt *mtest.T) {
+		repo := &FollowRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+
+		removed, err := repo.Unfollow(context.Background(), "user1", domain.FollowTargetAuthor, "author1")
+		assert.NoError(t, err)
+		assert.False(t, removed)
+	})
+}
+
+func TestFollowRepository_ListFollowers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("newest first with pagination", func(mt *mtest.T) {
+		repo := &FollowRepository{collection: mt.Coll}
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		follow := FollowModel{ID: primitive.NewObjectID(), FollowerID: "user1", TargetType: "author", TargetID: "author1", CreatedAt: now}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "n", Value: 3}}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(follow)),
+		)
+
+		follows, pagination, err := repo.ListFollowers(context.Background(), domain.FollowTargetAuthor, "author1", 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, *pagination.Total)
+		assert.True(t, pagination.HasNext)
+		if assert.Len(t, follows, 1) {
+			assert.Equal(t, "user1", follows[0].FollowerID)
+			assert.Equal(t, now, follows[0].CreatedAt.UTC())
+		}
+
+		events := mt.GetAllStartedEvents()
+		find := events[len(events)-1].Command
+		assert.Equal(t, "author", find.Lookup("filter", "target_type").StringValue())
+		assert.Equal(t, "author1", find.Lookup("filter", "target_id").StringValue())
+		assert.Equal(t, int32(-1), find.Lookup("sort", "created_at").Int32())
+	})
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/database"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ensureIndexes brings a collection's indexes in line with their declaration at startup. Drift that
+// would need an index rebuilt is logged for an operator rather than fixed behind their back.
+func ensureIndexes(coll *mongo.Collection, declared database.CollectionIndexes) {
+	report, err := database.EnsureIndexes(context.Background(), database.NewIndexView(coll), declared)
+	if err != nil {
+		infrastructure.Log.Fatalf("Failed to create %s indexes: %v", declared.Collection, err)
+	}
+	for _, name := range report.Dropped {
+		infrastructure.Log.Printf("Dropped retired index %s on %s", name, declared.Collection)
+	}
+	for _, drift := range report.Drift {
+		infrastructure.Log.Printf("Index drift on %s: %s", declared.Collection, drift)
+	}
+}
@@ -4,16 +4,40 @@ import (
 	"context"
 	"errors"
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/database"
+	"g3-g65-bsp/utils"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// PasswordResetTokenResponseDTO stores the reset token's digest; Token is only set on documents written
+// before tokens were hashed. Those documents may also hold the email under user_id, as LegacyEmail.
 type PasswordResetTokenResponseDTO struct {
-	Token     string    `bson:"token,omitempty"`
-	Email     string    `bson:"user_id,omitempty"`
-	ExpiresAt time.Time `bson:"expires_at,omitempty"`
+	Token       string    `bson:"token,omitempty"`
+	TokenHash   string    `bson:"token_hash,omitempty"`
+	Email       string    `bson:"email,omitempty"`
+	LegacyEmail string    `bson:"user_id,omitempty"`
+	ExpiresAt   time.Time `bson:"expires_at,omitempty"`
+}
+
+// passwordResetIndexes expire tokens at their expiry and back the lookups by digest and email
+var passwordResetIndexes = database.CollectionIndexes{
+	Collection: "password_reset",
+	Indexes: []database.IndexSpec{
+		database.ExpireAt("expires_at"),
+		{
+			Keys:          bson.D{{Key: "token_hash", Value: 1}},
+			Unique:        true,
+			PartialFilter: bson.M{"token_hash": bson.M{"$exists": true}},
+		},
+		{
+			Keys:          bson.D{{Key: "token", Value: 1}},
+			PartialFilter: bson.M{"token": bson.M{"$exists": true}},
+		},
+		{Keys: bson.D{{Key: "email", Value: 1}}},
+	},
 }
 
 type PasswordReset struct {
@@ -21,8 +45,10 @@ type PasswordReset struct {
 }
 
 func NewPasswordReset(db *mongo.Database) *PasswordReset {
+	coll := db.Collection(passwordResetIndexes.Collection)
+	ensureIndexes(coll, passwordResetIndexes)
 	return &PasswordReset{
-		collection: db.Collection("password_reset"),
+		collection: coll,
 	}
 }
 
@@ -33,34 +59,50 @@ func (pr *PasswordReset) Create(ctx context.Context, token *domain.PasswordReset
 
 func (pr *PasswordReset) GetByToken(ctx context.Context, token string) (*domain.PasswordResetToken, error) {
 	var dto PasswordResetTokenResponseDTO
-	filter := bson.M{"token": token}
-	if err := pr.collection.FindOne(ctx, filter).Decode(&dto); err != nil {
+	filter := bson.M{"token_hash": utils.HashToken(token)}
+	err := pr.collection.FindOne(ctx, filter).Decode(&dto)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		upgraded, upgradeErr := upgradeLegacyToken(ctx, pr.collection, "token", "token_hash", token)
+		if upgradeErr != nil {
+			return nil, upgradeErr
+		}
+		if upgraded {
+			err = pr.collection.FindOne(ctx, filter).Decode(&dto)
+		}
+	}
+	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, errors.New("token not found")
 		}
 		return nil, err
 	}
-	return toDomainPass(&dto), nil
+	resetToken := toDomainPass(&dto)
+	resetToken.Token = token
+	return resetToken, nil
 }
 
 func (pr *PasswordReset) Delete(ctx context.Context, token string) error {
-	filter := bson.M{"token": token}
+	filter := bson.M{"$or": bson.A{bson.M{"token_hash": utils.HashToken(token)}, bson.M{"token": token}}}
 	_, err := pr.collection.DeleteOne(ctx, filter)
 	return err
 }
 
 func toDTOPass(token *domain.PasswordResetToken) *PasswordResetTokenResponseDTO {
 	return &PasswordResetTokenResponseDTO{
-		Token:     token.Token,
+		TokenHash: utils.HashToken(token.Token),
 		Email:     token.Email,
 		ExpiresAt: token.ExpiresAt,
 	}
 }
 
 func toDomainPass(dto *PasswordResetTokenResponseDTO) *domain.PasswordResetToken {
+	email := dto.Email
+	if email == "" {
+		email = dto.LegacyEmail
+	}
 	return &domain.PasswordResetToken{
 		Token:     dto.Token,
-		Email:     dto.Email,
+		Email:     email,
 		ExpiresAt: dto.ExpiresAt,
 	}
 }
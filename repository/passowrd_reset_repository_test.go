@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/utils"
 )
 
 func TestPasswordReset_Create(t *testing.T) {
@@ -26,6 +28,11 @@ func TestPasswordReset_Create(t *testing.T) {
 
 		err := repo.Create(context.Background(), token)
 		assert.NoError(t, err)
+		document := mt.GetStartedEvent().Command.Lookup("documents", "0").Document()
+		assert.Equal(t, utils.HashToken("reset-token"), document.Lookup("token_hash").StringValue())
+		assert.Equal(t, "test@gmail.com", document.Lookup("email").StringValue())
+		_, err = document.LookupErr("token")
+		assert.Error(t, err, "the plaintext token must not be stored")
 	})
 }
 
@@ -52,9 +59,29 @@ func TestPasswordReset_GetByToken(t *testing.T) {
 		repo := &PasswordReset{collection: mt.Coll}
 		token := "reset-token"
 
-		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+		)
 
 		_, err := repo.GetByToken(context.Background(), token)
 		assert.Error(t, err)
 	})
+
+	mt.Run("stored in plaintext before hashing", func(mt *mtest.T) {
+		repo := &PasswordReset{collection: mt.Coll}
+		// documents this old also hold the email under user_id
+		migrated := &PasswordResetTokenResponseDTO{TokenHash: utils.HashToken("legacy"), LegacyEmail: "test@gmail.com", ExpiresAt: time.Now().Add(time.Hour)}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(migrated)),
+		)
+
+		result, err := repo.GetByToken(context.Background(), "legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", result.Token)
+		assert.Equal(t, "test@gmail.com", result.Email)
+	})
 }
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// embeddedReactions is a blog as it was stored before reactions had a collection, with the users who
+// liked and disliked it listed on the blog itself
+type embeddedReactions struct {
+	ID      primitive.ObjectID `bson:"_id"`
+	Metrics struct {
+		Likes    *embeddedLikes `bson:"likes"`
+		Dislikes *embeddedLikes `bson:"dislikes"`
+	} `bson:"metrics"`
+}
+
+// embeddedLikes is a like or dislike list embedded in a blog document
+type embeddedLikes struct {
+	Count int      `bson:"count"`
+	Users []string `bson:"users"`
+}
+
+// ReactionMigrationResult reports how many blogs and reactions MigrateEmbeddedReactions moved
+type ReactionMigrationResult struct {
+	Blogs     int
+	Reactions int
+}
+
+// MigrateEmbeddedReactions moves the likes and dislikes embedded in blog documents into the reactions
+// collection as "like" and "dislike" reactions, then removes the lists from the blog and sets its
+// reaction counts from the reactions collection. Reactions that were already moved are skipped, so a
+// run interrupted between the two steps can be repeated safely.
+func MigrateEmbeddedReactions(ctx context.Context, db *mongo.Database) (*ReactionMigrationResult, error) {
+	blogs := db.Collection("blogs")
+	reactions := db.Collection("reactions")
+
+	result := &ReactionMigrationResult{}
+	filter := bson.M{"$or": bson.A{
+		bson.M{"metrics.likes": bson.M{"$exists": true}},
+		bson.M{"metrics.dislikes": bson.M{"$exists": true}},
+	}}
+	opts := options.Find().SetProjection(bson.M{"metrics.likes": 1, "metrics.dislikes": 1}).SetBatchSize(100)
+	cursor, err := blogs.Find(ctx, filter, opts)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var blog embeddedReactions
+		if err := cursor.Decode(&blog); err != nil {
+			return result, err
+		}
+		models := embeddedReactionModels(&blog)
+		if len(models) > 0 {
+			docs := make([]interface{}, len(models))
+			for i, model := range models {
+				docs[i] = model
+			}
+			_, err := reactions.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+			if err != nil && !onlyDuplicateKeys(err) {
+				return result, err
+			}
+		}
+
+		counts := bson.M{}
+		for _, reactionType := range []string{"like", "dislike"} {
+			n, err := reactions.CountDocuments(ctx, bson.M{
+				"target_type": string(domain.ReactionTargetBlog),
+				"target_id":   blog.ID,
+				"type":        reactionType,
+			})
+			if err != nil {
+				return result, err
+			}
+			counts["reaction_counts."+reactionType] = n
+		}
+		update := bson.M{
+			"$unset": bson.M{"metrics.likes": "", "metrics.dislikes": ""},
+			"$set":   counts,
+		}
+		if _, err := blogs.UpdateOne(ctx, bson.M{"_id": blog.ID}, update); err != nil {
+			return result, err
+		}
+		result.Blogs++
+		result.Reactions += len(models)
+	}
+	return result, cursor.Err()
+}
+
+// embeddedReactionModels turns a blog's like and dislike lists into reaction documents
+func embeddedReactionModels(blog *embeddedReactions) []*ReactionModel {
+	var models []*ReactionModel
+	add := func(reactionType string, likes *embeddedLikes) {
+		if likes == nil {
+			return
+		}
+		seen := make(map[string]bool, len(likes.Users))
+		for _, userID := range likes.Users {
+			if userID == "" || seen[userID] {
+				continue
+			}
+			seen[userID] = true
+			// the lists never recorded when a user reacted, so the blog's own creation time stands in
+			models = append(models, &ReactionModel{
+				ID:         primitive.NewObjectID(),
+				TargetType: string(domain.ReactionTargetBlog),
+				TargetID:   blog.ID,
+				BlogID:     blog.ID,
+				UserID:     userID,
+				Type:       reactionType,
+				CreatedAt:  blog.ID.Timestamp(),
+			})
+		}
+	}
+	add("like", blog.Metrics.Likes)
+	add("dislike", blog.Metrics.Dislikes)
+	return models
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReactionModel is the MongoDB representation of a reaction
+type ReactionModel struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	TargetType string             `bson:"target_type"`
+	TargetID   primitive.ObjectID `bson:"target_id"`
+	BlogID     primitive.ObjectID `bson:"blog_id"`
+	UserID     string             `bson:"user_id"`
+	Username   string             `bson:"username,omitempty"`
+	Type       string             `bson:"type"`
+	CreatedAt  time.Time          `bson:"created_at"`
+}
+
+func (m *ReactionModel) ToDomain() *domain.Reaction {
+	return &domain.Reaction{
+		ID:         m.ID.Hex(),
+		TargetType: domain.ReactionTarget(m.TargetType),
+		TargetID:   m.TargetID.Hex(),
+		BlogID:     m.BlogID.Hex(),
+		UserID:     m.UserID,
+		Username:   m.Username,
+		Type:       m.Type,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+type ReactionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewReactionRepository returns a MongoDB implementation of ReactionRepository
+func NewReactionRepository(db *mongo.Database) domain.ReactionRepository {
+	coll := db.Collection("reactions")
+	indexes := []mongo.IndexModel{
+		// a user leaves each type of reaction on a target at most once
+		{
+			Keys:    bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "type", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		// who reacted to a target, newest first, with or without picking one type
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "type", Value: 1}, {Key: "created_at", Value: -1}}},
+		// every reaction in a blog, for removing them with it
+		{Keys: bson.D{{Key: "blog_id", Value: 1}}},
+	}
+
+	if _, err := coll.Indexes().CreateMany(context.Background(), indexes); err != nil {
+		infrastructure.Log.Fatalf("Failed to create reaction indexes: %v", err)
+	}
+
+	return &ReactionRepository{
+		collection: coll,
+	}
+}
+
+// ToggleReaction inserts the reaction and lets the unique index catch one the user already left, which
+// is then removed. Concurrent toggles by the same user therefore add and remove the one document in
+// turn, and the returned delta always matches what happened to it.
+func (r *ReactionRepository) ToggleReaction(ctx context.Context, reaction *domain.Reaction) (int, error) {
+	model, err := reactionModel(reaction)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = r.collection.InsertOne(ctx, model)
+	if err == nil {
+		reaction.ID = model.ID.Hex()
+		reaction.CreatedAt = model.CreatedAt
+		return 1, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return 0, err
+	}
+
+	filter := bson.M{
+		"target_type": model.TargetType,
+		"target_id":   model.TargetID,
+		"user_id":     model.UserID,
+		"type":        model.Type,
+	}
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	if result.DeletedCount == 0 {
+		// a concurrent toggle removed it between the insert and the delete
+		return 0, nil
+	}
+	return -1, nil
+}
+
+// UserReactions returns the types of reaction the user has left on a target, in the order they were left
+func (r *ReactionRepository) UserReactions(ctx context.Context, targetType domain.ReactionTarget, targetID string, userID string) ([]string, error) {
+	oid, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return nil, targetNotFound(targetType)
+	}
+	filter := bson.M{"target_type": string(targetType), "target_id": oid, "user_id": userID}
+	opts := options.Find().SetProjection(bson.M{"type": 1}).SetSort(bson.D{{Key: "created_at", Value: 1}})
+	var models []ReactionModel
+	if err := r.findAll(ctx, filter, opts, &models); err != nil {
+		return nil, err
+	}
+	types := make([]string, len(models))
+	for i, model := range models {
+		types[i] = model.Type
+	}
+	return types, nil
+}
+
+// ListReactions pages through the reactions on a target, newest first. An empty reactionType lists every type.
+func (r *ReactionRepository) ListReactions(ctx context.Context, targetType domain.ReactionTarget, targetID string, reactionType string, page, limit int) ([]*domain.Reaction, *domain.Pagination, error) {
+	oid, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return nil, nil, targetNotFound(targetType)
+	}
+	filter := bson.M{"target_type": string(targetType), "target_id": oid}
+	if reactionType != "" {
+		filter["type"] = reactionType
+	}
+	total64, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	total := int(total64)
+	pagination := &domain.Pagination{
+		Total:   &total,
+		Page:    page,
+		Limit:   limit,
+		HasNext: page*limit < total,
+		HasPrev: page > 1,
+	}
+
+	newestFirst := bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}
+	opts := options.Find().SetSort(newestFirst).SetSkip(int64((page - 1) * limit)).SetLimit(int64(limit))
+	var models []ReactionModel
+	if err := r.findAll(ctx, filter, opts, &models); err != nil {
+		return nil, nil, err
+	}
+	reactions := make([]*domain.Reaction, len(models))
+	for i := range models {
+		reactions[i] = models[i].ToDomain()
+	}
+	return reactions, pagination, nil
+}
+
+func (r *ReactionRepository) findAll(ctx context.Context, filter bson.M, opts *options.FindOptions, into *[]ReactionModel) error {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	return cursor.All(ctx, into)
+}
+
+// DeleteReactions removes every reaction left on one blog or comment
+func (r *ReactionRepository) DeleteReactions(ctx context.Context, targetType domain.ReactionTarget, targetID string) error {
+	oid, err := primitive.ObjectIDFromHex(targetID)
+	if err != nil {
+		return targetNotFound(targetType)
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"target_type": string(targetType), "target_id": oid})
+	return err
+}
+
+// DeleteBlogReactions removes the reactions on a blog and on all of its comments
+func (r *ReactionRepository) DeleteBlogReactions(ctx context.Context, blogID string) error {
+	oid, err := primitive.ObjectIDFromHex(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"blog_id": oid})
+	return err
+}
+
+func reactionModel(reaction *domain.Reaction) (*ReactionModel, error) {
+	blogID, err := primitive.ObjectIDFromHex(reaction.BlogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	targetID, err := primitive.ObjectIDFromHex(reaction.TargetID)
+	if err != nil {
+		return nil, targetNotFound(reaction.TargetType)
+	}
+	createdAt := reaction.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return &ReactionModel{
+		ID:         primitive.NewObjectID(),
+		TargetType: string(reaction.TargetType),
+		TargetID:   targetID,
+		BlogID:     blogID,
+		UserID:     reaction.UserID,
+		Username:   reaction.Username,
+		Type:       reaction.Type,
+		CreatedAt:  createdAt,
+	}, nil
+}
+
+func targetNotFound(targetType domain.ReactionTarget) error {
+	if targetType == domain.ReactionTargetComment {
+		return domain.ErrCommentNotFound
+	}
+	return ErrBlogNotFound
+}
@@ -0,0 +1,238 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestReactionRepository_ToggleReaction(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	blogID := primitive.NewObjectID().Hex()
+	duplicate := mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key error"})
+
+	mt.Run("new reaction is added", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		reaction := &domain.Reaction{TargetType: domain.ReactionTargetBlog, TargetID: blogID, BlogID: blogID, UserID: "user1", Type: "❤️"}
+
+		delta, err := repo.ToggleReaction(context.Background(), reaction)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, delta)
+		assert.NotEmpty(t, reaction.ID)
+		doc := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		assert.Equal(t, "blog", doc.Lookup("target_type").StringValue())
+		assert.Equal(t, "❤️", doc.Lookup("type").StringValue())
+	})
+
+	mt.Run("reaction the user already left is taken back", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+		mt.AddMockResponses(duplicate, mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		commentID := primitive.NewObjectID().Hex()
+		reaction := &domain.Reaction{TargetType: domain.ReactionTargetComment, TargetID: commentID, BlogID: blogID, UserID: "user1", Type: "👍"}
+
+		delta, err := repo.ToggleReaction(context.Background(), reaction)
+		assert.NoError(t, err)
+		assert.Equal(t, -1, delta)
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 2) {
+			q := events[1].Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+			assert.Equal(t, "comment", q.Lookup("target_type").StringValue())
+			assert.Equal(t, "user1", q.Lookup("user_id").StringValue())
+			assert.Equal(t, "👍", q.Lookup("type").StringValue())
+		}
+	})
+
+	mt.Run("concurrent toggle removed it first", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+		mt.AddMockResponses(duplicate, mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		reaction := &domain.Reaction{TargetType: domain.ReactionTargetBlog, TargetID: blogID, BlogID: blogID, UserID: "user1", Type: "👍"}
+
+		delta, err := repo.ToggleReaction(context.Background(), reaction)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delta)
+	})
+
+	mt.Run("invalid target", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+
+		_, err := repo.ToggleReaction(context.Background(), &domain.Reaction{TargetType: domain.ReactionTargetComment, TargetID: "nope", BlogID: blogID})
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+		_, err = repo.ToggleReaction(context.Background(), &domain.Reaction{TargetType: domain.ReactionTargetBlog, TargetID: blogID, BlogID: "nope"})
+		assert.ErrorIs(t, err, ErrBlogNotFound)
+		assert.Empty(t, mt.GetAllStartedEvents())
+	})
+}
+
+func TestReactionRepository_UserReactions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("types the user left", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "type", Value: "❤️"}},
+			bson.D{{Key: "type", Value: "🎉"}},
+		))
+
+		types, err := repo.UserReactions(context.Background(), domain.ReactionTargetBlog, blogID.Hex(), "user1")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"❤️", "🎉"}, types)
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.Equal(t, blogID, filter.Lookup("target_id").ObjectID())
+		assert.Equal(t, "user1", filter.Lookup("user_id").StringValue())
+	})
+}
+
+func TestReactionRepository_ListReactions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("one page of one type", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		reaction := ReactionModel{ID: primitive.NewObjectID(), TargetType: "blog", TargetID: blogID, BlogID: blogID, UserID: "user1", Username: "one", Type: "🎉", CreatedAt: time.Now()}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{Key: "n", Value: 3}}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(reaction)),
+		)
+
+		reactions, pagination, err := repo.ListReactions(context.Background(), domain.ReactionTargetBlog, blogID.Hex(), "🎉", 2, 2)
+		assert.NoError(t, err)
+		if assert.Len(t, reactions, 1) {
+			assert.Equal(t, "one", reactions[0].Username)
+			assert.Equal(t, "🎉", reactions[0].Type)
+		}
+		assert.Equal(t, 3, *pagination.Total)
+		assert.False(t, pagination.HasNext)
+		assert.True(t, pagination.HasPrev)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 2) {
+			find := events[1].Command
+			assert.Equal(t, "🎉", find.Lookup("filter", "type").StringValue())
+			assert.Equal(t, int64(2), find.Lookup("skip").AsInt64())
+		}
+	})
+
+	mt.Run("invalid blog", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+
+		_, _, err := repo.ListReactions(context.Background(), domain.ReactionTargetBlog, "nope", "", 1, 20)
+		assert.ErrorIs(t, err, ErrBlogNotFound)
+	})
+}
+
+func TestReactionRepository_ToggleReactionConcurrent(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	if err != nil {
+		t.Skip("skipping mongo tests, could not create mongo client: " + err.Error())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Skip("skipping mongo tests, could not connect to mongodb: " + err.Error())
+	}
+	db := client.Database("testdb")
+	defer func() {
+		db.Collection("reactions").Drop(context.Background())
+		client.Disconnect(context.Background())
+	}()
+	repo := NewReactionRepository(db)
+
+	blogID := primitive.NewObjectID().Hex()
+	const users = 40
+	const toggles = 5
+	types := []string{"👍", "❤️"}
+
+	// every toggle's delta is summed per type, the way the usecase applies them to the blog's counts
+	var mu sync.Mutex
+	counts := map[string]int{}
+	toggle := func(userID, reactionType string) {
+		delta, err := repo.ToggleReaction(context.Background(), &domain.Reaction{
+			TargetType: domain.ReactionTargetBlog, TargetID: blogID, BlogID: blogID, UserID: userID, Type: reactionType,
+		})
+		assert.NoError(t, err)
+		mu.Lock()
+		counts[reactionType] += delta
+		mu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	// many users toggling at once; each ends up reacting since toggles is odd
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func(userID, reactionType string) {
+			defer wg.Done()
+			for j := 0; j < toggles; j++ {
+				toggle(userID, reactionType)
+			}
+		}(fmt.Sprintf("user%d", i), types[i%2])
+	}
+	// one user toggling the same reactions from many requests at once
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(reactionType string) {
+			defer wg.Done()
+			toggle("flipper", reactionType)
+		}(types[i%2])
+	}
+	wg.Wait()
+
+	for _, reactionType := range types {
+		stored, err := db.Collection("reactions").CountDocuments(context.Background(), bson.M{"type": reactionType})
+		assert.NoError(t, err)
+		assert.Equal(t, int(stored), counts[reactionType], reactionType)
+	}
+	stored, err := db.Collection("reactions").CountDocuments(context.Background(), bson.M{"user_id": bson.M{"$ne": "flipper"}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(users), stored)
+}
+
+func TestReactionRepository_DeleteBlogReactions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("removes the reactions on the blog and its comments", func(mt *mtest.T) {
+		repo := &ReactionRepository{collection: mt.Coll}
+		blogID := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}))
+
+		err := repo.DeleteBlogReactions(context.Background(), blogID.Hex())
+		assert.NoError(t, err)
+		q := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(t, blogID, q.Lookup("blog_id").ObjectID())
+	})
+}
+
+func TestEmbeddedReactionModels(t *testing.T) {
+	blog := &embeddedReactions{ID: primitive.NewObjectID()}
+	blog.Metrics.Likes = &embeddedLikes{Count: 3, Users: []string{"u1", "u2", "u1"}}
+	blog.Metrics.Dislikes = &embeddedLikes{Count: 1, Users: []string{"u3", ""}}
+
+	models := embeddedReactionModels(blog)
+
+	if assert.Len(t, models, 3) {
+		assert.Equal(t, "like", models[0].Type)
+		assert.Equal(t, "u1", models[0].UserID)
+		assert.Equal(t, "u2", models[1].UserID)
+		assert.Equal(t, "dislike", models[2].Type)
+		assert.Equal(t, "u3", models[2].UserID)
+		for _, model := range models {
+			assert.Equal(t, blog.ID, model.TargetID)
+			assert.Equal(t, blog.ID, model.BlogID)
+			assert.Equal(t, string(domain.ReactionTargetBlog), model.TargetType)
+			assert.False(t, model.ID.IsZero())
+		}
+	}
+	assert.Empty(t, embeddedReactionModels(&embeddedReactions{ID: primitive.NewObjectID()}))
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sitemapBatchSize keeps the cursor from pulling a whole 50k chunk into memory at once
+const sitemapBatchSize = 1000
+
+// sitemapEntryModel is a document produced by the sitemap pipelines.
+// _id is the blog or author ObjectID, or the tag itself.
+type sitemapEntryModel struct {
+	ID           bson.RawValue `bson:"_id"`
+	Slug         string        `bson:"slug,omitempty"`
+	LastModified *time.Time    `bson:"last_modified"`
+}
+
+func (m *sitemapEntryModel) ToDomain() *domain.SitemapEntry {
+	entry := &domain.SitemapEntry{Slug: m.Slug, LastModified: m.LastModified}
+	if oid, ok := m.ID.ObjectIDOK(); ok {
+		entry.ID = oid.Hex()
+	} else {
+		entry.ID, _ = m.ID.StringValueOK()
+	}
+	return entry
+}
+
+type sitemapSummaryModel struct {
+	Count        int        `bson:"count"`
+	LastModified *time.Time `bson:"last_modified"`
+}
+
+type SitemapRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSitemapRepository reads the sitemap pages straight from the blogs collection
+func NewSitemapRepository(collection *mongo.Collection) domain.SitemapRepository {
+	return &SitemapRepository{collection: collection}
+}
+
+// Summarize counts the pages in a section and finds the newest change among them
+func (r *SitemapRepository) Summarize(ctx context.Context, section domain.SitemapSection) (*domain.SitemapSummary, error) {
+	pipeline := append(sitemapPipeline(section), bson.M{"$group": bson.M{
+		"_id":           nil,
+		"count":         bson.M{"$sum": 1},
+		"last_modified": bson.M{"$max": sitemapLastModified(section)},
+	}})
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var summary sitemapSummaryModel
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&summary); err != nil {
+			return nil, err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+	return &domain.SitemapSummary{Count: summary.Count, LastModified: summary.LastModified}, nil
+}
+
+// LastID finds where the sitemap file after the one starting after ends. The skip is bounded by one
+// file, however deep into the section it is.
+func (r *SitemapRepository) LastID(ctx context.Context, section domain.SitemapSection, after string, limit int) (string, error) {
+	pipeline, err := sitemapRange(section, after)
+	if err != nil {
+		return "", err
+	}
+	// the greatest of the next limit entries
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.M{"_id": 1}},
+		bson.M{"$limit": limit},
+		bson.M{"$sort": bson.M{"_id": -1}},
+		bson.M{"$limit": 1},
+		bson.M{"$project": bson.M{"_id": 1}},
+	)
+	cursor, err := r.collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return "", err
+	}
+	defer cursor.Close(ctx)
+
+	var model sitemapEntryModel
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&model); err != nil {
+			return "", err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return "", err
+	}
+	return model.ToDomain().ID, nil
+}
+
+// Stream calls fn for each page of a section after the given ID, in ID order. It seeks by ID range,
+// like the blog listing cursors, so a file deep in the section costs no more than the first.
+// Entries are decoded one at a time from the cursor so a full chunk is never held in memory.
+func (r *SitemapRepository) Stream(ctx context.Context, section domain.SitemapSection, after string, limit int, fn func(*domain.SitemapEntry) error) error {
+	pipeline, err := sitemapRange(section, after)
+	if err != nil {
+		return err
+	}
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.M{"_id": 1}},
+		bson.M{"$limit": limit},
+	)
+	if section == domain.SitemapSectionBlogs {
+		// project after paging so the sort on _id can use the index
+		pipeline = append(pipeline, bson.M{"$project": bson.M{"slug": 1, "last_modified": sitemapLastModified(section)}})
+	}
+	opts := options.Aggregate().SetAllowDiskUse(true).SetBatchSize(sitemapBatchSize)
+	cursor, err := r.collection.Aggregate(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var model sitemapEntryModel
+		if err := cursor.Decode(&model); err != nil {
+			return err
+		}
+		if err := fn(model.ToDomain()); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// sitemapRange produces the entries of a section whose ID sorts after after
+func sitemapRange(section domain.SitemapSection, after string) ([]bson.M, error) {
+	pipeline := sitemapPipeline(section)
+	if after == "" {
+		return pipeline, nil
+	}
+	var key any = after
+	if section != domain.SitemapSectionTags {
+		oid, err := primitive.ObjectIDFromHex(after)
+		if err != nil {
+			return nil, domain.ErrSitemapNotFound
+		}
+		key = oid
+	}
+	return append(pipeline, bson.M{"$match": bson.M{"_id": bson.M{"$gt": key}}}), nil
+}
+
+// sitemapPipeline produces one document per page in the section. Tag and author pages are grouped
+// and carry their last_modified; blog pages are the blog documents themselves.
+func sitemapPipeline(section domain.SitemapSection) []bson.M {
+	published := bson.M{"$match": statusFilter(nil)}
+	// grouped pages changed when the newest blog in the group did
+	lastModified := sitemapLastModified(domain.SitemapSectionBlogs)
+
+	switch section {
+	case domain.SitemapSectionTags:
+		return []bson.M{
+			published,
+			{"$unwind": "$tags"},
+			{"$match": bson.M{"tags": bson.M{"$nin": bson.A{"", nil}}}},
+			{"$group": bson.M{"_id": "$tags", "last_modified": bson.M{"$max": lastModified}}},
+		}
+	case domain.SitemapSectionAuthors:
+		return []bson.M{
+			published,
+			{"$match": bson.M{"author_id": bson.M{"$ne": primitive.NilObjectID}}},
+			{"$group": bson.M{"_id": "$author_id", "last_modified": bson.M{"$max": lastModified}}},
+		}
+	default:
+		return []bson.M{published}
+	}
+}
+
+// sitemapLastModified is the expression for when a page produced by sitemapPipeline last changed
+func sitemapLastModified(section domain.SitemapSection) any {
+	if section == domain.SitemapSectionBlogs {
+		return bson.M{"$ifNull": bson.A{"$updated_at", "$created_at"}}
+	}
+	return "$last_modified"
+}
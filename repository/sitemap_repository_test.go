@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/domain"
+)
+
+func TestSitemapRepository_Summarize(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("counts the section", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+		lastModified := time.Date(2024, 5, 2, 12, 30, 0, 0, time.UTC)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: nil}, {Key: "count", Value: 3}, {Key: "last_modified", Value: lastModified}}))
+
+		summary, err := repo.Summarize(context.Background(), domain.SitemapSectionTags)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, summary.Count)
+		assert.True(t, lastModified.Equal(*summary.LastModified))
+
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline").Array()
+		values, _ := pipeline.Values()
+		assert.Len(t, values, 5)
+		_, hasUnwind := values[1].Document().Lookup("$unwind").StringValueOK()
+		assert.True(t, hasUnwind)
+	})
+
+	mt.Run("empty section", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		summary, err := repo.Summarize(context.Background(), domain.SitemapSectionBlogs)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, summary.Count)
+		assert.Nil(t, summary.LastModified)
+	})
+}
+
+func TestSitemapRepository_Stream(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("streams blogs across batches", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+		updated := time.Date(2024, 5, 2, 12, 30, 0, 0, time.UTC)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(42, "foo.bar", mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: first}, {Key: "slug", Value: "hello-world"}, {Key: "last_modified", Value: updated}}),
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch,
+				bson.D{{Key: "_id", Value: second}, {Key: "last_modified", Value: nil}}),
+		)
+
+		after := primitive.NewObjectID()
+		var entries []*domain.SitemapEntry
+		err := repo.Stream(context.Background(), domain.SitemapSectionBlogs, after.Hex(), 50, func(entry *domain.SitemapEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 2) {
+			assert.Equal(t, first.Hex(), entries[0].ID)
+			assert.Equal(t, "hello-world", entries[0].Slug)
+			assert.True(t, updated.Equal(*entries[0].LastModified))
+			assert.Equal(t, second.Hex(), entries[1].ID)
+			assert.Nil(t, entries[1].LastModified)
+		}
+
+		started := mt.GetStartedEvent()
+		assert.Equal(t, int32(sitemapBatchSize), started.Command.Lookup("cursor", "batchSize").Int32())
+		values, _ := started.Command.Lookup("pipeline").Array().Values()
+		assert.Equal(t, after, values[1].Document().Lookup("$match", "_id", "$gt").ObjectID())
+		assert.Equal(t, int32(50), values[3].Document().Lookup("$limit").Int32())
+	})
+
+	mt.Run("tags are keyed by name", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "go"}, {Key: "last_modified", Value: time.Now()}}))
+
+		var entries []*domain.SitemapEntry
+		err := repo.Stream(context.Background(), domain.SitemapSectionTags, "", 10, func(entry *domain.SitemapEntry) error {
+			entries = append(entries, entry)
+			return nil
+		})
+		assert.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, "go", entries[0].ID)
+		}
+	})
+
+	mt.Run("invalid start", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+
+		err := repo.Stream(context.Background(), domain.SitemapSectionAuthors, "not-an-id", 10, func(*domain.SitemapEntry) error { return nil })
+		assert.ErrorIs(t, err, domain.ErrSitemapNotFound)
+	})
+}
+
+func TestSitemapRepository_LastID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("finds the last ID of the page", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: "rust"}}))
+
+		last, err := repo.LastID(context.Background(), domain.SitemapSectionTags, "go", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "rust", last)
+
+		values, _ := mt.GetStartedEvent().Command.Lookup("pipeline").Array().Values()
+		var stages []string
+		for _, value := range values {
+			elements, _ := value.Document().Elements()
+			stages = append(stages, elements[0].Key())
+		}
+		assert.Contains(t, stages, "$match")
+		assert.NotContains(t, stages, "$skip")
+	})
+
+	mt.Run("empty section", func(mt *mtest.T) {
+		repo := &SitemapRepository{collection: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		last, err := repo.LastID(context.Background(), domain.SitemapSectionBlogs, "", 10)
+		assert.NoError(t, err)
+		assert.Empty(t, last)
+	})
+}
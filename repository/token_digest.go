@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// upgradeLegacyToken converts a document still holding token in plaintext under rawField to hold its
+// digest under hashField instead, and reports whether there was such a document. Lookups by digest
+// that miss call it, so documents written before tokens were hashed are migrated on first use.
+func upgradeLegacyToken(ctx context.Context, coll *mongo.Collection, rawField, hashField, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	update := bson.M{
+		"$set":   bson.M{hashField: utils.HashToken(token)},
+		"$unset": bson.M{rawField: ""},
+	}
+	result, err := coll.UpdateOne(ctx, bson.M{rawField: token}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
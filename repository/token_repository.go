@@ -3,7 +3,8 @@ package repository
 import (
 	"context"
 	"g3-g65-bsp/domain"
-	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/database"
+	"g3-g65-bsp/utils"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -12,19 +13,60 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// RefreshTokenDTO is a Data Transfer Object for Refresh Tokens
+// RefreshTokenDTO is a Data Transfer Object for Refresh Tokens. Only the token's digest is stored;
+// Token is only set on documents written before that, until they are next used.
 type RefreshTokenDTO struct {
-	UserID    primitive.ObjectID `bson:"user_id"`
-	Token     string             `bson:"token"`
-	ExpiresAt time.Time          `bson:"expires_at"`
+	UserID     primitive.ObjectID `bson:"user_id"`
+	Token      string             `bson:"token,omitempty"`
+	TokenHash  string             `bson:"token_hash,omitempty"`
+	FamilyID   string             `bson:"family_id,omitempty"`
+	ExpiresAt  time.Time          `bson:"expires_at"`
+	ConsumedAt *time.Time         `bson:"consumed_at,omitempty"`
+	DeviceName string             `bson:"device_name,omitempty"`
+	UserAgent  string             `bson:"user_agent,omitempty"`
+	IP         string             `bson:"ip,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at,omitempty"`
+	LastUsedAt time.Time          `bson:"last_used_at,omitempty"`
+	// the access token issued along with this one
+	AccessTokenID   string    `bson:"access_token_id,omitempty"`
+	AccessExpiresAt time.Time `bson:"access_expires_at,omitempty"`
 }
 
 // ConvertToDomain converts RefreshTokenDTO to domain.RefreshToken
 func (dto *RefreshTokenDTO) ConvertToDomain() *domain.RefreshToken {
 	return &domain.RefreshToken{
-		UserID:    dto.UserID.Hex(),
-		Token:     dto.Token,
-		ExpiresAt: dto.ExpiresAt,
+		UserID:     dto.UserID.Hex(),
+		Token:      dto.Token,
+		FamilyID:   dto.FamilyID,
+		ExpiresAt:  dto.ExpiresAt,
+		ConsumedAt: dto.ConsumedAt,
+		DeviceName: dto.DeviceName,
+		UserAgent:  dto.UserAgent,
+		IP:         dto.IP,
+		CreatedAt:  dto.CreatedAt,
+		LastUsedAt: dto.LastUsedAt,
+
+		AccessTokenID:   dto.AccessTokenID,
+		AccessExpiresAt: dto.AccessExpiresAt,
+	}
+}
+
+// ConvertToSession describes the family of a live token as a session. A token stored before families
+// existed is named after its digest, as consuming it would name its family.
+func (dto *RefreshTokenDTO) ConvertToSession() *domain.Session {
+	id := dto.FamilyID
+	if id == "" {
+		id = dto.TokenHash
+	}
+	return &domain.Session{
+		ID:         id,
+		UserID:     dto.UserID.Hex(),
+		DeviceName: dto.DeviceName,
+		UserAgent:  dto.UserAgent,
+		IP:         dto.IP,
+		CreatedAt:  dto.CreatedAt,
+		LastUsedAt: dto.LastUsedAt,
+		ExpiresAt:  dto.ExpiresAt,
 	}
 }
 
@@ -32,9 +74,19 @@ func (dto *RefreshTokenDTO) ConvertToDomain() *domain.RefreshToken {
 func ConvertToDTO(token *domain.RefreshToken) *RefreshTokenDTO {
 	userID, _ := primitive.ObjectIDFromHex(token.UserID)
 	return &RefreshTokenDTO{
-		UserID:    userID,
-		Token:     token.Token,
-		ExpiresAt: token.ExpiresAt,
+		UserID:     userID,
+		TokenHash:  utils.HashToken(token.Token),
+		FamilyID:   token.FamilyID,
+		ExpiresAt:  token.ExpiresAt,
+		ConsumedAt: token.ConsumedAt,
+		DeviceName: token.DeviceName,
+		UserAgent:  token.UserAgent,
+		IP:         token.IP,
+		CreatedAt:  token.CreatedAt,
+		LastUsedAt: token.LastUsedAt,
+
+		AccessTokenID:   token.AccessTokenID,
+		AccessExpiresAt: token.AccessExpiresAt,
 	}
 }
 
@@ -46,35 +98,141 @@ type TokenRepository struct {
 	collection *mongo.Collection
 }
 
-func NewTokenRepository(db *mongo.Database) *TokenRepository {
-	coll := db.Collection("refresh_tokens")
-	index := mongo.IndexModel{
-		Keys:    bson.M{"activation_token_expiry": 1},
-		Options: options.Index().SetExpireAfterSeconds(0),
-	}
+// refreshTokenIndexes expire tokens at their expiry and back the lookups by digest, user and family.
+// Earlier versions put the TTL index on activation_token_expiry, a field refresh tokens lack, so it
+// never removed anything, and indexed the digest without making it unique; both are dropped.
+var refreshTokenIndexes = database.CollectionIndexes{
+	Collection: "refresh_tokens",
+	Indexes: []database.IndexSpec{
+		database.ExpireAt("expires_at"),
+		{
+			Name:   "token_hash_unique",
+			Keys:   bson.D{{Key: "token_hash", Value: 1}},
+			Unique: true,
+			// documents still holding a plaintext token have no digest yet
+			PartialFilter: bson.M{"token_hash": bson.M{"$exists": true}},
+		},
+		// only documents still holding a plaintext token, until they are migrated
+		{
+			Keys:          bson.D{{Key: "token", Value: 1}},
+			PartialFilter: bson.M{"token": bson.M{"$exists": true}},
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "family_id", Value: 1}}},
+	},
+	Retired: []string{"activation_token_expiry_1", "token_hash_1"},
+}
 
-	if _, err := coll.Indexes().CreateOne(context.Background(), index); err != nil {
-		infrastructure.Log.Fatalf("Failed to create TTL index: %v", err)
-	}
+func NewTokenRepository(db *mongo.Database) *TokenRepository {
+	coll := db.Collection(refreshTokenIndexes.Collection)
+	ensureIndexes(coll, refreshTokenIndexes)
 	return &TokenRepository{
 		collection: coll,
 	}
 }
 
 func (r *TokenRepository) StoreRefreshToken(ctx context.Context, refreshToken *domain.RefreshToken) error {
+	if refreshToken.FamilyID == "" {
+		refreshToken.FamilyID = primitive.NewObjectID().Hex()
+	}
 	_, err := r.collection.InsertOne(ctx, ConvertToDTO(refreshToken))
 	return err
 }
 
 // For single device logout
 func (r *TokenRepository) FindRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	result, err := r.findByHash(ctx, token)
+	if err == mongo.ErrNoDocuments {
+		if upgraded, upgradeErr := upgradeLegacyToken(ctx, r.collection, "token", "token_hash", token); upgradeErr != nil {
+			return nil, upgradeErr
+		} else if upgraded {
+			result, err = r.findByHash(ctx, token)
+		}
+	}
+	return result, err
+}
+
+func (r *TokenRepository) findByHash(ctx context.Context, token string) (*domain.RefreshToken, error) {
 	var result RefreshTokenDTO
-	err := r.collection.FindOne(ctx, bson.M{"token": token}).Decode(&result)
-	return result.ConvertToDomain(), err
+	err := r.collection.FindOne(ctx, bson.M{"token_hash": utils.HashToken(token)}).Decode(&result)
+	refreshToken := result.ConvertToDomain()
+	refreshToken.Token = token
+	return refreshToken, err
+}
+
+// ConsumeRefreshToken marks the token used in one conditional update, so of two requests presenting the
+// same token only one gets to rotate it. Used tokens are kept until they expire to recognise reuse.
+func (r *TokenRepository) ConsumeRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	consumed, err := r.consume(ctx, token)
+	if err == mongo.ErrNoDocuments {
+		if upgraded, upgradeErr := upgradeLegacyToken(ctx, r.collection, "token", "token_hash", token); upgradeErr != nil {
+			return nil, upgradeErr
+		} else if upgraded {
+			consumed, err = r.consume(ctx, token)
+		}
+	}
+	if err != mongo.ErrNoDocuments {
+		return consumed, err
+	}
+
+	used, err := r.findByHash(ctx, token)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return used, domain.ErrRefreshTokenReused
+}
+
+func (r *TokenRepository) consume(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	filter := bson.M{"token_hash": utils.HashToken(token), "consumed_at": bson.M{"$exists": false}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"consumed_at": time.Now(),
+		// a token stored before families existed starts its own, named after its digest
+		"family_id": bson.M{"$ifNull": bson.A{"$family_id", "$token_hash"}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var result RefreshTokenDTO
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result); err != nil {
+		return nil, err
+	}
+	consumed := result.ConvertToDomain()
+	consumed.Token = token
+	return consumed, nil
+}
+
+func (r *TokenRepository) RevokeFamily(ctx context.Context, familyID string) ([]*domain.RefreshToken, error) {
+	tokens, _, err := r.deleteTokens(ctx, bson.M{"family_id": familyID})
+	return tokens, err
+}
+
+// deleteTokens deletes the tokens matching filter. It returns those whose access token is still valid,
+// read just before, and how many tokens it deleted.
+func (r *TokenRepository) deleteTokens(ctx context.Context, filter bson.M) ([]*domain.RefreshToken, int64, error) {
+	live := bson.M{"$and": bson.A{filter, bson.M{"access_expires_at": bson.M{"$gt": time.Now()}}}}
+	cursor, err := r.collection.Find(ctx, live)
+	if err != nil {
+		return nil, 0, err
+	}
+	var results []RefreshTokenDTO
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
+	}
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	tokens := make([]*domain.RefreshToken, len(results))
+	for i := range results {
+		tokens[i] = results[i].ConvertToDomain()
+	}
+	return tokens, result.DeletedCount, nil
 }
 
 func (r *TokenRepository) DeleteRefreshToken(ctx context.Context, token string) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"token": token})
+	filter := bson.M{"$or": bson.A{bson.M{"token_hash": utils.HashToken(token)}, bson.M{"token": token}}}
+	_, err := r.collection.DeleteOne(ctx, filter)
 	return err
 }
 
@@ -84,3 +242,56 @@ func (r *TokenRepository) DeleteAllForUser(ctx context.Context, userID string) e
 	_, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userIDObj})
 	return err
 }
+
+// ListSessions finds the live token of each of the user's families; used tokens are kept for reuse
+// detection but no longer stand for a session
+func (r *TokenRepository) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	userIDObj, _ := primitive.ObjectIDFromHex(userID)
+	filter := bson.M{
+		"user_id":     userIDObj,
+		"consumed_at": bson.M{"$exists": false},
+		"expires_at":  bson.M{"$gt": time.Now()},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var results []RefreshTokenDTO
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	sessions := make([]*domain.Session, len(results))
+	for i := range results {
+		if results[i].Token != "" {
+			// stored in plaintext before hashing; migrate it now, so the session can be revoked by its digest
+			if _, err := upgradeLegacyToken(ctx, r.collection, "token", "token_hash", results[i].Token); err != nil {
+				return nil, err
+			}
+			results[i].TokenHash = utils.HashToken(results[i].Token)
+		}
+		sessions[i] = results[i].ConvertToSession()
+	}
+	return sessions, nil
+}
+
+// RevokeSession deletes the family only when it is the user's, so guessing another user's session ID
+// revokes nothing
+func (r *TokenRepository) RevokeSession(ctx context.Context, userID, sessionID string) ([]*domain.RefreshToken, error) {
+	userIDObj, _ := primitive.ObjectIDFromHex(userID)
+	filter := bson.M{
+		"user_id": userIDObj,
+		"$or": bson.A{
+			bson.M{"family_id": sessionID},
+			bson.M{"family_id": bson.M{"$exists": false}, "token_hash": sessionID},
+		},
+	}
+	tokens, deleted, err := r.deleteTokens(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if deleted == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+	return tokens, nil
+}
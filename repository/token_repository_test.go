@@ -6,10 +6,13 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/database"
+	"g3-g65-bsp/utils"
 )
 
 func TestTokenRepository_StoreRefreshToken(t *testing.T) {
@@ -27,6 +30,12 @@ func TestTokenRepository_StoreRefreshToken(t *testing.T) {
 
 		err := repo.StoreRefreshToken(context.Background(), token)
 		assert.NoError(t, err)
+		assert.NotEmpty(t, token.FamilyID)
+		document := mt.GetStartedEvent().Command.Lookup("documents", "0").Document()
+		assert.Equal(t, token.FamilyID, document.Lookup("family_id").StringValue())
+		assert.Equal(t, utils.HashToken("refresh-token"), document.Lookup("token_hash").StringValue())
+		_, err = document.LookupErr("token")
+		assert.Error(t, err, "the plaintext token must not be stored")
 	})
 }
 
@@ -49,3 +58,220 @@ func TestTokenRepository_FindRefreshToken(t *testing.T) {
 		assert.Equal(t, expectedToken.UserID.Hex(), result.UserID)
 	})
 }
+
+func TestTokenRepository_ConsumeRefreshToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	userID := primitive.NewObjectID()
+
+	mt.Run("first use", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		consumedAt := time.Now()
+		stored := &RefreshTokenDTO{UserID: userID, Token: "refresh-token", FamilyID: "family", ConsumedAt: &consumedAt}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: toBSOND(stored)}})
+
+		result, err := repo.ConsumeRefreshToken(context.Background(), "refresh-token")
+		assert.NoError(t, err)
+		assert.Equal(t, "family", result.FamilyID)
+		assert.Equal(t, userID.Hex(), result.UserID)
+		command := mt.GetStartedEvent().Command
+		assert.Equal(t, utils.HashToken("refresh-token"), command.Lookup("query", "token_hash").StringValue())
+		assert.False(t, command.Lookup("query", "consumed_at", "$exists").Boolean())
+	})
+
+	mt.Run("already used", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		consumedAt := time.Now().Add(-time.Minute)
+		stored := &RefreshTokenDTO{UserID: userID, Token: "refresh-token", FamilyID: "family", ConsumedAt: &consumedAt}
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(stored)),
+		)
+
+		result, err := repo.ConsumeRefreshToken(context.Background(), "refresh-token")
+		assert.Equal(t, domain.ErrRefreshTokenReused, err)
+		assert.Equal(t, "family", result.FamilyID)
+	})
+
+	mt.Run("unknown", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}},
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+		)
+
+		_, err := repo.ConsumeRefreshToken(context.Background(), "nope")
+		assert.Equal(t, domain.ErrInvalidRefreshToken, err)
+	})
+}
+
+func TestTokenRepository_ConsumeRefreshToken_Legacy(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("a plaintext token is migrated on first use", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		consumedAt := time.Now()
+		migrated := &RefreshTokenDTO{UserID: primitive.NewObjectID(), TokenHash: utils.HashToken("legacy"), FamilyID: utils.HashToken("legacy"), ConsumedAt: &consumedAt}
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: toBSOND(migrated)}},
+		)
+
+		result, err := repo.ConsumeRefreshToken(context.Background(), "legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", result.Token)
+		assert.Equal(t, utils.HashToken("legacy"), result.FamilyID)
+
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 3) {
+			upgrade := events[1].Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, "legacy", upgrade.Lookup("q", "token").StringValue())
+			assert.Equal(t, utils.HashToken("legacy"), upgrade.Lookup("u", "$set", "token_hash").StringValue())
+			_, err := upgrade.LookupErr("u", "$unset", "token")
+			assert.NoError(t, err)
+		}
+	})
+}
+
+func TestTokenRepository_RevokeFamily(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("deletes the family and returns the live access tokens", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		expiresAt := time.Now().Add(time.Minute).UTC().Truncate(time.Millisecond)
+		live := &RefreshTokenDTO{UserID: primitive.NewObjectID(), FamilyID: "family", AccessTokenID: "jti-1", AccessExpiresAt: expiresAt}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(live)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}),
+		)
+
+		tokens, err := repo.RevokeFamily(context.Background(), "family")
+		assert.NoError(t, err)
+		if assert.Len(t, tokens, 1) {
+			assert.Equal(t, "jti-1", tokens[0].AccessTokenID)
+			assert.Equal(t, expiresAt, tokens[0].AccessExpiresAt)
+		}
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 2) {
+			_, err := events[0].Command.LookupErr("filter", "$and", "1", "access_expires_at", "$gt")
+			assert.NoError(t, err, "only access tokens that have not expired are returned")
+			deletion := events[1].Command.Lookup("deletes").Array().Index(0).Value().Document()
+			assert.Equal(t, "family", deletion.Lookup("q", "family_id").StringValue())
+		}
+	})
+}
+
+func TestTokenRepository_ListSessions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	userID := primitive.NewObjectID()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	mt.Run("one session per live token", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		live := &RefreshTokenDTO{
+			UserID: userID, TokenHash: utils.HashToken("live"), FamilyID: "family", ExpiresAt: now.Add(time.Hour),
+			DeviceName: "Chrome on macOS", UserAgent: "Mozilla/5.0", IP: "203.0.113.7", CreatedAt: now.Add(-time.Hour), LastUsedAt: now,
+		}
+		beforeFamilies := &RefreshTokenDTO{UserID: userID, TokenHash: utils.HashToken("older"), ExpiresAt: now.Add(time.Hour)}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(live), toBSOND(beforeFamilies)))
+
+		sessions, err := repo.ListSessions(context.Background(), userID.Hex())
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.Session{
+			{
+				ID: "family", UserID: userID.Hex(), DeviceName: "Chrome on macOS", UserAgent: "Mozilla/5.0", IP: "203.0.113.7",
+				CreatedAt: now.Add(-time.Hour), LastUsedAt: now, ExpiresAt: now.Add(time.Hour),
+			},
+			{ID: utils.HashToken("older"), UserID: userID.Hex(), ExpiresAt: now.Add(time.Hour)},
+		}, sessions)
+		filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+		assert.False(t, filter.Lookup("consumed_at", "$exists").Boolean())
+		_, err = filter.LookupErr("expires_at", "$gt")
+		assert.NoError(t, err, "expired tokens are not sessions")
+	})
+
+	mt.Run("a plaintext token is migrated to be revocable", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		legacy := &RefreshTokenDTO{UserID: userID, Token: "legacy", ExpiresAt: now.Add(time.Hour)}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(legacy)),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+
+		sessions, err := repo.ListSessions(context.Background(), userID.Hex())
+		assert.NoError(t, err)
+		if assert.Len(t, sessions, 1) {
+			assert.Equal(t, utils.HashToken("legacy"), sessions[0].ID)
+		}
+	})
+}
+
+func TestTokenRepository_RevokeSession(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	userID := primitive.NewObjectID()
+
+	mt.Run("deletes the user's family", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		live := &RefreshTokenDTO{UserID: userID, FamilyID: "family", AccessTokenID: "jti-1", AccessExpiresAt: time.Now().Add(time.Minute)}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(live)),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+		)
+
+		tokens, err := repo.RevokeSession(context.Background(), userID.Hex(), "family")
+		assert.NoError(t, err)
+		if assert.Len(t, tokens, 1) {
+			assert.Equal(t, "jti-1", tokens[0].AccessTokenID)
+		}
+		deletion := mt.GetAllStartedEvents()[1].Command.Lookup("deletes").Array().Index(0).Value().Document()
+		assert.Equal(t, userID, deletion.Lookup("q", "user_id").ObjectID())
+		assert.Equal(t, "family", deletion.Lookup("q", "$or", "0", "family_id").StringValue())
+	})
+
+	mt.Run("another user's family is not found", func(mt *mtest.T) {
+		repo := &TokenRepository{collection: mt.Coll}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}),
+		)
+
+		_, err := repo.RevokeSession(context.Background(), userID.Hex(), "family")
+		assert.Equal(t, domain.ErrSessionNotFound, err)
+	})
+}
+
+func TestTokenRepository_Indexes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("replaces the TTL index on the wrong field", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch,
+				bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}},
+				bson.D{
+					{Key: "v", Value: 2},
+					{Key: "key", Value: bson.D{{Key: "activation_token_expiry", Value: 1}}},
+					{Key: "name", Value: "activation_token_expiry_1"},
+					{Key: "expireAfterSeconds", Value: int32(0)},
+				},
+			),
+			mtest.CreateSuccessResponse(),
+		)
+		for range refreshTokenIndexes.Indexes {
+			mt.AddMockResponses(mtest.CreateSuccessResponse())
+		}
+
+		report, err := database.EnsureIndexes(context.Background(), database.NewIndexView(mt.Coll), refreshTokenIndexes)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"activation_token_expiry_1"}, report.Dropped)
+		assert.Equal(t, []string{"expires_at_1", "token_hash_unique", "token_1", "user_id_1", "family_id_1"}, report.Created)
+		assert.Empty(t, report.Drift)
+
+		events := mt.GetAllStartedEvents()
+		assert.Equal(t, "activation_token_expiry_1", events[1].Command.Lookup("index").StringValue())
+		ttl := events[2].Command.Lookup("indexes", "0").Document()
+		assert.Equal(t, "expires_at", ttl.Lookup("key").Document().Index(0).Key())
+		assert.Equal(t, int32(0), ttl.Lookup("expireAfterSeconds").Int32())
+	})
+}
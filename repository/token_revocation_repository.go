@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/infrastructure/database"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RevokedAccessTokenDTO is a denied access token, kept until it would have expired anyway
+type RevokedAccessTokenDTO struct {
+	ID        string    `bson:"_id"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// TokenWatermarkDTO invalidates a user's access tokens issued before RevokedBefore. Once every such
+// token has expired it means nothing any more, so it is kept until ExpiresAt.
+type TokenWatermarkDTO struct {
+	UserID        string    `bson:"_id"`
+	RevokedBefore time.Time `bson:"revoked_before"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
+
+var revokedAccessTokenIndexes = database.CollectionIndexes{
+	Collection: "revoked_access_tokens",
+	Indexes:    []database.IndexSpec{database.ExpireAt("expires_at")},
+}
+
+var tokenWatermarkIndexes = database.CollectionIndexes{
+	Collection: "access_token_watermarks",
+	Indexes:    []database.IndexSpec{database.ExpireAt("expires_at")},
+}
+
+type mongoTokenRevocationRepository struct {
+	revoked      *mongo.Collection
+	watermarks   *mongo.Collection
+	accessExpiry time.Duration
+}
+
+func (r *mongoTokenRevocationRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	update := bson.M{"$max": bson.M{"expires_at": expiresAt}}
+	_, err := r.revoked.UpdateOne(ctx, bson.M{"_id": tokenID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// IsAccessTokenRevoked skips expired entries, which the TTL monitor only removes once a minute
+func (r *mongoTokenRevocationRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	filter := bson.M{"_id": tokenID, "expires_at": bson.M{"$gt": time.Now()}}
+	err := r.revoked.FindOne(ctx, filter).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *mongoTokenRevocationRepository) RevokeTokensIssuedBefore(ctx context.Context, userID string, t time.Time) error {
+	update := bson.M{"$max": bson.M{"revoked_before": t, "expires_at": t.Add(r.accessExpiry)}}
+	_, err := r.watermarks.UpdateOne(ctx, bson.M{"_id": userID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoTokenRevocationRepository) TokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	var watermark TokenWatermarkDTO
+	err := r.watermarks.FindOne(ctx, bson.M{"_id": userID}).Decode(&watermark)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return watermark.RevokedBefore, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// revocationRecheckInterval bounds how long a cached "not revoked" answer is trusted. The cache is
+// per instance, so a revocation made on another instance is only seen here once it lapses.
+const revocationRecheckInterval = 30 * time.Second
+
+// cachedTokenRevocationRepository answers revocation checks, made on every authenticated request, from
+// the cache and falls back to MongoDB, where revocations are kept for every instance to see
+type cachedTokenRevocationRepository struct {
+	repo         domain.TokenRevocationRepository
+	cache        CacheService
+	accessExpiry time.Duration
+}
+
+// NewTokenRevocationRepository keeps revocations in MongoDB for as long as accessExpiry, after which
+// every access token they could deny has expired anyway
+func NewTokenRevocationRepository(db *mongo.Database, cache CacheService, accessExpiry time.Duration) domain.TokenRevocationRepository {
+	revoked := db.Collection(revokedAccessTokenIndexes.Collection)
+	ensureIndexes(revoked, revokedAccessTokenIndexes)
+	watermarks := db.Collection(tokenWatermarkIndexes.Collection)
+	ensureIndexes(watermarks, tokenWatermarkIndexes)
+
+	return &cachedTokenRevocationRepository{
+		repo:         &mongoTokenRevocationRepository{revoked: revoked, watermarks: watermarks, accessExpiry: accessExpiry},
+		cache:        cache,
+		accessExpiry: accessExpiry,
+	}
+}
+
+func revokedAccessTokenCacheKey(tokenID string) string {
+	return "revoked_access_token:" + tokenID
+}
+
+func tokenWatermarkCacheKey(userID string) string {
+	return "access_token_watermark:" + userID
+}
+
+func (r *cachedTokenRevocationRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if err := r.repo.RevokeAccessToken(ctx, tokenID, expiresAt); err != nil {
+		return err
+	}
+	// a token that has expired is refused anyway, and the cache would keep a negative duration forever
+	if ttl := time.Until(expiresAt); ttl > 0 {
+		r.cache.Set(revokedAccessTokenCacheKey(tokenID), true, ttl)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked caches a revocation for as long as the token could live, since it is never
+// lifted, but a token found valid only for revocationRecheckInterval
+func (r *cachedTokenRevocationRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	key := revokedAccessTokenCacheKey(tokenID)
+	if cached, found := r.cache.Get(key); found {
+		if revoked, ok := cached.(bool); ok {
+			return revoked, nil
+		}
+	}
+	revoked, err := r.repo.IsAccessTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return false, err
+	}
+	if revoked {
+		r.cache.Set(key, true, r.accessExpiry)
+	} else {
+		r.cache.Set(key, false, revocationRecheckInterval)
+	}
+	return revoked, nil
+}
+
+func (r *cachedTokenRevocationRepository) RevokeTokensIssuedBefore(ctx context.Context, userID string, t time.Time) error {
+	if err := r.repo.RevokeTokensIssuedBefore(ctx, userID, t); err != nil {
+		return err
+	}
+	// an earlier watermark read from the database may be cached; the new one only moves it forward
+	r.cache.Delete(tokenWatermarkCacheKey(userID))
+	return nil
+}
+
+func (r *cachedTokenRevocationRepository) TokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	key := tokenWatermarkCacheKey(userID)
+	if cached, found := r.cache.Get(key); found {
+		if watermark, ok := cached.(time.Time); ok {
+			return watermark, nil
+		}
+	}
+	watermark, err := r.repo.TokensRevokedBefore(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	r.cache.Set(key, watermark, revocationRecheckInterval)
+	return watermark, nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"g3-g65-bsp/infrastructure/cache"
+)
+
+func TestTokenRevocationRepository_RevokeAccessToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("denies the token until it expires", func(mt *mtest.T) {
+		repo := &mongoTokenRevocationRepository{revoked: mt.Coll, watermarks: mt.Coll, accessExpiry: 15 * time.Minute}
+		expiresAt := time.Now().Add(15 * time.Minute).UTC().Truncate(time.Millisecond)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}, {Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "jti"}}}}})
+
+		assert.NoError(t, repo.RevokeAccessToken(context.Background(), "jti", expiresAt))
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "jti", update.Lookup("q", "_id").StringValue())
+		assert.Equal(t, expiresAt, update.Lookup("u", "$max", "expires_at").Time().UTC())
+		assert.True(t, update.Lookup("upsert").Boolean())
+	})
+}
+
+func TestTokenRevocationRepository_IsAccessTokenRevoked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("revoked", func(mt *mtest.T) {
+		repo := &mongoTokenRevocationRepository{revoked: mt.Coll, watermarks: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&RevokedAccessTokenDTO{ID: "jti", ExpiresAt: time.Now().Add(time.Minute)})))
+
+		revoked, err := repo.IsAccessTokenRevoked(context.Background(), "jti")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		_, err = mt.GetStartedEvent().Command.Lookup("filter").Document().LookupErr("expires_at", "$gt")
+		assert.NoError(t, err, "expired entries the TTL monitor has not removed yet do not count")
+	})
+
+	mt.Run("not revoked", func(mt *mtest.T) {
+		repo := &mongoTokenRevocationRepository{revoked: mt.Coll, watermarks: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		revoked, err := repo.IsAccessTokenRevoked(context.Background(), "jti")
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+}
+
+func TestTokenRevocationRepository_Watermark(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("expires with the last token it covers", func(mt *mtest.T) {
+		repo := &mongoTokenRevocationRepository{revoked: mt.Coll, watermarks: mt.Coll, accessExpiry: 15 * time.Minute}
+		t0 := time.Date(2026, 10, 17, 12, 0, 0, 1500000, time.UTC)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		assert.NoError(t, repo.RevokeTokensIssuedBefore(context.Background(), "user-1", t0))
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "user-1", update.Lookup("q", "_id").StringValue())
+		assert.Equal(t, t0.Truncate(time.Millisecond), update.Lookup("u", "$max", "revoked_before").Time().UTC())
+		assert.Equal(t, t0.Add(15*time.Minute).Truncate(time.Millisecond), update.Lookup("u", "$max", "expires_at").Time().UTC())
+	})
+
+	mt.Run("none", func(mt *mtest.T) {
+		repo := &mongoTokenRevocationRepository{revoked: mt.Coll, watermarks: mt.Coll}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		watermark, err := repo.TokensRevokedBefore(context.Background(), "user-1")
+		assert.NoError(t, err)
+		assert.True(t, watermark.IsZero())
+	})
+}
+
+func TestCachedTokenRevocationRepository(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	newRepo := func(mt *mtest.T) *cachedTokenRevocationRepository {
+		return &cachedTokenRevocationRepository{
+			repo:         &mongoTokenRevocationRepository{revoked: mt.Coll, watermarks: mt.Coll, accessExpiry: 15 * time.Minute},
+			cache:        cache.NewInMemoryCache(time.Minute, time.Minute),
+			accessExpiry: 15 * time.Minute,
+		}
+	}
+
+	mt.Run("answers repeated checks from the cache", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
+
+		for range 3 {
+			revoked, err := repo.IsAccessTokenRevoked(context.Background(), "jti")
+			assert.NoError(t, err)
+			assert.False(t, revoked)
+		}
+		assert.Len(t, mt.GetAllStartedEvents(), 1)
+	})
+
+	mt.Run("a revocation made here is seen at once", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+
+		revoked, _ := repo.IsAccessTokenRevoked(context.Background(), "jti")
+		assert.False(t, revoked)
+		assert.NoError(t, repo.RevokeAccessToken(context.Background(), "jti", time.Now().Add(time.Minute)))
+		revoked, err := repo.IsAccessTokenRevoked(context.Background(), "jti")
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+	})
+
+	mt.Run("an expired token's revocation is not cached", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		assert.NoError(t, repo.RevokeAccessToken(context.Background(), "jti", time.Now().Add(-time.Minute)))
+		_, found := repo.cache.Get(revokedAccessTokenCacheKey("jti"))
+		assert.False(t, found)
+	})
+
+	mt.Run("a new watermark replaces the cached one", func(mt *mtest.T) {
+		repo := newRepo(mt)
+		revokedBefore := time.Now().UTC().Truncate(time.Millisecond)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(&TokenWatermarkDTO{UserID: "user-1", RevokedBefore: revokedBefore})),
+		)
+
+		watermark, _ := repo.TokensRevokedBefore(context.Background(), "user-1")
+		assert.True(t, watermark.IsZero())
+		assert.NoError(t, repo.RevokeTokensIssuedBefore(context.Background(), "user-1", revokedBefore))
+		watermark, err := repo.TokensRevokedBefore(context.Background(), "user-1")
+		assert.NoError(t, err)
+		assert.Equal(t, revokedBefore, watermark.UTC())
+	})
+}
@@ -4,16 +4,17 @@ import (
 	"context"
 	"errors"
 	"g3-g65-bsp/domain"
-	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/database"
+	"g3-g65-bsp/utils"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// UnactivatedUserDTO represents a user who has not yet activated their account
+// UnactivatedUserDTO represents a user who has not yet activated their account. Only the activation
+// token's digest is stored; ActivationToken is only set on documents written before that.
 type UnactivatedUserDTO struct {
 	ID                    primitive.ObjectID `bson:"_id,omitempty"`
 	Username              string             `bson:"username"`
@@ -21,6 +22,7 @@ type UnactivatedUserDTO struct {
 	Password              string             `bson:"password"`
 	Activated             bool               `bson:"activated"`
 	ActivationToken       string             `bson:"activation_token,omitempty"`
+	ActivationTokenHash   string             `bson:"activation_token_hash,omitempty"`
 	ActivationTokenExpiry *time.Time         `bson:"activation_token_expiry,omitempty"`
 	CreatedAt             time.Time          `bson:"created_at"`
 	UpdatedAt             time.Time          `bson:"updated_at"`
@@ -28,13 +30,17 @@ type UnactivatedUserDTO struct {
 
 // ConvertToDomain converts UnactivatedUserDTO to domain.UnactivatedUser
 func (dto *UnactivatedUserDTO) ConvertToUnactivatedUserDomain() *domain.UnactivatedUser {
+	tokenHash := dto.ActivationTokenHash
+	if tokenHash == "" && dto.ActivationToken != "" {
+		tokenHash = utils.HashToken(dto.ActivationToken)
+	}
 	return &domain.UnactivatedUser{
 		ID:                    dto.ID.Hex(),
 		Username:              dto.Username,
 		Email:                 dto.Email,
 		Password:              dto.Password,
 		Activated:             dto.Activated,
-		ActivationToken:       dto.ActivationToken,
+		ActivationTokenHash:   tokenHash,
 		ActivationTokenExpiry: dto.ActivationTokenExpiry,
 		CreatedAt:             dto.CreatedAt,
 		UpdatedAt:             dto.UpdatedAt,
@@ -44,13 +50,17 @@ func (dto *UnactivatedUserDTO) ConvertToUnactivatedUserDomain() *domain.Unactiva
 // ConvertToDTO converts domain.UnactivatedUser to UnactivatedUserDTO
 func ConvertToUnactivatedUserDTO(u *domain.UnactivatedUser) *UnactivatedUserDTO {
 	userID, _ := primitive.ObjectIDFromHex(u.ID)
+	tokenHash := u.ActivationTokenHash
+	if u.ActivationToken != "" {
+		tokenHash = utils.HashToken(u.ActivationToken)
+	}
 	return &UnactivatedUserDTO{
 		ID:                    userID,
 		Username:              u.Username,
 		Email:                 u.Email,
 		Password:              u.Password,
 		Activated:             u.Activated,
-		ActivationToken:       u.ActivationToken,
+		ActivationTokenHash:   tokenHash,
 		ActivationTokenExpiry: u.ActivationTokenExpiry,
 		CreatedAt:             u.CreatedAt,
 		UpdatedAt:             u.UpdatedAt,
@@ -61,17 +71,18 @@ type UnactiveUserRepo struct {
 	collection *mongo.Collection
 }
 
-func NewUnactiveUserRepo(db *mongo.Database) domain.UnactiveUserRepo {
-	coll := db.Collection("unactivated_users")
-
-	index := mongo.IndexModel{
-		Keys:    bson.M{"activation_token_expiry": 1},
-		Options: options.Index().SetExpireAfterSeconds(0),
-	}
+// unactivatedUserIndexes remove registrations not activated in time and back the lookups by email
+var unactivatedUserIndexes = database.CollectionIndexes{
+	Collection: "unactivated_users",
+	Indexes: []database.IndexSpec{
+		database.ExpireAt("activation_token_expiry"),
+		{Keys: bson.D{{Key: "email", Value: 1}}},
+	},
+}
 
-	if _, err := coll.Indexes().CreateOne(context.Background(), index); err != nil {
-		infrastructure.Log.Fatalf("Failed to create TTL index: %v", err)
-	}
+func NewUnactiveUserRepo(db *mongo.Database) domain.UnactiveUserRepo {
+	coll := db.Collection(unactivatedUserIndexes.Collection)
+	ensureIndexes(coll, unactivatedUserIndexes)
 
 	return &UnactiveUserRepo{
 		collection: coll,
@@ -93,6 +104,12 @@ func (at *UnactiveUserRepo) FindByEmailUnactive(ctx context.Context, email strin
 		}
 		return nil, err
 	}
+	if user.ActivationToken != "" {
+		// written before tokens were hashed; store the digest in its place now that it is read
+		if _, err := upgradeLegacyToken(ctx, at.collection, "activation_token", "activation_token_hash", user.ActivationToken); err != nil {
+			return nil, err
+		}
+	}
 	return user.ConvertToUnactivatedUserDomain(), nil
 }
 
@@ -106,10 +123,11 @@ func (at *UnactiveUserRepo) UpdateActiveToken(ctx context.Context, email, token
 	filter := bson.M{"email": email}
 	update := bson.M{
 		"$set": bson.M{
-			"activation_token":         token,
+			"activation_token_hash":    utils.HashToken(token),
 			"activation_token_expiry":  expiry,
 			"updated_at":               time.Now(),
 		},
+		"$unset": bson.M{"activation_token": ""},
 	}
 	_, err := at.collection.UpdateOne(ctx, filter, update)
 	return err
@@ -5,10 +5,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/utils"
 )
 
 func TestUnactiveUserRepo_CreateUnactiveUser(t *testing.T) {
@@ -17,15 +19,20 @@ func TestUnactiveUserRepo_CreateUnactiveUser(t *testing.T) {
 	mt.Run("success", func(mt *mtest.T) {
 		repo := &UnactiveUserRepo{collection: mt.Coll}
 		user := &domain.UnactivatedUser{
-			Username: "testuser",
-			Email:    "test@example.com",
-			Password: "password",
+			Username:        "testuser",
+			Email:           "test@example.com",
+			Password:        "password",
+			ActivationToken: "activation-token",
 		}
 
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
 
 		err := repo.CreateUnactiveUser(context.Background(), user)
 		assert.NoError(t, err)
+		document := mt.GetStartedEvent().Command.Lookup("documents", "0").Document()
+		assert.Equal(t, utils.HashToken("activation-token"), document.Lookup("activation_token_hash").StringValue())
+		_, err = document.LookupErr("activation_token")
+		assert.Error(t, err, "the plaintext token must not be stored")
 	})
 }
 
@@ -47,4 +54,24 @@ func TestUnactiveUserRepo_FindByEmailUnactive(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expectedUser.Username, user.Username)
 	})
+
+	mt.Run("stored in plaintext before hashing", func(mt *mtest.T) {
+		repo := &UnactiveUserRepo{collection: mt.Coll}
+		legacy := &UnactivatedUserDTO{ID: primitive.NewObjectID(), Email: "test@example.com", ActivationToken: "legacy"}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, toBSOND(legacy)),
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+		)
+
+		user, err := repo.FindByEmailUnactive(context.Background(), "test@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, utils.HashToken("legacy"), user.ActivationTokenHash)
+		assert.Empty(t, user.ActivationToken)
+		events := mt.GetAllStartedEvents()
+		if assert.Len(t, events, 2) {
+			upgrade := events[1].Command.Lookup("updates").Array().Index(0).Value().Document()
+			assert.Equal(t, utils.HashToken("legacy"), upgrade.Lookup("u", "$set", "activation_token_hash").StringValue())
+		}
+	})
 }
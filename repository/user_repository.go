@@ -31,6 +31,9 @@ type UserProfileDTO struct {
 	Bio               string `bson:"bio,omitempty"`
 	ProfilePictureURL string `bson:"profile_picture_url,omitempty"`
 	ContactInfo       string `bson:"contact_information,omitempty"`
+	// the follow counts are only ever changed with $inc
+	FollowerCount  int `bson:"followers_count,omitempty"`
+	FollowingCount int `bson:"following_count,omitempty"`
 }
 
 // ConvertToDomain converts UserDTO to domain.User
@@ -46,6 +49,8 @@ func (dto *UserDTO) ConvertToUserDomain() *domain.User {
 			Bio:               dto.Profile.Bio,
 			ProfilePictureURL: dto.Profile.ProfilePictureURL,
 			ContactInfo:       dto.Profile.ContactInfo,
+			FollowerCount:     dto.Profile.FollowerCount,
+			FollowingCount:    dto.Profile.FollowingCount,
 		},
 		CreatedAt: dto.CreatedAt,
 		UpdatedAt: dto.UpdatedAt,
@@ -117,20 +122,25 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*domain
 
 func (r *UserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
 	var user UserDTO
-	idObj, _ := primitive.ObjectIDFromHex(id)
-	err := r.collection.FindOne(ctx, bson.M{"_id": idObj}).Decode(&user)
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	err = r.collection.FindOne(ctx, bson.M{"_id": idObj}).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		return nil, domain.ErrUserNotFound
+	}
 	return user.ConvertToUserDomain(), err
 }
 
 func (mr *UserRepository) UpdateUserProfile(ctx context.Context, bio string, contactInfo string, imagePath string, Email string) error {
 	filter := bson.M{"email": Email}
 	update := bson.M{
+		// field by field, so the follow counts kept in the profile survive
 		"$set": bson.M{
-			"profile": bson.M{
-				"bio":                 bio,
-				"profile_picture_url": imagePath,
-				"contact_information": contactInfo,
-			},
+			"profile.bio":                 bio,
+			"profile.profile_picture_url": imagePath,
+			"profile.contact_information": contactInfo,
 		},
 	}
 
@@ -197,6 +207,26 @@ func (mr *UserRepository) UpdateUserPassword(ctx context.Context, email string,
 	}
 }
 
+// IncrementFollowCounts adds to the user's follower and following counts
+func (r *UserRepository) IncrementFollowCounts(ctx context.Context, userID string, followers, following int) error {
+	oid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+	update := bson.M{"$inc": bson.M{
+		"profile.followers_count": followers,
+		"profile.following_count": following,
+	}}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": oid}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
 func (ur *UserRepository) GetAllUsers(ctx context.Context, page, limit int) ([]domain.User, int64, error) {
 	setskip := int64((page - 1) * limit)
 	setlimit := int64(limit)
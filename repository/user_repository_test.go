@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
@@ -86,6 +87,53 @@ func TestUserRepository_FindById(t *testing.T) {
 		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch))
 
 		_, err := repo.FindByID(context.Background(), id.Hex())
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+
+	mt.Run("malformed id", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll}
+
+		_, err := repo.FindByID(context.Background(), "not-an-id")
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+		assert.Empty(t, mt.GetAllStartedEvents())
+	})
+}
+
+func TestUserRepository_UpdateUserProfile(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("follow counts are left alone", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.UpdateUserProfile(context.Background(), "bio", "contact", "pic.png", "test@example.com")
+		assert.NoError(t, err)
+		set := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$set").Document()
+		assert.Equal(t, "bio", set.Lookup("profile.bio").StringValue())
+		_, err = set.LookupErr("profile")
 		assert.Error(t, err)
 	})
 }
+
+func TestUserRepository_IncrementFollowCounts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.IncrementFollowCounts(context.Background(), primitive.NewObjectID().Hex(), 1, 0)
+		assert.NoError(t, err)
+		inc := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("u", "$inc").Document()
+		assert.Equal(t, int32(1), inc.Lookup("profile.followers_count").Int32())
+		assert.Equal(t, int32(0), inc.Lookup("profile.following_count").Int32())
+	})
+
+	mt.Run("unknown user", func(mt *mtest.T) {
+		repo := &UserRepository{collection: mt.Coll}
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.IncrementFollowCounts(context.Background(), primitive.NewObjectID().Hex(), 0, -1)
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"g3-g65-bsp/domain"
@@ -19,6 +20,8 @@ type AuthUsecase struct {
 	unactiveRepo domain.UnactiveUserRepo
 	emailService *email.EmailService
 	passRepo     domain.PasswordResetRepository
+	auditRepo    domain.AuditRepository
+	revocations  domain.TokenRevocationRepository
 }
 
 func NewAuthUsecase(
@@ -28,6 +31,8 @@ func NewAuthUsecase(
 	ar domain.UnactiveUserRepo,
 	es *email.EmailService,
 	passRepo domain.PasswordResetRepository,
+	auditRepo domain.AuditRepository,
+	revocations domain.TokenRevocationRepository,
 ) *AuthUsecase {
 	return &AuthUsecase{
 		userRepo:     ur,
@@ -37,6 +42,8 @@ func NewAuthUsecase(
 		unactiveRepo: ar,
 		emailService: es,
 		passRepo:     passRepo,
+		auditRepo:    auditRepo,
+		revocations:  revocations,
 	}
 }
 
@@ -99,7 +106,7 @@ func (uc *AuthUsecase) Login(ctx context.Context, email, password string) (strin
 		return "", "", 0, nil, errors.New("invalid credentials")
 	}
 
-	accessToken, err := uc.jwt.GenerateAccessToken(user.ID, user.Role)
+	accessToken, accessClaims, err := uc.jwt.IssueAccessToken(user.ID, user.Role)
 	if err != nil {
 		return "", "", 0, nil, errors.New("failed to generate token")
 	}
@@ -111,11 +118,7 @@ func (uc *AuthUsecase) Login(ctx context.Context, email, password string) (strin
 
 	// Store the refresh token
 	expiry := time.Now().Add(uc.jwt.RefreshExpiry)
-	newRefreshTokenModel := &domain.RefreshToken{
-		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: expiry,
-	}
+	newRefreshTokenModel := newSessionToken(ctx, user.ID, refreshToken, expiry, accessClaims)
 
 	if err := uc.tokenRepo.StoreRefreshToken(ctx, newRefreshTokenModel); err != nil {
 		return "", "", 0, nil, errors.New("failed to store refresh token")
@@ -130,7 +133,7 @@ func (uc *AuthUsecase) ActivateUser(ctx context.Context, token, email string) er
 		return errors.New("user not found")
 	}
 
-	if unActiveUser.ActivationToken != token {
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(token)), []byte(unActiveUser.ActivationTokenHash)) != 1 {
 		return errors.New("invalid activation token")
 	}
 
@@ -245,38 +248,137 @@ func (uc *AuthUsecase) ResetPassword(c context.Context, token, newPassword strin
 	return nil
 }
 
+// RefreshTokens exchanges a refresh token for a new access token and a new refresh token of the same
+// family; the old refresh token cannot be used again. Presenting a used token means it has been copied,
+// so every token of its family is revoked, logging out both the thief and the owner.
 func (uc *AuthUsecase) RefreshTokens(ctx context.Context, refreshToken string) (string, string, int, error) {
-	// 1. Validate and delete the old refresh token
-	refreshTokenModel, err := uc.tokenRepo.FindRefreshToken(ctx, refreshToken)
+	current, err := uc.tokenRepo.ConsumeRefreshToken(ctx, refreshToken)
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		if err := uc.revokeReusedFamily(ctx, current); err != nil {
+			return "", "", 0, err
+		}
+		return "", "", 0, domain.ErrRefreshTokenReused
+	}
 	if err != nil {
-		return "", "", 0, errors.New("invalid refresh token")
+		return "", "", 0, domain.ErrInvalidRefreshToken
 	}
 
-	if refreshTokenModel.ExpiresAt.Before(time.Now()) {
-		return "", "", 0, errors.New("refresh token has expired")
+	if current.ExpiresAt.Before(time.Now()) {
+		return "", "", 0, domain.ErrRefreshTokenExpired
 	}
 
-	// 2. Get user details
-	user, err := uc.userRepo.FindByID(ctx, refreshTokenModel.UserID)
+	user, err := uc.userRepo.FindByID(ctx, current.UserID)
 	if err != nil {
 		return "", "", 0, errors.New("user not found")
 	}
 
-	accessTokenNew, err := uc.jwt.GenerateAccessToken(user.ID, user.Role)
+	accessTokenNew, accessClaims, err := uc.jwt.IssueAccessToken(user.ID, user.Role)
 	if err != nil {
 		return "", "", 0, errors.New("failed to generate access token")
 	}
 
-	return refreshToken, accessTokenNew, int(uc.jwt.AccessExpiry.Seconds()), nil
+	refreshTokenNew, err := uc.jwt.GenerateRefreshToken()
+	if err != nil {
+		return "", "", 0, errors.New("failed to generate refresh token")
+	}
+	successor := newSessionToken(ctx, user.ID, refreshTokenNew, time.Now().Add(uc.jwt.RefreshExpiry), accessClaims)
+	successor.FamilyID = current.FamilyID
+	successor.DeviceName = current.DeviceName
+	if !current.CreatedAt.IsZero() {
+		successor.CreatedAt = current.CreatedAt
+	}
+	if successor.UserAgent == "" {
+		successor.UserAgent = current.UserAgent
+	}
+	if err := uc.tokenRepo.StoreRefreshToken(ctx, successor); err != nil {
+		return "", "", 0, errors.New("failed to store refresh token")
+	}
+
+	return accessTokenNew, refreshTokenNew, int(uc.jwt.AccessExpiry.Seconds()), nil
+}
+
+// newSessionToken starts a session for the device the request came from, as recorded in ctx, holding
+// on to the access token issued with it
+func newSessionToken(ctx context.Context, userID, token string, expiresAt time.Time, access *auth.Claims) *domain.RefreshToken {
+	now := time.Now()
+	userAgent := domain.UserAgent(ctx)
+	return &domain.RefreshToken{
+		UserID:          userID,
+		Token:           token,
+		ExpiresAt:       expiresAt,
+		DeviceName:      utils.DeviceName(userAgent),
+		UserAgent:       userAgent,
+		IP:              domain.ClientIP(ctx),
+		CreatedAt:       now,
+		LastUsedAt:      now,
+		AccessTokenID:   access.ID,
+		AccessExpiresAt: access.ExpiresAt.Time,
+	}
+}
+
+// revokeReusedFamily cuts off every token descended from the reused one's login, along with the access
+// tokens issued with them, and records the reuse
+func (uc *AuthUsecase) revokeReusedFamily(ctx context.Context, reused *domain.RefreshToken) error {
+	revoked, err := uc.tokenRepo.RevokeFamily(ctx, reused.FamilyID)
+	if err != nil {
+		return err
+	}
+	if err := uc.revokeAccessTokens(ctx, revoked); err != nil {
+		return err
+	}
+	details := map[string]string{"family_id": reused.FamilyID}
+	if ip := domain.ClientIP(ctx); ip != "" {
+		details["client_ip"] = ip
+	}
+	return uc.auditRepo.Record(ctx, &domain.AuditEvent{
+		Type:    domain.AuditRefreshTokenReuse,
+		UserID:  reused.UserID,
+		Details: details,
+	})
 }
 
 // Logout (single device)
-func (uc *AuthUsecase) Logout(ctx context.Context, refreshToken string) error {
-	err := uc.tokenRepo.DeleteRefreshToken(ctx, refreshToken)
-	return err
+func (uc *AuthUsecase) Logout(ctx context.Context, refreshToken, accessTokenID string, accessExpiresAt time.Time) error {
+	if err := uc.tokenRepo.DeleteRefreshToken(ctx, refreshToken); err != nil {
+		return err
+	}
+	if accessTokenID == "" {
+		return nil
+	}
+	return uc.revocations.RevokeAccessToken(ctx, accessTokenID, accessExpiresAt)
 }
 
-// LogoutAll (all devices)
+// LogoutAll (all devices); access tokens already handed out stop working too, not only the refresh tokens
 func (uc *AuthUsecase) LogoutAll(ctx context.Context, userID string) error {
-	return uc.tokenRepo.DeleteAllForUser(ctx, userID)
+	if err := uc.tokenRepo.DeleteAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return uc.revocations.RevokeTokensIssuedBefore(ctx, userID, time.Now())
+}
+
+func (uc *AuthUsecase) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	return uc.tokenRepo.ListSessions(ctx, userID)
+}
+
+// RevokeSession logs one device out by revoking its refresh token family and the access tokens issued
+// with it
+func (uc *AuthUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	revoked, err := uc.tokenRepo.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	return uc.revokeAccessTokens(ctx, revoked)
+}
+
+// revokeAccessTokens denies the access tokens issued along with the refresh tokens, until they expire
+func (uc *AuthUsecase) revokeAccessTokens(ctx context.Context, tokens []*domain.RefreshToken) error {
+	for _, token := range tokens {
+		if token.AccessTokenID == "" {
+			continue
+		}
+		if err := uc.revocations.RevokeAccessToken(ctx, token.AccessTokenID, token.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
 }
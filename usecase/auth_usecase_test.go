@@ -6,6 +6,7 @@ import (
 	"g3-g65-bsp/domain"
 	"g3-g65-bsp/infrastructure/auth"
 	"g3-g65-bsp/infrastructure/email"
+	"g3-g65-bsp/utils"
 	"testing"
 	"time"
 
@@ -68,6 +69,11 @@ func (m *MockUserRepository) GetAllUsers(ctx context.Context, page int, limit in
 	return args.Get(0).([]domain.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockUserRepository) IncrementFollowCounts(ctx context.Context, userID string, followers, following int) error {
+	args := m.Called(ctx, userID, followers, following)
+	return args.Error(0)
+}
+
 // MockUnactiveUserRepo mocks domain.UnactiveUserRepo
 type MockUnactiveUserRepo struct {
 	mock.Mock
@@ -114,6 +120,22 @@ func (m *MockTokenRepository) FindRefreshToken(ctx context.Context, token string
 	return args.Get(0).(*domain.RefreshToken), args.Error(1)
 }
 
+func (m *MockTokenRepository) ConsumeRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeFamily(ctx context.Context, familyID string) ([]*domain.RefreshToken, error) {
+	args := m.Called(ctx, familyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RefreshToken), args.Error(1)
+}
+
 func (m *MockTokenRepository) DeleteRefreshToken(ctx context.Context, token string) error {
 	args := m.Called(ctx, token)
 	return args.Error(0)
@@ -124,6 +146,22 @@ func (m *MockTokenRepository) DeleteAllForUser(ctx context.Context, userID strin
 	return args.Error(0)
 }
 
+func (m *MockTokenRepository) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Session), args.Error(1)
+}
+
+func (m *MockTokenRepository) RevokeSession(ctx context.Context, userID, sessionID string) ([]*domain.RefreshToken, error) {
+	args := m.Called(ctx, userID, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RefreshToken), args.Error(1)
+}
+
 // MockPasswordResetRepository mocks domain.PasswordResetRepository
 type MockPasswordResetRepository struct {
 	mock.Mock
@@ -155,7 +193,7 @@ func TestAuthUsecase_Register(t *testing.T) {
 	t.Setenv("SMTP_PORT", "587")
 	emailService := email.NewEmailService()
 
-	uc := NewAuthUsecase(mockUserRepo, nil, nil, mockUnactiveRepo, emailService, nil)
+	uc := NewAuthUsecase(mockUserRepo, nil, nil, mockUnactiveRepo, emailService, nil, nil, nil)
 
 	ctx := context.Background()
 	emailAddr := "test@example.com"
@@ -198,7 +236,7 @@ func TestAuthUsecase_Login(t *testing.T) {
 	mockUnactiveRepo := new(MockUnactiveUserRepo)
 	mockTokenRepo := new(MockTokenRepository)
 	jwt := auth.NewJWT("secret", "refresh_secret", time.Hour, time.Hour)
-	uc := NewAuthUsecase(mockUserRepo, mockTokenRepo, jwt, mockUnactiveRepo, nil, nil)
+	uc := NewAuthUsecase(mockUserRepo, mockTokenRepo, jwt, mockUnactiveRepo, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	emailAddr := "test@example.com"
@@ -224,6 +262,22 @@ func TestAuthUsecase_Login(t *testing.T) {
 		mockTokenRepo.AssertExpectations(t)
 	})
 
+	t.Run("records the device", func(t *testing.T) {
+		ctx := domain.WithUserAgent(domain.WithClientIP(context.Background(), "203.0.113.7"), "curl/8.6.0")
+		mockUnactiveRepo.On("FindByEmailUnactive", ctx, emailAddr).Return(nil, errors.New("not found")).Once()
+		mockUserRepo.On("FindByEmail", ctx, emailAddr).Return(user, nil).Once()
+		var stored *domain.RefreshToken
+		mockTokenRepo.On("StoreRefreshToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.RefreshToken) }).Return(nil).Once()
+
+		_, _, _, _, err := uc.Login(ctx, emailAddr, password)
+		assert.NoError(t, err)
+		assert.Equal(t, "curl", stored.DeviceName)
+		assert.Equal(t, "curl/8.6.0", stored.UserAgent)
+		assert.Equal(t, "203.0.113.7", stored.IP)
+		assert.Equal(t, stored.CreatedAt, stored.LastUsedAt)
+	})
+
 	t.Run("user not activated", func(t *testing.T) {
 		mockUnactiveRepo.On("FindByEmailUnactive", ctx, emailAddr).Return(&domain.UnactivatedUser{}, nil).Once()
 		_, _, _, _, err := uc.Login(ctx, emailAddr, password)
@@ -240,3 +294,236 @@ func TestAuthUsecase_Login(t *testing.T) {
 		assert.Equal(t, "invalid credentials", err.Error())
 	})
 }
+
+func TestAuthUsecase_ActivateUser(t *testing.T) {
+	ctx := context.Background()
+	expiry := time.Now().Add(time.Hour)
+	pending := &domain.UnactivatedUser{Username: "testuser", Email: "test@example.com", ActivationTokenHash: utils.HashToken("activation-token"), ActivationTokenExpiry: &expiry}
+
+	t.Run("the token matches the stored digest", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		mockUnactiveRepo := new(MockUnactiveUserRepo)
+		uc := NewAuthUsecase(mockUserRepo, nil, nil, mockUnactiveRepo, nil, nil, nil, nil)
+		mockUnactiveRepo.On("FindByEmailUnactive", ctx, "test@example.com").Return(pending, nil).Once()
+		mockUserRepo.On("Create", ctx, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+		mockUnactiveRepo.On("DeleteUnactiveUser", ctx, "test@example.com").Return(nil).Once()
+
+		assert.NoError(t, uc.ActivateUser(ctx, "activation-token", "test@example.com"))
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("the digest itself is not a valid token", func(t *testing.T) {
+		mockUnactiveRepo := new(MockUnactiveUserRepo)
+		uc := NewAuthUsecase(nil, nil, nil, mockUnactiveRepo, nil, nil, nil, nil)
+		mockUnactiveRepo.On("FindByEmailUnactive", ctx, "test@example.com").Return(pending, nil).Once()
+
+		err := uc.ActivateUser(ctx, pending.ActivationTokenHash, "test@example.com")
+		assert.EqualError(t, err, "invalid activation token")
+	})
+}
+
+// MockAuditRepository mocks domain.AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, event *domain.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+// MockTokenRevocationRepository mocks domain.TokenRevocationRepository
+type MockTokenRevocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockTokenRevocationRepository) RevokeAccessToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	args := m.Called(ctx, tokenID, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockTokenRevocationRepository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	args := m.Called(ctx, tokenID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockTokenRevocationRepository) RevokeTokensIssuedBefore(ctx context.Context, userID string, t time.Time) error {
+	args := m.Called(ctx, userID, t)
+	return args.Error(0)
+}
+
+func (m *MockTokenRevocationRepository) TokensRevokedBefore(ctx context.Context, userID string) (time.Time, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func TestAuthUsecase_Logout(t *testing.T) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(15 * time.Minute)
+
+	t.Run("revokes the access token used", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockRevocations := new(MockTokenRevocationRepository)
+		uc := NewAuthUsecase(nil, mockTokenRepo, nil, nil, nil, nil, nil, mockRevocations)
+		mockTokenRepo.On("DeleteRefreshToken", ctx, "refresh").Return(nil).Once()
+		mockRevocations.On("RevokeAccessToken", ctx, "jti", expiresAt).Return(nil).Once()
+
+		assert.NoError(t, uc.Logout(ctx, "refresh", "jti", expiresAt))
+		mockTokenRepo.AssertExpectations(t)
+		mockRevocations.AssertExpectations(t)
+	})
+
+	t.Run("an access token without an ID cannot be denied", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockRevocations := new(MockTokenRevocationRepository)
+		uc := NewAuthUsecase(nil, mockTokenRepo, nil, nil, nil, nil, nil, mockRevocations)
+		mockTokenRepo.On("DeleteRefreshToken", ctx, "refresh").Return(nil).Once()
+
+		assert.NoError(t, uc.Logout(ctx, "refresh", "", time.Time{}))
+		mockRevocations.AssertNotCalled(t, "RevokeAccessToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUsecase_LogoutAll(t *testing.T) {
+	ctx := context.Background()
+	mockTokenRepo := new(MockTokenRepository)
+	mockRevocations := new(MockTokenRevocationRepository)
+	uc := NewAuthUsecase(nil, mockTokenRepo, nil, nil, nil, nil, nil, mockRevocations)
+	mockTokenRepo.On("DeleteAllForUser", ctx, "user-1").Return(nil).Once()
+	var revokedBefore time.Time
+	mockRevocations.On("RevokeTokensIssuedBefore", ctx, "user-1", mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { revokedBefore = args.Get(2).(time.Time) }).Return(nil).Once()
+
+	assert.NoError(t, uc.LogoutAll(ctx, "user-1"))
+	assert.WithinDuration(t, time.Now(), revokedBefore, time.Second)
+	mockTokenRepo.AssertExpectations(t)
+	mockRevocations.AssertExpectations(t)
+}
+
+func TestAuthUsecase_RevokeSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("revokes the session's access token", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockRevocations := new(MockTokenRevocationRepository)
+		uc := NewAuthUsecase(nil, mockTokenRepo, nil, nil, nil, nil, nil, mockRevocations)
+		expiresAt := time.Now().Add(10 * time.Minute)
+		mockTokenRepo.On("RevokeSession", ctx, "user-1", "family").Return([]*domain.RefreshToken{
+			{UserID: "user-1", FamilyID: "family", AccessTokenID: "jti", AccessExpiresAt: expiresAt},
+			{UserID: "user-1", FamilyID: "family"},
+		}, nil).Once()
+		mockRevocations.On("RevokeAccessToken", ctx, "jti", expiresAt).Return(nil).Once()
+
+		assert.NoError(t, uc.RevokeSession(ctx, "user-1", "family"))
+		mockTokenRepo.AssertExpectations(t)
+		mockRevocations.AssertExpectations(t)
+		mockRevocations.AssertNumberOfCalls(t, "RevokeAccessToken", 1)
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		mockTokenRepo := new(MockTokenRepository)
+		mockRevocations := new(MockTokenRevocationRepository)
+		uc := NewAuthUsecase(nil, mockTokenRepo, nil, nil, nil, nil, nil, mockRevocations)
+		mockTokenRepo.On("RevokeSession", ctx, "user-1", "family").Return(nil, domain.ErrSessionNotFound).Once()
+
+		assert.Equal(t, domain.ErrSessionNotFound, uc.RevokeSession(ctx, "user-1", "family"))
+		mockRevocations.AssertNotCalled(t, "RevokeAccessToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestAuthUsecase_RefreshTokens(t *testing.T) {
+	jwt := auth.NewJWT("secret", "refresh_secret", time.Hour, time.Hour)
+	user := &domain.User{ID: primitive.NewObjectID().Hex(), Role: "user"}
+
+	t.Run("rotates within the family", func(t *testing.T) {
+		ctx := context.Background()
+		mockUserRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		uc := NewAuthUsecase(mockUserRepo, mockTokenRepo, jwt, nil, nil, nil, nil, nil)
+		mockTokenRepo.On("ConsumeRefreshToken", ctx, "old").
+			Return(&domain.RefreshToken{UserID: user.ID, Token: "old", FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+		mockUserRepo.On("FindByID", ctx, user.ID).Return(user, nil).Once()
+		var successor *domain.RefreshToken
+		mockTokenRepo.On("StoreRefreshToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).
+			Run(func(args mock.Arguments) { successor = args.Get(1).(*domain.RefreshToken) }).Return(nil).Once()
+
+		accessToken, refreshToken, expiresIn, err := uc.RefreshTokens(ctx, "old")
+		assert.NoError(t, err)
+		claims, err := jwt.ValidateAccessToken(accessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.NotEqual(t, "old", refreshToken)
+		assert.Equal(t, refreshToken, successor.Token)
+		assert.Equal(t, "family", successor.FamilyID)
+		assert.Equal(t, int(time.Hour.Seconds()), expiresIn)
+		assert.Equal(t, claims.ID, successor.AccessTokenID)
+		assert.Equal(t, claims.ExpiresAt.Time, successor.AccessExpiresAt)
+		mockTokenRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the session's device and start", func(t *testing.T) {
+		ctx := domain.WithClientIP(context.Background(), "198.51.100.4")
+		mockUserRepo := new(MockUserRepository)
+		mockTokenRepo := new(MockTokenRepository)
+		uc := NewAuthUsecase(mockUserRepo, mockTokenRepo, jwt, nil, nil, nil, nil, nil)
+		loggedInAt := time.Now().Add(-48 * time.Hour)
+		mockTokenRepo.On("ConsumeRefreshToken", ctx, "old").Return(&domain.RefreshToken{
+			UserID: user.ID, Token: "old", FamilyID: "family", ExpiresAt: time.Now().Add(time.Hour),
+			DeviceName: "Firefox on Linux", UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0",
+			IP: "203.0.113.7", CreatedAt: loggedInAt, LastUsedAt: loggedInAt,
+		}, nil).Once()
+		mockUserRepo.On("FindByID", ctx, user.ID).Return(user, nil).Once()
+		var successor *domain.RefreshToken
+		mockTokenRepo.On("StoreRefreshToken", ctx, mock.AnythingOfType("*domain.RefreshToken")).
+			Run(func(args mock.Arguments) { successor = args.Get(1).(*domain.RefreshToken) }).Return(nil).Once()
+
+		_, _, _, err := uc.RefreshTokens(ctx, "old")
+		assert.NoError(t, err)
+		assert.Equal(t, "Firefox on Linux", successor.DeviceName)
+		assert.Equal(t, "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", successor.UserAgent)
+		assert.Equal(t, "198.51.100.4", successor.IP)
+		assert.Equal(t, loggedInAt, successor.CreatedAt)
+		assert.WithinDuration(t, time.Now(), successor.LastUsedAt, time.Minute)
+	})
+
+	t.Run("reuse revokes the family", func(t *testing.T) {
+		ctx := domain.WithClientIP(context.Background(), "203.0.113.7")
+		mockTokenRepo := new(MockTokenRepository)
+		mockAuditRepo := new(MockAuditRepository)
+		mockRevocations := new(MockTokenRevocationRepository)
+		uc := NewAuthUsecase(nil, mockTokenRepo, jwt, nil, nil, nil, mockAuditRepo, mockRevocations)
+		accessExpiresAt := time.Now().Add(10 * time.Minute)
+		mockTokenRepo.On("ConsumeRefreshToken", ctx, "old").
+			Return(&domain.RefreshToken{UserID: user.ID, Token: "old", FamilyID: "family"}, domain.ErrRefreshTokenReused).Once()
+		mockTokenRepo.On("RevokeFamily", ctx, "family").Return([]*domain.RefreshToken{
+			{UserID: user.ID, FamilyID: "family", AccessTokenID: "jti", AccessExpiresAt: accessExpiresAt},
+		}, nil).Once()
+		mockRevocations.On("RevokeAccessToken", ctx, "jti", accessExpiresAt).Return(nil).Once()
+		mockAuditRepo.On("Record", ctx, &domain.AuditEvent{
+			Type:    domain.AuditRefreshTokenReuse,
+			UserID:  user.ID,
+			Details: map[string]string{"family_id": "family", "client_ip": "203.0.113.7"},
+		}).Return(nil).Once()
+
+		_, _, _, err := uc.RefreshTokens(ctx, "old")
+		assert.Equal(t, domain.ErrRefreshTokenReused, err)
+		mockTokenRepo.AssertExpectations(t)
+		mockAuditRepo.AssertExpectations(t)
+		mockRevocations.AssertExpectations(t)
+		mockTokenRepo.AssertNotCalled(t, "StoreRefreshToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown or expired", func(t *testing.T) {
+		ctx := context.Background()
+		mockTokenRepo := new(MockTokenRepository)
+		uc := NewAuthUsecase(nil, mockTokenRepo, jwt, nil, nil, nil, nil, nil)
+		mockTokenRepo.On("ConsumeRefreshToken", ctx, "nope").Return(nil, domain.ErrInvalidRefreshToken).Once()
+		mockTokenRepo.On("ConsumeRefreshToken", ctx, "stale").
+			Return(&domain.RefreshToken{UserID: user.ID, FamilyID: "family", ExpiresAt: time.Now().Add(-time.Minute)}, nil).Once()
+
+		_, _, _, err := uc.RefreshTokens(ctx, "nope")
+		assert.Equal(t, domain.ErrInvalidRefreshToken, err)
+		_, _, _, err = uc.RefreshTokens(ctx, "stale")
+		assert.Equal(t, domain.ErrRefreshTokenExpired, err)
+	})
+}
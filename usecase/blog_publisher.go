@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/cache"
+	"time"
+)
+
+// BlogPublisher periodically publishes drafts whose publish_at has passed.
+// Several API replicas can run it at once because the repository claims each blog atomically.
+type BlogPublisher struct {
+	repo domain.BlogRepository
+	// pages holds the cached blog lists, dropped whenever a blog is published
+	pages    cache.Service
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewBlogPublisher creates a publisher that checks for due blogs every interval.
+// pages is the cache the blog list pages are kept in. now is the clock used to decide what is due;
+// nil means time.Now.
+func NewBlogPublisher(repo domain.BlogRepository, pages cache.Service, interval time.Duration, now func() time.Time) *BlogPublisher {
+	if now == nil {
+		now = time.Now
+	}
+	return &BlogPublisher{
+		repo:     repo,
+		pages:    pages,
+		interval: interval,
+		now:      now,
+	}
+}
+
+// Start runs the publisher until ctx is cancelled.
+func (p *BlogPublisher) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := p.PublishDue(ctx); err != nil && ctx.Err() == nil {
+			infrastructure.Log.Printf("Scheduled publishing failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue publishes every blog that is due and returns them. The cached blog lists are dropped
+// when any were published, even if a later one failed.
+func (p *BlogPublisher) PublishDue(ctx context.Context) (published []*domain.Blog, err error) {
+	defer func() {
+		if len(published) > 0 {
+			cache.InvalidateGroup(p.pages, domain.BlogListCacheGroup)
+		}
+	}()
+	now := p.now()
+	for {
+		blog, err := p.repo.PublishDueBlog(ctx, now)
+		if errors.Is(err, domain.ErrBlogNotFound) {
+			return published, nil
+		}
+		if err != nil {
+			return published, err
+		}
+		published = append(published, blog)
+	}
+}
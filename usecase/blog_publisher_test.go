@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlogPublisher_PublishDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("publishes every due blog", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		pages := cache.NewInMemoryCache(time.Minute, time.Minute)
+		publisher := NewBlogPublisher(mockBlogRepo, pages, time.Minute, clock)
+
+		first := &domain.Blog{ID: "blog1", Status: domain.BlogStatusPublished}
+		second := &domain.Blog{ID: "blog2", Status: domain.BlogStatusPublished}
+		mockBlogRepo.On("PublishDueBlog", ctx, now).Return(first, nil).Once()
+		mockBlogRepo.On("PublishDueBlog", ctx, now).Return(second, nil).Once()
+		mockBlogRepo.On("PublishDueBlog", ctx, now).Return(nil, domain.ErrBlogNotFound).Once()
+		listKey := cache.GroupKey(pages, domain.BlogListCacheGroup, "/blogs/")
+		pages.Set(listKey, "stale list", time.Minute)
+
+		published, err := publisher.PublishDue(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.Blog{first, second}, published)
+		_, found := pages.Get(cache.GroupKey(pages, domain.BlogListCacheGroup, "/blogs/"))
+		assert.False(t, found)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		pages := cache.NewInMemoryCache(time.Minute, time.Minute)
+		publisher := NewBlogPublisher(mockBlogRepo, pages, time.Minute, clock)
+		mockBlogRepo.On("PublishDueBlog", ctx, now).Return(nil, domain.ErrBlogNotFound).Once()
+		listKey := cache.GroupKey(pages, domain.BlogListCacheGroup, "/blogs/")
+
+		published, err := publisher.PublishDue(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, published)
+		assert.Equal(t, listKey, cache.GroupKey(pages, domain.BlogListCacheGroup, "/blogs/"))
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("stops on repository error", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		pages := cache.NewInMemoryCache(time.Minute, time.Minute)
+		publisher := NewBlogPublisher(mockBlogRepo, pages, time.Minute, clock)
+		dbErr := errors.New("connection reset")
+		mockBlogRepo.On("PublishDueBlog", ctx, now).Return(nil, dbErr).Once()
+
+		_, err := publisher.PublishDue(ctx)
+		assert.Equal(t, dbErr, err)
+		mockBlogRepo.AssertExpectations(t)
+	})
+}
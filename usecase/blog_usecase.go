@@ -2,19 +2,46 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"g3-g65-bsp/utils"
+	"slices"
 	"time"
 
 )
 
 
+// maxSearchSnippets is how many highlighted excerpts of the content a search result carries
+const maxSearchSnippets = 3
+
+// maxSlugCandidates is how many numbered suffixes (title-2, title-3, ...) are tried before a random one
+const maxSlugCandidates = 20
+
+// maxSlugSaveAttempts bounds the retries when another blog claims the chosen slug before it is saved
+const maxSlugSaveAttempts = 3
+
 type blogUsecase struct {
     repo domain.BlogRepository
     userRepo domain.UserRepository
+    revisionRepo domain.BlogRevisionRepository
+    commentRepo domain.CommentRepository
+    reactionRepo domain.ReactionRepository
+    statsRepo domain.BlogStatsRepository
+    bookmarkRepo domain.BookmarkRepository
+    cursors *utils.CursorCodec
+    views *ViewCounter
+    // cache keeps each blog's related list
+    cache cache.Service
+    // now is the clock publish times are set and checked against
+    now func() time.Time
 }
 
-func NewBlogUsecase(repo domain.BlogRepository, userRepo domain.UserRepository) domain.BlogUsecase {
-    return &blogUsecase{repo: repo, userRepo: userRepo}
+func NewBlogUsecase(repo domain.BlogRepository, userRepo domain.UserRepository, revisionRepo domain.BlogRevisionRepository, commentRepo domain.CommentRepository, reactionRepo domain.ReactionRepository, statsRepo domain.BlogStatsRepository, bookmarkRepo domain.BookmarkRepository, cursors *utils.CursorCodec, views *ViewCounter, cache cache.Service) domain.BlogUsecase {
+    return &blogUsecase{repo: repo, userRepo: userRepo, revisionRepo: revisionRepo, commentRepo: commentRepo, reactionRepo: reactionRepo, statsRepo: statsRepo, bookmarkRepo: bookmarkRepo, cursors: cursors, views: views, cache: cache, now: time.Now}
 }
 
 func (u *blogUsecase) CreateBlog(ctx context.Context, blog *domain.Blog, userid string) (*domain.Blog, error) {
@@ -27,64 +54,139 @@ func (u *blogUsecase) CreateBlog(ctx context.Context, blog *domain.Blog, userid
     blog.AuthorUsername = existingUser.Username
 
 
-    now := time.Now()
+    now := u.now()
     blog.CreatedAt = &now
     blog.UpdatedAt = blog.CreatedAt
     blog.Metrics = &domain.Metrics{
         ViewCount: 0,
-        Likes:     &domain.Likes{Count: 0, Users: []string{}},
-        Dislikes:  &domain.Likes{Count: 0, Users: []string{}},
     }
-    blog.Comments = []domain.Comment{}
 
+    switch blog.Status {
+    case "":
+        blog.Status = domain.BlogStatusDraft
+    case domain.BlogStatusDraft:
+    case domain.BlogStatusPublished:
+        blog.PublishedAt = &now
+    default:
+        return nil, domain.ErrInvalidBlogStatus
+    }
+    if err := renderContent(blog); err != nil {
+        return nil, err
+    }
+    // A publish time schedules the draft for the background publisher
+    if blog.PublishAt != nil {
+        if blog.Status != domain.BlogStatusDraft {
+            return nil, domain.ErrInvalidStatusTransition
+        }
+        if !blog.PublishAt.After(now) {
+            return nil, domain.ErrInvalidPublishTime
+        }
+    }
 
-    blogid, err := u.repo.CreateBlog(ctx, blog)
+    blog.Slug, err = u.uniqueSlug(ctx, blog.Title, "")
+    if err != nil {
+        return nil, err
+    }
+    var blogid string
+    err = u.saveWithSlug(ctx, blog, func() error {
+        blogid, err = u.repo.CreateBlog(ctx, blog)
+        return err
+    })
     if err != nil {
         return nil, err
     }
     blog.ID = blogid
+    if err := u.saveRevision(ctx, blog, userid, 0); err != nil {
+        return nil, err
+    }
     return blog, nil
 }
 
-func (u *blogUsecase) GetBlogByID(ctx context.Context, id string) (*domain.Blog, error) {
+func (u *blogUsecase) GetBlogByID(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
     blog, err := u.repo.GetBlogByID(ctx, id)
     if err != nil {
         return nil, err
     }
-    // Atomically increment the view count in the database
-    if blog != nil {
-        // Launch a goroutine to handle the database update concurrently.
-        blog.Metrics.ViewCount += 1 // reflect increment in returned object
-        
-		go func() {
-			// Create a new context for the goroutine to ensure it has its own lifecycle.
-			// This is important for managing timeouts and cancellations independently
-			// of the original request context.
-			bgCtx := context.Background()
-			_ = u.repo.IncrementBlogViewCount(bgCtx, id, blog)
-		}()
+    return u.viewBlog(ctx, blog, userid, role)
+}
+
+// GetBlogBySlug looks a blog up by its current slug or a previous one.
+// Callers compare the returned blog's Slug with the requested one to detect an outdated link.
+func (u *blogUsecase) GetBlogBySlug(ctx context.Context, slug, userid, role string) (*domain.Blog, error) {
+	blog, err := u.repo.GetBlogBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return u.viewBlog(ctx, blog, userid, role)
+}
 
+// canView tells whether a user may read a blog: unpublished posts are only visible to their author and admins
+func canView(blog *domain.Blog, userid, role string) bool {
+    return blog.Status == domain.BlogStatusPublished || blog.AuthorID == userid || role == string(domain.RoleAdmin)
+}
+
+// viewBlog applies the visibility rules to a blog being read, fills in the reader's own reactions and
+// counts the view. Authors reading their own post are not counted, nor is a reader who was counted
+// within the dedup window.
+func (u *blogUsecase) viewBlog(ctx context.Context, blog *domain.Blog, userid, role string) (*domain.Blog, error) {
+    if !canView(blog, userid, role) {
+        return nil, domain.ErrBlogNotFound
     }
-    return blog, nil
+    // the repository may hand the same cached blog to every reader, so per-reader changes go on a copy
+    viewed := *blog
+    ensureRendered(&viewed)
+    if userid != "" {
+        reactions, err := u.reactionRepo.UserReactions(ctx, domain.ReactionTargetBlog, blog.ID, userid)
+        if err != nil {
+            return nil, err
+        }
+        viewed.ViewerReactions = reactions
+    }
+    counted := blog.AuthorID != userid && u.views.Record(blog, ViewerKey(userid, domain.ClientIP(ctx)))
+    if counted && blog.Metrics != nil {
+        metrics := *blog.Metrics
+        metrics.ViewCount++ // reflect the pending view in the returned object
+        viewed.Metrics = &metrics
+    }
+    return &viewed, nil
 }
 
 func (u *blogUsecase) UpdateBlog(ctx context.Context, blog *domain.Blog, userid, id string) (*domain.Blog, error) {
     // Ensure the blog belongs to the user
-    existingBlog, err := u.repo.GetBlogByID(ctx, id)
+    existingBlog, err := u.getEditableBlog(ctx, id, userid, "")
     if err != nil {
         return nil, err
     }
-    if existingBlog.AuthorID != userid {
-        return nil, domain.ErrUnauthorized
+    if err := u.backfillRevision(ctx, existingBlog); err != nil {
+        return nil, err
     }
+    oldTitle := existingBlog.Title
+    tagsChanged := !sameTags(existingBlog.Tags, blog.Tags)
     existingBlog.Title = blog.Title
     existingBlog.Content = blog.Content
     existingBlog.Tags = blog.Tags
+    if blog.ContentFormat != "" {
+        existingBlog.ContentFormat = blog.ContentFormat
+    }
+    if err := renderContent(existingBlog); err != nil {
+        return nil, err
+    }
+    if err := u.refreshSlug(ctx, existingBlog, oldTitle); err != nil {
+        return nil, err
+    }
     
-    e := u.repo.UpdateBlog(ctx, existingBlog)
+    e := u.saveWithSlug(ctx, existingBlog, func() error {
+        return u.repo.UpdateBlog(ctx, existingBlog)
+    })
     if e != nil {
         return nil, e
     }
+    if tagsChanged {
+        u.cache.Delete(relatedCacheKey(id))
+    }
+    if err := u.saveRevision(ctx, existingBlog, userid, 0); err != nil {
+        return nil, err
+    }
     return existingBlog, nil
 }
 
@@ -97,21 +199,432 @@ func (u *blogUsecase) DeleteBlog(ctx context.Context, id, userid, role string) e
     if existingBlog.AuthorID != userid && role != "admin" {
         return domain.ErrUnauthorized
     }
-    return u.repo.DeleteBlog(ctx, id)
+    if err := u.repo.DeleteBlog(ctx, id); err != nil {
+        return err
+    }
+    if err := u.revisionRepo.DeleteRevisions(ctx, id); err != nil {
+        return err
+    }
+    if err := u.commentRepo.DeleteComments(ctx, id); err != nil {
+        return err
+    }
+    if err := u.reactionRepo.DeleteBlogReactions(ctx, id); err != nil {
+        return err
+    }
+    // bookmarks and reading lists keep the blog, shown as unavailable
+    if err := u.bookmarkRepo.MarkBlogUnavailable(ctx, id); err != nil {
+        return err
+    }
+    return u.statsRepo.DeleteBlogStats(ctx, id)
 }
 
-// ListBlogs allows filtering by tags ([]string), date (created_at_from, created_at_to), or popularity (min_views)
+// ListBlogs allows filtering by tags ([]string), date (created_at_from, created_at_to), or popularity (min_views).
+// A search runs a full-text query whose results carry a relevance score and highlighted snippets.
+// sortBy=trending ranks by recent activity, as last scored by the trending job. sortBy=published_at
+// orders by when posts went public; posts from before the lifecycle have no publish time and come last
+// in descending order.
+// followed_authors and followed_tags ([]string) match the posts of any of the authors or with any of the tags.
+// Passing a cursor (from a previous page's NextCursor or PrevCursor) switches from page/limit to keyset
+// pagination; the total is then only counted if include_total is set.
+// Only published blogs are listed unless status is set, in which case the listing is limited
+// to the viewer's own blogs (viewer_id) for everyone but admins (viewer_role).
 func (u *blogUsecase) ListBlogs(ctx context.Context, filter map[string]any, page, limit int) ([]*domain.Blog, *domain.Pagination, error) {
+    status, _ := filter["status"].(string)
+    switch domain.BlogStatus(status) {
+    case "", domain.BlogStatusPublished:
+        filter["status"] = string(domain.BlogStatusPublished)
+    case domain.BlogStatusDraft, domain.BlogStatusArchived:
+        viewerID, _ := filter["viewer_id"].(string)
+        viewerRole, _ := filter["viewer_role"].(string)
+        if viewerRole != string(domain.RoleAdmin) {
+            if viewerID == "" {
+                return nil, nil, domain.ErrUnauthorized
+            }
+            filter["author_id"] = viewerID
+        }
+    default:
+        return nil, nil, domain.ErrInvalidBlogStatus
+    }
+    search, _ := filter["search"].(string)
     sortBy, ok := filter["sortBy"].(string)
     switch {
-    case ok && (sortBy == "title" || sortBy == "created_at"):
+    case ok && (sortBy == "title" || sortBy == "created_at" || sortBy == "published_at"):
         // valid sortBy, do nothing
     case ok && sortBy == "view_count":
         filter["sortBy"] = "metrics.view_count"
+    case ok && sortBy == "trending":
+        // hottest first unless asked otherwise
+        filter["sortBy"] = "trending_score"
+        if _, ok := filter["order"]; !ok {
+            filter["order"] = "desc"
+        }
+    case search != "" && (!ok || sortBy == "relevance"):
+        // searches rank by relevance unless another order was asked for
+        filter["sortBy"] = "relevance"
     default:
         filter["sortBy"] = "created_at"
         filter["order"] = "desc"
     }
-    return u.repo.ListBlogs(ctx, filter, page, limit)
+    sortBy, _ = filter["sortBy"].(string)
+    order, _ := filter["order"].(string)
+    if order != "desc" {
+        order = "asc"
+    }
+    filter["order"] = order
+
+    token, _ := filter["cursor"].(string)
+    delete(filter, "cursor")
+    if token != "" {
+        cursor, err := u.cursors.Decode(token)
+        if err != nil {
+            return nil, nil, err
+        }
+        // a cursor is only meaningful for the sort it was issued for; relevance has no stable keyset
+        if sortBy == "relevance" || cursor.SortBy != sortBy || cursor.Order != order {
+            return nil, nil, domain.ErrInvalidCursor
+        }
+        filter["cursor"] = cursor
+    }
+    if _, ok := filter["include_total"]; !ok {
+        // page/limit callers have always received the total
+        filter["include_total"] = token == ""
+    }
+
+    blogs, pagination, err := u.repo.ListBlogs(ctx, filter, page, limit)
+    if err != nil {
+        return nil, nil, err
+    }
+    if sortBy != "relevance" && len(blogs) > 0 {
+        if pagination.HasNext {
+            if pagination.NextCursor, err = u.encodeCursor(blogs[len(blogs)-1], sortBy, order, false); err != nil {
+                return nil, nil, err
+            }
+        }
+        if pagination.HasPrev {
+            if pagination.PrevCursor, err = u.encodeCursor(blogs[0], sortBy, order, true); err != nil {
+                return nil, nil, err
+            }
+        }
+    }
+    // the page may be cached and shared with other readers, so its blogs are filled in on copies
+    listed := make([]*domain.Blog, len(blogs))
+    for i, blog := range blogs {
+        copied := *blog
+        ensureRendered(&copied)
+        if search != "" {
+            copied.Highlight = &domain.SearchHighlight{
+                Title:   utils.MarkMatches(blog.Title, search),
+                Content: utils.Highlight(blog.Content, search, maxSearchSnippets),
+            }
+        }
+        listed[i] = &copied
+    }
+    return listed, pagination, nil
+}
+
+// GetAuthor looks up the author of a per-author listing, so an unknown author is told apart from one without posts
+func (u *blogUsecase) GetAuthor(ctx context.Context, authorID string) (*domain.User, error) {
+    return u.userRepo.FindByID(ctx, authorID)
+}
+
+// PublishBlog makes a draft or archived blog publicly visible
+func (u *blogUsecase) PublishBlog(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+	return u.changeStatus(ctx, id, userid, role, domain.BlogStatusPublished, domain.BlogStatusDraft, domain.BlogStatusArchived)
+}
+
+// UnpublishBlog moves a published blog back to draft
+func (u *blogUsecase) UnpublishBlog(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+	return u.changeStatus(ctx, id, userid, role, domain.BlogStatusDraft, domain.BlogStatusPublished)
+}
+
+// ArchiveBlog hides a blog from listings without deleting it
+func (u *blogUsecase) ArchiveBlog(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+	return u.changeStatus(ctx, id, userid, role, domain.BlogStatusArchived, domain.BlogStatusDraft, domain.BlogStatusPublished)
+}
+
+// ScheduleBlog sets the time at which a draft is published automatically.
+// A nil publishAt cancels the schedule.
+func (u *blogUsecase) ScheduleBlog(ctx context.Context, id, userid, role string, publishAt *time.Time) (*domain.Blog, error) {
+	existingBlog, err := u.getEditableBlog(ctx, id, userid, role)
+	if err != nil {
+		return nil, err
+	}
+	if existingBlog.Status != domain.BlogStatusDraft {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+	if publishAt != nil && !publishAt.After(u.now()) {
+		return nil, domain.ErrInvalidPublishTime
+	}
+	existingBlog.PublishAt = publishAt
+	if err := u.repo.UpdateBlog(ctx, existingBlog); err != nil {
+		return nil, err
+	}
+	return existingBlog, nil
+}
+
+// changeStatus moves a blog to the target status if the caller is its author or an admin
+// and the blog is currently in one of the allowed source states.
+func (u *blogUsecase) changeStatus(ctx context.Context, id, userid, role string, target domain.BlogStatus, from ...domain.BlogStatus) (*domain.Blog, error) {
+	existingBlog, err := u.getEditableBlog(ctx, id, userid, role)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(from, existingBlog.Status) {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+	existingBlog.Status = target
+	existingBlog.PublishAt = nil
+	if target == domain.BlogStatusPublished && existingBlog.PublishedAt == nil {
+		now := u.now()
+		existingBlog.PublishedAt = &now
+	}
+	if err := u.repo.UpdateBlog(ctx, existingBlog); err != nil {
+		return nil, err
+	}
+	return existingBlog, nil
+}
+
+// ListRevisions returns the edit history of a blog, newest first
+func (u *blogUsecase) ListRevisions(ctx context.Context, id, userid, role string) ([]*domain.BlogRevision, error) {
+	if _, err := u.getEditableBlog(ctx, id, userid, role); err != nil {
+		return nil, err
+	}
+	return u.revisionRepo.ListRevisions(ctx, id)
+}
+
+// DiffRevisions compares two revisions of a blog line by line
+func (u *blogUsecase) DiffRevisions(ctx context.Context, id string, from, to int, userid, role string) (*domain.RevisionDiff, error) {
+	if _, err := u.getEditableBlog(ctx, id, userid, role); err != nil {
+		return nil, err
+	}
+	fromRevision, err := u.revisionRepo.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := u.revisionRepo.GetRevision(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+	diff := &domain.RevisionDiff{From: fromRevision, To: toRevision}
+	if diff.Title, err = utils.DiffLines(fromRevision.Title, toRevision.Title); err != nil {
+		return nil, err
+	}
+	if diff.Tags, err = utils.DiffSlices(fromRevision.Tags, toRevision.Tags); err != nil {
+		return nil, err
+	}
+	if diff.Content, err = utils.DiffLines(fromRevision.Content, toRevision.Content); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// RestoreRevision copies an old revision back onto the blog, recording the restore as a new revision
+func (u *blogUsecase) RestoreRevision(ctx context.Context, id string, number int, userid, role string) (*domain.Blog, error) {
+	existingBlog, err := u.getEditableBlog(ctx, id, userid, role)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := u.revisionRepo.GetRevision(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.backfillRevision(ctx, existingBlog); err != nil {
+		return nil, err
+	}
+	oldTitle := existingBlog.Title
+	tagsChanged := !sameTags(existingBlog.Tags, revision.Tags)
+	existingBlog.Title = revision.Title
+	existingBlog.Content = revision.Content
+	existingBlog.Tags = revision.Tags
+	if revision.ContentFormat != "" {
+		existingBlog.ContentFormat = revision.ContentFormat
+	}
+	if err := renderContent(existingBlog); err != nil {
+		return nil, err
+	}
+	if err := u.refreshSlug(ctx, existingBlog, oldTitle); err != nil {
+		return nil, err
+	}
+	err = u.saveWithSlug(ctx, existingBlog, func() error {
+		return u.repo.UpdateBlog(ctx, existingBlog)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tagsChanged {
+		u.cache.Delete(relatedCacheKey(id))
+	}
+	if err := u.saveRevision(ctx, existingBlog, userid, revision.Number); err != nil {
+		return nil, err
+	}
+	return existingBlog, nil
+}
+
+// getEditableBlog loads a blog the caller may edit: its author or an admin. The blog is a copy, as the
+// repository may hand out a cached one, so an edit whose write fails is not seen by other readers.
+func (u *blogUsecase) getEditableBlog(ctx context.Context, id, userid, role string) (*domain.Blog, error) {
+	existingBlog, err := u.repo.GetBlogByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existingBlog.AuthorID != userid && role != string(domain.RoleAdmin) {
+		return nil, domain.ErrUnauthorized
+	}
+	editable := *existingBlog
+	ensureRendered(&editable)
+	return &editable, nil
+}
+
+// saveRevision snapshots the blog's current title, content and tags
+func (u *blogUsecase) saveRevision(ctx context.Context, blog *domain.Blog, editorID string, restoredFrom int) error {
+	_, err := u.revisionRepo.CreateRevision(ctx, &domain.BlogRevision{
+		BlogID:       blog.ID,
+		Title:        blog.Title,
+		Content:      blog.Content,
+		ContentFormat: blog.ContentFormat,
+		Tags:         blog.Tags,
+		EditorID:     editorID,
+		RestoredFrom: restoredFrom,
+		CreatedAt:    u.now(),
+	})
+	return err
+}
+
+// backfillRevision records the state of a blog written before revisions existed,
+// so its first edit does not lose the original text.
+func (u *blogUsecase) backfillRevision(ctx context.Context, blog *domain.Blog) error {
+	_, err := u.revisionRepo.GetRevision(ctx, blog.ID, 1)
+	if !errors.Is(err, domain.ErrRevisionNotFound) {
+		return err
+	}
+	createdAt := u.now()
+	if blog.CreatedAt != nil {
+		createdAt = *blog.CreatedAt
+	}
+	_, err = u.revisionRepo.CreateRevision(ctx, &domain.BlogRevision{
+		BlogID:    blog.ID,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		ContentFormat: blog.ContentFormat,
+		Tags:      blog.Tags,
+		EditorID:  blog.AuthorID,
+		CreatedAt: createdAt,
+	})
+	return err
 }
 
+// uniqueSlug derives a slug from the title that no other blog uses, trying
+// "title", "title-2", "title-3", ... before falling back to a random suffix.
+// blogID is the blog being saved, so it may keep slugs it already owns.
+func (u *blogUsecase) uniqueSlug(ctx context.Context, title, blogID string) (string, error) {
+	base := utils.Slugify(title)
+	if base == "" {
+		base = "post"
+	}
+	for n := 1; n <= maxSlugCandidates; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken, err := u.repo.SlugExists(ctx, candidate, blogID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	return base + "-" + hex.EncodeToString(suffix), nil
+}
+
+// refreshSlug gives the blog a new slug when its title changed enough to change the slug.
+// The old slug is kept in PreviousSlugs so existing links keep resolving.
+func (u *blogUsecase) refreshSlug(ctx context.Context, blog *domain.Blog, oldTitle string) error {
+	if blog.Slug != "" && utils.Slugify(blog.Title) == utils.Slugify(oldTitle) {
+		return nil
+	}
+	slug, err := u.uniqueSlug(ctx, blog.Title, blog.ID)
+	if err != nil {
+		return err
+	}
+	if slug == blog.Slug {
+		return nil
+	}
+	if blog.Slug != "" {
+		blog.PreviousSlugs = append(blog.PreviousSlugs, blog.Slug)
+	}
+	// a blog renamed back to an earlier title takes its old slug back
+	blog.PreviousSlugs = slices.DeleteFunc(blog.PreviousSlugs, func(s string) bool { return s == slug })
+	blog.Slug = slug
+	return nil
+}
+
+// saveWithSlug runs save and, if another blog claimed the slug in the meantime,
+// picks the next free slug and tries again.
+func (u *blogUsecase) saveWithSlug(ctx context.Context, blog *domain.Blog, save func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := save()
+		if !errors.Is(err, domain.ErrSlugTaken) || attempt == maxSlugSaveAttempts {
+			return err
+		}
+		if blog.Slug, err = u.uniqueSlug(ctx, blog.Title, blog.ID); err != nil {
+			return err
+		}
+	}
+}
+
+// renderContent validates the blog's content format and stores the sanitized HTML next to the content,
+// so it is persisted and cached with the blog instead of being rendered on every read.
+func renderContent(blog *domain.Blog) error {
+	switch blog.ContentFormat {
+	case "":
+		blog.ContentFormat = domain.ContentFormatMarkdown
+	case domain.ContentFormatMarkdown, domain.ContentFormatHTML:
+	default:
+		return domain.ErrInvalidContentFormat
+	}
+	blog.ContentHTML = utils.RenderContent(blog.Content, blog.ContentFormat)
+	return nil
+}
+
+// ensureRendered fills in the HTML of blogs written before it was stored.
+// Their format is unknown, and rendering them as markdown still passes plain HTML through the sanitizer.
+func ensureRendered(blog *domain.Blog) {
+	if blog.ContentHTML == "" && blog.Content != "" {
+		format := blog.ContentFormat
+		if format == "" {
+			format = domain.ContentFormatMarkdown
+		}
+		blog.ContentHTML = utils.RenderContent(blog.Content, format)
+	}
+}
+
+// encodeCursor builds the token pointing at the blog's position in the listing
+func (u *blogUsecase) encodeCursor(blog *domain.Blog, sortBy, order string, backward bool) (string, error) {
+	cursor := &domain.BlogCursor{SortBy: sortBy, Order: order, ID: blog.ID, Backward: backward}
+	switch sortBy {
+	case "created_at":
+		var createdAt time.Time
+		if blog.CreatedAt != nil {
+			createdAt = *blog.CreatedAt
+		}
+		cursor.Value = createdAt
+	case "published_at":
+		var publishedAt time.Time
+		if blog.PublishedAt != nil {
+			publishedAt = *blog.PublishedAt
+		}
+		cursor.Value = publishedAt
+	case "metrics.view_count":
+		cursor.Value = blog.Metrics.ViewCount
+	case "trending_score":
+		cursor.Value = blog.TrendingScore
+	default:
+		cursor.Value = blog.Title
+	}
+	return u.cursors.Encode(cursor)
+}
@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"g3-g65-bsp/utils"
 	"testing"
 	"time"
 
@@ -28,6 +31,19 @@ func (m *MockBlogRepository) GetBlogByID(ctx context.Context, id string) (*domai
 	return args.Get(0).(*domain.Blog), args.Error(1)
 }
 
+func (m *MockBlogRepository) GetBlogBySlug(ctx context.Context, slug string) (*domain.Blog, error) {
+	args := m.Called(ctx, slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) SlugExists(ctx context.Context, slug string, excludeID string) (bool, error) {
+	args := m.Called(ctx, slug, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockBlogRepository) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
 	args := m.Called(ctx, blog)
 	return args.Error(0)
@@ -38,22 +54,97 @@ func (m *MockBlogRepository) DeleteBlog(ctx context.Context, id string) error {
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) IncrementBlogViewCount(ctx context.Context, id string, blog *domain.Blog) error {
-	args := m.Called(ctx, id, blog)
+func (m *MockBlogRepository) IncrementViewCounts(ctx context.Context, views map[string]int) error {
+	args := m.Called(ctx, views)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) AddComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, blogID, comment)
+func (m *MockBlogRepository) IncrementCommentCount(ctx context.Context, id string, delta int) error {
+	args := m.Called(ctx, id, delta)
+	return args.Error(0)
+}
+
+func (m *MockBlogRepository) IncrementReactionCount(ctx context.Context, id string, reactionType string, delta int) error {
+	args := m.Called(ctx, id, reactionType, delta)
+	return args.Error(0)
+}
+
+func (m *MockBlogRepository) ListBlogs(ctx context.Context, filter map[string]any, page, limit int) ([]*domain.Blog, *domain.Pagination, error) {
+	args := m.Called(ctx, filter, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Blog), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockBlogRepository) PublishDueBlog(ctx context.Context, now time.Time) (*domain.Blog, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) RelatedCandidates(ctx context.Context, blog *domain.Blog, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, blog, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) GetBlogsByIDs(ctx context.Context, ids []string) ([]*domain.Blog, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+// MockBlogRevisionRepository is a mock implementation of the BlogRevisionRepository interface.
+type MockBlogRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlogRevisionRepository) CreateRevision(ctx context.Context, revision *domain.BlogRevision) (*domain.BlogRevision, error) {
+	args := m.Called(ctx, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BlogRevision), args.Error(1)
+}
+
+func (m *MockBlogRevisionRepository) ListRevisions(ctx context.Context, blogID string) ([]*domain.BlogRevision, error) {
+	args := m.Called(ctx, blogID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.BlogRevision), args.Error(1)
+}
+
+func (m *MockBlogRevisionRepository) GetRevision(ctx context.Context, blogID string, number int) (*domain.BlogRevision, error) {
+	args := m.Called(ctx, blogID, number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.BlogRevision), args.Error(1)
+}
+
+func (m *MockBlogRevisionRepository) DeleteRevisions(ctx context.Context, blogID string) error {
+	args := m.Called(ctx, blogID)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) UpdateComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, blogID, comment)
+type MockCommentRepository struct {
+	mock.Mock
+}
+
+func (m *MockCommentRepository) CreateComment(ctx context.Context, comment *domain.Comment) error {
+	args := m.Called(ctx, comment)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
+func (m *MockCommentRepository) GetCommentByID(ctx context.Context, blogID string, commentID string) (*domain.Comment, error) {
 	args := m.Called(ctx, blogID, commentID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -61,23 +152,39 @@ func (m *MockBlogRepository) GetCommentByID(ctx context.Context, blogID string,
 	return args.Get(0).(*domain.Comment), args.Error(1)
 }
 
-func (m *MockBlogRepository) DeleteComment(ctx context.Context, blogID string, commentID string) error {
+func (m *MockCommentRepository) UpdateComment(ctx context.Context, comment *domain.Comment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) DeleteComment(ctx context.Context, blogID string, commentID string) error {
 	args := m.Called(ctx, blogID, commentID)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) ListBlogs(ctx context.Context, filter map[string]any, page, limit int) ([]*domain.Blog, *domain.Pagination, error) {
-	args := m.Called(ctx, filter, page, limit)
+func (m *MockCommentRepository) ListThreads(ctx context.Context, blogID string, page, limit int) ([]*domain.Comment, *domain.Pagination, error) {
+	args := m.Called(ctx, blogID, page, limit)
 	if args.Get(0) == nil {
 		return nil, nil, args.Error(2)
 	}
-	return args.Get(0).([]*domain.Blog), args.Get(1).(*domain.Pagination), args.Error(2)
+	return args.Get(0).([]*domain.Comment), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockCommentRepository) DeleteComments(ctx context.Context, blogID string) error {
+	args := m.Called(ctx, blogID)
+	return args.Error(0)
+}
+
+func (m *MockCommentRepository) IncrementReactionCount(ctx context.Context, blogID string, commentID string, reactionType string, delta int) error {
+	args := m.Called(ctx, blogID, commentID, reactionType, delta)
+	return args.Error(0)
 }
 
 func TestBlogUsecase_CreateBlog(t *testing.T) {
 	mockBlogRepo := new(MockBlogRepository)
 	mockUserRepo := new(MockUserRepository)
-	uc := NewBlogUsecase(mockBlogRepo, mockUserRepo)
+	mockRevisionRepo := new(MockBlogRevisionRepository)
+	uc := NewBlogUsecase(mockBlogRepo, mockUserRepo, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	userID := "user123"
@@ -89,7 +196,12 @@ func TestBlogUsecase_CreateBlog(t *testing.T) {
 	// Mock user repository
 	mockUserRepo.On("FindByID", ctx, userID).Return(&domain.User{Username: "testuser"}, nil).Once()
 	// Mock blog repository
+	mockBlogRepo.On("SlugExists", ctx, "test-title", "").Return(true, nil).Once()
+	mockBlogRepo.On("SlugExists", ctx, "test-title-2", "").Return(false, nil).Once()
 	mockBlogRepo.On("CreateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return("blog123", nil).Once()
+	mockRevisionRepo.On("CreateRevision", ctx, mock.MatchedBy(func(r *domain.BlogRevision) bool {
+		return r.BlogID == "blog123" && r.Title == "Test Title" && r.EditorID == userID
+	})).Return(&domain.BlogRevision{Number: 1}, nil).Once()
 
 	createdBlog, err := uc.CreateBlog(ctx, blog, userID)
 
@@ -98,98 +210,724 @@ func TestBlogUsecase_CreateBlog(t *testing.T) {
 	assert.Equal(t, "blog123", createdBlog.ID)
 	assert.Equal(t, userID, createdBlog.AuthorID)
 	assert.Equal(t, "testuser", createdBlog.AuthorUsername)
+	assert.Equal(t, domain.BlogStatusDraft, createdBlog.Status)
+	assert.Equal(t, "test-title-2", createdBlog.Slug)
+	assert.Equal(t, domain.ContentFormatMarkdown, createdBlog.ContentFormat)
+	assert.Equal(t, "<p>Test Content</p>\n", createdBlog.ContentHTML)
+	assert.Nil(t, createdBlog.PublishedAt)
 	mockBlogRepo.AssertExpectations(t)
 	mockUserRepo.AssertExpectations(t)
+	mockRevisionRepo.AssertExpectations(t)
+}
+
+func TestBlogUsecase_CreateBlogScheduled(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	for name, publishAt := range map[string]time.Time{
+		"rejects a time in the past": now.Add(-time.Second),
+		"rejects the current time":   now,
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockBlogRepo := new(MockBlogRepository)
+			mockUserRepo := new(MockUserRepository)
+			uc := NewBlogUsecase(mockBlogRepo, mockUserRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+			uc.(*blogUsecase).now = func() time.Time { return now }
+			mockUserRepo.On("FindByID", ctx, "user123").Return(&domain.User{Username: "testuser"}, nil).Once()
+
+			_, err := uc.CreateBlog(ctx, &domain.Blog{Title: "Test Title", Content: "Test Content", PublishAt: &publishAt}, "user123")
+			assert.Equal(t, domain.ErrInvalidPublishTime, err)
+			mockBlogRepo.AssertNotCalled(t, "CreateBlog", mock.Anything, mock.Anything)
+		})
+	}
 }
 
 func TestBlogUsecase_GetBlogByID(t *testing.T) {
 	mockBlogRepo := new(MockBlogRepository)
-	uc := NewBlogUsecase(mockBlogRepo, nil)
+	mockReactionRepo := new(MockReactionRepository)
+	mockStatsRepo := new(MockBlogStatsRepository)
+	views := NewViewCounter(mockBlogRepo, mockStatsRepo, time.Hour, time.Minute, nil)
+	uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, mockReactionRepo, nil, nil, nil, views, nil)
 
 	ctx := context.Background()
 	blogID := "blog123"
 	expectedBlog := &domain.Blog{
-		ID:      blogID,
-		Title:   "Test Title",
-		Content: "Test Content",
-		Metrics: &domain.Metrics{ViewCount: 0},
+		ID:       blogID,
+		AuthorID: "author",
+		Title:    "Test Title",
+		Content:  "Test Content",
+		Status:   domain.BlogStatusPublished,
+		Metrics:  &domain.Metrics{ViewCount: 0},
 	}
 
 	// Mock blog repository
-	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(expectedBlog, nil).Once()
-	mockBlogRepo.On("IncrementBlogViewCount", mock.Anything, blogID, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(expectedBlog, nil)
+	mockReactionRepo.On("UserReactions", ctx, domain.ReactionTargetBlog, blogID, "reader").Return([]string{"❤️", "🎉"}, nil)
+	mockReactionRepo.On("UserReactions", ctx, domain.ReactionTargetBlog, blogID, "author").Return([]string{}, nil)
 
-	blog, err := uc.GetBlogByID(ctx, blogID)
-	time.Sleep(50 * time.Millisecond) // allow goroutine to execute
+	blog, err := uc.GetBlogByID(ctx, blogID, "reader", "user")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, blog)
-	assert.Equal(t, expectedBlog, blog)
+	assert.Equal(t, expectedBlog.Title, blog.Title)
 	assert.Equal(t, 1, blog.Metrics.ViewCount) // Check if view count was incremented
+	assert.Equal(t, []string{"❤️", "🎉"}, blog.ViewerReactions)
+	assert.Nil(t, expectedBlog.ViewerReactions, "the reader's reactions must not end up on the cached blog")
+	assert.Equal(t, "<p>Test Content</p>\n", blog.ContentHTML)
+	assert.Empty(t, expectedBlog.ContentHTML, "the rendered HTML must not be written to the cached blog")
+	assert.Equal(t, 0, expectedBlog.Metrics.ViewCount)
+
+	// a refresh within the window and the author's own reads are not counted
+	blog, err = uc.GetBlogByID(ctx, blogID, "reader", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, blog.Metrics.ViewCount)
+	_, err = uc.GetBlogByID(ctx, blogID, "author", "user")
+	assert.NoError(t, err)
+
+	mockBlogRepo.On("IncrementViewCounts", ctx, map[string]int{blogID: 1}).Return(nil).Once()
+	mockStatsRepo.On("AddDailyStats", ctx, todaysStats(blogID, "author", domain.BlogStats{Views: 1, UniqueViewers: 1})).Return(nil).Once()
+	assert.NoError(t, views.Flush(ctx))
 	mockBlogRepo.AssertExpectations(t)
+	mockReactionRepo.AssertExpectations(t)
+	mockStatsRepo.AssertExpectations(t)
 }
 
-func TestBlogUsecase_UpdateBlog(t *testing.T) {
+func TestBlogUsecase_GetBlogByID_Draft(t *testing.T) {
 	mockBlogRepo := new(MockBlogRepository)
-	uc := NewBlogUsecase(mockBlogRepo, nil)
+	mockReactionRepo := new(MockReactionRepository)
+	uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, mockReactionRepo, nil, nil, nil, NewViewCounter(mockBlogRepo, nil, time.Hour, time.Minute, nil), nil)
 
 	ctx := context.Background()
-	userID := "user123"
 	blogID := "blog123"
-	existingBlog := &domain.Blog{
+	draft := &domain.Blog{
 		ID:       blogID,
-		AuthorID: userID,
-		Title:    "Old Title",
-		Content:  "Old Content",
+		AuthorID: "author",
+		Status:   domain.BlogStatusDraft,
+		Metrics:  &domain.Metrics{},
 	}
-	updatedBlog := &domain.Blog{
-		Title:   "New Title",
-		Content: "New Content",
-	}
-
-	// Mock blog repository
-	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existingBlog, nil).Once()
-	mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil)
+	mockReactionRepo.On("UserReactions", ctx, domain.ReactionTargetBlog, blogID, mock.Anything).Return([]string{}, nil)
 
-	result, err := uc.UpdateBlog(ctx, updatedBlog, userID, blogID)
+	// Other readers cannot see a draft
+	_, err := uc.GetBlogByID(ctx, blogID, "reader", "user")
+	assert.Equal(t, domain.ErrBlogNotFound, err)
 
+	// The author and admins can
+	blog, err := uc.GetBlogByID(ctx, blogID, "author", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, blogID, blog.ID)
+	_, err = uc.GetBlogByID(ctx, blogID, "admin", "admin")
 	assert.NoError(t, err)
-	assert.NotNil(t, result)
-	assert.Equal(t, "New Title", result.Title)
-	assert.Equal(t, "New Content", result.Content)
-	mockBlogRepo.AssertExpectations(t)
 }
 
-func TestBlogUsecase_DeleteBlog(t *testing.T) {
-	mockBlogRepo := new(MockBlogRepository)
-	uc := NewBlogUsecase(mockBlogRepo, nil)
+func TestBlogUsecase_ListBlogs_Status(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("defaults to published", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		filter := map[string]any{}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 10).Return([]*domain.Blog{}, &domain.Pagination{}, nil).Once()
+
+		_, _, err := uc.ListBlogs(ctx, filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "published", filter["status"])
+		assert.NotContains(t, filter, "author_id")
+	})
+
+	t.Run("drafts are scoped to the viewer", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		filter := map[string]any{"status": "draft", "viewer_id": "user123", "viewer_role": "user"}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 10).Return([]*domain.Blog{}, &domain.Pagination{}, nil).Once()
+
+		_, _, err := uc.ListBlogs(ctx, filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "user123", filter["author_id"])
+	})
+
+	t.Run("admins see every draft", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		filter := map[string]any{"status": "draft", "viewer_id": "admin1", "viewer_role": "admin"}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 10).Return([]*domain.Blog{}, &domain.Pagination{}, nil).Once()
+
+		_, _, err := uc.ListBlogs(ctx, filter, 1, 10)
+		assert.NoError(t, err)
+		assert.NotContains(t, filter, "author_id")
+	})
+
+	t.Run("anonymous drafts are rejected", func(t *testing.T) {
+		uc := NewBlogUsecase(new(MockBlogRepository), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		_, _, err := uc.ListBlogs(ctx, map[string]any{"status": "draft"}, 1, 10)
+		assert.Equal(t, domain.ErrUnauthorized, err)
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		uc := NewBlogUsecase(new(MockBlogRepository), nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		_, _, err := uc.ListBlogs(ctx, map[string]any{"status": "deleted"}, 1, 10)
+		assert.Equal(t, domain.ErrInvalidBlogStatus, err)
+	})
+}
 
+func TestBlogUsecase_StatusTransitions(t *testing.T) {
 	ctx := context.Background()
-	userID := "user123"
-	adminID := "admin456"
 	blogID := "blog123"
-	existingBlog := &domain.Blog{
-		ID:       blogID,
-		AuthorID: userID,
-	}
 
-	// Test case 1: Successful deletion by author
-	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existingBlog, nil).Once()
-	mockBlogRepo.On("DeleteBlog", ctx, blogID).Return(nil).Once()
-	err := uc.DeleteBlog(ctx, blogID, userID, "user")
-	assert.NoError(t, err)
+	t.Run("author publishes a draft", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+		uc.(*blogUsecase).now = func() time.Time { return now }
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+		blog, err := uc.PublishBlog(ctx, blogID, "author", "user")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BlogStatusPublished, blog.Status)
+		assert.Equal(t, &now, blog.PublishedAt)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("a failed write leaves the loaded blog alone", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		published := &domain.Blog{ID: blogID, AuthorID: "author", Content: "**post**", Status: domain.BlogStatusPublished}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(published, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(errors.New("db down")).Once()
+
+		_, err := uc.UnpublishBlog(ctx, blogID, "author", "user")
+		assert.Error(t, err)
+		// the repository may have handed out its cached blog
+		assert.Equal(t, &domain.Blog{ID: blogID, AuthorID: "author", Content: "**post**", Status: domain.BlogStatusPublished}, published)
+	})
+
+	t.Run("admin archives someone else's blog", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		published := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusPublished}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(published, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+		blog, err := uc.ArchiveBlog(ctx, blogID, "admin1", "admin")
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BlogStatusArchived, blog.Status)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("other users cannot unpublish", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		published := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusPublished}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(published, nil).Once()
+
+		_, err := uc.UnpublishBlog(ctx, blogID, "intruder", "user")
+		assert.Equal(t, domain.ErrUnauthorized, err)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("cannot unpublish a draft", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+
+		_, err := uc.UnpublishBlog(ctx, blogID, "author", "user")
+		assert.Equal(t, domain.ErrInvalidStatusTransition, err)
+		mockBlogRepo.AssertExpectations(t)
+	})
+}
 
-	// Test case 2: Successful deletion by admin
-	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existingBlog, nil).Once()
-	mockBlogRepo.On("DeleteBlog", ctx, blogID).Return(nil).Once()
-	err = uc.DeleteBlog(ctx, blogID, adminID, "admin")
-	assert.NoError(t, err)
+func TestBlogUsecase_ScheduleBlog(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("schedules a draft", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft}
+		publishAt := time.Now().Add(time.Hour)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+		blog, err := uc.ScheduleBlog(ctx, blogID, "author", "user", &publishAt)
+		assert.NoError(t, err)
+		assert.Equal(t, &publishAt, blog.PublishAt)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects a time in the past", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		uc.(*blogUsecase).now = clock
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft}
+		publishAt := now.Add(-time.Second)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+
+		_, err := uc.ScheduleBlog(ctx, blogID, "author", "user", &publishAt)
+		assert.Equal(t, domain.ErrInvalidPublishTime, err)
+		mockBlogRepo.AssertExpectations(t)
+		mockBlogRepo.AssertNotCalled(t, "UpdateBlog", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects the current time", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		uc.(*blogUsecase).now = clock
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft}
+		publishAt := now
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+
+		_, err := uc.ScheduleBlog(ctx, blogID, "author", "user", &publishAt)
+		assert.Equal(t, domain.ErrInvalidPublishTime, err)
+		mockBlogRepo.AssertNotCalled(t, "UpdateBlog", mock.Anything, mock.Anything)
+	})
+
+	t.Run("accepts the next instant", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		uc.(*blogUsecase).now = clock
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft}
+		publishAt := now.Add(time.Nanosecond)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+		blog, err := uc.ScheduleBlog(ctx, blogID, "author", "user", &publishAt)
+		assert.NoError(t, err)
+		assert.Equal(t, &publishAt, blog.PublishAt)
+	})
+
+	t.Run("cannot schedule a published blog", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		published := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusPublished}
+		publishAt := time.Now().Add(time.Hour)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(published, nil).Once()
+
+		_, err := uc.ScheduleBlog(ctx, blogID, "author", "user", &publishAt)
+		assert.Equal(t, domain.ErrInvalidStatusTransition, err)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("cancels a schedule", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		publishAt := time.Now().Add(time.Hour)
+		draft := &domain.Blog{ID: blogID, AuthorID: "author", Status: domain.BlogStatusDraft, PublishAt: &publishAt}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(draft, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+
+		blog, err := uc.ScheduleBlog(ctx, blogID, "author", "user", nil)
+		assert.NoError(t, err)
+		assert.Nil(t, blog.PublishAt)
+		mockBlogRepo.AssertExpectations(t)
+	})
+}
+
+func TestBlogUsecase_Revisions(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+	rev1 := &domain.BlogRevision{BlogID: blogID, Number: 1, Title: "First", Content: "line one\nline two", Tags: []string{"go"}}
+	rev2 := &domain.BlogRevision{BlogID: blogID, Number: 2, Title: "Second", Content: "line one\nline 2", Tags: []string{"go"}}
+
+	t.Run("list requires author or admin", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author"}, nil)
+		mockRevisionRepo.On("ListRevisions", ctx, blogID).Return([]*domain.BlogRevision{rev2, rev1}, nil).Once()
+
+		revisions, err := uc.ListRevisions(ctx, blogID, "author", "user")
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+
+		_, err = uc.ListRevisions(ctx, blogID, "stranger", "user")
+		assert.Equal(t, domain.ErrUnauthorized, err)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author"}, nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 1).Return(rev1, nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 2).Return(rev2, nil).Once()
+
+		diff, err := uc.DiffRevisions(ctx, blogID, 1, 2, "admin1", "admin")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.DiffLine{
+			{Op: domain.DiffEqual, Text: "line one"},
+			{Op: domain.DiffDelete, Text: "line two"},
+			{Op: domain.DiffInsert, Text: "line 2"},
+		}, diff.Content)
+		assert.Equal(t, []domain.DiffLine{
+			{Op: domain.DiffDelete, Text: "First"},
+			{Op: domain.DiffInsert, Text: "Second"},
+		}, diff.Title)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore creates a new revision", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, cache.NewInMemoryCache(time.Minute, time.Minute))
+		now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+		uc.(*blogUsecase).now = func() time.Time { return now }
+		current := &domain.Blog{ID: blogID, AuthorID: "author", Title: "Second", Slug: "second", PreviousSlugs: []string{"first"}, Content: "line one\nline 2"}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(current, nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 1).Return(rev1, nil).Twice()
+		mockBlogRepo.On("SlugExists", ctx, "first", blogID).Return(false, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+		mockRevisionRepo.On("CreateRevision", ctx, mock.MatchedBy(func(r *domain.BlogRevision) bool {
+			return r.Title == "First" && r.RestoredFrom == 1 && r.CreatedAt.Equal(now)
+		})).Return(&domain.BlogRevision{Number: 3}, nil).Once()
+
+		blog, err := uc.RestoreRevision(ctx, blogID, 1, "author", "user")
+		assert.NoError(t, err)
+		assert.Equal(t, "First", blog.Title)
+		assert.Equal(t, "line one\nline two", blog.Content)
+		// the blog takes back the slug it had under that title
+		assert.Equal(t, "first", blog.Slug)
+		assert.Equal(t, []string{"second"}, blog.PreviousSlugs)
+		mockBlogRepo.AssertExpectations(t)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("restore unknown revision", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author"}, nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 9).Return(nil, domain.ErrRevisionNotFound).Once()
+
+		_, err := uc.RestoreRevision(ctx, blogID, 9, "author", "user")
+		assert.Equal(t, domain.ErrRevisionNotFound, err)
+	})
+}
 
-	// Test case 3: Unauthorized deletion
-	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existingBlog, nil).Once()
-	err = uc.DeleteBlog(ctx, blogID, "anotheruser", "user")
-	assert.Error(t, err)
-	assert.Equal(t, domain.ErrUnauthorized, err)
+func TestBlogUsecase_Slugs(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
 
-	mockBlogRepo.AssertExpectations(t)
+	t.Run("get by slug", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, mockReactionRepo, nil, nil, nil, NewViewCounter(mockBlogRepo, nil, time.Hour, time.Minute, nil), nil)
+		blog := &domain.Blog{ID: blogID, Slug: "hello-world", Status: domain.BlogStatusPublished, Metrics: &domain.Metrics{}}
+		mockBlogRepo.On("GetBlogBySlug", ctx, "hello-world").Return(blog, nil).Once()
+		mockReactionRepo.On("UserReactions", ctx, domain.ReactionTargetBlog, blogID, "reader").Return([]string{}, nil).Once()
+
+		result, err := uc.GetBlogBySlug(ctx, "hello-world", "reader", "user")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Metrics.ViewCount)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("draft is hidden by slug", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		blog := &domain.Blog{ID: blogID, AuthorID: "author", Slug: "wip", Status: domain.BlogStatusDraft, Metrics: &domain.Metrics{}}
+		mockBlogRepo.On("GetBlogBySlug", ctx, "wip").Return(blog, nil).Once()
+
+		_, err := uc.GetBlogBySlug(ctx, "wip", "reader", "user")
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+	})
+
+	t.Run("title change keeping the slug", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		existing := &domain.Blog{ID: blogID, AuthorID: "author", Title: "Hello World", Slug: "hello-world"}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existing, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 1).Return(&domain.BlogRevision{Number: 1}, nil).Once()
+		mockRevisionRepo.On("CreateRevision", ctx, mock.Anything).Return(&domain.BlogRevision{Number: 2}, nil).Once()
+
+		result, err := uc.UpdateBlog(ctx, &domain.Blog{Title: "Hello, world!"}, "author", blogID)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello-world", result.Slug)
+		assert.Empty(t, result.PreviousSlugs)
+		mockBlogRepo.AssertNotCalled(t, "SlugExists", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("slug claimed concurrently", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, mockUserRepo, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		mockUserRepo.On("FindByID", ctx, "author").Return(&domain.User{Username: "author"}, nil).Once()
+		mockBlogRepo.On("SlugExists", ctx, "race", "").Return(false, nil).Once()
+		mockBlogRepo.On("CreateBlog", ctx, mock.MatchedBy(func(b *domain.Blog) bool { return b.Slug == "race" })).Return("", domain.ErrSlugTaken).Once()
+		mockBlogRepo.On("SlugExists", ctx, "race", "").Return(true, nil).Once()
+		mockBlogRepo.On("SlugExists", ctx, "race-2", "").Return(false, nil).Once()
+		mockBlogRepo.On("CreateBlog", ctx, mock.MatchedBy(func(b *domain.Blog) bool { return b.Slug == "race-2" })).Return(blogID, nil).Once()
+		mockRevisionRepo.On("CreateRevision", ctx, mock.Anything).Return(&domain.BlogRevision{Number: 1}, nil).Once()
+
+		result, err := uc.CreateBlog(ctx, &domain.Blog{Title: "Race"}, "author")
+		assert.NoError(t, err)
+		assert.Equal(t, "race-2", result.Slug)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("first edit of a legacy blog backfills its original text", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+		uc.(*blogUsecase).now = func() time.Time { return now }
+		existing := &domain.Blog{ID: blogID, AuthorID: "author", Title: "Hello World", Slug: "hello-world", Content: "original"}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existing, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 1).Return(nil, domain.ErrRevisionNotFound).Once()
+		// without a creation time the backfilled revision is stamped with the edit
+		mockRevisionRepo.On("CreateRevision", ctx, mock.MatchedBy(func(r *domain.BlogRevision) bool {
+			return r.Content == "original" && r.CreatedAt.Equal(now)
+		})).Return(&domain.BlogRevision{Number: 1}, nil).Once()
+		mockRevisionRepo.On("CreateRevision", ctx, mock.MatchedBy(func(r *domain.BlogRevision) bool {
+			return r.Content == "edited" && r.CreatedAt.Equal(now)
+		})).Return(&domain.BlogRevision{Number: 2}, nil).Once()
+
+		_, err := uc.UpdateBlog(ctx, &domain.Blog{Title: "Hello World", Content: "edited"}, "author", blogID)
+		assert.NoError(t, err)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("title without slug characters", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := &blogUsecase{repo: mockBlogRepo}
+		mockBlogRepo.On("SlugExists", ctx, "post", "").Return(false, nil).Once()
+
+		slug, err := uc.uniqueSlug(ctx, "???", "")
+		assert.NoError(t, err)
+		assert.Equal(t, "post", slug)
+	})
+}
+
+func TestBlogUsecase_ContentFormat(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+
+	t.Run("invalid format is rejected", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		uc := NewBlogUsecase(new(MockBlogRepository), mockUserRepo, nil, nil, nil, nil, nil, nil, nil, nil)
+		mockUserRepo.On("FindByID", ctx, "author").Return(&domain.User{Username: "author"}, nil).Once()
+
+		_, err := uc.CreateBlog(ctx, &domain.Blog{Title: "Title", Content: "text", ContentFormat: "bbcode"}, "author")
+		assert.ErrorIs(t, err, domain.ErrInvalidContentFormat)
+	})
+
+	t.Run("update re-renders and can switch format", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, nil)
+		existing := &domain.Blog{ID: blogID, AuthorID: "author", Title: "Title", Slug: "title", Content: "old", ContentFormat: domain.ContentFormatMarkdown}
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(existing, nil).Once()
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+		mockRevisionRepo.On("GetRevision", ctx, blogID, 1).Return(&domain.BlogRevision{Number: 1}, nil).Once()
+		mockRevisionRepo.On("CreateRevision", ctx, mock.MatchedBy(func(r *domain.BlogRevision) bool {
+			return r.ContentFormat == domain.ContentFormatHTML
+		})).Return(&domain.BlogRevision{Number: 2}, nil).Once()
+
+		update := &domain.Blog{Title: "Title", Content: `<p>new</p><img src="x" onerror="alert(1)">`, ContentFormat: domain.ContentFormatHTML}
+		result, err := uc.UpdateBlog(ctx, update, "author", blogID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.ContentFormatHTML, result.ContentFormat)
+		assert.Equal(t, `<p>new</p><img src="x">`, result.ContentHTML)
+		mockRevisionRepo.AssertExpectations(t)
+	})
+
+	t.Run("legacy blogs are rendered on read", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		legacy := &domain.Blog{
+			ID:      blogID,
+			Content: "**old** post",
+			Status:  domain.BlogStatusPublished,
+			Metrics: &domain.Metrics{},
+		}
+		mockBlogRepo.On("ListBlogs", ctx, mock.Anything, 1, 10).Return([]*domain.Blog{legacy}, &domain.Pagination{}, nil).Once()
+
+		blogs, _, err := uc.ListBlogs(ctx, map[string]any{}, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "<p><strong>old</strong> post</p>\n", blogs[0].ContentHTML)
+		assert.Empty(t, legacy.ContentHTML, "the repository's blog may be cached and shared")
+	})
+}
+
+func TestBlogUsecase_ListBlogs_Search(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ranks by relevance and highlights matches", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		filter := map[string]any{"search": "generics"}
+		result := &domain.Blog{Title: "Go generics", Content: "Generics landed in Go 1.18.", Score: 1.5, Metrics: &domain.Metrics{}}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 10).Return([]*domain.Blog{result}, &domain.Pagination{}, nil).Once()
+
+		blogs, _, err := uc.ListBlogs(ctx, filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "relevance", filter["sortBy"])
+		assert.Equal(t, &domain.SearchHighlight{
+			Title:   "Go <mark>generics</mark>",
+			Content: []string{"<mark>Generics</mark> landed in Go 1.18."},
+		}, blogs[0].Highlight)
+		assert.Nil(t, result.Highlight, "the repository's blog may be cached and shared")
+	})
+
+	t.Run("explicit sort wins over relevance", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		filter := map[string]any{"search": "generics", "sortBy": "view_count"}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 10).Return([]*domain.Blog{}, &domain.Pagination{}, nil).Once()
+
+		_, _, err := uc.ListBlogs(ctx, filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "metrics.view_count", filter["sortBy"])
+	})
+
+	t.Run("relevance without a search falls back to newest first", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		filter := map[string]any{"sortBy": "relevance"}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 10).Return([]*domain.Blog{}, &domain.Pagination{}, nil).Once()
+
+		_, _, err := uc.ListBlogs(ctx, filter, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "created_at", filter["sortBy"])
+	})
+}
+
+func TestBlogUsecase_ListBlogs_Cursor(t *testing.T) {
+	ctx := context.Background()
+	codec := utils.NewCursorCodec("secret")
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	page := []*domain.Blog{
+		{ID: "64b7f0c2a1b2c3d4e5f60701", Title: "B", CreatedAt: &createdAt, Metrics: &domain.Metrics{}},
+		{ID: "64b7f0c2a1b2c3d4e5f60702", Title: "A", CreatedAt: &createdAt, Metrics: &domain.Metrics{}},
+	}
+
+	t.Run("page mode counts and hands out cursors", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, codec, nil, nil)
+		filter := map[string]any{}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 2, 2).Return(page, &domain.Pagination{HasNext: true, HasPrev: true}, nil).Once()
+
+		_, pagination, err := uc.ListBlogs(ctx, filter, 2, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, true, filter["include_total"])
+
+		next, err := codec.Decode(pagination.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.BlogCursor{SortBy: "created_at", Order: "desc", Value: createdAt, ID: page[1].ID}, next)
+		prev, err := codec.Decode(pagination.PrevCursor)
+		assert.NoError(t, err)
+		assert.True(t, prev.Backward)
+		assert.Equal(t, page[0].ID, prev.ID)
+	})
+
+	t.Run("cursor mode skips the count", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, codec, nil, nil)
+		token, _ := codec.Encode(&domain.BlogCursor{SortBy: "title", Order: "asc", Value: "A", ID: page[1].ID})
+		filter := map[string]any{"sortBy": "title", "cursor": token}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 2).Return([]*domain.Blog{}, &domain.Pagination{HasPrev: true}, nil).Once()
+
+		_, pagination, err := uc.ListBlogs(ctx, filter, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, false, filter["include_total"])
+		assert.Equal(t, &domain.BlogCursor{SortBy: "title", Order: "asc", Value: "A", ID: page[1].ID}, filter["cursor"])
+		assert.Empty(t, pagination.NextCursor)
+	})
+
+	t.Run("trending sorts hottest first and pages by score", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, codec, nil, nil)
+		hot := []*domain.Blog{
+			{ID: "64b7f0c2a1b2c3d4e5f60701", TrendingScore: 40.5, Metrics: &domain.Metrics{}},
+			{ID: "64b7f0c2a1b2c3d4e5f60702", TrendingScore: 12.25, Metrics: &domain.Metrics{}},
+		}
+		filter := map[string]any{"sortBy": "trending"}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 2).Return(hot, &domain.Pagination{HasNext: true}, nil).Once()
+
+		_, pagination, err := uc.ListBlogs(ctx, filter, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "trending_score", filter["sortBy"])
+		assert.Equal(t, "desc", filter["order"])
+		next, err := codec.Decode(pagination.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.BlogCursor{SortBy: "trending_score", Order: "desc", Value: 12.25, ID: hot[1].ID}, next)
+
+		// the cursor leads on to the next page of the same ranking
+		filter = map[string]any{"sortBy": "trending", "cursor": pagination.NextCursor}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 2).Return([]*domain.Blog{}, &domain.Pagination{HasPrev: true}, nil).Once()
+		_, _, err = uc.ListBlogs(ctx, filter, 1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, next, filter["cursor"])
+	})
+
+	t.Run("pages by publish time", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, codec, nil, nil)
+		publishedAt := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+		published := []*domain.Blog{
+			{ID: "64b7f0c2a1b2c3d4e5f60701", CreatedAt: &createdAt, PublishedAt: &publishedAt, Metrics: &domain.Metrics{}},
+		}
+		filter := map[string]any{"sortBy": "published_at", "order": "desc"}
+		mockBlogRepo.On("ListBlogs", ctx, filter, 1, 1).Return(published, &domain.Pagination{HasNext: true}, nil).Once()
+
+		_, pagination, err := uc.ListBlogs(ctx, filter, 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "published_at", filter["sortBy"])
+		next, err := codec.Decode(pagination.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.BlogCursor{SortBy: "published_at", Order: "desc", Value: publishedAt, ID: published[0].ID}, next)
+	})
+
+	t.Run("cursor for another sort is rejected", func(t *testing.T) {
+		uc := NewBlogUsecase(new(MockBlogRepository), nil, nil, nil, nil, nil, nil, codec, nil, nil)
+		token, _ := codec.Encode(&domain.BlogCursor{SortBy: "title", Order: "asc", Value: "A", ID: page[1].ID})
+
+		_, _, err := uc.ListBlogs(ctx, map[string]any{"sortBy": "view_count", "cursor": token}, 1, 2)
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor)
+	})
+}
+
+func TestBlogUsecase_DeleteBlog(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reading lists keep the blog as unavailable", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		mockStatsRepo := new(MockBlogStatsRepository)
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, mockCommentRepo, mockReactionRepo, mockStatsRepo, mockBookmarkRepo, nil, nil, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, "blog1").Return(&domain.Blog{ID: "blog1", AuthorID: "author"}, nil).Once()
+		mockBlogRepo.On("DeleteBlog", ctx, "blog1").Return(nil).Once()
+		mockRevisionRepo.On("DeleteRevisions", ctx, "blog1").Return(nil).Once()
+		mockCommentRepo.On("DeleteComments", ctx, "blog1").Return(nil).Once()
+		mockReactionRepo.On("DeleteBlogReactions", ctx, "blog1").Return(nil).Once()
+		mockBookmarkRepo.On("MarkBlogUnavailable", ctx, "blog1").Return(nil).Once()
+		mockStatsRepo.On("DeleteBlogStats", ctx, "blog1").Return(nil).Once()
+
+		assert.NoError(t, uc.DeleteBlog(ctx, "blog1", "author", "user"))
+		mockBookmarkRepo.AssertExpectations(t)
+		mockStatsRepo.AssertExpectations(t)
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, mockBookmarkRepo, nil, nil, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, "blog1").Return(&domain.Blog{ID: "blog1", AuthorID: "author"}, nil).Once()
+
+		assert.Equal(t, domain.ErrUnauthorized, uc.DeleteBlog(ctx, "blog1", "reader", "user"))
+		mockBookmarkRepo.AssertNotCalled(t, "MarkBlogUnavailable", mock.Anything, mock.Anything)
+	})
 }
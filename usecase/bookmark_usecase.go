@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/utils"
+	"strings"
+	"unicode/utf8"
+)
+
+type bookmarkUsecase struct {
+	bookmarkRepo domain.BookmarkRepository
+	blogRepo     domain.BlogRepository
+}
+
+// NewBookmarkUsecase resolves bookmarked and listed blogs through blogRepo, one lookup per page or list
+func NewBookmarkUsecase(bookmarkRepo domain.BookmarkRepository, blogRepo domain.BlogRepository) domain.BookmarkUsecase {
+	return &bookmarkUsecase{bookmarkRepo: bookmarkRepo, blogRepo: blogRepo}
+}
+
+// Bookmark saves a blog the user can see; its title is kept in case the blog is deleted later
+func (u *bookmarkUsecase) Bookmark(ctx context.Context, userID, blogID, role string) error {
+	blog, err := u.visibleBlog(ctx, blogID, userID, role)
+	if err != nil {
+		return err
+	}
+	_, err = u.bookmarkRepo.AddBookmark(ctx, &domain.Bookmark{UserID: userID, BlogID: blog.ID, Title: blog.Title})
+	return err
+}
+
+func (u *bookmarkUsecase) RemoveBookmark(ctx context.Context, userID, blogID string) error {
+	return u.bookmarkRepo.RemoveBookmark(ctx, userID, blogID)
+}
+
+// ListBookmarks pages through the user's bookmarks, newest first, with the blogs they can still see
+// filled in and the rest marked unavailable
+func (u *bookmarkUsecase) ListBookmarks(ctx context.Context, userID, role string, page, limit int) ([]*domain.Bookmark, *domain.Pagination, error) {
+	bookmarks, pagination, err := u.bookmarkRepo.ListBookmarks(ctx, userID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		ids[i] = bookmark.BlogID
+	}
+	blogs, err := u.resolveBlogs(ctx, ids, userID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, bookmark := range bookmarks {
+		bookmark.Blog = blogs[bookmark.BlogID]
+		if bookmark.Blog == nil {
+			bookmark.Unavailable = true
+		} else {
+			bookmark.Title = bookmark.Blog.Title
+		}
+	}
+	return bookmarks, pagination, nil
+}
+
+func (u *bookmarkUsecase) BookmarkStates(ctx context.Context, userID string, blogIDs []string) (map[string]bool, error) {
+	if len(blogIDs) > domain.MaxBookmarkStateIDs {
+		return nil, domain.ErrTooManyBlogIDs
+	}
+	states := make(map[string]bool, len(blogIDs))
+	for _, id := range blogIDs {
+		states[id] = false
+	}
+	if userID == "" || len(blogIDs) == 0 {
+		return states, nil
+	}
+	bookmarked, err := u.bookmarkRepo.BookmarkedBlogIDs(ctx, userID, blogIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range bookmarked {
+		states[id] = true
+	}
+	return states, nil
+}
+
+func (u *bookmarkUsecase) CreateReadingList(ctx context.Context, userID, name string, shared bool) (*domain.ReadingList, error) {
+	name, err := readingListName(name)
+	if err != nil {
+		return nil, err
+	}
+	list := &domain.ReadingList{OwnerID: userID, Name: name, Items: []*domain.ReadingListItem{}}
+	if err := setSharing(list, shared); err != nil {
+		return nil, err
+	}
+	if err := u.bookmarkRepo.CreateReadingList(ctx, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListReadingLists returns the user's lists as stored, without resolving their blogs
+func (u *bookmarkUsecase) ListReadingLists(ctx context.Context, userID string) ([]*domain.ReadingList, error) {
+	return u.bookmarkRepo.ListReadingLists(ctx, userID)
+}
+
+func (u *bookmarkUsecase) GetReadingList(ctx context.Context, id, userID, role string) (*domain.ReadingList, error) {
+	list, err := u.ownList(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return list, u.resolveItems(ctx, list, userID, role)
+}
+
+// GetSharedReadingList shows a shared list to anyone holding its link. The viewer only sees the blogs
+// they could read anyway; the others show as unavailable.
+func (u *bookmarkUsecase) GetSharedReadingList(ctx context.Context, token, viewerID, role string) (*domain.ReadingList, error) {
+	list, err := u.bookmarkRepo.GetReadingListByShareToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return list, u.resolveItems(ctx, list, viewerID, role)
+}
+
+// UpdateReadingList renames the list or changes its sharing. Sharing a list gives it a new link, so
+// unsharing and sharing again revokes the old link.
+func (u *bookmarkUsecase) UpdateReadingList(ctx context.Context, id, userID string, name *string, shared *bool) (*domain.ReadingList, error) {
+	list, err := u.ownList(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	readAt := list.UpdatedAt
+	if name != nil {
+		if list.Name, err = readingListName(*name); err != nil {
+			return nil, err
+		}
+	}
+	if shared != nil && *shared != list.Shared {
+		if err := setSharing(list, *shared); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.bookmarkRepo.UpdateReadingList(ctx, list, readAt); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ReorderReadingList saves a new order for the list's blogs. The order must hold exactly the blogs in
+// the list, so a reorder based on a stale copy fails instead of dropping or reviving blogs.
+func (u *bookmarkUsecase) ReorderReadingList(ctx context.Context, id, userID string, blogIDs []string) (*domain.ReadingList, error) {
+	list, err := u.ownList(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(blogIDs) != len(list.Items) {
+		return nil, domain.ErrInvalidReadingListOrder
+	}
+	items := make(map[string]*domain.ReadingListItem, len(list.Items))
+	for _, item := range list.Items {
+		items[item.BlogID] = item
+	}
+	reordered := make([]*domain.ReadingListItem, len(blogIDs))
+	for i, blogID := range blogIDs {
+		item, ok := items[blogID]
+		if !ok {
+			return nil, domain.ErrInvalidReadingListOrder
+		}
+		// a repeated ID leaves another blog out, which the length check above then misses
+		delete(items, blogID)
+		reordered[i] = item
+	}
+	readAt := list.UpdatedAt
+	list.Items = reordered
+	if err := u.bookmarkRepo.UpdateReadingList(ctx, list, readAt); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (u *bookmarkUsecase) DeleteReadingList(ctx context.Context, id, userID string) error {
+	if _, err := u.ownList(ctx, id, userID); err != nil {
+		return err
+	}
+	return u.bookmarkRepo.DeleteReadingList(ctx, id)
+}
+
+// AddToReadingList appends a blog the user can see to the end of the list; adding it again does nothing
+func (u *bookmarkUsecase) AddToReadingList(ctx context.Context, id, userID, blogID, role string) error {
+	if _, err := u.ownList(ctx, id, userID); err != nil {
+		return err
+	}
+	blog, err := u.visibleBlog(ctx, blogID, userID, role)
+	if err != nil {
+		return err
+	}
+	return u.bookmarkRepo.AddReadingListItem(ctx, id, &domain.ReadingListItem{BlogID: blog.ID, Title: blog.Title})
+}
+
+func (u *bookmarkUsecase) RemoveFromReadingList(ctx context.Context, id, userID, blogID string) error {
+	if _, err := u.ownList(ctx, id, userID); err != nil {
+		return err
+	}
+	return u.bookmarkRepo.RemoveReadingListItem(ctx, id, blogID)
+}
+
+// ownList loads one of the user's lists; other users' lists are reported as not found, shared or not
+func (u *bookmarkUsecase) ownList(ctx context.Context, id, userID string) (*domain.ReadingList, error) {
+	list, err := u.bookmarkRepo.GetReadingList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if list.OwnerID != userID {
+		return nil, domain.ErrReadingListNotFound
+	}
+	return list, nil
+}
+
+func (u *bookmarkUsecase) visibleBlog(ctx context.Context, blogID, userID, role string) (*domain.Blog, error) {
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if !canView(blog, userID, role) {
+		return nil, domain.ErrBlogNotFound
+	}
+	return blog, nil
+}
+
+func (u *bookmarkUsecase) resolveItems(ctx context.Context, list *domain.ReadingList, viewerID, role string) error {
+	ids := make([]string, len(list.Items))
+	for i, item := range list.Items {
+		ids[i] = item.BlogID
+	}
+	blogs, err := u.resolveBlogs(ctx, ids, viewerID, role)
+	if err != nil {
+		return err
+	}
+	for _, item := range list.Items {
+		item.Blog = blogs[item.BlogID]
+		if item.Blog == nil {
+			item.Unavailable = true
+		} else {
+			item.Title = item.Blog.Title
+		}
+	}
+	return nil
+}
+
+// resolveBlogs looks up the blogs in one query and keeps those the viewer may read, by ID. Deleted blogs
+// and those since unpublished are left out, so they show as unavailable.
+func (u *bookmarkUsecase) resolveBlogs(ctx context.Context, ids []string, viewerID, role string) (map[string]*domain.Blog, error) {
+	resolved := make(map[string]*domain.Blog, len(ids))
+	if len(ids) == 0 {
+		return resolved, nil
+	}
+	blogs, err := u.blogRepo.GetBlogsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, blog := range blogs {
+		if canView(blog, viewerID, role) {
+			resolved[blog.ID] = blog
+		}
+	}
+	return resolved, nil
+}
+
+func readingListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > domain.MaxReadingListNameLength {
+		return "", domain.ErrInvalidReadingListName
+	}
+	return name, nil
+}
+
+// setSharing turns sharing on with a fresh share token, or off, dropping the token
+func setSharing(list *domain.ReadingList, shared bool) error {
+	list.Shared = shared
+	list.ShareToken = ""
+	if !shared {
+		return nil
+	}
+	token, _, err := utils.GenerateRandomToken()
+	if err != nil {
+		return err
+	}
+	list.ShareToken = token
+	return nil
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBookmarkRepository is a mock implementation of the BookmarkRepository interface.
+type MockBookmarkRepository struct {
+	mock.Mock
+}
+
+func (m *MockBookmarkRepository) AddBookmark(ctx context.Context, bookmark *domain.Bookmark) (bool, error) {
+	args := m.Called(ctx, bookmark)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockBookmarkRepository) RemoveBookmark(ctx context.Context, userID, blogID string) error {
+	args := m.Called(ctx, userID, blogID)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkRepository) ListBookmarks(ctx context.Context, userID string, page, limit int) ([]*domain.Bookmark, *domain.Pagination, error) {
+	args := m.Called(ctx, userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Bookmark), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockBookmarkRepository) BookmarkedBlogIDs(ctx context.Context, userID string, blogIDs []string) ([]string, error) {
+	args := m.Called(ctx, userID, blogIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockBookmarkRepository) CreateReadingList(ctx context.Context, list *domain.ReadingList) error {
+	args := m.Called(ctx, list)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkRepository) GetReadingList(ctx context.Context, id string) (*domain.ReadingList, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkRepository) GetReadingListByShareToken(ctx context.Context, token string) (*domain.ReadingList, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkRepository) ListReadingLists(ctx context.Context, ownerID string) ([]*domain.ReadingList, error) {
+	args := m.Called(ctx, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.ReadingList), args.Error(1)
+}
+
+func (m *MockBookmarkRepository) UpdateReadingList(ctx context.Context, list *domain.ReadingList, readAt time.Time) error {
+	args := m.Called(ctx, list, readAt)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkRepository) DeleteReadingList(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkRepository) AddReadingListItem(ctx context.Context, listID string, item *domain.ReadingListItem) error {
+	args := m.Called(ctx, listID, item)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkRepository) RemoveReadingListItem(ctx context.Context, listID, blogID string) error {
+	args := m.Called(ctx, listID, blogID)
+	return args.Error(0)
+}
+
+func (m *MockBookmarkRepository) MarkBlogUnavailable(ctx context.Context, blogID string) error {
+	args := m.Called(ctx, blogID)
+	return args.Error(0)
+}
+
+func TestBookmarkUsecase_Bookmark(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps the title", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, mockBlogRepo)
+		mockBlogRepo.On("GetBlogByID", ctx, "blog1").Return(&domain.Blog{ID: "blog1", Title: "Hello", Status: domain.BlogStatusPublished}, nil).Once()
+		mockBookmarkRepo.On("AddBookmark", ctx, &domain.Bookmark{UserID: "reader", BlogID: "blog1", Title: "Hello"}).Return(true, nil).Once()
+
+		assert.NoError(t, uc.Bookmark(ctx, "reader", "blog1", "user"))
+		mockBookmarkRepo.AssertExpectations(t)
+	})
+
+	t.Run("someone else's draft", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, mockBlogRepo)
+		mockBlogRepo.On("GetBlogByID", ctx, "blog1").Return(&domain.Blog{ID: "blog1", AuthorID: "author", Status: domain.BlogStatusDraft}, nil).Once()
+
+		assert.Equal(t, domain.ErrBlogNotFound, uc.Bookmark(ctx, "reader", "blog1", "user"))
+		mockBookmarkRepo.AssertNotCalled(t, "AddBookmark", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBookmarkUsecase_ListBookmarks(t *testing.T) {
+	ctx := context.Background()
+	mockBookmarkRepo := new(MockBookmarkRepository)
+	mockBlogRepo := new(MockBlogRepository)
+	uc := NewBookmarkUsecase(mockBookmarkRepo, mockBlogRepo)
+	bookmarks := []*domain.Bookmark{
+		{BlogID: "live", Title: "Old title"},
+		{BlogID: "deleted", Title: "Gone", Unavailable: true},
+		{BlogID: "unpublished", Title: "Hidden"},
+	}
+	mockBookmarkRepo.On("ListBookmarks", ctx, "reader", 1, 20).Return(bookmarks, &domain.Pagination{Page: 1, Limit: 20}, nil).Once()
+	mockBlogRepo.On("GetBlogsByIDs", ctx, []string{"live", "deleted", "unpublished"}).Return([]*domain.Blog{
+		{ID: "live", Title: "New title", Status: domain.BlogStatusPublished},
+		{ID: "unpublished", AuthorID: "author", Status: domain.BlogStatusDraft},
+	}, nil).Once()
+
+	result, _, err := uc.ListBookmarks(ctx, "reader", "user", 1, 20)
+	assert.NoError(t, err)
+	assert.False(t, result[0].Unavailable)
+	assert.Equal(t, "New title", result[0].Title)
+	assert.NotNil(t, result[0].Blog)
+	assert.True(t, result[1].Unavailable)
+	assert.Equal(t, "Gone", result[1].Title)
+	assert.True(t, result[2].Unavailable)
+	assert.Nil(t, result[2].Blog)
+}
+
+func TestBookmarkUsecase_BookmarkStates(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("every requested id answered", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, nil)
+		mockBookmarkRepo.On("BookmarkedBlogIDs", ctx, "reader", []string{"a", "b"}).Return([]string{"b"}, nil).Once()
+
+		states, err := uc.BookmarkStates(ctx, "reader", []string{"a", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"a": false, "b": true}, states)
+	})
+
+	t.Run("too many ids", func(t *testing.T) {
+		uc := NewBookmarkUsecase(new(MockBookmarkRepository), nil)
+		_, err := uc.BookmarkStates(ctx, "reader", make([]string, domain.MaxBookmarkStateIDs+1))
+		assert.Equal(t, domain.ErrTooManyBlogIDs, err)
+	})
+}
+
+func TestBookmarkUsecase_CreateReadingList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("shared lists get a token", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, nil)
+		mockBookmarkRepo.On("CreateReadingList", ctx, mock.Anything).Return(nil).Once()
+
+		list, err := uc.CreateReadingList(ctx, "reader", "  Weekend  ", true)
+		assert.NoError(t, err)
+		assert.Equal(t, "Weekend", list.Name)
+		assert.Equal(t, "reader", list.OwnerID)
+		assert.NotEmpty(t, list.ShareToken)
+	})
+
+	t.Run("name required", func(t *testing.T) {
+		uc := NewBookmarkUsecase(new(MockBookmarkRepository), nil)
+		_, err := uc.CreateReadingList(ctx, "reader", " ", false)
+		assert.Equal(t, domain.ErrInvalidReadingListName, err)
+	})
+}
+
+func TestBookmarkUsecase_UpdateReadingList(t *testing.T) {
+	ctx := context.Background()
+	readAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("unsharing revokes the link", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, nil)
+		mockBookmarkRepo.On("GetReadingList", ctx, "list1").Return(&domain.ReadingList{ID: "list1", OwnerID: "reader", Name: "Later", Shared: true, ShareToken: "token", UpdatedAt: readAt}, nil).Once()
+		mockBookmarkRepo.On("UpdateReadingList", ctx, mock.MatchedBy(func(list *domain.ReadingList) bool {
+			return list.Name == "Soon" && !list.Shared && list.ShareToken == ""
+		}), readAt).Return(nil).Once()
+
+		name, shared := "Soon", false
+		_, err := uc.UpdateReadingList(ctx, "list1", "reader", &name, &shared)
+		assert.NoError(t, err)
+		mockBookmarkRepo.AssertExpectations(t)
+	})
+
+	t.Run("someone else's list", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, nil)
+		mockBookmarkRepo.On("GetReadingList", ctx, "list1").Return(&domain.ReadingList{ID: "list1", OwnerID: "owner", Shared: true}, nil).Once()
+
+		name := "Mine"
+		_, err := uc.UpdateReadingList(ctx, "list1", "reader", &name, nil)
+		assert.Equal(t, domain.ErrReadingListNotFound, err)
+	})
+}
+
+func TestBookmarkUsecase_ReorderReadingList(t *testing.T) {
+	ctx := context.Background()
+	stored := func() *domain.ReadingList {
+		return &domain.ReadingList{ID: "list1", OwnerID: "reader", Items: []*domain.ReadingListItem{{BlogID: "a"}, {BlogID: "b"}, {BlogID: "c"}}}
+	}
+
+	t.Run("new order saved", func(t *testing.T) {
+		mockBookmarkRepo := new(MockBookmarkRepository)
+		uc := NewBookmarkUsecase(mockBookmarkRepo, nil)
+		mockBookmarkRepo.On("GetReadingList", ctx, "list1").Return(stored(), nil).Once()
+		mockBookmarkRepo.On("UpdateReadingList", ctx, mock.Anything, mock.Anything).Return(nil).Once()
+
+		list, err := uc.ReorderReadingList(ctx, "list1", "reader", []string{"c", "a", "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, "c", list.Items[0].BlogID)
+		assert.Equal(t, "b", list.Items[2].BlogID)
+	})
+
+	for name, order := range map[string][]string{
+		"missing a blog": {"a", "b"},
+		"repeated blog":  {"a", "a", "b"},
+		"unknown blog":   {"a", "b", "x"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockBookmarkRepo := new(MockBookmarkRepository)
+			uc := NewBookmarkUsecase(mockBookmarkRepo, nil)
+			mockBookmarkRepo.On("GetReadingList", ctx, "list1").Return(stored(), nil).Once()
+
+			_, err := uc.ReorderReadingList(ctx, "list1", "reader", order)
+			assert.Equal(t, domain.ErrInvalidReadingListOrder, err)
+			mockBookmarkRepo.AssertNotCalled(t, "UpdateReadingList", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestBookmarkUsecase_GetSharedReadingList(t *testing.T) {
+	ctx := context.Background()
+	mockBookmarkRepo := new(MockBookmarkRepository)
+	mockBlogRepo := new(MockBlogRepository)
+	uc := NewBookmarkUsecase(mockBookmarkRepo, mockBlogRepo)
+	list := &domain.ReadingList{ID: "list1", OwnerID: "owner", Shared: true, Items: []*domain.ReadingListItem{{BlogID: "a"}, {BlogID: "b", Unavailable: true}}}
+	mockBookmarkRepo.On("GetReadingListByShareToken", ctx, "token").Return(list, nil).Once()
+	mockBlogRepo.On("GetBlogsByIDs", ctx, []string{"a", "b"}).Return([]*domain.Blog{{ID: "a", Title: "A", Status: domain.BlogStatusPublished}}, nil).Once()
+
+	result, err := uc.GetSharedReadingList(ctx, "token", "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "A", result.Items[0].Blog.Title)
+	assert.True(t, result.Items[1].Unavailable)
+}
+
+func TestBookmarkUsecase_AddToReadingList(t *testing.T) {
+	ctx := context.Background()
+	mockBookmarkRepo := new(MockBookmarkRepository)
+	mockBlogRepo := new(MockBlogRepository)
+	uc := NewBookmarkUsecase(mockBookmarkRepo, mockBlogRepo)
+	mockBookmarkRepo.On("GetReadingList", ctx, "list1").Return(&domain.ReadingList{ID: "list1", OwnerID: "reader"}, nil).Once()
+	mockBlogRepo.On("GetBlogByID", ctx, "blog1").Return(&domain.Blog{ID: "blog1", Title: "Hello", Status: domain.BlogStatusPublished}, nil).Once()
+	mockBookmarkRepo.On("AddReadingListItem", ctx, "list1", &domain.ReadingListItem{BlogID: "blog1", Title: "Hello"}).Return(domain.ErrReadingListFull).Once()
+
+	assert.Equal(t, domain.ErrReadingListFull, uc.AddToReadingList(ctx, "list1", "reader", "blog1", "user"))
+	mockBookmarkRepo.AssertExpectations(t)
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"strings"
+)
+
+type followUsecase struct {
+	followRepo domain.FollowRepository
+	userRepo   domain.UserRepository
+	blogs      domain.BlogUsecase
+}
+
+// NewFollowUsecase builds the home feed through the blog listing, so feed pages get the same cursors
+func NewFollowUsecase(followRepo domain.FollowRepository, userRepo domain.UserRepository, blogs domain.BlogUsecase) domain.FollowUsecase {
+	return &followUsecase{followRepo: followRepo, userRepo: userRepo, blogs: blogs}
+}
+
+// Follow makes the user follow an author or a tag. Following an author adds to the author's follower
+// count and the user's following count, once however often it is repeated.
+func (u *followUsecase) Follow(ctx context.Context, userID string, targetType domain.FollowTarget, targetID string) error {
+	targetID, err := u.checkTarget(ctx, userID, targetType, targetID)
+	if err != nil {
+		return err
+	}
+	created, err := u.followRepo.Follow(ctx, &domain.Follow{FollowerID: userID, TargetType: targetType, TargetID: targetID})
+	if err != nil || !created {
+		return err
+	}
+	return u.countFollow(ctx, userID, targetType, targetID, 1)
+}
+
+// Unfollow stops the user following an author or a tag; unfollowing something not followed does nothing
+func (u *followUsecase) Unfollow(ctx context.Context, userID string, targetType domain.FollowTarget, targetID string) error {
+	if targetType != domain.FollowTargetAuthor && targetType != domain.FollowTargetTag {
+		return domain.ErrInvalidFollowTarget
+	}
+	if targetType == domain.FollowTargetTag {
+		targetID = strings.TrimSpace(targetID)
+	}
+	removed, err := u.followRepo.Unfollow(ctx, userID, targetType, targetID)
+	if err != nil || !removed {
+		return err
+	}
+	return u.countFollow(ctx, userID, targetType, targetID, -1)
+}
+
+func (u *followUsecase) ListFollowers(ctx context.Context, targetType domain.FollowTarget, targetID string, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	if targetType != domain.FollowTargetAuthor && targetType != domain.FollowTargetTag {
+		return nil, nil, domain.ErrInvalidFollowTarget
+	}
+	return u.followRepo.ListFollowers(ctx, targetType, targetID, page, limit)
+}
+
+func (u *followUsecase) ListFollowing(ctx context.Context, userID string, targetType domain.FollowTarget, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	if targetType != domain.FollowTargetAuthor && targetType != domain.FollowTargetTag {
+		return nil, nil, domain.ErrInvalidFollowTarget
+	}
+	return u.followRepo.ListFollowing(ctx, userID, targetType, page, limit)
+}
+
+// Feed lists the published posts by the authors the user follows and those with the tags they follow,
+// newest first. A post matching several follows appears once. Pages after the first are reached
+// through the returned NextCursor.
+func (u *followUsecase) Feed(ctx context.Context, userID, cursor string, limit int) ([]*domain.Blog, *domain.Pagination, error) {
+	authors, err := u.followedTargets(ctx, userID, domain.FollowTargetAuthor)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := u.followedTargets(ctx, userID, domain.FollowTargetTag)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(authors) == 0 && len(tags) == 0 {
+		return []*domain.Blog{}, &domain.Pagination{Page: 1, Limit: limit}, nil
+	}
+
+	filter := map[string]any{
+		"followed_authors": authors,
+		"followed_tags":    tags,
+		"sortBy":           "created_at",
+		"order":            "desc",
+		"include_total":    false,
+	}
+	if cursor != "" {
+		filter["cursor"] = cursor
+	}
+	return u.blogs.ListBlogs(ctx, filter, 1, limit)
+}
+
+func (u *followUsecase) followedTargets(ctx context.Context, userID string, targetType domain.FollowTarget) ([]string, error) {
+	follows, _, err := u.followRepo.ListFollowing(ctx, userID, targetType, 1, domain.MaxFeedFollows)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]string, len(follows))
+	for i, follow := range follows {
+		targets[i] = follow.TargetID
+	}
+	return targets, nil
+}
+
+// checkTarget validates a follow's target and returns it as it is stored: authors must exist and not be
+// the user, tags are trimmed
+func (u *followUsecase) checkTarget(ctx context.Context, userID string, targetType domain.FollowTarget, targetID string) (string, error) {
+	switch targetType {
+	case domain.FollowTargetAuthor:
+		if targetID == userID {
+			return "", domain.ErrCannotFollowSelf
+		}
+		if _, err := u.userRepo.FindByID(ctx, targetID); err != nil {
+			return "", err
+		}
+		return targetID, nil
+	case domain.FollowTargetTag:
+		tag := strings.TrimSpace(targetID)
+		if tag == "" {
+			return "", domain.ErrInvalidFollowTarget
+		}
+		return tag, nil
+	default:
+		return "", domain.ErrInvalidFollowTarget
+	}
+}
+
+// countFollow keeps the follow counts on both users in step with an author being followed or unfollowed
+func (u *followUsecase) countFollow(ctx context.Context, userID string, targetType domain.FollowTarget, targetID string, delta int) error {
+	if targetType != domain.FollowTargetAuthor {
+		return nil
+	}
+	if err := u.userRepo.IncrementFollowCounts(ctx, targetID, delta, 0); err != nil {
+		return err
+	}
+	return u.userRepo.IncrementFollowCounts(ctx, userID, 0, delta)
+}
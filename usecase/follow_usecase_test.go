@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockFollowRepository is a mock implementation of the FollowRepository interface.
+type MockFollowRepository struct {
+	mock.Mock
+}
+
+func (m *MockFollowRepository) Follow(ctx context.Context, follow *domain.Follow) (bool, error) {
+	args := m.Called(ctx, follow)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) Unfollow(ctx context.Context, followerID string, targetType domain.FollowTarget, targetID string) (bool, error) {
+	args := m.Called(ctx, followerID, targetType, targetID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) ListFollowers(ctx context.Context, targetType domain.FollowTarget, targetID string, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	args := m.Called(ctx, targetType, targetID, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Follow), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func (m *MockFollowRepository) ListFollowing(ctx context.Context, followerID string, targetType domain.FollowTarget, page, limit int) ([]*domain.Follow, *domain.Pagination, error) {
+	args := m.Called(ctx, followerID, targetType, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Follow), args.Get(1).(*domain.Pagination), args.Error(2)
+}
+
+func TestFollowUsecase_Follow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("following an author counts on both users", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := NewFollowUsecase(mockFollowRepo, mockUserRepo, nil)
+		mockUserRepo.On("FindByID", ctx, "author").Return(&domain.User{ID: "author"}, nil).Once()
+		mockFollowRepo.On("Follow", ctx, &domain.Follow{FollowerID: "reader", TargetType: domain.FollowTargetAuthor, TargetID: "author"}).Return(true, nil).Once()
+		mockUserRepo.On("IncrementFollowCounts", ctx, "author", 1, 0).Return(nil).Once()
+		mockUserRepo.On("IncrementFollowCounts", ctx, "reader", 0, 1).Return(nil).Once()
+
+		err := uc.Follow(ctx, "reader", domain.FollowTargetAuthor, "author")
+		assert.NoError(t, err)
+		mockFollowRepo.AssertExpectations(t)
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("following again changes nothing", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := NewFollowUsecase(mockFollowRepo, mockUserRepo, nil)
+		mockUserRepo.On("FindByID", ctx, "author").Return(&domain.User{ID: "author"}, nil).Once()
+		mockFollowRepo.On("Follow", ctx, mock.Anything).Return(false, nil).Once()
+
+		err := uc.Follow(ctx, "reader", domain.FollowTargetAuthor, "author")
+		assert.NoError(t, err)
+		mockUserRepo.AssertNotCalled(t, "IncrementFollowCounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("authors must exist and not be the user", func(t *testing.T) {
+		mockUserRepo := new(MockUserRepository)
+		uc := NewFollowUsecase(new(MockFollowRepository), mockUserRepo, nil)
+		mockUserRepo.On("FindByID", ctx, "ghost").Return(nil, domain.ErrUserNotFound).Once()
+
+		assert.Equal(t, domain.ErrUserNotFound, uc.Follow(ctx, "reader", domain.FollowTargetAuthor, "ghost"))
+		assert.Equal(t, domain.ErrCannotFollowSelf, uc.Follow(ctx, "reader", domain.FollowTargetAuthor, "reader"))
+	})
+
+	t.Run("tags are trimmed and not counted", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := NewFollowUsecase(mockFollowRepo, mockUserRepo, nil)
+		mockFollowRepo.On("Follow", ctx, &domain.Follow{FollowerID: "reader", TargetType: domain.FollowTargetTag, TargetID: "go"}).Return(true, nil).Once()
+
+		assert.NoError(t, uc.Follow(ctx, "reader", domain.FollowTargetTag, " go "))
+		assert.Equal(t, domain.ErrInvalidFollowTarget, uc.Follow(ctx, "reader", domain.FollowTargetTag, "  "))
+		assert.Equal(t, domain.ErrInvalidFollowTarget, uc.Follow(ctx, "reader", "blog", "go"))
+		mockFollowRepo.AssertExpectations(t)
+		mockUserRepo.AssertNotCalled(t, "IncrementFollowCounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFollowUsecase_Unfollow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("unfollowing an author takes back the counts", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := NewFollowUsecase(mockFollowRepo, mockUserRepo, nil)
+		mockFollowRepo.On("Unfollow", ctx, "reader", domain.FollowTargetAuthor, "author").Return(true, nil).Once()
+		mockUserRepo.On("IncrementFollowCounts", ctx, "author", -1, 0).Return(nil).Once()
+		mockUserRepo.On("IncrementFollowCounts", ctx, "reader", 0, -1).Return(nil).Once()
+
+		assert.NoError(t, uc.Unfollow(ctx, "reader", domain.FollowTargetAuthor, "author"))
+		mockUserRepo.AssertExpectations(t)
+	})
+
+	t.Run("unfollowing what was not followed", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		mockUserRepo := new(MockUserRepository)
+		uc := NewFollowUsecase(mockFollowRepo, mockUserRepo, nil)
+		mockFollowRepo.On("Unfollow", ctx, "reader", domain.FollowTargetAuthor, "author").Return(false, nil).Once()
+
+		assert.NoError(t, uc.Unfollow(ctx, "reader", domain.FollowTargetAuthor, "author"))
+		mockUserRepo.AssertNotCalled(t, "IncrementFollowCounts", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFollowUsecase_Feed(t *testing.T) {
+	ctx := context.Background()
+	codec := utils.NewCursorCodec("secret")
+
+	t.Run("posts of followed authors and tags, newest first", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewFollowUsecase(mockFollowRepo, nil, NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, codec, nil, nil))
+		mockFollowRepo.On("ListFollowing", ctx, "reader", domain.FollowTargetAuthor, 1, domain.MaxFeedFollows).
+			Return([]*domain.Follow{{TargetID: "author"}}, &domain.Pagination{}, nil).Once()
+		mockFollowRepo.On("ListFollowing", ctx, "reader", domain.FollowTargetTag, 1, domain.MaxFeedFollows).
+			Return([]*domain.Follow{{TargetID: "go"}}, &domain.Pagination{}, nil).Once()
+		createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		page := []*domain.Blog{{ID: "64b7f0c2a1b2c3d4e5f60701", CreatedAt: &createdAt, Metrics: &domain.Metrics{}}}
+		mockBlogRepo.On("ListBlogs", ctx, mock.MatchedBy(func(filter map[string]any) bool {
+			return assert.ObjectsAreEqual([]string{"author"}, filter["followed_authors"]) &&
+				assert.ObjectsAreEqual([]string{"go"}, filter["followed_tags"]) &&
+				filter["status"] == string(domain.BlogStatusPublished) &&
+				filter["include_total"] == false
+		}), 1, 1).Return(page, &domain.Pagination{HasNext: true}, nil).Once()
+
+		blogs, pagination, err := uc.Feed(ctx, "reader", "", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, page, blogs)
+		next, err := codec.Decode(pagination.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.BlogCursor{SortBy: "created_at", Order: "desc", Value: createdAt, ID: page[0].ID}, next)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("following nothing", func(t *testing.T) {
+		mockFollowRepo := new(MockFollowRepository)
+		uc := NewFollowUsecase(mockFollowRepo, nil, nil)
+		mockFollowRepo.On("ListFollowing", ctx, "reader", mock.Anything, 1, domain.MaxFeedFollows).
+			Return([]*domain.Follow{}, &domain.Pagination{}, nil).Twice()
+
+		blogs, pagination, err := uc.Feed(ctx, "reader", "", 10)
+		assert.NoError(t, err)
+		assert.Empty(t, blogs)
+		assert.Empty(t, pagination.NextCursor)
+	})
+}
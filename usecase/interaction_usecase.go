@@ -3,93 +3,192 @@ package usecase
 import (
 	"context"
 	"g3-g65-bsp/domain"
+	"g3-g65-bsp/utils"
 	"slices"
 	"time"
-
 )
 
 type InteractionUsecase struct {
-	blogRepo domain.BlogRepository
-	userRepo domain.UserRepository
+	blogRepo      domain.BlogRepository
+	userRepo      domain.UserRepository
+	commentRepo   domain.CommentRepository
+	reactionRepo  domain.ReactionRepository
+	statsRepo     domain.BlogStatsRepository
+	reactionTypes []string
 }
 
-func NewInteractionUsecase(blogRepo domain.BlogRepository, userRepo domain.UserRepository) domain.InteractionUsecase {
+// NewInteractionUsecase accepts only the given reaction types
+func NewInteractionUsecase(blogRepo domain.BlogRepository, userRepo domain.UserRepository, commentRepo domain.CommentRepository, reactionRepo domain.ReactionRepository, statsRepo domain.BlogStatsRepository, reactionTypes []string) domain.InteractionUsecase {
 	return &InteractionUsecase{
-		blogRepo: blogRepo,
-		userRepo: userRepo,
+		blogRepo:      blogRepo,
+		userRepo:      userRepo,
+		commentRepo:   commentRepo,
+		reactionRepo:  reactionRepo,
+		statsRepo:     statsRepo,
+		reactionTypes: reactionTypes,
 	}
 }
 
-
-func (u *InteractionUsecase) LikeBlog(ctx context.Context, userID string, blogID string, preftype string) error {
-	// Validate preftype
-	if preftype != "like" && preftype != "dislike" {
-		return domain.ErrInvalidpreftype
+// ReactToBlog toggles one of the user's reactions on a blog the user can see
+func (u *InteractionUsecase) ReactToBlog(ctx context.Context, userID string, blogID string, role string, reactionType string) (bool, error) {
+	if !slices.Contains(u.reactionTypes, reactionType) {
+		return false, domain.ErrInvalidpreftype
 	}
-	existingBlog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
 	if err != nil {
-		return err
+		return false, err
 	}
-
-	if preftype == "like" {
-		if containsUser(existingBlog.Metrics.Likes.Users, userID) {
-			existingBlog.Metrics.Likes.Users = removeUser(existingBlog.Metrics.Likes.Users, userID)
-			existingBlog.Metrics.Likes.Count--
-		} else {
-			if containsUser(existingBlog.Metrics.Dislikes.Users, userID) {
-				existingBlog.Metrics.Dislikes.Users = removeUser(existingBlog.Metrics.Dislikes.Users, userID)
-				existingBlog.Metrics.Dislikes.Count--
-
-			}
-			existingBlog.Metrics.Likes.Users = append(existingBlog.Metrics.Likes.Users, userID)
-			existingBlog.Metrics.Likes.Count++
+	if !canView(blog, userID, role) {
+		return false, domain.ErrBlogNotFound
+	}
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	delta, err := u.reactionRepo.ToggleReaction(ctx, &domain.Reaction{
+		TargetType: domain.ReactionTargetBlog,
+		TargetID:   blogID,
+		BlogID:     blogID,
+		UserID:     userID,
+		Username:   user.Username,
+		Type:       reactionType,
+	})
+	if err != nil {
+		return false, err
+	}
+	if delta != 0 {
+		if err := u.blogRepo.IncrementReactionCount(ctx, blogID, reactionType, delta); err != nil {
+			return false, err
+		}
+		if err := u.addDailyStats(ctx, blogID, blog.AuthorID, domain.BlogStats{Reactions: delta}); err != nil {
+			return false, err
 		}
 	}
+	return delta > 0, nil
+}
 
-	if preftype == "dislike" {
-		if containsUser(existingBlog.Metrics.Dislikes.Users, userID) {
-			existingBlog.Metrics.Dislikes.Users = removeUser(existingBlog.Metrics.Dislikes.Users, userID)
-			existingBlog.Metrics.Dislikes.Count--
-		} else {
-			if containsUser(existingBlog.Metrics.Likes.Users, userID) {
-				existingBlog.Metrics.Likes.Users = removeUser(existingBlog.Metrics.Likes.Users, userID)
-				existingBlog.Metrics.Likes.Count--
-
-			}
-			existingBlog.Metrics.Dislikes.Users = append(existingBlog.Metrics.Dislikes.Users, userID)
-			existingBlog.Metrics.Dislikes.Count++
+// ReactToComment toggles one of the user's reactions on a comment of a blog the user can see; deleted
+// comments take no reactions
+func (u *InteractionUsecase) ReactToComment(ctx context.Context, userID string, blogID string, commentID string, role string, reactionType string) (bool, error) {
+	if !slices.Contains(u.reactionTypes, reactionType) {
+		return false, domain.ErrInvalidpreftype
+	}
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return false, err
+	}
+	if !canView(blog, userID, role) {
+		return false, domain.ErrBlogNotFound
+	}
+	comment, err := u.commentRepo.GetCommentByID(ctx, blogID, commentID)
+	if err != nil {
+		return false, err
+	}
+	if comment.Deleted {
+		return false, domain.ErrCommentNotFound
+	}
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	delta, err := u.reactionRepo.ToggleReaction(ctx, &domain.Reaction{
+		TargetType: domain.ReactionTargetComment,
+		TargetID:   commentID,
+		BlogID:     blogID,
+		UserID:     userID,
+		Username:   user.Username,
+		Type:       reactionType,
+	})
+	if err != nil {
+		return false, err
+	}
+	if delta != 0 {
+		if err := u.commentRepo.IncrementReactionCount(ctx, blogID, commentID, reactionType, delta); err != nil {
+			return false, err
 		}
-
 	}
+	return delta > 0, nil
+}
 
-	// Update the blog metrics in the repository
-	if err := u.blogRepo.UpdateBlog(ctx, existingBlog); err != nil {
-		return err
+// ListReactions pages through who reacted to a blog, optionally to one reaction type. Only the blog's
+// author and admins may list them, so reactions cannot be scraped to see what a user has reacted to.
+func (u *InteractionUsecase) ListReactions(ctx context.Context, blogID, userID, role, reactionType string, page, limit int) ([]*domain.Reaction, *domain.Pagination, error) {
+	if reactionType != "" && !slices.Contains(u.reactionTypes, reactionType) {
+		return nil, nil, domain.ErrInvalidpreftype
 	}
-	return nil
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blog.AuthorID != userID && role != string(domain.RoleAdmin) {
+		return nil, nil, domain.ErrUnauthorized
+	}
+	return u.reactionRepo.ListReactions(ctx, domain.ReactionTargetBlog, blogID, reactionType, page, limit)
 }
 
-func (u *InteractionUsecase) CommentOnBlog(ctx context.Context, userID string, blogID string, comment *domain.Comment) error {
+// CommentOnBlog adds a comment, or a reply when ParentID is set, to a blog the user can see
+func (u *InteractionUsecase) CommentOnBlog(ctx context.Context, userID string, blogID string, role string, comment *domain.Comment) error {
 	existingUser, err := u.userRepo.FindByID(ctx, userID)
 	if err != nil {
 		return err
 	}
-	_, e := u.blogRepo.GetBlogByID(ctx, blogID)
+	blog, e := u.blogRepo.GetBlogByID(ctx, blogID)
 	if e != nil {
 		return e
 	}
+	if !canView(blog, userID, role) {
+		return domain.ErrBlogNotFound
+	}
+	comment.Depth = 0
+	if comment.ParentID != "" {
+		parent, err := u.commentRepo.GetCommentByID(ctx, blogID, comment.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.Deleted {
+			return domain.ErrCommentNotFound
+		}
+		if parent.Depth >= domain.MaxCommentDepth {
+			return domain.ErrCommentTooDeep
+		}
+		comment.Depth = parent.Depth + 1
+	}
+	comment.BlogID = blogID
 	comment.AuthorID = userID
 	comment.AuthorUsername = existingUser.Username
+	comment.ContentHTML = utils.RenderContent(comment.Content, domain.ContentFormatMarkdown)
 	now := time.Now()
 	comment.CreatedAt = &now
-	if err := u.blogRepo.AddComment(ctx, blogID, comment); err != nil {
+	if err := u.commentRepo.CreateComment(ctx, comment); err != nil {
 		return err
 	}
-	return nil
+	if err := u.blogRepo.IncrementCommentCount(ctx, blogID, 1); err != nil {
+		return err
+	}
+	return u.addDailyStats(ctx, blogID, blog.AuthorID, domain.BlogStats{Comments: 1})
+}
+
+// addDailyStats adds to today's stats of the blog. Comments are counted on the day they are posted and
+// stay counted if deleted later.
+func (u *InteractionUsecase) addDailyStats(ctx context.Context, blogID, authorID string, stats domain.BlogStats) error {
+	return u.statsRepo.AddDailyStats(ctx, []*domain.DailyBlogStats{{
+		BlogID:    blogID,
+		AuthorID:  authorID,
+		Day:       domain.StatsDay(time.Now()),
+		BlogStats: stats,
+	}})
 }
 
-func (u *InteractionUsecase) UpdateComment(ctx context.Context, userID string, blogID string, commentID string, content string) error {
-	comment, err := u.blogRepo.GetCommentByID(ctx, blogID, commentID)
+// UpdateComment edits the user's own comment on a blog the user can still see
+func (u *InteractionUsecase) UpdateComment(ctx context.Context, userID string, blogID string, commentID string, role string, content string) error {
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return err
+	}
+	if !canView(blog, userID, role) {
+		return domain.ErrBlogNotFound
+	}
+	comment, err := u.commentRepo.GetCommentByID(ctx, blogID, commentID)
 	if err != nil {
 		return err
 	}
@@ -97,38 +196,95 @@ func (u *InteractionUsecase) UpdateComment(ctx context.Context, userID string, b
 		return domain.ErrUnauthorized
 	}
 	comment.Content = content
-	if err := u.blogRepo.UpdateComment(ctx, blogID, comment); err != nil {
+	comment.ContentHTML = utils.RenderContent(content, domain.ContentFormatMarkdown)
+	if err := u.commentRepo.UpdateComment(ctx, comment); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (u *InteractionUsecase) DeleteComment(ctx context.Context, userID string, blogID string, commentID string) error {
-	comment, err := u.blogRepo.GetCommentByID(ctx, blogID, commentID)
+	comment, err := u.commentRepo.GetCommentByID(ctx, blogID, commentID)
 	if err != nil {
 		return err
 	}
 	if comment.AuthorID != userID {
 		return domain.ErrUnauthorized
 	}
-	if err := u.blogRepo.DeleteComment(ctx, blogID, commentID); err != nil {
+	if err := u.commentRepo.DeleteComment(ctx, blogID, commentID); err != nil {
 		return err
 	}
-	return nil
+	if err := u.reactionRepo.DeleteReactions(ctx, domain.ReactionTargetComment, commentID); err != nil {
+		return err
+	}
+	return u.blogRepo.IncrementCommentCount(ctx, blogID, -1)
 }
 
-func containsUser(users []string, userID string) bool {
-    return slices.Contains(users, userID)
+// ListComments returns a page of a blog's top-level comments with all their replies, as reply trees or
+// flat when flat is set. The flat list is in thread order (each comment followed by its replies), so it
+// can be shown indented by Depth. Pagination counts top-level comments.
+func (u *InteractionUsecase) ListComments(ctx context.Context, blogID, userID, role string, flat bool, page, limit int) ([]*domain.Comment, *domain.Pagination, error) {
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canView(blog, userID, role) {
+		return nil, nil, domain.ErrBlogNotFound
+	}
+	comments, pagination, err := u.commentRepo.ListThreads(ctx, blogID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, comment := range comments {
+		if comment.ContentHTML == "" && comment.Content != "" {
+			comment.ContentHTML = utils.RenderContent(comment.Content, domain.ContentFormatMarkdown)
+		}
+	}
+
+	threads := commentThreads(comments)
+	if flat {
+		return flattenThreads(threads, nil), pagination, nil
+	}
+	return threads, pagination, nil
 }
 
-func removeUser(users []string, userID string) []string {
-    result := make([]string, 0, len(users))
-    for _, id := range users {
-        if id != userID {
-            result = append(result, id)
-        }
-    }
-    return result
+// commentThreads arranges comments into reply trees and sets each depth. Comments come oldest first,
+// so every level stays oldest first.
+// Replies whose parent is missing (removed before tombstones existed) are treated as top-level comments.
+func commentThreads(comments []*domain.Comment) []*domain.Comment {
+	nodes := make(map[string]*domain.Comment, len(comments))
+	for _, comment := range comments {
+		comment.Replies = nil
+		nodes[comment.ID] = comment
+	}
+
+	var roots []*domain.Comment
+	for _, comment := range comments {
+		parent := nodes[comment.ParentID]
+		if comment.ParentID == "" || parent == nil || parent == comment {
+			roots = append(roots, comment)
+			continue
+		}
+		parent.Replies = append(parent.Replies, comment)
+	}
+	setDepth(roots, 0)
+	return roots
 }
 
+func setDepth(comments []*domain.Comment, depth int) {
+	for _, comment := range comments {
+		comment.Depth = depth
+		setDepth(comment.Replies, depth+1)
+	}
+}
 
+// flattenThreads lists the comments of the trees depth first, without their Replies
+func flattenThreads(threads []*domain.Comment, into []*domain.Comment) []*domain.Comment {
+	for _, comment := range threads {
+		replies := comment.Replies
+		comment.Replies = nil
+		into = append(into, comment)
+		into = flattenThreads(replies, into)
+	}
+	return into
+}
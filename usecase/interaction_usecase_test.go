@@ -9,156 +9,467 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
-// MockInteractionRepository is a mock implementation of the InteractionRepository interface.
-type MockInteractionRepository struct {
+// MockReactionRepository is a mock implementation of the ReactionRepository interface.
+type MockReactionRepository struct {
 	mock.Mock
 }
 
-func (m *MockInteractionRepository) AddComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, blogID, comment)
-	return args.Error(0)
+func (m *MockReactionRepository) ToggleReaction(ctx context.Context, reaction *domain.Reaction) (int, error) {
+	args := m.Called(ctx, reaction)
+	return args.Int(0), args.Error(1)
 }
 
-func (m *MockInteractionRepository) GetCommentByID(ctx context.Context, blogID, commentID string) (*domain.Comment, error) {
-	args := m.Called(ctx, blogID, commentID)
+func (m *MockReactionRepository) UserReactions(ctx context.Context, targetType domain.ReactionTarget, targetID string, userID string) ([]string, error) {
+	args := m.Called(ctx, targetType, targetID, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.Comment), args.Error(1)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockReactionRepository) ListReactions(ctx context.Context, targetType domain.ReactionTarget, targetID string, reactionType string, page, limit int) ([]*domain.Reaction, *domain.Pagination, error) {
+	args := m.Called(ctx, targetType, targetID, reactionType, page, limit)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*domain.Reaction), args.Get(1).(*domain.Pagination), args.Error(2)
 }
 
-func (m *MockInteractionRepository) UpdateComment(ctx context.Context, blogID string, comment *domain.Comment) error {
-	args := m.Called(ctx, blogID, comment)
+func (m *MockReactionRepository) DeleteReactions(ctx context.Context, targetType domain.ReactionTarget, targetID string) error {
+	args := m.Called(ctx, targetType, targetID)
 	return args.Error(0)
 }
 
-func (m *MockInteractionRepository) DeleteComment(ctx context.Context, blogID, commentID string) error {
-	args := m.Called(ctx, blogID, commentID)
+func (m *MockReactionRepository) DeleteBlogReactions(ctx context.Context, blogID string) error {
+	args := m.Called(ctx, blogID)
 	return args.Error(0)
 }
 
-func TestInteractionUsecase_LikeBlog(t *testing.T) {
-	mockBlogRepo := new(MockBlogRepository)
-	uc := NewInteractionUsecase(mockBlogRepo, nil)
+var testReactionTypes = []string{"👍", "❤️", "🎉"}
 
+func TestInteractionUsecase_ReactToBlog(t *testing.T) {
 	ctx := context.Background()
 	userID := "user123"
 	blogID := "blog123"
+	reaction := mock.MatchedBy(func(r *domain.Reaction) bool {
+		return r.TargetType == domain.ReactionTargetBlog && r.TargetID == blogID && r.BlogID == blogID &&
+			r.UserID == userID && r.Username == "testuser" && r.Type == "❤️"
+	})
 
-	t.Run("like a blog for the first time", func(t *testing.T) {
-		blog := &domain.Blog{
-			ID: blogID,
-			Metrics: &domain.Metrics{
-				Likes:    &domain.Likes{Count: 0, Users: []string{}},
-				Dislikes: &domain.Likes{Count: 0, Users: []string{}},
-			},
-		}
-		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil).Once()
-		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	setup := func() (domain.InteractionUsecase, *MockBlogRepository, *MockReactionRepository, *MockBlogStatsRepository) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		mockStatsRepo := new(MockBlogStatsRepository)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusPublished}, nil)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&domain.User{Username: "testuser"}, nil)
+		return NewInteractionUsecase(mockBlogRepo, mockUserRepo, nil, mockReactionRepo, mockStatsRepo, testReactionTypes), mockBlogRepo, mockReactionRepo, mockStatsRepo
+	}
+
+	t.Run("adds the reaction and counts it", func(t *testing.T) {
+		uc, mockBlogRepo, mockReactionRepo, mockStatsRepo := setup()
+		mockReactionRepo.On("ToggleReaction", ctx, reaction).Return(1, nil).Once()
+		mockBlogRepo.On("IncrementReactionCount", ctx, blogID, "❤️", 1).Return(nil).Once()
+		mockStatsRepo.On("AddDailyStats", ctx, todaysStats(blogID, "author1", domain.BlogStats{Reactions: 1})).Return(nil).Once()
 
-		err := uc.LikeBlog(ctx, userID, blogID, "like")
+		reacted, err := uc.ReactToBlog(ctx, userID, blogID, "user", "❤️")
 		assert.NoError(t, err)
-		assert.Equal(t, 1, blog.Metrics.Likes.Count)
-		assert.Contains(t, blog.Metrics.Likes.Users, userID)
+		assert.True(t, reacted)
 		mockBlogRepo.AssertExpectations(t)
+		mockReactionRepo.AssertExpectations(t)
+		mockStatsRepo.AssertExpectations(t)
 	})
 
-	t.Run("unlike a blog", func(t *testing.T) {
-		blog := &domain.Blog{
-			ID: blogID,
-			Metrics: &domain.Metrics{
-				Likes:    &domain.Likes{Count: 1, Users: []string{userID}},
-				Dislikes: &domain.Likes{Count: 0, Users: []string{}},
-			},
-		}
-		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil).Once()
-		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	t.Run("takes the reaction back", func(t *testing.T) {
+		uc, mockBlogRepo, mockReactionRepo, mockStatsRepo := setup()
+		mockReactionRepo.On("ToggleReaction", ctx, reaction).Return(-1, nil).Once()
+		mockBlogRepo.On("IncrementReactionCount", ctx, blogID, "❤️", -1).Return(nil).Once()
+		mockStatsRepo.On("AddDailyStats", ctx, todaysStats(blogID, "author1", domain.BlogStats{Reactions: -1})).Return(nil).Once()
 
-		err := uc.LikeBlog(ctx, userID, blogID, "like")
+		reacted, err := uc.ReactToBlog(ctx, userID, blogID, "user", "❤️")
 		assert.NoError(t, err)
-		assert.Equal(t, 0, blog.Metrics.Likes.Count)
-		assert.NotContains(t, blog.Metrics.Likes.Users, userID)
+		assert.False(t, reacted)
 		mockBlogRepo.AssertExpectations(t)
+		mockStatsRepo.AssertExpectations(t)
 	})
 
-	t.Run("dislike a blog", func(t *testing.T) {
-		blog := &domain.Blog{
-			ID: blogID,
-			Metrics: &domain.Metrics{
-				Likes:    &domain.Likes{Count: 0, Users: []string{}},
-				Dislikes: &domain.Likes{Count: 0, Users: []string{}},
-			},
-		}
-		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil).Once()
-		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	t.Run("lost race leaves the count alone", func(t *testing.T) {
+		uc, mockBlogRepo, mockReactionRepo, mockStatsRepo := setup()
+		mockReactionRepo.On("ToggleReaction", ctx, reaction).Return(0, nil).Once()
 
-		err := uc.LikeBlog(ctx, userID, blogID, "dislike")
+		_, err := uc.ReactToBlog(ctx, userID, blogID, "user", "❤️")
 		assert.NoError(t, err)
-		assert.Equal(t, 1, blog.Metrics.Dislikes.Count)
-		assert.Contains(t, blog.Metrics.Dislikes.Users, userID)
-		mockBlogRepo.AssertExpectations(t)
+		mockBlogRepo.AssertNotCalled(t, "IncrementReactionCount")
+		mockStatsRepo.AssertNotCalled(t, "AddDailyStats")
 	})
 
-	t.Run("undislike a blog", func(t *testing.T) {
-		blog := &domain.Blog{
-			ID: blogID,
-			Metrics: &domain.Metrics{
-				Likes:    &domain.Likes{Count: 0, Users: []string{}},
-				Dislikes: &domain.Likes{Count: 1, Users: []string{userID}},
-			},
-		}
-		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil).Once()
-		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	t.Run("missing blog", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(nil, domain.ErrBlogNotFound)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, nil, mockReactionRepo, nil, testReactionTypes)
+
+		_, err := uc.ReactToBlog(ctx, userID, blogID, "user", "❤️")
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+		mockReactionRepo.AssertNotCalled(t, "ToggleReaction")
+	})
+
+	t.Run("draft of another author", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		mockStatsRepo := new(MockBlogStatsRepository)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusDraft}, nil)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, nil, mockReactionRepo, mockStatsRepo, testReactionTypes)
+
+		_, err := uc.ReactToBlog(ctx, userID, blogID, "user", "❤️")
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+		mockReactionRepo.AssertNotCalled(t, "ToggleReaction")
+		mockStatsRepo.AssertNotCalled(t, "AddDailyStats")
+	})
+
+	t.Run("type that is not configured", func(t *testing.T) {
+		uc, mockBlogRepo, mockReactionRepo, _ := setup()
+
+		_, err := uc.ReactToBlog(ctx, userID, blogID, "user", "like")
+		assert.ErrorIs(t, err, domain.ErrInvalidpreftype)
+		mockBlogRepo.AssertNotCalled(t, "GetBlogByID")
+		mockReactionRepo.AssertNotCalled(t, "ToggleReaction")
+	})
+}
 
-		err := uc.LikeBlog(ctx, userID, blogID, "dislike")
+func TestInteractionUsecase_ReactToComment(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	blogID := "blog123"
+
+	published := &domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusPublished}
+
+	t.Run("adds the reaction and counts it", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, mockUserRepo, mockCommentRepo, mockReactionRepo, nil, testReactionTypes)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(published, nil)
+		mockCommentRepo.On("GetCommentByID", ctx, blogID, "c1").Return(&domain.Comment{ID: "c1"}, nil)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&domain.User{Username: "testuser"}, nil)
+		mockReactionRepo.On("ToggleReaction", ctx, mock.MatchedBy(func(r *domain.Reaction) bool {
+			return r.TargetType == domain.ReactionTargetComment && r.TargetID == "c1" && r.BlogID == blogID
+		})).Return(1, nil).Once()
+		mockCommentRepo.On("IncrementReactionCount", ctx, blogID, "c1", "🎉", 1).Return(nil).Once()
+
+		reacted, err := uc.ReactToComment(ctx, userID, blogID, "c1", "user", "🎉")
 		assert.NoError(t, err)
-		assert.Equal(t, 0, blog.Metrics.Dislikes.Count)
-		assert.NotContains(t, blog.Metrics.Dislikes.Users, userID)
-		mockBlogRepo.AssertExpectations(t)
+		assert.True(t, reacted)
+		mockCommentRepo.AssertExpectations(t)
+		mockReactionRepo.AssertExpectations(t)
 	})
 
-	t.Run("change from like to dislike", func(t *testing.T) {
-		blog := &domain.Blog{
-			ID: blogID,
-			Metrics: &domain.Metrics{
-				Likes:    &domain.Likes{Count: 1, Users: []string{userID}},
-				Dislikes: &domain.Likes{Count: 0, Users: []string{}},
-			},
-		}
-		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil).Once()
-		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil).Once()
+	t.Run("deleted comment", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, mockReactionRepo, nil, testReactionTypes)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(published, nil)
+		mockCommentRepo.On("GetCommentByID", ctx, blogID, "c1").Return(&domain.Comment{ID: "c1", Deleted: true}, nil)
+
+		_, err := uc.ReactToComment(ctx, userID, blogID, "c1", "user", "🎉")
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+		mockReactionRepo.AssertNotCalled(t, "ToggleReaction")
+	})
+
+	t.Run("comment on a draft of another author", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, mockReactionRepo, nil, testReactionTypes)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusDraft}, nil)
 
-		err := uc.LikeBlog(ctx, userID, blogID, "dislike")
+		_, err := uc.ReactToComment(ctx, userID, blogID, "c1", "user", "🎉")
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+		mockCommentRepo.AssertNotCalled(t, "GetCommentByID", mock.Anything, mock.Anything, mock.Anything)
+		mockReactionRepo.AssertNotCalled(t, "ToggleReaction", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInteractionUsecase_ListReactions(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+	blog := &domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusPublished}
+	total := 1
+	pagination := &domain.Pagination{Total: &total, Page: 1, Limit: 20}
+	reactions := []*domain.Reaction{{UserID: "user1", Username: "one", Type: "🎉"}}
+
+	setup := func() (domain.InteractionUsecase, *MockReactionRepository) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil)
+		mockReactionRepo.On("ListReactions", ctx, domain.ReactionTargetBlog, blogID, "🎉", 1, 20).Return(reactions, pagination, nil)
+		return NewInteractionUsecase(mockBlogRepo, nil, nil, mockReactionRepo, nil, testReactionTypes), mockReactionRepo
+	}
+
+	t.Run("author and admins see who reacted", func(t *testing.T) {
+		uc, _ := setup()
+
+		result, page, err := uc.ListReactions(ctx, blogID, "author1", "user", "🎉", 1, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, reactions, result)
+		assert.Equal(t, pagination, page)
+		_, _, err = uc.ListReactions(ctx, blogID, "someone", "admin", "🎉", 1, 20)
 		assert.NoError(t, err)
-		assert.Equal(t, 0, blog.Metrics.Likes.Count)
-		assert.Equal(t, 1, blog.Metrics.Dislikes.Count)
-		assert.NotContains(t, blog.Metrics.Likes.Users, userID)
-		assert.Contains(t, blog.Metrics.Dislikes.Users, userID)
-		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("other users are refused", func(t *testing.T) {
+		uc, mockReactionRepo := setup()
+
+		_, _, err := uc.ListReactions(ctx, blogID, "someone", "user", "🎉", 1, 20)
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+		mockReactionRepo.AssertNotCalled(t, "ListReactions")
+	})
+
+	t.Run("type that is not configured", func(t *testing.T) {
+		uc, mockReactionRepo := setup()
+
+		_, _, err := uc.ListReactions(ctx, blogID, "author1", "user", "like", 1, 20)
+		assert.ErrorIs(t, err, domain.ErrInvalidpreftype)
+		mockReactionRepo.AssertNotCalled(t, "ListReactions")
 	})
 }
 
 func TestInteractionUsecase_CommentOnBlog(t *testing.T) {
 	mockBlogRepo := new(MockBlogRepository)
 	mockUserRepo := new(MockUserRepository)
-	uc := NewInteractionUsecase(mockBlogRepo, mockUserRepo)
+	mockCommentRepo := new(MockCommentRepository)
+	mockStatsRepo := new(MockBlogStatsRepository)
+	uc := NewInteractionUsecase(mockBlogRepo, mockUserRepo, mockCommentRepo, nil, mockStatsRepo, nil)
 
 	ctx := context.Background()
 	userID := "user123"
 	blogID := "blog123"
-	comment := &domain.Comment{Content: "Test comment"}
+	comment := &domain.Comment{Content: "Test *comment* <script>alert(1)</script>"}
 
 	mockUserRepo.On("FindByID", ctx, userID).Return(&domain.User{Username: "testuser"}, nil).Once()
-	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{}, nil).Once()
-	mockBlogRepo.On("AddComment", ctx, blogID, mock.AnythingOfType("*domain.Comment")).Return(nil).Once()
+	mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{AuthorID: "author1", Status: domain.BlogStatusPublished}, nil).Once()
+	mockCommentRepo.On("CreateComment", ctx, mock.AnythingOfType("*domain.Comment")).Return(nil).Once()
+	mockBlogRepo.On("IncrementCommentCount", ctx, blogID, 1).Return(nil).Once()
+	mockStatsRepo.On("AddDailyStats", ctx, todaysStats(blogID, "author1", domain.BlogStats{Comments: 1})).Return(nil).Once()
 
-	err := uc.CommentOnBlog(ctx, userID, blogID, comment)
+	err := uc.CommentOnBlog(ctx, userID, blogID, "user", comment)
 
 	assert.NoError(t, err)
+	assert.Equal(t, blogID, comment.BlogID)
 	assert.Equal(t, userID, comment.AuthorID)
 	assert.Equal(t, "testuser", comment.AuthorUsername)
+	assert.Equal(t, "<p>Test <em>comment</em> </p>\n", comment.ContentHTML)
 	mockBlogRepo.AssertExpectations(t)
 	mockUserRepo.AssertExpectations(t)
+	mockCommentRepo.AssertExpectations(t)
+	mockStatsRepo.AssertExpectations(t)
 }
 
-// ... (add tests for UpdateComment and DeleteComment)
+func TestInteractionUsecase_UpdateComment(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+
+	t.Run("author edits the comment", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, nil, nil, testReactionTypes)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusPublished}, nil)
+		mockCommentRepo.On("GetCommentByID", ctx, blogID, "c1").Return(&domain.Comment{ID: "c1", AuthorID: "user123"}, nil)
+		mockCommentRepo.On("UpdateComment", ctx, mock.MatchedBy(func(c *domain.Comment) bool {
+			return c.Content == "**edited**" && c.ContentHTML == "<p><strong>edited</strong></p>\n"
+		})).Return(nil).Once()
+
+		assert.NoError(t, uc.UpdateComment(ctx, "user123", blogID, "c1", "user", "**edited**"))
+		mockCommentRepo.AssertExpectations(t)
+	})
+
+	t.Run("comment on a draft of another author", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, nil, nil, testReactionTypes)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusDraft}, nil)
+
+		err := uc.UpdateComment(ctx, "user123", blogID, "c1", "user", "edited")
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+		mockCommentRepo.AssertNotCalled(t, "UpdateComment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestInteractionUsecase_DeleteComment(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	blogID := "blog123"
+
+	t.Run("author deletes and the count drops", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockReactionRepo := new(MockReactionRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, mockReactionRepo, nil, nil)
+		mockCommentRepo.On("GetCommentByID", ctx, blogID, "c1").Return(&domain.Comment{ID: "c1", AuthorID: userID}, nil)
+		mockCommentRepo.On("DeleteComment", ctx, blogID, "c1").Return(nil).Once()
+		mockReactionRepo.On("DeleteReactions", ctx, domain.ReactionTargetComment, "c1").Return(nil).Once()
+		mockBlogRepo.On("IncrementCommentCount", ctx, blogID, -1).Return(nil).Once()
+
+		assert.NoError(t, uc.DeleteComment(ctx, userID, blogID, "c1"))
+		mockBlogRepo.AssertExpectations(t)
+		mockCommentRepo.AssertExpectations(t)
+		mockReactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("someone else's comment", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		uc := NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, nil, nil, nil)
+		mockCommentRepo.On("GetCommentByID", ctx, blogID, "c1").Return(&domain.Comment{ID: "c1", AuthorID: "other"}, nil)
+
+		assert.ErrorIs(t, uc.DeleteComment(ctx, userID, blogID, "c1"), domain.ErrUnauthorized)
+		mockCommentRepo.AssertNotCalled(t, "DeleteComment")
+		mockBlogRepo.AssertNotCalled(t, "IncrementCommentCount")
+	})
+}
+
+func TestInteractionUsecase_ReplyToComment(t *testing.T) {
+	ctx := context.Background()
+	userID := "user123"
+	blogID := "blog123"
+
+	setup := func(parent *domain.Comment, parentErr error) (*InteractionUsecase, *MockBlogRepository, *MockCommentRepository) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&domain.User{Username: "testuser"}, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{Status: domain.BlogStatusPublished}, nil)
+		mockCommentRepo.On("GetCommentByID", ctx, blogID, "parent1").Return(parent, parentErr)
+		mockStatsRepo := new(MockBlogStatsRepository)
+		mockStatsRepo.On("AddDailyStats", ctx, mock.Anything).Return(nil)
+		return &InteractionUsecase{blogRepo: mockBlogRepo, userRepo: mockUserRepo, commentRepo: mockCommentRepo, statsRepo: mockStatsRepo}, mockBlogRepo, mockCommentRepo
+	}
+
+	t.Run("reply is one level below its parent", func(t *testing.T) {
+		uc, mockBlogRepo, mockCommentRepo := setup(&domain.Comment{ID: "parent1", Depth: 2}, nil)
+		mockCommentRepo.On("CreateComment", ctx, mock.AnythingOfType("*domain.Comment")).Return(nil).Once()
+		mockBlogRepo.On("IncrementCommentCount", ctx, blogID, 1).Return(nil).Once()
+
+		reply := &domain.Comment{Content: "Reply", ParentID: "parent1", Depth: 9}
+		err := uc.CommentOnBlog(ctx, userID, blogID, "user", reply)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, reply.Depth)
+		mockBlogRepo.AssertExpectations(t)
+		mockCommentRepo.AssertExpectations(t)
+	})
+
+	t.Run("depth limit", func(t *testing.T) {
+		uc, _, mockCommentRepo := setup(&domain.Comment{ID: "parent1", Depth: domain.MaxCommentDepth}, nil)
+
+		err := uc.CommentOnBlog(ctx, userID, blogID, "user", &domain.Comment{Content: "Reply", ParentID: "parent1"})
+		assert.ErrorIs(t, err, domain.ErrCommentTooDeep)
+		mockCommentRepo.AssertNotCalled(t, "CreateComment")
+	})
+
+	t.Run("deleted parent", func(t *testing.T) {
+		uc, _, mockCommentRepo := setup(&domain.Comment{ID: "parent1", Deleted: true}, nil)
+
+		err := uc.CommentOnBlog(ctx, userID, blogID, "user", &domain.Comment{Content: "Reply", ParentID: "parent1"})
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+		mockCommentRepo.AssertNotCalled(t, "CreateComment")
+	})
+
+	t.Run("draft of another author", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockUserRepo := new(MockUserRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockUserRepo.On("FindByID", ctx, userID).Return(&domain.User{Username: "testuser"}, nil)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{AuthorID: "author1", Status: domain.BlogStatusDraft}, nil)
+		uc := NewInteractionUsecase(mockBlogRepo, mockUserRepo, mockCommentRepo, nil, nil, nil)
+
+		err := uc.CommentOnBlog(ctx, userID, blogID, "user", &domain.Comment{Content: "Comment"})
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+		mockCommentRepo.AssertNotCalled(t, "CreateComment")
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		uc, _, mockCommentRepo := setup(nil, domain.ErrCommentNotFound)
+
+		err := uc.CommentOnBlog(ctx, userID, blogID, "user", &domain.Comment{Content: "Reply", ParentID: "parent1"})
+		assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+		mockCommentRepo.AssertNotCalled(t, "CreateComment")
+	})
+}
+
+func TestInteractionUsecase_ListComments(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+	blog := &domain.Blog{ID: blogID, AuthorID: "author1", Status: domain.BlogStatusPublished}
+	// a page of threads as the repository returns it: the top-level comments, then their replies
+	threads := func() []*domain.Comment {
+		return []*domain.Comment{
+			{ID: "c1", Content: "First"},
+			{ID: "c3", Content: domain.DeletedCommentContent, Deleted: true},
+			{ID: "c6", ParentID: "gone", Content: "Orphan"},
+			{ID: "c2", ParentID: "c1", Content: "Reply to first"},
+			{ID: "c4", ParentID: "c2", Content: "Nested reply"},
+			{ID: "c5", ParentID: "c3", Content: "Reply to deleted"},
+		}
+	}
+	total := 3
+	pagination := &domain.Pagination{Total: &total, Page: 1, Limit: 20}
+
+	setup := func(blog *domain.Blog) (domain.InteractionUsecase, *MockCommentRepository) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockCommentRepo := new(MockCommentRepository)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(blog, nil)
+		mockCommentRepo.On("ListThreads", ctx, blogID, 1, 20).Return(threads(), pagination, nil)
+		return NewInteractionUsecase(mockBlogRepo, nil, mockCommentRepo, nil, nil, nil), mockCommentRepo
+	}
+
+	t.Run("tree", func(t *testing.T) {
+		uc, _ := setup(blog)
+
+		threads, page, err := uc.ListComments(ctx, blogID, "", "", false, 1, 20)
+		assert.NoError(t, err)
+		assert.Equal(t, pagination, page)
+		if assert.Len(t, threads, 3) {
+			assert.Equal(t, "c1", threads[0].ID)
+			assert.Equal(t, "c2", threads[0].Replies[0].ID)
+			assert.Equal(t, "c4", threads[0].Replies[0].Replies[0].ID)
+			assert.Equal(t, 2, threads[0].Replies[0].Replies[0].Depth)
+			assert.True(t, threads[1].Deleted)
+			assert.Equal(t, "c5", threads[1].Replies[0].ID)
+			assert.Equal(t, "c6", threads[2].ID)
+			assert.Equal(t, 0, threads[2].Depth)
+		}
+		assert.Equal(t, "<p>First</p>\n", threads[0].ContentHTML)
+	})
+
+	t.Run("flat in thread order", func(t *testing.T) {
+		uc, _ := setup(blog)
+
+		comments, _, err := uc.ListComments(ctx, blogID, "", "", true, 1, 20)
+		assert.NoError(t, err)
+		var ids []string
+		var depths []int
+		for _, c := range comments {
+			ids = append(ids, c.ID)
+			depths = append(depths, c.Depth)
+			assert.Nil(t, c.Replies)
+		}
+		assert.Equal(t, []string{"c1", "c2", "c4", "c3", "c5", "c6"}, ids)
+		assert.Equal(t, []int{0, 1, 2, 0, 1, 0}, depths)
+	})
+
+	t.Run("unpublished blog is hidden from other users", func(t *testing.T) {
+		draft := *blog
+		draft.Status = domain.BlogStatusDraft
+		uc, mockCommentRepo := setup(&draft)
+
+		_, _, err := uc.ListComments(ctx, blogID, "someone", "user", false, 1, 20)
+		assert.ErrorIs(t, err, domain.ErrBlogNotFound)
+		mockCommentRepo.AssertNotCalled(t, "ListThreads")
+
+		comments, _, err := uc.ListComments(ctx, blogID, "author1", "user", true, 1, 20)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 6)
+	})
+}
@@ -82,7 +82,7 @@ func (uc *OAuthUsecase) OAuthLogin(
 
 	if existingUser != nil {
 		// User exists, generate tokens for them
-		accessToken, err := uc.jwtService.GenerateAccessToken(existingUser.ID, existingUser.Role)
+		accessToken, accessClaims, err := uc.jwtService.IssueAccessToken(existingUser.ID, existingUser.Role)
 		if err != nil {
 			return "", "", 0, nil, fmt.Errorf("failed to generate access token: %w", err)
 		}
@@ -93,11 +93,7 @@ func (uc *OAuthUsecase) OAuthLogin(
 
 		// Store the refresh token in the database
 		expiry := time.Now().Add(uc.jwtService.RefreshExpiry)
-		newRefreshToken := &domain.RefreshToken{
-			UserID:    existingUser.ID,
-			Token:     refreshToken,
-			ExpiresAt: expiry,
-		}
+		newRefreshToken := newSessionToken(ctx, existingUser.ID, refreshToken, expiry, accessClaims)
 
 		if err := uc.tokenRepo.StoreRefreshToken(ctx, newRefreshToken); err != nil {
 			return "", "", 0, nil, fmt.Errorf("failed to store refresh token: %w", err)
@@ -128,7 +124,7 @@ func (uc *OAuthUsecase) OAuthLogin(
 
 		// --- Generate Application-Specific Tokens ---
 		// Generate access token
-		accessToken, err := uc.jwtService.GenerateAccessToken(user.ID, user.Role)
+		accessToken, accessClaims, err := uc.jwtService.IssueAccessToken(user.ID, user.Role)
 		if err != nil {
 			return "", "", 0, nil, errors.New("failed to generate access token")
 		}
@@ -141,11 +137,7 @@ func (uc *OAuthUsecase) OAuthLogin(
 
 		// Store the refresh token in the database
 		expiry := time.Now().Add(uc.jwtService.RefreshExpiry)
-		newRefreshToken := &domain.RefreshToken{
-			UserID:    user.ID,
-			Token:     refreshToken,
-			ExpiresAt: expiry,
-		}
+		newRefreshToken := newSessionToken(ctx, user.ID, refreshToken, expiry, accessClaims)
 
 		if err := uc.tokenRepo.StoreRefreshToken(ctx, newRefreshToken); err != nil {
 			return "", "", 0, nil, errors.New("failed to store refresh token")
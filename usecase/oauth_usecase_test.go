@@ -64,6 +64,11 @@ func (m *MockOAuthUserRepository) GetAllUsers(ctx context.Context, page int, lim
 	return args.Get(0).([]domain.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockOAuthUserRepository) IncrementFollowCounts(ctx context.Context, userID string, followers, following int) error {
+	args := m.Called(ctx, userID, followers, following)
+	return args.Error(0)
+}
+
 // MockOAuthTokenRepository is a mock implementation of the TokenRepository for OAuth tests.
 type MockOAuthTokenRepository struct {
 	mock.Mock
@@ -82,6 +87,22 @@ func (m *MockOAuthTokenRepository) FindRefreshToken(ctx context.Context, token s
 	return args.Get(0).(*domain.RefreshToken), args.Error(1)
 }
 
+func (m *MockOAuthTokenRepository) ConsumeRefreshToken(ctx context.Context, token string) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RefreshToken), args.Error(1)
+}
+
+func (m *MockOAuthTokenRepository) RevokeFamily(ctx context.Context, familyID string) ([]*domain.RefreshToken, error) {
+	args := m.Called(ctx, familyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RefreshToken), args.Error(1)
+}
+
 func (m *MockOAuthTokenRepository) DeleteRefreshToken(ctx context.Context, token string) error {
 	args := m.Called(ctx, token)
 	return args.Error(0)
@@ -92,6 +113,22 @@ func (m *MockOAuthTokenRepository) DeleteAllForUser(ctx context.Context, userID
 	return args.Error(0)
 }
 
+func (m *MockOAuthTokenRepository) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Session), args.Error(1)
+}
+
+func (m *MockOAuthTokenRepository) RevokeSession(ctx context.Context, userID, sessionID string) ([]*domain.RefreshToken, error) {
+	args := m.Called(ctx, userID, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.RefreshToken), args.Error(1)
+}
+
 func TestOAuthUsecase_Placeholder(t *testing.T) {
 	// This is a placeholder test to make the file compile.
 	// Testing the full OAuthLogin method is complex as it involves external calls.
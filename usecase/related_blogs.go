@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/utils"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Weights of each signal in how closely two blogs relate; every signal is between 0 and 1, and so is the sum
+const (
+	relatedTagWeight    = 0.5
+	relatedAuthorWeight = 0.2
+	relatedTermWeight   = 0.3
+)
+
+// relatedCandidates is how many blogs each of the repository's candidate queries returns for ranking
+const relatedCandidates = 50
+
+// relatedCacheTTL bounds how long a related list misses out on changes to the other blogs
+const relatedCacheTTL = 30 * time.Minute
+
+func relatedCacheKey(id string) string {
+	return "related:" + id
+}
+
+// RelatedBlogs ranks the candidates from the repository by the tags they share with the blog (Jaccard
+// similarity), whether they have the same author, and the cosine similarity of their titles and content
+// weighted by TF-IDF over the candidates. The ranking is cached until the blog's tags change.
+func (u *blogUsecase) RelatedBlogs(ctx context.Context, id, userid, role string, limit int) ([]*domain.Blog, error) {
+	blog, err := u.repo.GetBlogByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canView(blog, userid, role) {
+		return nil, domain.ErrBlogNotFound
+	}
+
+	related, found := u.cache.Get(relatedCacheKey(id))
+	ranked, ok := related.([]*domain.Blog)
+	if !found || !ok {
+		ranked, err = u.rankRelated(ctx, blog)
+		if err != nil {
+			return nil, err
+		}
+		u.cache.Set(relatedCacheKey(id), ranked, relatedCacheTTL)
+	}
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	// the list is shared through the cache, so appending to it must not write into it
+	return slices.Clip(ranked), nil
+}
+
+// rankRelated scores the blog's candidates and keeps the domain.MaxRelatedBlogs best. Candidates sharing
+// nothing with the blog are dropped.
+func (u *blogUsecase) rankRelated(ctx context.Context, blog *domain.Blog) ([]*domain.Blog, error) {
+	candidates, err := u.repo.RelatedCandidates(ctx, blog, relatedCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]map[string]float64, len(candidates))
+	for i, candidate := range candidates {
+		counts[i] = termCounts(candidate)
+	}
+	sourceCounts := termCounts(blog)
+	idf := inverseDocumentFrequency(append(counts, sourceCounts))
+	source := tfidf(sourceCounts, idf)
+
+	ranked := make([]*domain.Blog, 0, len(candidates))
+	for i, candidate := range candidates {
+		score := relatedTagWeight*jaccard(blog.Tags, candidate.Tags) +
+			relatedTermWeight*cosine(source, tfidf(counts[i], idf))
+		if candidate.AuthorID == blog.AuthorID {
+			score += relatedAuthorWeight
+		}
+		if score <= 0 {
+			continue
+		}
+		related := *candidate
+		ensureRendered(&related)
+		related.Score = score
+		ranked = append(ranked, &related)
+	}
+	// ties go to the newer blog
+	slices.SortStableFunc(ranked, func(a, b *domain.Blog) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(blogCreatedAt(b), blogCreatedAt(a))
+	})
+	if len(ranked) > domain.MaxRelatedBlogs {
+		ranked = ranked[:domain.MaxRelatedBlogs]
+	}
+	return ranked, nil
+}
+
+func blogCreatedAt(blog *domain.Blog) int64 {
+	if blog.CreatedAt == nil {
+		return 0
+	}
+	return blog.CreatedAt.UnixNano()
+}
+
+// termCounts counts the stemmed words of a blog's title and content
+func termCounts(blog *domain.Blog) map[string]float64 {
+	counts := make(map[string]float64)
+	for _, term := range utils.SearchTerms(blog.Title + " " + blog.Content) {
+		counts[term]++
+	}
+	return counts
+}
+
+// inverseDocumentFrequency weighs each term by how few of the documents use it
+func inverseDocumentFrequency(docs []map[string]float64) map[string]float64 {
+	idf := make(map[string]float64)
+	for _, doc := range docs {
+		for term := range doc {
+			idf[term]++
+		}
+	}
+	for term, df := range idf {
+		idf[term] = math.Log(1 + float64(len(docs))/df)
+	}
+	return idf
+}
+
+func tfidf(counts, idf map[string]float64) map[string]float64 {
+	weights := make(map[string]float64, len(counts))
+	for term, count := range counts {
+		weights[term] = count * idf[term]
+	}
+	return weights
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, weight := range a {
+		dot += weight * b[term]
+		normA += weight * weight
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// jaccard is the share of all the tags on either blog that are on both, ignoring case
+func jaccard(a, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, tag := range a {
+		set[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	union := len(set)
+	shared := 0
+	seen := make(map[string]bool, len(b))
+	for _, tag := range b {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		if set[tag] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// sameTags reports whether two lists hold the same tags, in any order and ignoring case
+func sameTags(a, b []string) bool {
+	return jaccard(a, b) == 1 || len(a) == 0 && len(b) == 0
+}
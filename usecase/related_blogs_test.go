@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestBlogUsecase_RelatedBlogs(t *testing.T) {
+	ctx := context.Background()
+	source := &domain.Blog{
+		ID: "source", AuthorID: "author", Status: domain.BlogStatusPublished,
+		Title: "Concurrency in Go", Content: "Goroutines and channels make concurrency simple.", Tags: []string{"go", "concurrency"},
+	}
+	sharedTags := &domain.Blog{ID: "tags", AuthorID: "other", Title: "Worker pools", Content: "A pool of workers.", Tags: []string{"Go", "concurrency"}}
+	sameAuthor := &domain.Blog{ID: "author", AuthorID: "author", Title: "My garden", Content: "Tomatoes grow well.", Tags: []string{"garden"}}
+	sameWords := &domain.Blog{ID: "words", AuthorID: "other", Title: "Channels explained", Content: "Goroutines talk over channels.", Tags: []string{"tutorial"}}
+	nothing := &domain.Blog{ID: "nothing", AuthorID: "other", Title: "Baking", Content: "Bread needs flour.", Tags: []string{"food"}}
+
+	t.Run("ranks candidates and caches the ranking", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, cache.NewInMemoryCache(time.Minute, time.Minute))
+		mockBlogRepo.On("GetBlogByID", ctx, "source").Return(source, nil).Twice()
+		mockBlogRepo.On("RelatedCandidates", ctx, source, relatedCandidates).
+			Return([]*domain.Blog{nothing, sameWords, sameAuthor, sharedTags}, nil).Once()
+
+		related, err := uc.RelatedBlogs(ctx, "source", "", "", 5)
+		assert.NoError(t, err)
+		ids := make([]string, len(related))
+		for i, blog := range related {
+			ids[i] = blog.ID
+			assert.Greater(t, blog.Score, 0.0)
+		}
+		assert.Equal(t, []string{"tags", "author", "words"}, ids)
+		// the candidates themselves are left as the repository returned them
+		assert.Zero(t, sharedTags.Score)
+
+		related, err = uc.RelatedBlogs(ctx, "source", "", "", 1)
+		assert.NoError(t, err)
+		assert.Len(t, related, 1)
+		assert.Equal(t, "tags", related[0].ID)
+		mockBlogRepo.AssertExpectations(t)
+	})
+
+	t.Run("unpublished blogs are hidden from other readers", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		uc := NewBlogUsecase(mockBlogRepo, nil, nil, nil, nil, nil, nil, nil, nil, cache.NewInMemoryCache(time.Minute, time.Minute))
+		draft := &domain.Blog{ID: "draft", AuthorID: "author", Status: domain.BlogStatusDraft}
+		mockBlogRepo.On("GetBlogByID", ctx, "draft").Return(draft, nil).Once()
+
+		_, err := uc.RelatedBlogs(ctx, "draft", "stranger", "user", 5)
+		assert.Equal(t, domain.ErrBlogNotFound, err)
+		mockBlogRepo.AssertNotCalled(t, "RelatedCandidates", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("changing the tags drops the cached ranking", func(t *testing.T) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockRevisionRepo := new(MockBlogRevisionRepository)
+		related := cache.NewInMemoryCache(time.Minute, time.Minute)
+		uc := NewBlogUsecase(mockBlogRepo, nil, mockRevisionRepo, nil, nil, nil, nil, nil, nil, related)
+		blog := &domain.Blog{ID: "source", AuthorID: "author", Title: "Title", Slug: "title", Tags: []string{"go", "concurrency"}}
+		mockBlogRepo.On("GetBlogByID", ctx, "source").Return(blog, nil)
+		mockBlogRepo.On("UpdateBlog", ctx, mock.AnythingOfType("*domain.Blog")).Return(nil)
+		mockRevisionRepo.On("GetRevision", ctx, "source", 1).Return(&domain.BlogRevision{Number: 1}, nil)
+		mockRevisionRepo.On("CreateRevision", ctx, mock.Anything).Return(&domain.BlogRevision{Number: 2}, nil)
+
+		related.Set(relatedCacheKey("source"), []*domain.Blog{sharedTags}, time.Minute)
+		_, err := uc.UpdateBlog(ctx, &domain.Blog{Title: "Title", Tags: []string{"Concurrency", "go"}}, "author", "source")
+		assert.NoError(t, err)
+		_, found := related.Get(relatedCacheKey("source"))
+		assert.True(t, found, "the same tags in another order keep the ranking")
+
+		_, err = uc.UpdateBlog(ctx, &domain.Blog{Title: "Title", Tags: []string{"go"}}, "author", "source")
+		assert.NoError(t, err)
+		_, found = related.Get(relatedCacheKey("source"))
+		assert.False(t, found)
+	})
+}
+
+func TestJaccard(t *testing.T) {
+	assert.Equal(t, 1.0, jaccard([]string{"go", "Web"}, []string{"web", "go"}))
+	assert.Equal(t, 1.0/3, jaccard([]string{"go", "web"}, []string{"go", "db"}))
+	assert.Zero(t, jaccard(nil, nil))
+	assert.True(t, sameTags(nil, []string{}))
+	assert.False(t, sameTags(nil, []string{"go"}))
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"slices"
+)
+
+type SitemapUsecase struct {
+	repo      domain.SitemapRepository
+	chunkSize int
+}
+
+// NewSitemapUsecase splits each sitemap section into files of at most domain.SitemapMaxURLs URLs
+func NewSitemapUsecase(repo domain.SitemapRepository) domain.SitemapUsecase {
+	return &SitemapUsecase{repo: repo, chunkSize: domain.SitemapMaxURLs}
+}
+
+// Index lists every sitemap file, section by section, with the ID each file starts after. Empty
+// sections have no files.
+func (u *SitemapUsecase) Index(ctx context.Context) ([]*domain.SitemapChunk, error) {
+	var chunks []*domain.SitemapChunk
+	for _, section := range domain.SitemapSections {
+		summary, err := u.repo.Summarize(ctx, section)
+		if err != nil {
+			return nil, err
+		}
+		pages := (summary.Count + u.chunkSize - 1) / u.chunkSize
+		after := ""
+		for page := 1; page <= pages; page++ {
+			if page > 1 {
+				if after, err = u.repo.LastID(ctx, section, after, u.chunkSize); err != nil {
+					return nil, err
+				}
+			}
+			chunks = append(chunks, &domain.SitemapChunk{Section: section, Page: page, After: after, LastModified: summary.LastModified})
+		}
+	}
+	return chunks, nil
+}
+
+// StreamChunk calls fn for each entry of one sitemap file, the one starting after the given ID. Only
+// the first page starts at the beginning of its section.
+// It returns domain.ErrSitemapNotFound for an unknown section or a page past the end.
+func (u *SitemapUsecase) StreamChunk(ctx context.Context, section domain.SitemapSection, page int, after string, fn func(*domain.SitemapEntry) error) error {
+	if !slices.Contains(domain.SitemapSections, section) || page < 1 || (page == 1) != (after == "") {
+		return domain.ErrSitemapNotFound
+	}
+	found := false
+	err := u.repo.Stream(ctx, section, after, u.chunkSize, func(entry *domain.SitemapEntry) error {
+		found = true
+		return fn(entry)
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return domain.ErrSitemapNotFound
+	}
+	return nil
+}
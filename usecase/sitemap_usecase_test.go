@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSitemapRepository struct {
+	mock.Mock
+}
+
+func (m *MockSitemapRepository) Summarize(ctx context.Context, section domain.SitemapSection) (*domain.SitemapSummary, error) {
+	args := m.Called(ctx, section)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SitemapSummary), args.Error(1)
+}
+
+func (m *MockSitemapRepository) LastID(ctx context.Context, section domain.SitemapSection, after string, limit int) (string, error) {
+	args := m.Called(ctx, section, after, limit)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSitemapRepository) Stream(ctx context.Context, section domain.SitemapSection, after string, limit int, fn func(*domain.SitemapEntry) error) error {
+	args := m.Called(ctx, section, after, limit, fn)
+	if entries, ok := args.Get(0).([]*domain.SitemapEntry); ok {
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func TestSitemapUsecase_Index(t *testing.T) {
+	ctx := context.Background()
+	blogsModified := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	tagsModified := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("splits sections into chunks", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := &SitemapUsecase{repo: mockRepo, chunkSize: 2}
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionBlogs).Return(&domain.SitemapSummary{Count: 5, LastModified: &blogsModified}, nil)
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionTags).Return(&domain.SitemapSummary{Count: 2, LastModified: &tagsModified}, nil)
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionAuthors).Return(&domain.SitemapSummary{}, nil)
+		mockRepo.On("LastID", ctx, domain.SitemapSectionBlogs, "", 2).Return("b2", nil)
+		mockRepo.On("LastID", ctx, domain.SitemapSectionBlogs, "b2", 2).Return("b4", nil)
+
+		chunks, err := u.Index(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.SitemapChunk{
+			{Section: domain.SitemapSectionBlogs, Page: 1, LastModified: &blogsModified},
+			{Section: domain.SitemapSectionBlogs, Page: 2, After: "b2", LastModified: &blogsModified},
+			{Section: domain.SitemapSectionBlogs, Page: 3, After: "b4", LastModified: &blogsModified},
+			{Section: domain.SitemapSectionTags, Page: 1, LastModified: &tagsModified},
+		}, chunks)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("uses the protocol limit by default", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := NewSitemapUsecase(mockRepo)
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionBlogs).Return(&domain.SitemapSummary{Count: domain.SitemapMaxURLs + 1}, nil)
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionTags).Return(&domain.SitemapSummary{Count: domain.SitemapMaxURLs}, nil)
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionAuthors).Return(&domain.SitemapSummary{Count: 1}, nil)
+		mockRepo.On("LastID", ctx, domain.SitemapSectionBlogs, "", domain.SitemapMaxURLs).Return("b50000", nil)
+
+		chunks, err := u.Index(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 4)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := NewSitemapUsecase(mockRepo)
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionBlogs).Return(nil, errors.New("db down"))
+
+		_, err := u.Index(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("error finding where a page starts", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := &SitemapUsecase{repo: mockRepo, chunkSize: 2}
+		mockRepo.On("Summarize", ctx, domain.SitemapSectionBlogs).Return(&domain.SitemapSummary{Count: 3}, nil)
+		mockRepo.On("LastID", ctx, domain.SitemapSectionBlogs, "", 2).Return("", errors.New("db down"))
+
+		_, err := u.Index(ctx)
+		assert.Error(t, err)
+	})
+}
+
+func TestSitemapUsecase_StreamChunk(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("streams the requested page", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := NewSitemapUsecase(mockRepo)
+		entries := []*domain.SitemapEntry{{ID: "go"}, {ID: "rust"}}
+		mockRepo.On("Stream", ctx, domain.SitemapSectionTags, "go", domain.SitemapMaxURLs, mock.Anything).Return(entries, nil)
+
+		var got []*domain.SitemapEntry
+		err := u.StreamChunk(ctx, domain.SitemapSectionTags, 2, "go", func(entry *domain.SitemapEntry) error {
+			got = append(got, entry)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, entries, got)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty page is not found", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := NewSitemapUsecase(mockRepo)
+		mockRepo.On("Stream", ctx, domain.SitemapSectionBlogs, "", domain.SitemapMaxURLs, mock.Anything).Return(nil, nil)
+
+		err := u.StreamChunk(ctx, domain.SitemapSectionBlogs, 1, "", func(*domain.SitemapEntry) error { return nil })
+		assert.ErrorIs(t, err, domain.ErrSitemapNotFound)
+	})
+
+	t.Run("unknown section or page", func(t *testing.T) {
+		mockRepo := new(MockSitemapRepository)
+		u := NewSitemapUsecase(mockRepo)
+
+		assert.ErrorIs(t, u.StreamChunk(ctx, "users", 1, "", nil), domain.ErrSitemapNotFound)
+		assert.ErrorIs(t, u.StreamChunk(ctx, domain.SitemapSectionBlogs, 0, "", nil), domain.ErrSitemapNotFound)
+		assert.ErrorIs(t, u.StreamChunk(ctx, domain.SitemapSectionBlogs, 2, "", nil), domain.ErrSitemapNotFound)
+		assert.ErrorIs(t, u.StreamChunk(ctx, domain.SitemapSectionBlogs, 1, "b2", nil), domain.ErrSitemapNotFound)
+		mockRepo.AssertNotCalled(t, "Stream")
+	})
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"time"
+)
+
+type StatsUsecase struct {
+	blogRepo  domain.BlogRepository
+	statsRepo domain.BlogStatsRepository
+}
+
+func NewStatsUsecase(blogRepo domain.BlogRepository, statsRepo domain.BlogStatsRepository) domain.StatsUsecase {
+	return &StatsUsecase{blogRepo: blogRepo, statsRepo: statsRepo}
+}
+
+// BlogStats returns a blog's time series between from and to, both inclusive days, with a bucket for
+// every day or week in the period, empty or not. Only the blog's author and admins may see it.
+func (u *StatsUsecase) BlogStats(ctx context.Context, blogID, userID, role string, from, to time.Time, granularity domain.StatsGranularity) ([]*domain.StatsBucket, error) {
+	from, to = domain.StatsDay(from), domain.StatsDay(to)
+	if err := validateStatsRange(from, to); err != nil {
+		return nil, err
+	}
+	var start func(time.Time) time.Time
+	step := 1
+	switch granularity {
+	case domain.StatsGranularityDay:
+		start = domain.StatsDay
+	case domain.StatsGranularityWeek:
+		start, step = domain.StatsWeek, 7
+	default:
+		return nil, domain.ErrInvalidGranularity
+	}
+
+	blog, err := u.blogRepo.GetBlogByID(ctx, blogID)
+	if err != nil {
+		return nil, err
+	}
+	if blog.AuthorID != userID && role != string(domain.RoleAdmin) {
+		return nil, domain.ErrUnauthorized
+	}
+
+	recorded, err := u.statsRepo.BlogStats(ctx, blogID, from, to, granularity)
+	if err != nil {
+		return nil, err
+	}
+	byStart := make(map[time.Time]domain.BlogStats, len(recorded))
+	for _, bucket := range recorded {
+		byStart[bucket.Start] = bucket.BlogStats
+	}
+	var buckets []*domain.StatsBucket
+	for day := start(from); !day.After(to); day = day.AddDate(0, 0, step) {
+		buckets = append(buckets, &domain.StatsBucket{Start: day, BlogStats: byStart[day]})
+	}
+	return buckets, nil
+}
+
+// AuthorDashboard sums the stats of every post by the user between from and to, both inclusive days
+func (u *StatsUsecase) AuthorDashboard(ctx context.Context, userID string, from, to time.Time) (*domain.AuthorDashboard, error) {
+	from, to = domain.StatsDay(from), domain.StatsDay(to)
+	if err := validateStatsRange(from, to); err != nil {
+		return nil, err
+	}
+	posts, err := u.statsRepo.AuthorStats(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	dashboard := &domain.AuthorDashboard{From: from, To: to, Posts: posts}
+	for _, post := range posts {
+		dashboard.Totals.Add(post.BlogStats)
+	}
+	return dashboard, nil
+}
+
+func validateStatsRange(from, to time.Time) error {
+	if to.Before(from) || to.Sub(from) >= domain.MaxStatsRange {
+		return domain.ErrInvalidStatsRange
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlogStatsRepository is a mock implementation of the BlogStatsRepository interface.
+type MockBlogStatsRepository struct {
+	mock.Mock
+}
+
+func (m *MockBlogStatsRepository) AddDailyStats(ctx context.Context, stats []*domain.DailyBlogStats) error {
+	args := m.Called(ctx, stats)
+	return args.Error(0)
+}
+
+func (m *MockBlogStatsRepository) BlogStats(ctx context.Context, blogID string, from, to time.Time, granularity domain.StatsGranularity) ([]*domain.StatsBucket, error) {
+	args := m.Called(ctx, blogID, from, to, granularity)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.StatsBucket), args.Error(1)
+}
+
+func (m *MockBlogStatsRepository) AuthorStats(ctx context.Context, authorID string, from, to time.Time) ([]*domain.PostStats, error) {
+	args := m.Called(ctx, authorID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.PostStats), args.Error(1)
+}
+
+func (m *MockBlogStatsRepository) DeleteBlogStats(ctx context.Context, blogID string) error {
+	args := m.Called(ctx, blogID)
+	return args.Error(0)
+}
+
+func (m *MockBlogStatsRepository) UpdateTrendingScores(ctx context.Context, now time.Time, halfLife time.Duration) error {
+	args := m.Called(ctx, now, halfLife)
+	return args.Error(0)
+}
+
+// todaysStats is the batch recorded for a single blog's activity today
+func todaysStats(blogID, authorID string, stats domain.BlogStats) []*domain.DailyBlogStats {
+	return []*domain.DailyBlogStats{{BlogID: blogID, AuthorID: authorID, Day: domain.StatsDay(time.Now()), BlogStats: stats}}
+}
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestStatsUsecase_BlogStats(t *testing.T) {
+	ctx := context.Background()
+	blogID := "blog123"
+
+	setup := func() (domain.StatsUsecase, *MockBlogStatsRepository) {
+		mockBlogRepo := new(MockBlogRepository)
+		mockStatsRepo := new(MockBlogStatsRepository)
+		mockBlogRepo.On("GetBlogByID", ctx, blogID).Return(&domain.Blog{ID: blogID, AuthorID: "author1"}, nil)
+		return NewStatsUsecase(mockBlogRepo, mockStatsRepo), mockStatsRepo
+	}
+
+	t.Run("fills the days without activity", func(t *testing.T) {
+		uc, mockStatsRepo := setup()
+		from, to := day(2025, 3, 1), day(2025, 3, 3)
+		mockStatsRepo.On("BlogStats", ctx, blogID, from, to, domain.StatsGranularityDay).Return([]*domain.StatsBucket{
+			{Start: day(2025, 3, 2), BlogStats: domain.BlogStats{Views: 5, UniqueViewers: 4, Reactions: 1}},
+		}, nil).Once()
+
+		// times within a day are rounded down to it
+		buckets, err := uc.BlogStats(ctx, blogID, "author1", "user", from.Add(13*time.Hour), to.Add(time.Hour), domain.StatsGranularityDay)
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.StatsBucket{
+			{Start: day(2025, 3, 1)},
+			{Start: day(2025, 3, 2), BlogStats: domain.BlogStats{Views: 5, UniqueViewers: 4, Reactions: 1}},
+			{Start: day(2025, 3, 3)},
+		}, buckets)
+		mockStatsRepo.AssertExpectations(t)
+	})
+
+	t.Run("weeks start on Monday", func(t *testing.T) {
+		uc, mockStatsRepo := setup()
+		// Wednesday 5 March to Tuesday 18 March 2025
+		from, to := day(2025, 3, 5), day(2025, 3, 18)
+		mockStatsRepo.On("BlogStats", ctx, blogID, from, to, domain.StatsGranularityWeek).Return([]*domain.StatsBucket{
+			{Start: day(2025, 3, 10), BlogStats: domain.BlogStats{Views: 7}},
+		}, nil).Once()
+
+		buckets, err := uc.BlogStats(ctx, blogID, "admin1", "admin", from, to, domain.StatsGranularityWeek)
+		assert.NoError(t, err)
+		assert.Equal(t, []*domain.StatsBucket{
+			{Start: day(2025, 3, 3)},
+			{Start: day(2025, 3, 10), BlogStats: domain.BlogStats{Views: 7}},
+			{Start: day(2025, 3, 17)},
+		}, buckets)
+	})
+
+	t.Run("other users are refused", func(t *testing.T) {
+		uc, mockStatsRepo := setup()
+
+		_, err := uc.BlogStats(ctx, blogID, "reader", "user", day(2025, 3, 1), day(2025, 3, 3), domain.StatsGranularityDay)
+		assert.ErrorIs(t, err, domain.ErrUnauthorized)
+		mockStatsRepo.AssertNotCalled(t, "BlogStats")
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		uc, _ := setup()
+
+		_, err := uc.BlogStats(ctx, blogID, "author1", "user", day(2025, 3, 3), day(2025, 3, 1), domain.StatsGranularityDay)
+		assert.ErrorIs(t, err, domain.ErrInvalidStatsRange)
+		_, err = uc.BlogStats(ctx, blogID, "author1", "user", day(2023, 1, 1), day(2025, 1, 1), domain.StatsGranularityDay)
+		assert.ErrorIs(t, err, domain.ErrInvalidStatsRange)
+		_, err = uc.BlogStats(ctx, blogID, "author1", "user", day(2025, 3, 1), day(2025, 3, 3), "month")
+		assert.ErrorIs(t, err, domain.ErrInvalidGranularity)
+	})
+}
+
+func TestStatsUsecase_AuthorDashboard(t *testing.T) {
+	ctx := context.Background()
+	from, to := day(2025, 3, 1), day(2025, 3, 30)
+
+	t.Run("sums every post", func(t *testing.T) {
+		mockStatsRepo := new(MockBlogStatsRepository)
+		uc := NewStatsUsecase(nil, mockStatsRepo)
+		posts := []*domain.PostStats{
+			{BlogID: "blog1", Title: "First", BlogStats: domain.BlogStats{Views: 10, UniqueViewers: 8, Reactions: 3, Comments: 2}},
+			{BlogID: "blog2", Title: "Second", BlogStats: domain.BlogStats{Views: 4, UniqueViewers: 4, Reactions: -1, Comments: 1}},
+		}
+		mockStatsRepo.On("AuthorStats", ctx, "author1", from, to).Return(posts, nil).Once()
+
+		dashboard, err := uc.AuthorDashboard(ctx, "author1", from, to)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.BlogStats{Views: 14, UniqueViewers: 12, Reactions: 2, Comments: 3}, dashboard.Totals)
+		assert.Equal(t, posts, dashboard.Posts)
+		assert.Equal(t, from, dashboard.From)
+		assert.Equal(t, to, dashboard.To)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		mockStatsRepo := new(MockBlogStatsRepository)
+		uc := NewStatsUsecase(nil, mockStatsRepo)
+		mockStatsRepo.On("AuthorStats", ctx, "author1", from, to).Return(nil, errors.New("db down")).Once()
+
+		_, err := uc.AuthorDashboard(ctx, "author1", from, to)
+		assert.Error(t, err)
+	})
+}
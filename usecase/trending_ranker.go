@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"g3-g65-bsp/infrastructure/cache"
+	"time"
+)
+
+// TrendingRanker periodically rescores blogs for sortBy=trending from their recent daily stats.
+// Rescoring is idempotent, so several API replicas can run it at once.
+type TrendingRanker struct {
+	repo domain.BlogStatsRepository
+	// pages holds the cached blog lists, dropped after every rescore so trending lists follow the new scores
+	pages    cache.Service
+	halfLife time.Duration
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewTrendingRanker creates a ranker that rescores every interval, activity losing half its weight
+// every halfLife. pages is the cache the blog list pages are kept in. now is the clock scores decay
+// against; nil means time.Now.
+func NewTrendingRanker(repo domain.BlogStatsRepository, pages cache.Service, halfLife, interval time.Duration, now func() time.Time) *TrendingRanker {
+	if now == nil {
+		now = time.Now
+	}
+	return &TrendingRanker{
+		repo:     repo,
+		pages:    pages,
+		halfLife: halfLife,
+		interval: interval,
+		now:      now,
+	}
+}
+
+// Start runs the ranker until ctx is cancelled.
+func (t *TrendingRanker) Start(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		if err := t.Rescore(ctx); err != nil && ctx.Err() == nil {
+			infrastructure.Log.Printf("Updating trending scores failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Rescore brings every blog's trending score up to date. The scores are written straight to the
+// database, so the cached blog lists are dropped afterwards.
+func (t *TrendingRanker) Rescore(ctx context.Context) error {
+	if err := t.repo.UpdateTrendingScores(ctx, t.now(), t.halfLife); err != nil {
+		return err
+	}
+	cache.InvalidateGroup(t.pages, domain.BlogListCacheGroup)
+	return nil
+}
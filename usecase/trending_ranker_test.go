@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure/cache"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendingRanker_Rescore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("rescores as of now", func(t *testing.T) {
+		mockStatsRepo := new(MockBlogStatsRepository)
+		pages := cache.NewInMemoryCache(time.Minute, time.Minute)
+		ranker := NewTrendingRanker(mockStatsRepo, pages, 12*time.Hour, time.Minute, clock)
+		mockStatsRepo.On("UpdateTrendingScores", ctx, now, 12*time.Hour).Return(nil).Once()
+		pages.Set(cache.GroupKey(pages, domain.BlogListCacheGroup, "/blogs/?sortBy=trending"), "stale list", time.Minute)
+
+		assert.NoError(t, ranker.Rescore(ctx))
+		_, found := pages.Get(cache.GroupKey(pages, domain.BlogListCacheGroup, "/blogs/?sortBy=trending"))
+		assert.False(t, found)
+		mockStatsRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		mockStatsRepo := new(MockBlogStatsRepository)
+		pages := cache.NewInMemoryCache(time.Minute, time.Minute)
+		ranker := NewTrendingRanker(mockStatsRepo, pages, 12*time.Hour, time.Minute, clock)
+		mockStatsRepo.On("UpdateTrendingScores", ctx, now, 12*time.Hour).Return(errors.New("db down")).Once()
+
+		assert.Error(t, ranker.Rescore(ctx))
+	})
+}
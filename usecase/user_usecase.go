@@ -5,17 +5,21 @@ import (
 	"errors"
 	"g3-g65-bsp/domain"
 	"io"
+	"time"
 )
 
 type UserUsecase struct {
 	userRepo      domain.UserRepository
 	imageUploader domain.ImageUploader
+	revocations   domain.TokenRevocationRepository
 }
 
-func NewUserUsecase(ur domain.UserRepository, iu domain.ImageUploader) domain.UserUsecase {
+// NewUserUsecase revokes a demoted user's access tokens through revocations, as they still name the old role
+func NewUserUsecase(ur domain.UserRepository, iu domain.ImageUploader, revocations domain.TokenRevocationRepository) domain.UserUsecase {
 	return &UserUsecase{
 		userRepo:      ur,
 		imageUploader: iu,
+		revocations:   revocations,
 	}
 }
 
@@ -55,7 +59,11 @@ func (upd *UserUsecase) Demote(ctx context.Context, userid, Email string) error
 		return ErrAlreadyHasRole
 	}
 
-	return upd.userRepo.UpdateUserRole(ctx, string(domain.RoleUser), Email)
+	if err := upd.userRepo.UpdateUserRole(ctx, string(domain.RoleUser), Email); err != nil {
+		return err
+	}
+	// tokens issued before now still carry the admin role; the user gets a new one by refreshing
+	return upd.revocations.RevokeTokensIssuedBefore(ctx, targetUser.ID, time.Now())
 }
 
 func (upd *UserUsecase) ProfileUpdate(ctx context.Context, userid string, bio string, contactinfo string, file io.Reader) error {
@@ -29,7 +29,7 @@ func TestUserUsecase_Promote(t *testing.T) {
 
 	t.Run("successful promotion", func(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
-		uc := NewUserUsecase(mockUserRepo, nil)
+		uc := NewUserUsecase(mockUserRepo, nil, nil)
 		user := &domain.User{ID: userID, Email: userEmail, Role: string(domain.RoleUser)}
 		mockUserRepo.On("FindByEmail", ctx, userEmail).Return(user, nil).Once()
 		mockUserRepo.On("UpdateUserRole", ctx, string(domain.RoleAdmin), userEmail).Return(nil).Once()
@@ -41,7 +41,7 @@ func TestUserUsecase_Promote(t *testing.T) {
 
 	t.Run("cannot promote self", func(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
-		uc := NewUserUsecase(mockUserRepo, nil)
+		uc := NewUserUsecase(mockUserRepo, nil, nil)
 		user := &domain.User{ID: userID, Email: userEmail, Role: string(domain.RoleUser)}
 		mockUserRepo.On("FindByEmail", ctx, userEmail).Return(user, nil).Once()
 
@@ -53,7 +53,7 @@ func TestUserUsecase_Promote(t *testing.T) {
 
 	t.Run("user already has the target role", func(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
-		uc := NewUserUsecase(mockUserRepo, nil)
+		uc := NewUserUsecase(mockUserRepo, nil, nil)
 		adminUser := &domain.User{ID: "admin456", Email: "admin@example.com", Role: string(domain.RoleAdmin)}
 		mockUserRepo.On("FindByEmail", ctx, "admin@example.com").Return(adminUser, nil).Once()
 
@@ -72,19 +72,23 @@ func TestUserUsecase_Demote(t *testing.T) {
 
 	t.Run("successful demotion", func(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
-		uc := NewUserUsecase(mockUserRepo, nil)
+		mockRevocations := new(MockTokenRevocationRepository)
+		uc := NewUserUsecase(mockUserRepo, nil, mockRevocations)
 		user := &domain.User{ID: userID, Email: userEmail, Role: string(domain.RoleAdmin)}
 		mockUserRepo.On("FindByEmail", ctx, userEmail).Return(user, nil).Once()
 		mockUserRepo.On("UpdateUserRole", ctx, string(domain.RoleUser), userEmail).Return(nil).Once()
+		// the admin tokens the user already holds stop working
+		mockRevocations.On("RevokeTokensIssuedBefore", ctx, userID, mock.AnythingOfType("time.Time")).Return(nil).Once()
 
 		err := uc.Demote(ctx, adminID, userEmail)
 		assert.NoError(t, err)
 		mockUserRepo.AssertExpectations(t)
+		mockRevocations.AssertExpectations(t)
 	})
 
 	t.Run("cannot demote self", func(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
-		uc := NewUserUsecase(mockUserRepo, nil)
+		uc := NewUserUsecase(mockUserRepo, nil, nil)
 		user := &domain.User{ID: userID, Email: userEmail, Role: string(domain.RoleAdmin)}
 		mockUserRepo.On("FindByEmail", ctx, userEmail).Return(user, nil).Once()
 
@@ -96,7 +100,7 @@ func TestUserUsecase_Demote(t *testing.T) {
 
 	t.Run("user already has the target role", func(t *testing.T) {
 		mockUserRepo := new(MockUserRepository)
-		uc := NewUserUsecase(mockUserRepo, nil)
+		uc := NewUserUsecase(mockUserRepo, nil, nil)
 		normalUser := &domain.User{ID: "user456", Email: "normal@example.com", Role: string(domain.RoleUser)}
 		mockUserRepo.On("FindByEmail", ctx, "normal@example.com").Return(normalUser, nil).Once()
 
@@ -110,7 +114,7 @@ func TestUserUsecase_Demote(t *testing.T) {
 func TestUserUsecase_ProfileUpdate(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockImageUploader := new(MockImageUploader)
-	uc := NewUserUsecase(mockUserRepo, mockImageUploader)
+	uc := NewUserUsecase(mockUserRepo, mockImageUploader, nil)
 
 	ctx := context.Background()
 	userID := "user123"
@@ -134,7 +138,7 @@ func TestUserUsecase_ProfileUpdate(t *testing.T) {
 
 func TestUserUsecase_GetAllUsers(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
-	uc := NewUserUsecase(mockUserRepo, nil)
+	uc := NewUserUsecase(mockUserRepo, nil, nil)
 
 	ctx := context.Background()
 	page := 1
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"g3-g65-bsp/domain"
+	"g3-g65-bsp/infrastructure"
+	"sync"
+	"time"
+)
+
+// finalFlushTimeout bounds the flush Start makes on its way out, so a stuck database cannot hold up shutdown forever
+const finalFlushTimeout = 30 * time.Second
+
+// ViewCounter counts unique views. A reader is counted at most once per blog within the window, and the
+// counted views are kept in memory and written to the repository in batches, together with each blog's
+// daily stats: the views counted that day and how many distinct readers it had.
+// The dedup state is per process, so with several replicas a reader can be counted once by each.
+type ViewCounter struct {
+	repo     domain.BlogRepository
+	stats    domain.BlogStatsRepository
+	window   time.Duration
+	interval time.Duration
+	now      func() time.Time
+
+	mu           sync.Mutex
+	seen         map[string]time.Time                // blog and reader -> when their view was last counted
+	viewedOn     map[string]time.Time                // blog and reader -> the last day they were counted as a unique viewer
+	pending      map[string]int                      // blog ID -> views not yet written
+	pendingStats map[dailyKey]*domain.DailyBlogStats // blog and day -> stats not yet written
+}
+
+type dailyKey struct {
+	blogID string
+	day    time.Time
+}
+
+// NewViewCounter creates a counter that ignores repeat views within window and writes every interval.
+// now is the clock used for the window; nil means time.Now.
+func NewViewCounter(repo domain.BlogRepository, stats domain.BlogStatsRepository, window, interval time.Duration, now func() time.Time) *ViewCounter {
+	if now == nil {
+		now = time.Now
+	}
+	return &ViewCounter{
+		repo:         repo,
+		stats:        stats,
+		window:       window,
+		interval:     interval,
+		now:          now,
+		seen:         make(map[string]time.Time),
+		viewedOn:     make(map[string]time.Time),
+		pending:      make(map[string]int),
+		pendingStats: make(map[dailyKey]*domain.DailyBlogStats),
+	}
+}
+
+// Record counts a view of the blog unless the reader was already counted for it within the window, and
+// reports whether it was counted. reader identifies the viewer; see ViewerKey.
+// A reader's first read of the day makes them one of the day's unique viewers even if the view itself
+// falls within the window of one from the day before.
+func (c *ViewCounter) Record(blog *domain.Blog, reader string) bool {
+	key := blog.ID + "|" + reader
+	now := c.now()
+	day := domain.StatsDay(now)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	unique := !c.viewedOn[key].Equal(day)
+	last, ok := c.seen[key]
+	counted := !ok || now.Sub(last) >= c.window
+	if !unique && !counted {
+		return false
+	}
+
+	daily := c.pendingStats[dailyKey{blog.ID, day}]
+	if daily == nil {
+		daily = &domain.DailyBlogStats{BlogID: blog.ID, AuthorID: blog.AuthorID, Day: day}
+		c.pendingStats[dailyKey{blog.ID, day}] = daily
+	}
+	if unique {
+		c.viewedOn[key] = day
+		daily.UniqueViewers++
+	}
+	if counted {
+		c.seen[key] = now
+		c.pending[blog.ID]++
+		daily.Views++
+	}
+	return counted
+}
+
+// Flush writes the pending views and daily stats. When a batch is only partly written, the entries that
+// failed are kept for the next flush. When a write fails in any other way there is no telling which of
+// its entries were applied, so the batch is dropped: views may be lost, but are never counted twice.
+func (c *ViewCounter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	views, stats := c.pending, c.pendingStats
+	c.pending = make(map[string]int)
+	c.pendingStats = make(map[dailyKey]*domain.DailyBlogStats)
+	now := c.now()
+	today := domain.StatsDay(now)
+	for key, last := range c.seen {
+		if now.Sub(last) >= c.window {
+			delete(c.seen, key)
+		}
+	}
+	for key, day := range c.viewedOn {
+		if !day.Equal(today) {
+			delete(c.viewedOn, key)
+		}
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	if len(views) > 0 {
+		err := c.repo.IncrementViewCounts(ctx, views)
+		var partial *domain.PartialWriteError[string]
+		if errors.As(err, &partial) {
+			c.mu.Lock()
+			for _, id := range partial.Failed {
+				c.pending[id] += views[id]
+			}
+			c.mu.Unlock()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(stats) > 0 {
+		keys := make([]dailyKey, 0, len(stats))
+		batch := make([]*domain.DailyBlogStats, 0, len(stats))
+		for key, daily := range stats {
+			keys = append(keys, key)
+			batch = append(batch, daily)
+		}
+		err := c.stats.AddDailyStats(ctx, batch)
+		var partial *domain.PartialWriteError[int]
+		if errors.As(err, &partial) {
+			c.mu.Lock()
+			for _, i := range partial.Failed {
+				if kept := c.pendingStats[keys[i]]; kept != nil {
+					kept.Add(batch[i].BlogStats)
+				} else {
+					c.pendingStats[keys[i]] = batch[i]
+				}
+			}
+			c.mu.Unlock()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Start flushes every interval until ctx is cancelled, then flushes once more before returning, so
+// views recorded before shutdown are not lost.
+func (c *ViewCounter) Start(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+			defer cancel()
+			if err := c.Flush(flushCtx); err != nil {
+				infrastructure.Log.Printf("Writing view counts on shutdown failed: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := c.Flush(ctx); err != nil && ctx.Err() == nil {
+				infrastructure.Log.Printf("Writing view counts failed: %v", err)
+			}
+		}
+	}
+}
+
+// ViewerKey identifies a reader for deduplication: signed-in readers by user ID, anonymous ones by a hash
+// of their address so the address itself is never held onto
+func ViewerKey(userID, clientIP string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	sum := sha256.Sum256([]byte(clientIP))
+	return "ip:" + hex.EncodeToString(sum[:16])
+}
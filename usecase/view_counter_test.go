@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"g3-g65-bsp/domain"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// dailyStats matches a batch of daily stats in any order; expected is ordered by day, then blog
+func dailyStats(expected ...*domain.DailyBlogStats) any {
+	return mock.MatchedBy(func(batch []*domain.DailyBlogStats) bool {
+		sorted := slices.Clone(batch)
+		slices.SortFunc(sorted, func(a, b *domain.DailyBlogStats) int {
+			return cmp.Or(a.Day.Compare(b.Day), strings.Compare(a.BlogID, b.BlogID))
+		})
+		return assert.ObjectsAreEqual(expected, sorted)
+	})
+}
+
+func TestViewCounter_Record(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	ctx := context.Background()
+	blog1 := &domain.Blog{ID: "blog1", AuthorID: "author1"}
+	blog2 := &domain.Blog{ID: "blog2", AuthorID: "author2"}
+
+	mockBlogRepo := new(MockBlogRepository)
+	mockStatsRepo := new(MockBlogStatsRepository)
+	counter := NewViewCounter(mockBlogRepo, mockStatsRepo, 30*time.Minute, time.Minute, clock)
+
+	assert.True(t, counter.Record(blog1, "user:a"))
+	assert.False(t, counter.Record(blog1, "user:a"), "refresh within the window")
+	assert.True(t, counter.Record(blog1, "user:b"))
+	assert.True(t, counter.Record(blog2, "user:a"))
+
+	now = now.Add(30 * time.Minute)
+	assert.True(t, counter.Record(blog1, "user:a"), "the window has passed")
+
+	day := domain.StatsDay(now)
+	mockBlogRepo.On("IncrementViewCounts", ctx, map[string]int{"blog1": 3, "blog2": 1}).Return(nil).Once()
+	mockStatsRepo.On("AddDailyStats", ctx, dailyStats(
+		&domain.DailyBlogStats{BlogID: "blog1", AuthorID: "author1", Day: day, BlogStats: domain.BlogStats{Views: 3, UniqueViewers: 2}},
+		&domain.DailyBlogStats{BlogID: "blog2", AuthorID: "author2", Day: day, BlogStats: domain.BlogStats{Views: 1, UniqueViewers: 1}},
+	)).Return(nil).Once()
+	assert.NoError(t, counter.Flush(ctx))
+	// nothing pending, so the next flush does not touch the repositories
+	assert.NoError(t, counter.Flush(ctx))
+	mockBlogRepo.AssertExpectations(t)
+	mockStatsRepo.AssertExpectations(t)
+}
+
+func TestViewCounter_UniqueViewersPerDay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 23, 50, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	ctx := context.Background()
+	blog := &domain.Blog{ID: "blog1", AuthorID: "author1"}
+
+	mockBlogRepo := new(MockBlogRepository)
+	mockStatsRepo := new(MockBlogStatsRepository)
+	counter := NewViewCounter(mockBlogRepo, mockStatsRepo, 30*time.Minute, time.Minute, clock)
+
+	assert.True(t, counter.Record(blog, "user:a"))
+	now = now.Add(20 * time.Minute)
+	// still within the window, but the first read of the new day
+	assert.False(t, counter.Record(blog, "user:a"))
+
+	mockBlogRepo.On("IncrementViewCounts", ctx, map[string]int{"blog1": 1}).Return(nil).Once()
+	mockStatsRepo.On("AddDailyStats", ctx, dailyStats(
+		&domain.DailyBlogStats{BlogID: "blog1", AuthorID: "author1", Day: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), BlogStats: domain.BlogStats{Views: 1, UniqueViewers: 1}},
+		&domain.DailyBlogStats{BlogID: "blog1", AuthorID: "author1", Day: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), BlogStats: domain.BlogStats{UniqueViewers: 1}},
+	)).Return(nil).Once()
+	assert.NoError(t, counter.Flush(ctx))
+	mockBlogRepo.AssertExpectations(t)
+	mockStatsRepo.AssertExpectations(t)
+}
+
+// partlyFailingRepository stands in for both repositories and fails every write for the blogs in failing,
+// recording the writes it applied
+type partlyFailingRepository struct {
+	MockBlogRepository
+	failing map[string]bool
+	views   map[string]int
+	stats   map[string]domain.BlogStats
+}
+
+func newPartlyFailingRepository(failing ...string) *partlyFailingRepository {
+	r := &partlyFailingRepository{failing: map[string]bool{}, views: map[string]int{}, stats: map[string]domain.BlogStats{}}
+	for _, id := range failing {
+		r.failing[id] = true
+	}
+	return r
+}
+
+func (r *partlyFailingRepository) IncrementViewCounts(ctx context.Context, views map[string]int) error {
+	var failed []string
+	for id, count := range views {
+		if r.failing[id] {
+			failed = append(failed, id)
+			continue
+		}
+		r.views[id] += count
+	}
+	if len(failed) > 0 {
+		return &domain.PartialWriteError[string]{Failed: failed, Err: errors.New("write failed")}
+	}
+	return nil
+}
+
+func (r *partlyFailingRepository) AddDailyStats(ctx context.Context, stats []*domain.DailyBlogStats) error {
+	var failed []int
+	for i, entry := range stats {
+		if r.failing[entry.BlogID] {
+			failed = append(failed, i)
+			continue
+		}
+		total := r.stats[entry.BlogID]
+		total.Add(entry.BlogStats)
+		r.stats[entry.BlogID] = total
+	}
+	if len(failed) > 0 {
+		return &domain.PartialWriteError[int]{Failed: failed, Err: errors.New("write failed")}
+	}
+	return nil
+}
+
+func (r *partlyFailingRepository) BlogStats(ctx context.Context, blogID string, from, to time.Time, granularity domain.StatsGranularity) ([]*domain.StatsBucket, error) {
+	return nil, nil
+}
+
+func (r *partlyFailingRepository) AuthorStats(ctx context.Context, authorID string, from, to time.Time) ([]*domain.PostStats, error) {
+	return nil, nil
+}
+
+func (r *partlyFailingRepository) DeleteBlogStats(ctx context.Context, blogID string) error {
+	return nil
+}
+
+func (r *partlyFailingRepository) UpdateTrendingScores(ctx context.Context, now time.Time, halfLife time.Duration) error {
+	return nil
+}
+
+func TestViewCounter_FlushRetriesOnlyFailedWrites(t *testing.T) {
+	ctx := context.Background()
+	blog1 := &domain.Blog{ID: "blog1", AuthorID: "author1"}
+	blog2 := &domain.Blog{ID: "blog2", AuthorID: "author2"}
+	repo := newPartlyFailingRepository("blog2")
+	counter := NewViewCounter(repo, repo, time.Hour, time.Minute, nil)
+
+	counter.Record(blog1, "user:a")
+	counter.Record(blog2, "user:a")
+	var partial *domain.PartialWriteError[string]
+	assert.ErrorAs(t, counter.Flush(ctx), &partial)
+	assert.Equal(t, map[string]int{"blog1": 1}, repo.views)
+	assert.Equal(t, map[string]domain.BlogStats{"blog1": {Views: 1, UniqueViewers: 1}}, repo.stats)
+
+	// the write that went through is not repeated, the failed one is
+	delete(repo.failing, "blog2")
+	counter.Record(blog2, "user:b")
+	assert.NoError(t, counter.Flush(ctx))
+	assert.Equal(t, map[string]int{"blog1": 1, "blog2": 2}, repo.views)
+	assert.Equal(t, map[string]domain.BlogStats{
+		"blog1": {Views: 1, UniqueViewers: 1},
+		"blog2": {Views: 2, UniqueViewers: 2},
+	}, repo.stats)
+}
+
+func TestViewCounter_FlushDropsFailedBatch(t *testing.T) {
+	ctx := context.Background()
+	blog := &domain.Blog{ID: "blog1", AuthorID: "author1"}
+	mockBlogRepo := new(MockBlogRepository)
+	mockStatsRepo := new(MockBlogStatsRepository)
+	counter := NewViewCounter(mockBlogRepo, mockStatsRepo, time.Hour, time.Minute, nil)
+	day := domain.StatsDay(time.Now())
+
+	counter.Record(blog, "user:a")
+	mockBlogRepo.On("IncrementViewCounts", ctx, map[string]int{"blog1": 1}).Return(errors.New("db down")).Once()
+	mockStatsRepo.On("AddDailyStats", ctx, dailyStats(
+		&domain.DailyBlogStats{BlogID: "blog1", AuthorID: "author1", Day: day, BlogStats: domain.BlogStats{Views: 1, UniqueViewers: 1}},
+	)).Return(errors.New("db down")).Once()
+	assert.Error(t, counter.Flush(ctx))
+
+	// the failed write may have been applied, so its views are not written again
+	counter.Record(blog, "user:b")
+	mockBlogRepo.On("IncrementViewCounts", ctx, map[string]int{"blog1": 1}).Return(nil).Once()
+	mockStatsRepo.On("AddDailyStats", ctx, dailyStats(
+		&domain.DailyBlogStats{BlogID: "blog1", AuthorID: "author1", Day: day, BlogStats: domain.BlogStats{Views: 1, UniqueViewers: 1}},
+	)).Return(nil).Once()
+	assert.NoError(t, counter.Flush(ctx))
+	mockBlogRepo.AssertExpectations(t)
+	mockStatsRepo.AssertExpectations(t)
+}
+
+func TestViewCounter_StartFlushesOnShutdown(t *testing.T) {
+	mockBlogRepo := new(MockBlogRepository)
+	mockStatsRepo := new(MockBlogStatsRepository)
+	counter := NewViewCounter(mockBlogRepo, mockStatsRepo, time.Hour, time.Hour, nil)
+	mockBlogRepo.On("IncrementViewCounts", mock.Anything, map[string]int{"blog1": 1}).Return(nil).Once()
+	mockStatsRepo.On("AddDailyStats", mock.Anything, mock.Anything).Return(nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		counter.Start(ctx)
+		close(done)
+	}()
+	counter.Record(&domain.Blog{ID: "blog1"}, "user:a")
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after cancellation")
+	}
+	mockBlogRepo.AssertExpectations(t)
+	mockStatsRepo.AssertExpectations(t)
+}
+
+func TestViewerKey(t *testing.T) {
+	assert.Equal(t, "user:u1", ViewerKey("u1", "10.0.0.1"))
+
+	anonymous := ViewerKey("", "10.0.0.1")
+	assert.True(t, strings.HasPrefix(anonymous, "ip:"))
+	assert.NotContains(t, anonymous, "10.0.0.1")
+	assert.Equal(t, anonymous, ViewerKey("", "10.0.0.1"))
+	assert.NotEqual(t, anonymous, ViewerKey("", "10.0.0.2"))
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"bytes"
+	"g3-g65-bsp/domain"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// markdown renders GitHub flavoured markdown. Raw HTML is passed through
+// because everything it produces goes through the sanitizer afterwards.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// sanitizer is the allow-list of tags and attributes that may reach a reader's browser
+var sanitizer = newSanitizer()
+
+func newSanitizer() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements(
+		"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6",
+		"blockquote", "pre", "code", "em", "strong", "del", "sub", "sup",
+		"ul", "ol", "li", "table", "thead", "tbody", "tr", "th", "td",
+	)
+	p.AllowAttrs("href", "title").OnElements("a")
+	p.AllowAttrs("src", "alt", "title").OnElements("img")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	p.AllowStandardURLs()
+	p.RequireNoFollowOnLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// SanitizeHTML strips every tag and attribute that is not on the allow-list
+func SanitizeHTML(s string) string {
+	return sanitizer.Sanitize(s)
+}
+
+// RenderContent turns blog or comment content into HTML that is safe to embed as is.
+// Markdown is rendered first; HTML content is only sanitized.
+func RenderContent(content string, format domain.ContentFormat) string {
+	if format == domain.ContentFormatHTML {
+		return SanitizeHTML(content)
+	}
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(content), &buf); err != nil {
+		// the renderer only fails on writer errors, which a buffer never returns
+		return SanitizeHTML(content)
+	}
+	return SanitizeHTML(buf.String())
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"g3-g65-bsp/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderContent_Markdown(t *testing.T) {
+	html := RenderContent("# Title\n\nSome **bold** text and a [link](https://example.com).", domain.ContentFormatMarkdown)
+
+	assert.Contains(t, html, "<h1>Title</h1>")
+	assert.Contains(t, html, "<strong>bold</strong>")
+	assert.Contains(t, html, `<a href="https://example.com" rel="nofollow noopener" target="_blank">link</a>`)
+}
+
+func TestRenderContent_StripsScripts(t *testing.T) {
+	inputs := map[domain.ContentFormat]string{
+		domain.ContentFormatMarkdown: "hello <script>alert(1)</script>\n\n[x](javascript:alert(1))",
+		domain.ContentFormatHTML:     `<p onclick="alert(1)">hello</p><script>alert(1)</script><a href="javascript:alert(1)">x</a>`,
+	}
+	for format, input := range inputs {
+		html := RenderContent(input, format)
+		assert.NotContains(t, html, "<script", format)
+		assert.NotContains(t, html, "javascript:", format)
+		assert.NotContains(t, html, "onclick", format)
+		assert.Contains(t, html, "hello", format)
+	}
+}
+
+func TestRenderContent_HTMLKeepsAllowedTags(t *testing.T) {
+	html := RenderContent(`<p>text <em>emphasis</em></p><pre><code class="language-go">x := 1</code></pre><iframe src="https://example.com"></iframe>`, domain.ContentFormatHTML)
+
+	assert.Equal(t, `<p>text <em>emphasis</em></p><pre><code class="language-go">x := 1</code></pre>`, html)
+}
+
+func TestSanitizeHTML_Images(t *testing.T) {
+	html := SanitizeHTML(`<img src="https://example.com/a.png" alt="a" onerror="alert(1)">`)
+
+	assert.Equal(t, `<img src="https://example.com/a.png" alt="a">`, html)
+}
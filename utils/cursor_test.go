@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"g3-g65-bsp/domain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorCodec_RoundTrip(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	createdAt := time.Date(2025, 3, 1, 12, 30, 0, 123000000, time.UTC)
+	cursors := []*domain.BlogCursor{
+		{SortBy: "created_at", Order: "desc", Value: createdAt, ID: "64b7f0c2a1b2c3d4e5f60718"},
+		{SortBy: "published_at", Order: "desc", Value: createdAt, ID: "64b7f0c2a1b2c3d4e5f60718"},
+		{SortBy: "title", Order: "asc", Value: "Go generics", ID: "64b7f0c2a1b2c3d4e5f60718", Backward: true},
+		{SortBy: "metrics.view_count", Order: "desc", Value: 42, ID: "64b7f0c2a1b2c3d4e5f60718"},
+		{SortBy: "trending_score", Order: "desc", Value: 17.25, ID: "64b7f0c2a1b2c3d4e5f60718"},
+	}
+	for _, cursor := range cursors {
+		token, err := codec.Encode(cursor)
+		assert.NoError(t, err)
+
+		decoded, err := codec.Decode(token)
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	}
+}
+
+func TestCursorCodec_RejectsTampering(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	token, err := codec.Encode(&domain.BlogCursor{SortBy: "title", Order: "asc", Value: "a", ID: "1"})
+	assert.NoError(t, err)
+
+	other, _ := NewCursorCodec("other").Encode(&domain.BlogCursor{SortBy: "title", Order: "asc", Value: "a", ID: "1"})
+	payload, signature, _ := strings.Cut(token, ".")
+	forged := "X" + payload[1:] + "." + signature
+
+	for _, bad := range []string{"", "garbage", forged, other, payload + ".!!"} {
+		_, err := codec.Decode(bad)
+		assert.ErrorIs(t, err, domain.ErrInvalidCursor, bad)
+	}
+}
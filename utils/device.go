@@ -0,0 +1,58 @@
+package utils
+
+import "strings"
+
+// browsers are matched in order, since most browsers also name the ones they are built on; Edge and
+// Opera mention Chrome and Safari, and Chrome mentions Safari
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+}
+
+// platforms are matched in order for the same reason: Android mentions Linux and iOS mentions Mac OS X
+var platforms = []struct{ token, name string }{
+	{"Windows", "Windows"},
+	{"Android", "Android"},
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"CrOS", "ChromeOS"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// DeviceName describes the device behind a User-Agent in a few words, such as "Chrome on macOS",
+// for listing a user's logins. Clients that are not browsers are named by their product, such as
+// "curl"; an empty User-Agent gives "Unknown device".
+func DeviceName(userAgent string) string {
+	browser, platform := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, p := range platforms {
+		if strings.Contains(userAgent, p.token) {
+			platform = p.name
+			break
+		}
+	}
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform + " device"
+	}
+	product, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	if product, _, _ = strings.Cut(product, " "); product == "" {
+		return "Unknown device"
+	}
+	return product
+}
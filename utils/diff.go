@@ -0,0 +1,188 @@
+package utils
+
+import (
+	"g3-g65-bsp/domain"
+	"strings"
+)
+
+// DiffLines returns a line-based diff that turns a into b.
+// It uses Myers' algorithm, so the result is a shortest edit script. Inputs of more than
+// domain.MaxDiffLines lines on either side are refused with domain.ErrDiffTooLarge.
+func DiffLines(a, b string) ([]domain.DiffLine, error) {
+	return DiffSlices(splitLines(a), splitLines(b))
+}
+
+// DiffSlices diffs two lists element by element, e.g. the tags of two revisions.
+func DiffSlices(a, b []string) ([]domain.DiffLine, error) {
+	if len(a) > domain.MaxDiffLines || len(b) > domain.MaxDiffLines {
+		return nil, domain.ErrDiffTooLarge
+	}
+	return diff(a, b), nil
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// differ holds both inputs with every distinct line replaced by a number, so comparing lines is
+// comparing ints
+type differ struct {
+	a, b       []string
+	ids1, ids2 []int
+	lines      []domain.DiffLine
+	// forward and backward frontiers, shared by every bisection
+	v1, v2 []int
+}
+
+func diff(a, b []string) []domain.DiffLine {
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	size := len(a) + len(b) + 3
+	d := &differ{a: a, b: b, ids1: intern(a), ids2: intern(b), v1: make([]int, size), v2: make([]int, size)}
+	d.compare(0, len(a), 0, len(b))
+	return d.lines
+}
+
+// compare appends the edit script turning a[aLo:aHi] into b[bLo:bHi]. It is the linear-space
+// variant of Myers' algorithm: the middle snake splits the problem in two and each half is solved
+// on its own, so memory stays proportional to the input instead of to the edit distance squared.
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.ids1[aLo] == d.ids2[bLo] {
+		d.emit(domain.DiffEqual, d.a[aLo])
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.ids1[aHi-suffix-1] == d.ids2[bHi-suffix-1] {
+		suffix++
+	}
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for _, text := range d.b[bLo:bHi] {
+			d.emit(domain.DiffInsert, text)
+		}
+	case bLo == bHi:
+		for _, text := range d.a[aLo:aHi] {
+			d.emit(domain.DiffDelete, text)
+		}
+	default:
+		if x, y, ok := d.bisect(aLo, aHi, bLo, bHi); ok {
+			d.compare(aLo, x, bLo, y)
+			d.compare(x, aHi, y, bHi)
+		} else {
+			// nothing in common
+			for _, text := range d.a[aLo:aHi] {
+				d.emit(domain.DiffDelete, text)
+			}
+			for _, text := range d.b[bLo:bHi] {
+				d.emit(domain.DiffInsert, text)
+			}
+		}
+	}
+
+	for i := aHi; i < aHi+suffix; i++ {
+		d.emit(domain.DiffEqual, d.a[i])
+	}
+}
+
+func (d *differ) emit(op domain.DiffOp, text string) {
+	d.lines = append(d.lines, domain.DiffLine{Op: op, Text: text})
+}
+
+// bisect walks from both ends of the two ranges at once until the paths meet, and returns where
+// they meet. Both ranges are non-empty and differ in their first and last lines.
+func (d *differ) bisect(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	v1, v2 := d.v1[:2*maxD+2], d.v2[:2*maxD+2]
+	for i := range v1 {
+		v1[i] = -1
+		v2[i] = -1
+	}
+	v1[offset+1] = 0
+	v2[offset+1] = 0
+	delta := n - m
+	// with an odd delta the forward path is the one to meet the reverse path, otherwise the reverse
+	front := delta%2 != 0
+	// diagonals that ran off the edge of the grid are not walked again
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+
+	for step := 0; step < maxD; step++ {
+		for k1 := -step + k1start; k1 <= step-k1end; k1 += 2 {
+			k1Offset := offset + k1
+			var x1 int
+			if k1 == -step || (k1 != step && v1[k1Offset-1] < v1[k1Offset+1]) {
+				x1 = v1[k1Offset+1]
+			} else {
+				x1 = v1[k1Offset-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && d.ids1[aLo+x1] == d.ids2[bLo+y1] {
+				x1++
+				y1++
+			}
+			v1[k1Offset] = x1
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				k2Offset := offset + delta - k1
+				if k2Offset >= 0 && k2Offset < len(v2) && v2[k2Offset] != -1 && x1 >= n-v2[k2Offset] {
+					return aLo + x1, bLo + y1, true
+				}
+			}
+		}
+
+		for k2 := -step + k2start; k2 <= step-k2end; k2 += 2 {
+			k2Offset := offset + k2
+			var x2 int
+			if k2 == -step || (k2 != step && v2[k2Offset-1] < v2[k2Offset+1]) {
+				x2 = v2[k2Offset+1]
+			} else {
+				x2 = v2[k2Offset-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && d.ids1[aHi-x2-1] == d.ids2[bHi-y2-1] {
+				x2++
+				y2++
+			}
+			v2[k2Offset] = x2
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				k1Offset := offset + delta - k2
+				if k1Offset >= 0 && k1Offset < len(v1) && v1[k1Offset] != -1 {
+					x1 := v1[k1Offset]
+					y1 := offset + x1 - k1Offset
+					if x1 >= n-x2 {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"g3-g65-bsp/domain"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	t.Run("identical", func(t *testing.T) {
+		lines, err := DiffLines("a\nb", "a\nb")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.DiffLine{
+			{Op: domain.DiffEqual, Text: "a"},
+			{Op: domain.DiffEqual, Text: "b"},
+		}, lines)
+	})
+
+	t.Run("changed line", func(t *testing.T) {
+		lines, err := DiffLines("one\ntwo\nthree", "one\n2\nthree\nfour")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.DiffLine{
+			{Op: domain.DiffEqual, Text: "one"},
+			{Op: domain.DiffDelete, Text: "two"},
+			{Op: domain.DiffInsert, Text: "2"},
+			{Op: domain.DiffEqual, Text: "three"},
+			{Op: domain.DiffInsert, Text: "four"},
+		}, lines)
+	})
+
+	t.Run("from empty", func(t *testing.T) {
+		lines, err := DiffLines("", "new")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.DiffLine{{Op: domain.DiffInsert, Text: "new"}}, lines)
+	})
+
+	t.Run("to empty", func(t *testing.T) {
+		lines, err := DiffLines("old\r\ntext", "")
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.DiffLine{
+			{Op: domain.DiffDelete, Text: "old"},
+			{Op: domain.DiffDelete, Text: "text"},
+		}, lines)
+	})
+
+	t.Run("too many lines", func(t *testing.T) {
+		long := strings.Repeat("line\n", domain.MaxDiffLines)
+		_, err := DiffLines("short", long)
+		assert.ErrorIs(t, err, domain.ErrDiffTooLarge)
+	})
+
+	t.Run("both empty", func(t *testing.T) {
+		lines, err := DiffLines("", "")
+		assert.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+}
+
+func TestDiffSlices(t *testing.T) {
+	lines, err := DiffSlices([]string{"go", "web"}, []string{"go", "api"})
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.DiffLine{
+		{Op: domain.DiffEqual, Text: "go"},
+		{Op: domain.DiffDelete, Text: "web"},
+		{Op: domain.DiffInsert, Text: "api"},
+	}, lines)
+}
+
+// TestDiffSlices_ShortestEditScript checks on random inputs that the diff rebuilds both sides and
+// keeps as many lines as the longest common subsequence
+func TestDiffSlices_ShortestEditScript(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 500; i++ {
+		a, b := random(), random()
+		lines, err := DiffSlices(a, b)
+		assert.NoError(t, err)
+
+		var from, to []string
+		equal := 0
+		for _, line := range lines {
+			if line.Op != domain.DiffInsert {
+				from = append(from, line.Text)
+			}
+			if line.Op != domain.DiffDelete {
+				to = append(to, line.Text)
+			}
+			if line.Op == domain.DiffEqual {
+				equal++
+			}
+		}
+		assert.Equal(t, strings.Join(a, ""), strings.Join(from, ""))
+		assert.Equal(t, strings.Join(b, ""), strings.Join(to, ""))
+		assert.Equal(t, longestCommonSubsequence(a, b), equal, "a=%v b=%v", a, b)
+	}
+}
+
+func longestCommonSubsequence(a, b []string) int {
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+	return table[0][0]
+}
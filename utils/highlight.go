@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// snippetRadius is how many bytes of context a snippet keeps on each side of a match
+const snippetRadius = 60
+
+// flatten keeps line breaks in the content from breaking up a one-line snippet
+var flatten = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+type span struct {
+	start, end int
+}
+
+// SearchTerms splits a text search query into the lowercase terms it matches on.
+// Negated terms ("-word") are dropped and phrase quotes are ignored, as they are by Mongo's $text.
+func SearchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, word := range strings.FieldsFunc(field, func(r rune) bool { return !isWordRune(r) }) {
+			terms = append(terms, stem(word))
+		}
+	}
+	return terms
+}
+
+// MarkMatches returns the HTML-escaped text with every word matching the query wrapped in <mark>.
+// It returns an empty string when nothing matches.
+func MarkMatches(text, query string) string {
+	matches := matchingWords(text, SearchTerms(query))
+	if len(matches) == 0 {
+		return ""
+	}
+	return renderSnippet(text, 0, len(text), matches)
+}
+
+// Highlight returns up to maxSnippets excerpts of text around the words matching the query,
+// HTML-escaped with the matches wrapped in <mark>. It returns nil when nothing matches.
+func Highlight(text, query string, maxSnippets int) []string {
+	words := wordSpans(text)
+	matches := matchingWords(text, SearchTerms(query))
+
+	var snippets []string
+	for i := 0; i < len(matches) && len(snippets) < maxSnippets; {
+		start := matches[i].start - snippetRadius
+		end := matches[i].end + snippetRadius
+		// matches close to each other share a snippet, up to a few radii long
+		j := i + 1
+		for j < len(matches) && matches[j].start < end && matches[j].end-start < 4*snippetRadius {
+			end = matches[j].end + snippetRadius
+			j++
+		}
+		snippets = append(snippets, renderSnippet(text, snapStart(words, start), snapEnd(words, text, end), matches[i:j]))
+		i = j
+	}
+	return snippets
+}
+
+func matchingWords(text string, terms []string) []span {
+	if len(terms) == 0 {
+		return nil
+	}
+	var matches []span
+	for _, w := range wordSpans(text) {
+		word := strings.ToLower(text[w.start:w.end])
+		for _, term := range terms {
+			if strings.HasPrefix(word, term) {
+				matches = append(matches, w)
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func wordSpans(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		switch {
+		case isWordRune(r) && start < 0:
+			start = i
+		case !isWordRune(r) && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+	return spans
+}
+
+// snapStart moves pos forward to the start of the next whole word
+func snapStart(words []span, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	for _, w := range words {
+		if w.start >= pos {
+			return w.start
+		}
+	}
+	return pos
+}
+
+// snapEnd moves pos back to the end of the previous whole word
+func snapEnd(words []span, text string, pos int) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		if words[i].end <= pos {
+			return words[i].end
+		}
+	}
+	return pos
+}
+
+func renderSnippet(text string, start, end int, matches []span) string {
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, m := range matches {
+		b.WriteString(flatten.Replace(html.EscapeString(text[pos:m.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[m.start:m.end]))
+		b.WriteString("</mark>")
+		pos = m.end
+	}
+	b.WriteString(flatten.Replace(html.EscapeString(text[pos:end])))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// stem trims common English suffixes so that "posts" also highlights "posting",
+// roughly like the stemming Mongo applies to text search terms
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(t, []string{"go", "post", "web", "server"}, SearchTerms(`Go posts -draft "web server"`))
+	assert.Empty(t, SearchTerms("  "))
+}
+
+func TestMarkMatches(t *testing.T) {
+	assert.Equal(t, "Writing <mark>Go</mark> &amp; <mark>posting</mark> it", MarkMatches("Writing Go & posting it", "go posts"))
+	assert.Equal(t, "", MarkMatches("Nothing here", "go"))
+}
+
+func TestHighlight(t *testing.T) {
+	t.Run("snippet around a match", func(t *testing.T) {
+		text := strings.Repeat("filler ", 30) + "the <b>golang</b> runtime\nschedules goroutines " + strings.Repeat("more ", 30)
+		snippets := Highlight(text, "golang", 3)
+
+		assert.Len(t, snippets, 1)
+		assert.True(t, strings.HasPrefix(snippets[0], "…filler"))
+		assert.True(t, strings.HasSuffix(snippets[0], "more…"))
+		assert.Contains(t, snippets[0], "the &lt;b&gt;<mark>golang</mark>&lt;/b&gt; runtime schedules")
+	})
+
+	t.Run("distant matches get their own snippets", func(t *testing.T) {
+		text := "go " + strings.Repeat("filler ", 40) + "go " + strings.Repeat("filler ", 40) + "go"
+		assert.Len(t, Highlight(text, "go", 2), 2)
+		assert.Len(t, Highlight(text, "go", 5), 3)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		assert.Nil(t, Highlight("some text", "missing", 3))
+		assert.Nil(t, Highlight("some text", "", 3))
+	})
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Hello, World!":                "hello-world",
+		"  Go   1.24 -- what's new?  ": "go-1-24-what-s-new",
+		"Crème Brûlée à la carte":      "creme-brulee-a-la-carte",
+		"Straße nach Łódź":             "strasse-nach-lodz",
+		"Привет мир":                   "privet-mir",
+		"Καλημέρα κόσμε":               "kalimera-kosme",
+		"ﬁnal ½ release":               "final-1-2-release",
+		"!!!":                          "",
+		"ሰላም":                          "",
+	}
+	for title, expected := range cases {
+		assert.Equal(t, expected, Slugify(title), title)
+	}
+}
+
+func TestSlugify_TruncatesAtWordBoundary(t *testing.T) {
+	title := strings.Repeat("word ", 30)
+	slug := Slugify(title)
+
+	assert.LessOrEqual(t, len(slug), maxSlugLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "word"))
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"g3-g65-bsp/domain"
@@ -32,3 +34,10 @@ func CreateResetToken(email string, expiryDuration time.Duration) (*domain.Passw
 	}
 	return &newToken, nil
 }
+
+// HashToken returns the hex SHA-256 digest of a bearer token. Only digests are stored, so reading the
+// database does not hand out usable tokens; tokens are random enough that an unkeyed hash suffices.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
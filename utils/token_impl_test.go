@@ -26,3 +26,12 @@ func TestCreateResetToken(t *testing.T) {
 	assert.NotEmpty(t, resetToken.Token)
 	assert.WithinDuration(t, time.Now().Add(24*time.Hour), resetToken.ExpiresAt, time.Second)
 }
+
+func TestHashToken(t *testing.T) {
+	digest := HashToken("reset-token")
+
+	assert.Len(t, digest, 64)
+	assert.Equal(t, digest, HashToken("reset-token"))
+	assert.NotEqual(t, digest, HashToken("reset-token2"))
+	assert.NotContains(t, digest, "reset-token")
+}